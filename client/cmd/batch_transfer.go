@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"log"
+
+	"github.com/quocky/taproot-asset/taproot/address"
+	"github.com/spf13/cobra"
+)
+
+// batchTransferCmd represents the batch-transfer command
+var batchTransferCmd = &cobra.Command{
+	Use:   "batch-transfer <tap-address> [<tap-address>...]",
+	Short: "Send several assets to Taproot Asset addresses in one transaction",
+	Long: `Send the assets requested by one or more Taproot Asset addresses in a
+single anchor transaction. Unlike transferAsset, the addresses may request
+different assets. Addresses sharing an internal key are paid in the same output.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		addrs := make([]*address.Tap, len(args))
+		for idx, arg := range args {
+			addr, err := TaprootClient.DecodeAddress(arg)
+			if err != nil {
+				log.Fatalln("Error decoding address", arg, "err: ", err)
+			}
+
+			addrs[idx] = addr
+		}
+
+		feeRate, err := parseFeeRate(cmd)
+		if err != nil {
+			log.Fatalln("Error parsing fee rate, err: ", err)
+		}
+
+		err = TaprootClient.BatchTransferToAddresses(context.Background(), addrs, feeRate)
+		if err != nil {
+			log.Fatalln("Error transferring assets, err: ", err)
+		}
+
+		log.Println("Assets transferred successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(batchTransferCmd)
+	addFeeRateFlag(batchTransferCmd)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/quocky/taproot-asset/taproot"
+	"github.com/spf13/cobra"
+)
+
+// keystoreCmd represents the keystore command
+var keystoreCmd = &cobra.Command{
+	Use:   "keystore",
+	Short: "Manage the encrypted keystore holding the wallet key",
+	Long: `Create the keystore file at KEYSTORE_PATH, encrypted with
+KEYSTORE_PASSPHRASE. Assets and their anchor outputs are signed with its key.
+Without a keystore the key of the btcwallet sender address is used watch-only,
+btcwallet can't sign assets over RPC, so assets can't be spent.`,
+	// The keystore commands run before the keystore exists, so they don't
+	// create the taproot client.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+}
+
+// keystoreCreateCmd represents the keystore create command
+var keystoreCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a keystore holding a new wallet key",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		privKey, err := btcec.NewPrivateKey()
+		if err != nil {
+			log.Fatalln("Error generating key, err: ", err)
+		}
+
+		createKeystore(privKey)
+	},
+}
+
+// keystoreImportCmd represents the keystore import command
+var keystoreImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create a keystore holding a WIF encoded key read from stdin",
+	Long: `Create a keystore holding a WIF encoded key read from stdin, so the
+assets locked to that key stay spendable.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalln("Error reading key, err: ", err)
+		}
+
+		wif, err := btcutil.DecodeWIF(strings.TrimSpace(line))
+		if err != nil {
+			log.Fatalln("Error decoding key, err: ", err)
+		}
+
+		createKeystore(wif.PrivKey)
+	},
+}
+
+func createKeystore(privKey *btcec.PrivateKey) {
+	path := os.Getenv("KEYSTORE_PATH")
+	if path == "" {
+		log.Fatalln("KEYSTORE_PATH isn't set")
+	}
+
+	err := taproot.CreateKeystore(path, []byte(os.Getenv("KEYSTORE_PASSPHRASE")), privKey)
+	if err != nil {
+		log.Fatalln("Error creating keystore, err: ", err)
+	}
+
+	log.Println("Keystore created at", path)
+}
+
+func init() {
+	rootCmd.AddCommand(keystoreCmd)
+	keystoreCmd.AddCommand(keystoreCreateCmd, keystoreImportCmd)
+}
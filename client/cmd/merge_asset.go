@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// mergeAssetCmd represents the merge-asset command
+var mergeAssetCmd = &cobra.Command{
+	Use:   "merge-asset <asset-id>",
+	Short: "Consolidate all UTXOs of an asset into a single output",
+	Long: `Merge all UTXOs of the asset held by the wallet into a single output
+locked to the wallet key, for example to consolidate dust-sized UTXOs.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		feeRate, err := parseFeeRate(cmd)
+		if err != nil {
+			log.Fatalln("Error parsing fee rate, err: ", err)
+		}
+
+		err = TaprootClient.MergeAssets(context.Background(), args[0], feeRate)
+		if err != nil {
+			log.Fatalln("Error merging asset, err: ", err)
+		}
+
+		log.Println("Asset merged successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeAssetCmd)
+	addFeeRateFlag(mergeAssetCmd)
+}
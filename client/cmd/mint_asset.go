@@ -3,9 +3,11 @@ package cmd
 import (
 	"context"
 	"log"
+	"os"
 	"strconv"
 	"strings"
 
+	"github.com/quocky/taproot-asset/taproot/model/asset"
 	"github.com/spf13/cobra"
 )
 
@@ -18,20 +20,47 @@ var mintAssetCmd = &cobra.Command{
 		log.Println("mintAsset called!")
 
 		names := make([]string, 0)
-		amounts := make([]int32, 0)
+		amounts := make([]uint64, 0)
 
 		for _, arg := range args {
 			tmp := strings.Split(arg, ":")
 			names = append(names, tmp[0])
-			amount, err := strconv.ParseInt(tmp[1], 10, 32)
+			amount, err := strconv.ParseUint(tmp[1], 10, 64)
 			if err != nil {
 				log.Fatalln("Error parsing amount")
 			}
-			amounts = append(amounts, int32(amount))
+			amounts = append(amounts, amount)
 		}
 
+		grouped, err := cmd.Flags().GetBool("group")
+		if err != nil {
+			log.Fatalln("Error parsing group flag, err: ", err)
+		}
+
+		typeName, err := cmd.Flags().GetString("type")
+		if err != nil {
+			log.Fatalln("Error parsing type flag, err: ", err)
+		}
+
+		assetType, err := asset.ParseType(typeName)
+		if err != nil {
+			log.Fatalln("Error parsing asset type, err: ", err)
+		}
+
+		meta, err := parseMeta(cmd)
+		if err != nil {
+			log.Fatalln("Error parsing metadata, err: ", err)
+		}
+
+		feeRate, err := parseFeeRate(cmd)
+		if err != nil {
+			log.Fatalln("Error parsing fee rate flag, err: ", err)
+		}
+
+		log.Printf("Minting %v assets", assetType)
+
 		ctx := context.Background()
-		err := TaprootClient.MintAsset(ctx, names, amounts)
+		err = TaprootClient.MintAsset(ctx, names, amounts, assetType, meta, grouped, feeRate)
 		if err != nil {
 			log.Fatalln("Error minting asset, err: ", err)
 		}
@@ -40,6 +69,78 @@ var mintAssetCmd = &cobra.Command{
 	},
 }
 
+// parseMeta returns the metadata of the assets to mint from the command flags.
+// A metadata file takes precedence over the issuer flags, and nil is returned
+// if neither is set.
+func parseMeta(cmd *cobra.Command) (*asset.MetaReveal, error) {
+	flags := cmd.Flags()
+
+	metaFile, err := flags.GetString("meta-file")
+	if err != nil {
+		return nil, err
+	}
+
+	if metaFile != "" {
+		metaTypeName, err := flags.GetString("meta-type")
+		if err != nil {
+			return nil, err
+		}
+
+		metaType, err := asset.ParseMetaType(metaTypeName)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := os.ReadFile(metaFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return &asset.MetaReveal{
+			Type: metaType,
+			Data: data,
+		}, nil
+	}
+
+	if !flags.Changed("ticker") && !flags.Changed("decimals") &&
+		!flags.Changed("description") {
+
+		return nil, nil
+	}
+
+	var issuerMeta asset.IssuerMeta
+	if issuerMeta.Ticker, err = flags.GetString("ticker"); err != nil {
+		return nil, err
+	}
+
+	if issuerMeta.Decimals, err = flags.GetUint8("decimals"); err != nil {
+		return nil, err
+	}
+
+	if issuerMeta.Description, err = flags.GetString("description"); err != nil {
+		return nil, err
+	}
+
+	return asset.NewIssuerMetaReveal(issuerMeta)
+}
+
 func init() {
 	rootCmd.AddCommand(mintAssetCmd)
+
+	mintAssetCmd.Flags().String("type", asset.Normal.String(), "Type of "+
+		"the assets, either normal or collectible (amount must be 1)")
+	mintAssetCmd.Flags().Bool("group", false, "Issue the assets into the "+
+		"asset group of their name, so that minting the same name again "+
+		"adds to its supply")
+	mintAssetCmd.Flags().String("ticker", "", "Ticker of the assets, "+
+		"committed to in their metadata")
+	mintAssetCmd.Flags().Uint8("decimals", 0, "Number of decimal places "+
+		"of the assets, committed to in their metadata")
+	mintAssetCmd.Flags().String("description", "", "Description of the "+
+		"assets and their issuer, committed to in their metadata")
+	mintAssetCmd.Flags().String("meta-file", "", "File with the raw "+
+		"metadata of the assets, overrides the issuer metadata flags")
+	mintAssetCmd.Flags().String("meta-type", asset.MetaOpaque.String(),
+		"Type of the metadata file, either opaque or json")
+	addFeeRateFlag(mintAssetCmd)
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// newAddrCmd represents the new-addr command
+var newAddrCmd = &cobra.Command{
+	Use:   "new-addr <asset-id> <amount>",
+	Short: "Create a Taproot Asset address to receive an asset",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		amount, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			log.Fatalln("Error parsing amount, err: ", err)
+		}
+
+		addr, err := TaprootClient.NewAddress(args[0], amount)
+		if err != nil {
+			log.Fatalln("Error creating address, err: ", err)
+		}
+
+		encoded, err := addr.EncodeAddress()
+		if err != nil {
+			log.Fatalln("Error encoding address, err: ", err)
+		}
+
+		log.Println("Address created successfully")
+		cmd.Println(encoded)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(newAddrCmd)
+}
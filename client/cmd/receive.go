@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// receiveCmd represents the receive command
+var receiveCmd = &cobra.Command{
+	Use:   "receive <asset-id> <outpoint>",
+	Short: "Fetch, verify and import the proof of a received asset",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshot, err := TaprootClient.ReceiveProof(
+			context.Background(), args[0], args[1],
+		)
+		if err != nil {
+			log.Fatalln("Error receiving proof, err: ", err)
+		}
+
+		log.Println("Proof verified and imported successfully")
+		cmd.Printf("asset %x, amount %d at %v\n", snapshot.Asset.ID(),
+			snapshot.Asset.Amount, snapshot.OutPoint)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(receiveCmd)
+}
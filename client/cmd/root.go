@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/quocky/taproot-asset/taproot"
 	"github.com/quocky/taproot-asset/taproot/address"
@@ -18,6 +20,9 @@ var rootCmd = &cobra.Command{
 	Use:   "",
 	Short: "",
 	Long:  ``,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		initTaprootClient()
+	},
 }
 
 func Execute() {
@@ -32,7 +37,11 @@ func init() {
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
+}
 
+// initTaprootClient creates the taproot client the commands run against. It
+// fails if the keystore can't be unlocked.
+func initTaprootClient() {
 	networkCfg := config.LoadNetworkConfig()
 
 	btcClient, err := onchain.New(networkCfg)
@@ -40,14 +49,67 @@ func init() {
 		log.Fatalf("Error create btc client, err: %s \n", err.Error())
 	}
 
-	wif, err := btcClient.DumpWIF()
+	// The wallet signs the inputs paying the fees.
+	err = btcClient.OpenWallet()
+	if err != nil {
+		log.Fatalf("Error open wallet, err: %s \n", err.Error())
+	}
+
+	signer, err := newSigner(btcClient)
 	if err != nil {
-		log.Fatalf("Error dump wif, err: %s \n", err.Error())
+		log.Fatalf("Error create signer, err: %s \n", err.Error())
 	}
 
 	addressMaker := address.New(networkCfg.ParamsObject)
 
-	TaprootClient = taproot.NewTaproot(btcClient, wif, addressMaker)
+	TaprootClient, err = taproot.NewTaproot(btcClient, signer, addressMaker)
+	if err != nil {
+		log.Fatalf("Error create taproot client, err: %s \n", err.Error())
+	}
 
 	log.Println("Create taproot client success!")
 }
+
+// newSigner returns the signer of the wallet key. The keystore at KEYSTORE_PATH
+// is unlocked with KEYSTORE_PASSPHRASE if KEYSTORE_PATH is set. Otherwise the
+// key of the sender address stays in btcwallet, which can derive the keys of
+// the wallet but can't sign assets and their anchor outputs, so the commands
+// spending assets fail until a keystore is created.
+func newSigner(btcClient onchain.Interface) (taproot.Signer, error) {
+	path := os.Getenv("KEYSTORE_PATH")
+	if path == "" {
+		log.Println("KEYSTORE_PATH isn't set, using the btcwallet key " +
+			"watch-only: assets can't be spent without a keystore")
+
+		return taproot.NewWalletSigner(btcClient)
+	}
+
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("keystore %s not found, create it with "+
+			"the keystore create or keystore import command", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return taproot.OpenKeystore(path, []byte(os.Getenv("KEYSTORE_PASSPHRASE")))
+}
+
+// addFeeRateFlag adds the --fee-rate flag to a command creating an on-chain
+// transaction.
+func addFeeRateFlag(cmd *cobra.Command) {
+	cmd.Flags().Uint64("fee-rate", 0, "Fee rate of the transaction in "+
+		"sat/vB, estimated by the node if 0")
+}
+
+// parseFeeRate returns the fee rate of the --fee-rate flag, zero to estimate
+// it.
+func parseFeeRate(cmd *cobra.Command) (onchain.SatPerKVByte, error) {
+	satPerVByte, err := cmd.Flags().GetUint64("fee-rate")
+	if err != nil {
+		return 0, err
+	}
+
+	return onchain.SatPerVByte(satPerVByte), nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/quocky/taproot-asset/taproot/address"
+	"github.com/quocky/taproot-asset/taproot/model/packet"
+	"github.com/quocky/taproot-asset/taproot/onchain"
+	"github.com/spf13/cobra"
+)
+
+// transferCmd represents the transfer command
+var transferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "Fund, sign and finalize a transfer in separate steps",
+	Long: `Build a transfer to Taproot Asset addresses as a packet file holding
+the PSBT of the anchor transaction and the virtual packet of the assets. The
+file is funded once, signed by every holder of a key it spends, for example on
+an offline machine, and finalized to broadcast the transfer.`,
+}
+
+// transferFundCmd represents the transfer fund command
+var transferFundCmd = &cobra.Command{
+	Use:   "fund --out <file> <tap-address> [<tap-address>...]",
+	Short: "Fund a transfer to Taproot Asset addresses without signing it",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		out, err := cmd.Flags().GetString("out")
+		if err != nil {
+			log.Fatalln("Error parsing out flag, err: ", err)
+		}
+
+		addrs := make([]*address.Tap, len(args))
+		for idx, arg := range args {
+			addr, err := TaprootClient.DecodeAddress(arg)
+			if err != nil {
+				log.Fatalln("Error decoding address", arg, "err: ", err)
+			}
+
+			addrs[idx] = addr
+		}
+
+		feeRate, err := parseFeeRate(cmd)
+		if err != nil {
+			log.Fatalln("Error parsing fee rate, err: ", err)
+		}
+
+		p, err := TaprootClient.FundTransfer(context.Background(), addrs, feeRate)
+		if err != nil {
+			log.Fatalln("Error funding transfer, err: ", err)
+		}
+
+		writePacket(out, p)
+
+		log.Println("Transfer funded, packet written to", out)
+	},
+}
+
+// transferSignCmd represents the transfer sign command
+var transferSignCmd = &cobra.Command{
+	Use:   "sign <file>",
+	Short: "Sign the inputs of a funded transfer held by the wallet key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := readPacket(args[0])
+
+		if err := TaprootClient.SignTransfer(context.Background(), p); err != nil {
+			log.Fatalln("Error signing transfer, err: ", err)
+		}
+
+		writePacket(args[0], p)
+
+		log.Println("Transfer signed, packet written to", args[0])
+	},
+}
+
+// transferFinalizeCmd represents the transfer finalize command
+var transferFinalizeCmd = &cobra.Command{
+	Use:   "finalize <file>",
+	Short: "Finalize a signed transfer and broadcast it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := readPacket(args[0])
+
+		maxFeeRate, err := cmd.Flags().GetUint64("max-fee-rate")
+		if err != nil {
+			log.Fatalln("Error parsing max fee rate, err: ", err)
+		}
+
+		req, err := TaprootClient.FinalizeTransfer(context.Background(), p, onchain.SatPerVByte(maxFeeRate))
+		if err != nil {
+			log.Fatalln("Error finalizing transfer, err: ", err)
+		}
+
+		if err := TaprootClient.PublishTransfer(context.Background(), req); err != nil {
+			log.Fatalln("Error publishing transfer, err: ", err)
+		}
+
+		log.Println("Assets transferred successfully")
+	},
+}
+
+func readPacket(path string) *packet.Packet {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalln("Error reading packet, err: ", err)
+	}
+
+	var p packet.Packet
+	if err := p.Decode(blob); err != nil {
+		log.Fatalln("Error decoding packet, err: ", err)
+	}
+
+	return &p
+}
+
+func writePacket(path string, p *packet.Packet) {
+	blob, err := p.Encode()
+	if err != nil {
+		log.Fatalln("Error encoding packet, err: ", err)
+	}
+
+	if err := os.WriteFile(path, blob, 0600); err != nil {
+		log.Fatalln("Error writing packet, err: ", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(transferCmd)
+	transferCmd.AddCommand(transferFundCmd, transferSignCmd, transferFinalizeCmd)
+
+	transferFundCmd.Flags().String("out", "transfer.packet", "File the funded packet is written to")
+	addFeeRateFlag(transferFundCmd)
+
+	transferFinalizeCmd.Flags().Uint64("max-fee-rate", 0, "Highest fee rate "+
+		"of the transaction in sat/vB, twice the node's estimate if 0")
+}
@@ -4,50 +4,42 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"encoding/hex"
 	"fmt"
 
-	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/address"
 	"github.com/spf13/cobra"
 )
 
 // transferAssetCmd represents the transferAsset command
 var transferAssetCmd = &cobra.Command{
-	Use:   "transferAsset",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Use:   "transferAsset <tap-address> [<tap-address>...]",
+	Short: "Send assets to Taproot Asset addresses",
+	Long: `Send assets to one or more Taproot Asset addresses created by the
+receivers with new-addr. All addresses must request the same asset.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("transferAsset called")
 
-		// TODO:
-		// - Get pubkey
-		// - List all available assets (name, amount, assetID) - API
+		addrs := make([]*address.Tap, len(args))
+		for idx, arg := range args {
+			addr, err := TaprootClient.DecodeAddress(arg)
+			if err != nil {
+				fmt.Println("Error decode address", arg, err)
 
-		// 03bcbc720d1fba2172fd413e28e778ec3a6cc640629990f97428f8beb50060faf4
-		receiverPubKeyStr := "02498ecf86fb261f380e469524538b9b536a9eb1daa763001a1ddaec7b71279271"
-		receiverPubKey, err := hex.DecodeString(receiverPubKeyStr)
-		if err != nil {
-			fmt.Println("Error decode receiver public key", err)
+				return
+			}
 
-			return
+			addrs[idx] = addr
 		}
 
-		rcvByte := [33]byte(receiverPubKey)
+		feeRate, err := parseFeeRate(cmd)
+		if err != nil {
+			fmt.Println("Error parsing fee rate", err)
 
-		rcvSerializedKey := make([]asset.SerializedKey, 2)
-		rcvSerializedKey[0] = rcvByte
-		rcvSerializedKey[1] = rcvByte
+			return
+		}
 
-		err = TaprootClient.TransferAsset(
-			rcvSerializedKey,
-			"c389835fa96c3bf76004944bac586c618d894f7089e839c328800c322721f53f",
-			[]int32{3, 5},
-		)
+		err = TaprootClient.TransferAssetToAddresses(addrs, feeRate)
 		if err != nil {
 			fmt.Println("Error transfer asset", err)
 
@@ -58,6 +50,7 @@ to quickly create a Cobra application.`,
 
 func init() {
 	rootCmd.AddCommand(transferAssetCmd)
+	addFeeRateFlag(transferAssetCmd)
 
 	// Here you will define your flags and configuration settings.
 
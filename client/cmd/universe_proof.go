@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"log"
+
+	universesdk "github.com/quocky/taproot-asset/taproot/http_model/universe"
+	"github.com/quocky/taproot-asset/taproot/universe"
+	"github.com/spf13/cobra"
+)
+
+// universeProofCmd represents the universe-proof command
+var universeProofCmd = &cobra.Command{
+	Use:   "universe-proof <asset-id> <issuance|transfer> <outpoint> <script-key>",
+	Short: "Verify that an asset is included in the universe of the server",
+	Args:  cobra.ExactArgs(4),
+	Run: func(cmd *cobra.Command, args []string) {
+		treeType, err := universe.ParseTreeType(args[1])
+		if err != nil {
+			log.Fatalln("Error parsing tree type, err: ", err)
+		}
+
+		key, err := universesdk.LeafKey{
+			OutPoint:  args[2],
+			ScriptKey: args[3],
+		}.UniverseLeafKey()
+		if err != nil {
+			log.Fatalln("Error parsing leaf key, err: ", err)
+		}
+
+		leaf, root, err := TaprootClient.VerifyUniverseLeaf(
+			context.Background(), args[0], treeType, key,
+		)
+		if err != nil {
+			log.Fatalln("Error verifying universe leaf, err: ", err)
+		}
+
+		log.Println("Universe leaf verified successfully")
+		cmd.Printf("amount %d, %s root %s (sum %d)\n", leaf.Amount,
+			treeType, root.Hash, root.Sum)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(universeProofCmd)
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -11,16 +13,31 @@ import (
 	"github.com/quocky/taproot-asset/server/config/core"
 	"github.com/quocky/taproot-asset/server/internal/core/api"
 	v1 "github.com/quocky/taproot-asset/server/internal/core/api/v1"
+	assetoutpointD "github.com/quocky/taproot-asset/server/internal/domain/asset_outpoint"
+	chaintxD "github.com/quocky/taproot-asset/server/internal/domain/chain_tx"
+	"github.com/quocky/taproot-asset/server/internal/domain/genesis"
+	genesisassetD "github.com/quocky/taproot-asset/server/internal/domain/genesis_asset"
+	manageutxoD "github.com/quocky/taproot-asset/server/internal/domain/manage_utxo"
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
 	assetoutpoint "github.com/quocky/taproot-asset/server/internal/repo/asset_outpoint"
 	chaintx "github.com/quocky/taproot-asset/server/internal/repo/chain_tx"
 	genesisasset "github.com/quocky/taproot-asset/server/internal/repo/genesis_asset"
 	genesispoint "github.com/quocky/taproot-asset/server/internal/repo/genesis_point"
 	manageutxo "github.com/quocky/taproot-asset/server/internal/repo/manage_utxo"
+	mssmtstore "github.com/quocky/taproot-asset/server/internal/repo/mssmt_store"
+	proofarchive "github.com/quocky/taproot-asset/server/internal/repo/proof_archive"
+	universeleaf "github.com/quocky/taproot-asset/server/internal/repo/universe_leaf"
+	chaintxU "github.com/quocky/taproot-asset/server/internal/usecase/chain_tx"
 	mintU "github.com/quocky/taproot-asset/server/internal/usecase/mint"
 	transferU "github.com/quocky/taproot-asset/server/internal/usecase/transfer"
+	universeU "github.com/quocky/taproot-asset/server/internal/usecase/universe"
 	utxoU "github.com/quocky/taproot-asset/server/internal/usecase/utxo"
+	"github.com/quocky/taproot-asset/server/pkg/chain"
 	"github.com/quocky/taproot-asset/server/pkg/database"
 	"github.com/quocky/taproot-asset/server/pkg/logger"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func main() {
@@ -28,39 +45,269 @@ func main() {
 
 	cfg := config.NewConfig()
 
-	db, err := database.NewMongoDatabase(cfg)
+	repos, err := NewRepos(cfg)
 	if err != nil {
 		panic(err)
 	}
 
-	rpcClient, err := NewRPCClient()
+	blockNotifier := chain.NewBlockNotifier()
+
+	rpcClient, err := NewRPCClient(blockNotifier.Handlers())
+	if err != nil {
+		panic(err)
+	}
+
+	archiver, err := NewProofArchiver(cfg, repos.Mongo, "", proofarchive.ProofsBucket)
 	if err != nil {
 		panic(err)
 	}
 
+	deliveryArchiver, err := NewProofArchiver(cfg, repos.Mongo, deliveriesDir, proofarchive.DeliveriesBucket)
+	if err != nil {
+		panic(err)
+	}
+
+	numImported, skipped, err := proof.ImportLocatorDir(context.Background(), legacyLocatorDir, archiver)
+	if err != nil {
+		panic(fmt.Errorf("import legacy proof files from %s: %w", legacyLocatorDir, err))
+	}
+
+	for _, f := range skipped {
+		logger.Errorw("Skipped invalid legacy proof file", "file", f.Name, "err", f.Err)
+	}
+
+	logger.Infow("Imported legacy proof files", "num_files", numImported, "num_skipped", len(skipped))
+
 	router := NewServer()
 
-	// repo
-	genesisAssetRepo := genesisasset.NewRepoMongo(db)
-	assetOutpointRepo := assetoutpoint.NewRepoMongo(db)
-	chainTxRepo := chaintx.NewRepoMongo(db)
-	genesisPointRepo := genesispoint.NewRepoMongo(db)
-	manageUtxoRepo := manageutxo.NewRepoMongo(db)
+	newTreeStore, err := NewTreeStoreFactory(cfg, repos)
+	if err != nil {
+		panic(err)
+	}
 
 	// use case
-	mintUseCase := mintU.NewUseCase(genesisAssetRepo, assetOutpointRepo, chainTxRepo, genesisPointRepo, manageUtxoRepo, rpcClient)
-	utxoUseCase := utxoU.NewUseCase(genesisAssetRepo, assetOutpointRepo, genesisPointRepo)
-	transferUseCase := transferU.NewUseCase(assetOutpointRepo, chainTxRepo, manageUtxoRepo, rpcClient)
+	universeUseCase := universeU.NewUseCase(repos.UniverseLeaf, archiver, newTreeStore)
+	chainTxUseCase := chaintxU.NewUseCase(repos.ChainTx, repos.ManageUtxo, repos.AssetOutpoint, universeUseCase, rpcClient)
+	chainWatcher := chaintxU.NewWatcher(repos.ChainTx, chainTxUseCase, repos.ManageUtxo, repos.AssetOutpoint, rpcClient, blockNotifier, archiver, universeUseCase)
+	mintUseCase := mintU.NewUseCase(repos.GenesisAsset, repos.AssetOutpoint, chainTxUseCase, repos.GenesisPoint, repos.ManageUtxo, rpcClient, archiver)
+	utxoUseCase := utxoU.NewUseCase(repos.GenesisAsset, repos.AssetOutpoint, repos.GenesisPoint, repos.ChainTx, archiver)
+	transferUseCase := transferU.NewUseCase(repos.GenesisAsset, repos.AssetOutpoint, chainTxUseCase, repos.ManageUtxo, rpcClient, archiver)
+	universeSyncUseCase := universeU.NewSyncUseCase(universeUseCase, archiver, proof.NewRPCHeaderVerifier(rpcClient))
+
+	// "sync [peer-url...]" syncs the universe once with the given or the
+	// configured peers and exits.
+	if len(os.Args) > 1 && os.Args[1] == "sync" {
+		peers := os.Args[2:]
+		if len(peers) == 0 {
+			peers = cfg.UniverseSync.Peers
+		}
+
+		if err := syncUniverseOnce(universeSyncUseCase, peers); err != nil {
+			panic(err)
+		}
+
+		return
+	}
+
+	if err := rpcClient.NotifyBlocks(); err != nil {
+		panic(err)
+	}
+
+	go chainWatcher.Run(context.Background())
+
+	if cfg.UniverseSync.Interval > 0 && len(cfg.UniverseSync.Peers) > 0 {
+		go universeSyncUseCase.Run(context.Background(), cfg.UniverseSync.Peers, cfg.UniverseSync.Interval)
+	}
 
 	// controller
 	mintController := v1.NewMintController(mintUseCase, utxoUseCase, transferUseCase)
+	proofCourier := proof.NewArchiveCourier(archiver, deliveryArchiver, proof.NewRPCHeaderVerifier(rpcClient))
+	proofController := v1.NewProofController(proofCourier)
+	universeController := v1.NewUniverseController(universeUseCase)
+	chainTxController := v1.NewChainTxController(chainTxUseCase)
 
 	// register routes
-	api.RegisterRoutes(router, mintController)
+	api.RegisterRoutes(router, mintController, proofController, universeController, chainTxController)
 
 	router.Run()
 }
 
+// Repos are the repositories on the configured database backend. Either
+// Mongo or SQL is set, the database the repositories are on.
+type Repos struct {
+	GenesisAsset  genesisassetD.RepoInterface
+	AssetOutpoint assetoutpointD.RepoInterface
+	ChainTx       chaintxD.RepoInterface
+	GenesisPoint  genesis.RepoInterface
+	ManageUtxo    manageutxoD.RepoInterface
+	UniverseLeaf  universe.RepoInterface
+
+	Mongo *mongo.Database
+	SQL   *database.SQLDatabase
+}
+
+// NewRepos connects to the database of the configured backend and creates
+// the repositories on it.
+func NewRepos(cfg *config.Config) (*Repos, error) {
+	switch cfg.Database.Backend {
+	case "mongo":
+		db, err := database.NewMongoDatabase(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		numMigratedAmounts, err := database.MigrateAmounts(context.Background(), db)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Infow("Migrated asset amounts", "num_docs", numMigratedAmounts)
+
+		return &Repos{
+			GenesisAsset:  genesisasset.NewRepoMongo(db),
+			AssetOutpoint: assetoutpoint.NewRepoMongo(db),
+			ChainTx:       chaintx.NewRepoMongo(db),
+			GenesisPoint:  genesispoint.NewRepoMongo(db),
+			ManageUtxo:    manageutxo.NewRepoMongo(db),
+			UniverseLeaf:  universeleaf.NewRepoMongo(db),
+			Mongo:         db,
+		}, nil
+
+	case string(database.SQLite), string(database.Postgres):
+		db, err := database.NewSQLDatabase(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Repos{
+			GenesisAsset:  genesisasset.NewRepoSQL(db),
+			AssetOutpoint: assetoutpoint.NewRepoSQL(db),
+			ChainTx:       chaintx.NewRepoSQL(db),
+			GenesisPoint:  genesispoint.NewRepoSQL(db),
+			ManageUtxo:    manageutxo.NewRepoSQL(db),
+			UniverseLeaf:  universeleaf.NewRepoSQL(db),
+			SQL:           db,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown database backend %q", cfg.Database.Backend)
+	}
+}
+
+// legacyLocatorDir is the directory proof files were written to before they
+// were kept in an archive.
+const legacyLocatorDir = "./locator"
+
+// deliveriesDir is the directory below the proof archive directory the proof
+// files delivered to the proof courier are kept in.
+const deliveriesDir = "deliveries"
+
+// NewProofArchiver creates a proof archive of the configured backend, kept in
+// the sub directory dir of the archive directory or in the GridFS bucket.
+func NewProofArchiver(cfg *config.Config, db *mongo.Database, dir, bucket string) (proof.Archiver, error) {
+	switch cfg.ProofArchive.Backend {
+	case "file":
+		return proof.NewFileArchiver(filepath.Join(cfg.ProofArchive.Dir, dir))
+
+	case "gridfs":
+		if db == nil {
+			return nil, errors.New("the gridfs proof archive requires the mongo database backend")
+		}
+
+		return proofarchive.NewRepoGridFS(db, bucket)
+
+	case "memory":
+		return proof.NewMemArchiver(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown proof archive backend %q", cfg.ProofArchive.Backend)
+	}
+}
+
+// syncUniverseOnce syncs the universe with each of the peers.
+func syncUniverseOnce(syncUseCase universe.SyncUseCaseInterface, peers []string) error {
+	if len(peers) == 0 {
+		return errors.New("no universe sync peers given")
+	}
+
+	for _, peer := range peers {
+		stats, err := syncUseCase.SyncPeer(context.Background(), peer)
+		if err != nil {
+			return fmt.Errorf("sync with %s: %w", peer, err)
+		}
+
+		logger.Infow("Synced universe", "peer", peer, "num_assets", stats.NumAssets, "num_issuance_leaves", stats.NumIssuanceLeaves, "num_transfer_leaves", stats.NumTransferLeaves)
+	}
+
+	return nil
+}
+
+// NewTreeStoreFactory returns the factory of the universe tree stores of the
+// configured driver, by default the driver of the database backend. The
+// in-memory trees are rebuilt from the recorded universe leaves after a
+// restart.
+func NewTreeStoreFactory(cfg *config.Config, repos *Repos) (universeU.TreeStoreFactory, error) {
+	var (
+		driverName = cfg.TreeStore.Driver
+		db         any
+	)
+
+	if driverName == "" {
+		driverName = mssmtstore.MongoDriverName
+		if repos.SQL != nil {
+			driverName = mssmtstore.SQLDriverName
+		}
+	}
+
+	switch driverName {
+	case mssmtstore.MongoDriverName:
+		if repos.Mongo == nil {
+			return nil, errors.New("the mongo tree store requires the mongo database backend")
+		}
+
+		if err := mssmtstore.EnsureIndexes(context.Background(), repos.Mongo); err != nil {
+			return nil, err
+		}
+
+		db = repos.Mongo
+
+	case mssmtstore.SQLDriverName:
+		if repos.SQL == nil {
+			return nil, errors.New("the sql tree store requires a sql database backend")
+		}
+
+		db = repos.SQL
+
+	case "memory":
+		return func(string) (mssmt.TreeStore, error) {
+			return mssmt.NewDefaultStore(), nil
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown tree store driver %q", driverName)
+	}
+
+	driver, err := findTreeStoreDriver(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(namespace string) (mssmt.TreeStore, error) {
+		return driver.New(db, namespace)
+	}, nil
+}
+
+// findTreeStoreDriver returns the registered tree store driver with the name.
+func findTreeStoreDriver(name string) (*mssmt.TreeStoreDriver, error) {
+	for _, driver := range mssmt.RegisteredTreeStores() {
+		if driver.Name == name {
+			return driver, nil
+		}
+	}
+
+	return nil, fmt.Errorf("tree store driver %q is not registered", name)
+}
+
 func NewServer() *gin.Engine {
 	engine := gin.New()
 	engine.Use(gin.Logger(), gin.Recovery())
@@ -68,7 +315,9 @@ func NewServer() *gin.Engine {
 	return engine
 }
 
-func NewRPCClient() (*rpcclient.Client, error) {
+// NewRPCClient connects to the node over a websocket, which delivers its
+// notifications to the handlers.
+func NewRPCClient(handlers *rpcclient.NotificationHandlers) (*rpcclient.Client, error) {
 	certPath := filepath.Join(btcutil.AppDataDir("btcd", false), "rpc.cert")
 
 	cert, err := os.ReadFile(certPath)
@@ -84,7 +333,7 @@ func NewRPCClient() (*rpcclient.Client, error) {
 		Certificates: cert,
 	}
 
-	rpcClient, err := rpcclient.New(rpcCfg, nil)
+	rpcClient, err := rpcclient.New(rpcCfg, handlers)
 	if err != nil {
 		return nil, errors.New("create RPC client fail, " + err.Error())
 	}
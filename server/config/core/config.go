@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 )
@@ -9,7 +11,11 @@ type (
 	Config struct {
 		Env string `env-required:"true" env:"ENV"`
 		Network
+		Database
 		Mongo
+		ProofArchive
+		TreeStore
+		UniverseSync
 	}
 
 	Network struct {
@@ -20,9 +26,39 @@ type (
 		SenderAddrTest   string `env:"SENDER_ADDR_TEST_CONFIG"`
 	}
 
+	// Database selects the database backend of the repositories, one of
+	// "mongo", "sqlite" or "postgres". DSN is the data source name of the
+	// SQL backends, for sqlite the path of the database file.
+	Database struct {
+		Backend string `env:"DB_BACKEND" env-default:"mongo"`
+		DSN     string `env:"DB_DSN" env-default:"file:taproot.db"`
+	}
+
+	// Mongo is required if the database backend is "mongo".
 	Mongo struct {
-		ConnURI string `env-required:"true" env:"MONGO_CONN_URI"`
-		DBName  string `env-required:"true" env:"MONGO_DB_NAME"`
+		ConnURI string `env:"MONGO_CONN_URI"`
+		DBName  string `env:"MONGO_DB_NAME"`
+	}
+
+	// ProofArchive selects where proof files are archived. Backend is one
+	// of "file", "gridfs" or "memory".
+	ProofArchive struct {
+		Backend string `env:"PROOF_ARCHIVE_BACKEND" env-default:"file"`
+		Dir     string `env:"PROOF_ARCHIVE_DIR" env-default:"./proofs"`
+	}
+
+	// TreeStore selects the mssmt.TreeStore driver the universe trees are
+	// kept in, "mongo", "sql" or "memory". If empty, the trees are kept in
+	// the database of the repositories.
+	TreeStore struct {
+		Driver string `env:"TREE_STORE_DRIVER"`
+	}
+
+	// UniverseSync configures the sync of the universe trees with peer
+	// servers. The periodic sync is disabled if Interval is zero.
+	UniverseSync struct {
+		Peers    []string      `env:"UNIVERSE_SYNC_PEERS" env-separator:","`
+		Interval time.Duration `env:"UNIVERSE_SYNC_INTERVAL" env-default:"0"`
 	}
 )
 
@@ -35,7 +71,6 @@ func NewConfig() *Config {
 	err := cleanenv.ReadEnv(cfg)
 	if err != nil {
 		panic(err)
-		return nil
 	}
 
 	return cfg
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gin-gonic/gin"
+	"github.com/quocky/taproot-asset/server/internal/core/api"
+	chaintx "github.com/quocky/taproot-asset/server/internal/domain/chain_tx"
+)
+
+// ChainTxController serves the confirmation status of the anchor
+// transactions of mints and transfers.
+type ChainTxController struct {
+	chainTxUseCase chaintx.UseCaseInterface
+}
+
+func (c *ChainTxController) RegisterRoutes(route gin.IRoutes) {
+	route.GET("/chain/txs/:tx_hash", c.Status)
+}
+
+func (c *ChainTxController) Status(g *gin.Context) {
+	txHash, err := chainhash.NewHashFromStr(g.Param("tx_hash"))
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+
+		return
+	}
+
+	status, err := c.chainTxUseCase.Status(g, *txHash)
+	if errors.Is(err, chaintx.ErrChainTxNotFound) {
+		g.JSON(http.StatusNotFound, gin.H{
+			"message": err.Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		g.JSON(http.StatusInternalServerError, nil)
+
+		return
+	}
+
+	g.JSON(http.StatusOK, status)
+}
+
+func NewChainTxController(chainTxUseCase chaintx.UseCaseInterface) api.ControllerInterface {
+	return &ChainTxController{
+		chainTxUseCase: chainTxUseCase,
+	}
+}
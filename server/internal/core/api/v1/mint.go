@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -25,6 +26,7 @@ func (c *MintController) RegisterRoutes(route gin.IRoutes) {
 	route.POST("/asset", c.ListAssetsByPubKey)
 	route.POST("/unspent-asset-id", c.UnspentAssetsByID)
 	route.POST("/transfer-asset", c.TransferAsset)
+	route.GET("/assets/:id/meta", c.AssetMeta)
 }
 
 func (c *MintController) MintAsset(g *gin.Context) {
@@ -84,7 +86,6 @@ func (c *MintController) TransferAsset(g *gin.Context) {
 
 	err := c.transferUseCase.TransferAsset(
 		g,
-		req.GenesisAsset,
 		req.AnchorTx,
 		req.AmtSats,
 		req.BtcOutputInfos,
@@ -100,6 +101,33 @@ func (c *MintController) TransferAsset(g *gin.Context) {
 	g.JSON(http.StatusNoContent, nil)
 }
 
+func (c *MintController) AssetMeta(g *gin.Context) {
+	meta, err := c.utxoUseCase.GetAssetMeta(g, g.Param("id"))
+	if errors.Is(err, utxoasset.ErrInvalidAssetID) {
+		g.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+
+		return
+	}
+
+	if errors.Is(err, utxoasset.ErrAssetMetaNotFound) {
+		g.JSON(http.StatusNotFound, gin.H{
+			"message": err.Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		g.JSON(http.StatusInternalServerError, nil)
+
+		return
+	}
+
+	g.JSON(http.StatusOK, meta)
+}
+
 func (c *MintController) ListAssetsByPubKey(g *gin.Context) {
 	var req utxoassetmodel.ListAssetReq
 	if err := g.ShouldBindJSON(&req); err != nil {
@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quocky/taproot-asset/server/internal/core/api"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	"github.com/quocky/taproot-asset/taproot/http_model/courier"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+)
+
+// ProofController serves the proof files of transferred assets, so receivers
+// can fetch and verify them.
+type ProofController struct {
+	courier proof.ProofCourier
+}
+
+func (c *ProofController) RegisterRoutes(route gin.IRoutes) {
+	route.POST("/proofs", c.DeliverProof)
+	route.GET("/proofs", c.ReceiveProof)
+}
+
+func (c *ProofController) DeliverProof(g *gin.Context) {
+	var req courier.DeliverProofReq
+	if err := g.ShouldBindJSON(&req); err != nil || req.File == nil {
+		g.JSON(http.StatusBadRequest, nil)
+
+		return
+	}
+
+	loc, err := req.Locator.ProofLocator()
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+
+		return
+	}
+
+	err = c.courier.DeliverProof(g, loc, req.File)
+	if errors.Is(err, proof.ErrInvalidDelivery) {
+		g.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		logger.Errorw("deliver proof fail", "err", err)
+
+		g.JSON(http.StatusInternalServerError, nil)
+
+		return
+	}
+
+	g.JSON(http.StatusNoContent, nil)
+}
+
+func (c *ProofController) ReceiveProof(g *gin.Context) {
+	var req courier.Locator
+	if err := g.ShouldBindQuery(&req); err != nil {
+		g.JSON(http.StatusBadRequest, nil)
+
+		return
+	}
+
+	loc, err := req.ProofLocator()
+	if err != nil {
+		g.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+
+		return
+	}
+
+	file, err := c.courier.ReceiveProof(g, loc)
+	if errors.Is(err, proof.ErrProofNotFound) {
+		g.JSON(http.StatusNotFound, gin.H{
+			"message": err.Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		logger.Errorw("receive proof fail", "err", err)
+
+		g.JSON(http.StatusInternalServerError, nil)
+
+		return
+	}
+
+	g.JSON(http.StatusOK, courier.ReceiveProofResp{
+		File: file,
+	})
+}
+
+func NewProofController(courier proof.ProofCourier) api.ControllerInterface {
+	return &ProofController{
+		courier: courier,
+	}
+}
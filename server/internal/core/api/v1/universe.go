@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quocky/taproot-asset/server/internal/core/api"
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	universesdk "github.com/quocky/taproot-asset/taproot/http_model/universe"
+	universemodel "github.com/quocky/taproot-asset/taproot/universe"
+)
+
+// UniverseController serves the universe trees of the issuance and transfer
+// proofs of each asset.
+type UniverseController struct {
+	universeUseCase universe.UseCaseInterface
+}
+
+func (c *UniverseController) RegisterRoutes(route gin.IRoutes) {
+	route.GET("/universe/roots", c.AllRoots)
+	route.GET("/universe/roots/:asset_id", c.Roots)
+	route.GET("/universe/leaves/:asset_id/:tree_type", c.LeafKeys)
+	route.GET("/universe/leaf/:asset_id/:tree_type", c.Leaf)
+	route.GET("/universe/proof/:asset_id/:tree_type", c.InclusionProof)
+}
+
+func (c *UniverseController) Roots(g *gin.Context) {
+	roots, err := c.universeUseCase.Roots(g, g.Param("asset_id"))
+	if err != nil {
+		universeError(g, err)
+
+		return
+	}
+
+	g.JSON(http.StatusOK, roots)
+}
+
+func (c *UniverseController) AllRoots(g *gin.Context) {
+	roots, err := c.universeUseCase.AllRoots(g)
+	if err != nil {
+		universeError(g, err)
+
+		return
+	}
+
+	g.JSON(http.StatusOK, roots)
+}
+
+func (c *UniverseController) LeafKeys(g *gin.Context) {
+	treeType, err := universemodel.ParseTreeType(g.Param("tree_type"))
+	if err != nil {
+		universeError(g, err)
+
+		return
+	}
+
+	keys, err := c.universeUseCase.LeafKeys(g, g.Param("asset_id"), treeType)
+	if err != nil {
+		universeError(g, err)
+
+		return
+	}
+
+	g.JSON(http.StatusOK, keys)
+}
+
+func (c *UniverseController) Leaf(g *gin.Context) {
+	treeType, key, err := parseUniverseLeafReq(g)
+	if err != nil {
+		universeError(g, err)
+
+		return
+	}
+
+	leaf, err := c.universeUseCase.Leaf(g, g.Param("asset_id"), treeType, key)
+	if err != nil {
+		universeError(g, err)
+
+		return
+	}
+
+	g.JSON(http.StatusOK, leaf)
+}
+
+func (c *UniverseController) InclusionProof(g *gin.Context) {
+	treeType, key, err := parseUniverseLeafReq(g)
+	if err != nil {
+		universeError(g, err)
+
+		return
+	}
+
+	inclusionProof, err := c.universeUseCase.InclusionProof(g, g.Param("asset_id"), treeType, key)
+	if err != nil {
+		universeError(g, err)
+
+		return
+	}
+
+	g.JSON(http.StatusOK, inclusionProof)
+}
+
+// errBadUniverseRequest marks malformed leaf requests.
+var errBadUniverseRequest = errors.New("bad universe request")
+
+// parseUniverseLeafReq parses the tree type and the leaf key of a request.
+func parseUniverseLeafReq(g *gin.Context) (universemodel.TreeType, universemodel.LeafKey, error) {
+	treeType, err := universemodel.ParseTreeType(g.Param("tree_type"))
+	if err != nil {
+		return 0, universemodel.LeafKey{}, err
+	}
+
+	var req universesdk.LeafKey
+	if err := g.ShouldBindQuery(&req); err != nil {
+		return 0, universemodel.LeafKey{}, errors.Join(errBadUniverseRequest, err)
+	}
+
+	key, err := req.UniverseLeafKey()
+	if err != nil {
+		return 0, universemodel.LeafKey{}, errors.Join(errBadUniverseRequest, err)
+	}
+
+	return treeType, key, nil
+}
+
+// universeError responds with the status code matching the error.
+func universeError(g *gin.Context, err error) {
+	switch {
+	case errors.Is(err, universemodel.ErrLeafNotFound):
+		g.JSON(http.StatusNotFound, gin.H{
+			"message": err.Error(),
+		})
+
+	case errors.Is(err, universemodel.ErrUnknownTreeType),
+		errors.Is(err, universe.ErrInvalidAssetID),
+		errors.Is(err, errBadUniverseRequest):
+
+		g.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+
+	default:
+		g.JSON(http.StatusInternalServerError, nil)
+	}
+}
+
+func NewUniverseController(universeUseCase universe.UseCaseInterface) api.ControllerInterface {
+	return &UniverseController{
+		universeUseCase: universeUseCase,
+	}
+}
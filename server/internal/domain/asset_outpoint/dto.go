@@ -3,10 +3,13 @@ package assetoutpoint
 import "github.com/quocky/taproot-asset/server/internal/domain/common"
 
 type UnspentOutpointFilter struct {
-	IDs       *common.InOperator `json:"_id,omitempty"`
-	GenesisID *common.ID         `json:"genesis_id,omitempty"`
-	Spent     *bool              `json:"spent,omitempty"`
-	ScriptKey []byte             `json:"script_key,omitempty"`
+	IDs           *common.InOperator `json:"_id,omitempty"`
+	GenesisID     *common.ID         `json:"genesis_id,omitempty"`
+	Spent         *bool              `json:"spent,omitempty"`
+	ScriptKey     []byte             `json:"script_key,omitempty"`
+	AnchorUtxoIDs *common.InOperator `json:"anchor_utxo_id,omitempty"`
+	SpentByTxID   *common.ID         `json:"spent_by_tx_id,omitempty"`
+	IsDeleted     *bool              `json:"is_deleted,omitempty"`
 }
 
 type UnspentOutpointUpdate struct {
@@ -14,5 +17,7 @@ type UnspentOutpointUpdate struct {
 }
 
 type UnspentOutpointSetUpdate struct {
-	Spent *bool `json:"spent,omitempty"`
+	Spent       *bool      `json:"spent,omitempty"`
+	SpentByTxID *common.ID `json:"spent_by_tx_id,omitempty"`
+	IsDeleted   *bool      `json:"is_deleted,omitempty"`
 }
@@ -9,12 +9,16 @@ type AssetOutpoint struct {
 	common.Entity            `json:",inline"`
 	GenesisID                common.ID `json:"genesis_id"`
 	ScriptKey                []byte    `json:"script_key"`
-	Amount                   int32     `json:"amount"`
+	Amount                   uint64    `json:"amount"`
 	SplitCommitmentRootHash  []byte    `json:"split_commitment_root_hash"`
-	SplitCommitmentRootValue int32     `json:"split_commitment_root_value"`
+	SplitCommitmentRootValue uint64    `json:"split_commitment_root_value"`
 	AnchorUtxoID             common.ID `json:"anchor_utxo_id"`
 	ProofLocator             []byte    `json:"proof_locator"`
 	Spent                    bool      `json:"spent"`
+
+	// SpentByTxID is the chain tx which spent the outpoint. It is kept when
+	// the outpoint is unspent again after that transaction was dropped.
+	SpentByTxID common.ID `json:"spent_by_tx_id,omitempty"`
 }
 
 type UnspentOutpoint struct {
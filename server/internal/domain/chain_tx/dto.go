@@ -0,0 +1,23 @@
+package chaintx
+
+import "github.com/quocky/taproot-asset/server/internal/domain/common"
+
+type ChainTxFilter struct {
+	IDs       *common.InOperator `json:"_id,omitempty"`
+	TxID      []byte             `json:"tx_id,omitempty"`
+	Status    *Status            `json:"status,omitempty"`
+	BlockHash []byte             `json:"block_hash,omitempty"`
+}
+
+type ChainTxUpdate struct {
+	Set *ChainTxSetUpdate `json:"$set,omitempty"`
+}
+
+// ChainTxSetUpdate sets the state of a chain tx. The block fields are always
+// written, only a confirmed transaction has a block.
+type ChainTxSetUpdate struct {
+	Status      *Status `json:"status,omitempty"`
+	FailReason  *string `json:"fail_reason,omitempty"`
+	BlockHeight int32   `json:"block_height"`
+	BlockHash   []byte  `json:"block_hash"`
+}
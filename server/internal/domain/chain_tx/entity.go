@@ -2,6 +2,29 @@ package chaintx
 
 import "github.com/quocky/taproot-asset/server/internal/domain/common"
 
+// Status is the state of an anchor transaction in the mint and transfer state
+// machine: pending → broadcast → confirmed, or pending or broadcast → failed.
+type Status string
+
+const (
+	// StatusPending is the state of an anchor transaction which is recorded
+	// but not known to be accepted by the node yet. The assets it creates
+	// and spends are written in the same database transaction that records
+	// it.
+	StatusPending Status = "pending"
+
+	// StatusBroadcast is the state of an anchor transaction accepted by the
+	// node.
+	StatusBroadcast Status = "broadcast"
+
+	// StatusConfirmed is the state of an anchor transaction mined in a block.
+	StatusConfirmed Status = "confirmed"
+
+	// StatusFailed is the state of an anchor transaction the node rejected
+	// or dropped. Its assets are rolled back.
+	StatusFailed Status = "failed"
+)
+
 type ChainTx struct {
 	common.Entity `json:",inline"`
 	TxID          []byte `json:"tx_id"`
@@ -9,4 +32,11 @@ type ChainTx struct {
 	ChainFees     int32  `json:"chain_fees,omitempty"`
 	BlockHeight   int32  `json:"block_height,omitempty"`
 	BlockHash     []byte `json:"block_hash,omitempty"`
+
+	// Status is the state of the transaction. Transactions recorded before
+	// the state machine have no status.
+	Status Status `json:"status,omitempty"`
+
+	// FailReason is why a failed transaction was rejected or dropped.
+	FailReason string `json:"fail_reason,omitempty"`
 }
@@ -0,0 +1,71 @@
+package chaintx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	chaintxsdk "github.com/quocky/taproot-asset/taproot/http_model/chain_tx"
+)
+
+var (
+	// ErrChainTxNotFound is returned when the server has no anchor
+	// transaction with the given hash.
+	ErrChainTxNotFound = errors.New("chain tx not found")
+)
+
+// WriteAssetsFunc writes the assets an anchor transaction creates and spends.
+// It runs in the database transaction recording the anchor transaction.
+type WriteAssetsFunc func(ctx context.Context, chainTxID common.ID) error
+
+// UseCaseInterface drives an anchor transaction through its states. The
+// assets a transaction creates or spends are written with the pending
+// transaction, before it is broadcast, and rolled back in one database
+// transaction if the node rejects or drops it, so a server crash or database
+// failure after the broadcast can't lose them.
+type UseCaseInterface interface {
+	// RecordPending records the transaction in the pending state and runs
+	// the writes of its assets in the same database transaction.
+	RecordPending(ctx context.Context, tx *wire.MsgTx, writeAssets WriteAssetsFunc) (common.ID, error)
+
+	// Broadcast sends the pending transaction to the node and moves it
+	// into the broadcast state. If the node rejects it, the transaction is
+	// dropped.
+	Broadcast(ctx context.Context, chainTxID common.ID, tx *wire.MsgTx) error
+
+	// MarkBroadcast moves the transaction into the broadcast state if it is
+	// still pending.
+	MarkBroadcast(ctx context.Context, chainTxID common.ID) error
+
+	// Drop moves the transaction into the failed state and rolls back its
+	// assets in the same database transaction: the outpoints it created
+	// are deleted, the outpoints it spent are unspent again and the
+	// universe leaves of its proofs are removed.
+	Drop(ctx context.Context, chainTxID common.ID, reason string) error
+
+	// Unconfirm moves the confirmed transactions of a disconnected block
+	// back to the broadcast state and removes the universe leaves of their
+	// proofs in the same database transaction.
+	Unconfirm(ctx context.Context, chainTxIDs []common.ID) error
+
+	// Confirm moves the transaction into the confirmed state and records
+	// the block it was mined in.
+	Confirm(ctx context.Context, chainTxID common.ID, block *wire.MsgBlock, height uint32) error
+
+	// Status returns the state of the transaction with the given hash and
+	// its number of confirmations.
+	Status(ctx context.Context, txHash chainhash.Hash) (*chaintxsdk.StatusResp, error)
+}
+
+// WatcherInterface follows the blocks of the node. It confirms the pending and
+// broadcast transactions mined in a block together with the proofs of their
+// assets, moves the transactions of a disconnected block back to the broadcast
+// state and rolls back the assets of transactions the node dropped. Pending
+// transactions the node doesn't know, e.g. because the server stopped before
+// broadcasting them, are broadcast again.
+type WatcherInterface interface {
+	// Run processes the block events until the context is canceled.
+	Run(ctx context.Context)
+}
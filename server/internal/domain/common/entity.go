@@ -2,6 +2,8 @@ package common
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"time"
 )
 
@@ -9,8 +11,24 @@ var (
 	ErrKeySystemInternalServer     = errors.New("error.system.internal")
 	ErrDatabaseNotFound            = errors.New("error.database.not_found_data")
 	ErrDatabaseDuplicateIndexedKey = errors.New("error.database.duplicate_indexed_key")
+
+	// ErrDatabaseValueOutOfRange is returned when a value doesn't fit in
+	// the signed 64-bit integers the databases store, e.g. an amount above
+	// math.MaxInt64.
+	ErrDatabaseValueOutOfRange = errors.New("error.database.value_out_of_range")
 )
 
+// CheckAmountRange returns ErrDatabaseValueOutOfRange if the amount can't be
+// stored. Use cases check amounts before broadcasting the transactions they
+// will store, so that no asset is created on-chain without its record.
+func CheckAmountRange(name string, amount uint64) error {
+	if amount > math.MaxInt64 {
+		return fmt.Errorf("%w: %s = %d", ErrDatabaseValueOutOfRange, name, amount)
+	}
+
+	return nil
+}
+
 // ID is a custom type that helps to Marshal id value from Database.
 // to string in the JSON response.
 type ID string
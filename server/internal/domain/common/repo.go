@@ -9,7 +9,6 @@ type RepoInterface interface {
 	FindOne(ctx context.Context, filter, dest any) error
 	FindOneByID(ctx context.Context, id ID, dest any) error
 	FindMany(ctx context.Context, filter, dest any) error
-	FindAggregate(ctx context.Context, filter, dest any) error
 	UpdateMany(ctx context.Context, filter, update any) error
 	RunTransactions(ctx context.Context, txs []TransactionCallbackFunc) error
 }
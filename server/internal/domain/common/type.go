@@ -1,6 +1,7 @@
 package common
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"strconv"
 	"time"
@@ -73,6 +74,34 @@ func (id *ID) SetBSON(raw bson.RawValue) error {
 	return nil
 }
 
+// Value helps to store ID in a SQL database, an empty ID is stored as NULL.
+func (id ID) Value() (driver.Value, error) {
+	if id.IsEmpty() {
+		return nil, nil
+	}
+
+	return id.String(), nil
+}
+
+// Scan creates ID from a SQL column value.
+func (id *ID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*id = ""
+
+	case string:
+		*id = ID(v)
+
+	case []byte:
+		*id = ID(v)
+
+	default:
+		return fmt.Errorf("cannot scan %T into ID", src)
+	}
+
+	return nil
+}
+
 // Time returns time.Time value.
 func (t *UnixTimestamp) Time() time.Time {
 	return time.Time(*t)
@@ -190,3 +219,29 @@ func (c *CreatedAt) SetBSON(raw bson.RawValue) error {
 
 	return nil
 }
+
+// Value will run when inserting createdAt field into a SQL database, this
+// helps to auto set time like GetBSON.
+func (c CreatedAt) Value() (driver.Value, error) {
+	if c.Time().IsZero() {
+		return time.Now().UTC(), nil
+	}
+
+	return c.Time().UTC(), nil
+}
+
+// Scan creates a CreatedAt from a SQL timestamp column.
+func (c *CreatedAt) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*c = CreatedAt{}
+
+	case time.Time:
+		*c = CreatedAt(v)
+
+	default:
+		return fmt.Errorf("cannot scan %T into CreatedAt", src)
+	}
+
+	return nil
+}
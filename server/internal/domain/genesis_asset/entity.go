@@ -6,7 +6,22 @@ type GenesisAsset struct {
 	common.Entity  `json:",inline"`
 	AssetID        []byte    `json:"asset_id"`
 	AssetName      string    `json:"asset_name"`
-	Supply         int32     `json:"supply"`
+	Supply         uint64    `json:"supply"`
+	AssetType      uint8     `json:"asset_type"`
 	OutputIndex    int32     `json:"output_index"`
 	GenesisPointID common.ID `json:"genesis_point_id"`
+
+	// GroupKey is the group key the asset was issued into, if any. All
+	// tranches of a grouped asset share the same group key.
+	GroupKey []byte `json:"group_key,omitempty"`
+
+	// MetaHash is the hash of the metadata committed to in the genesis of
+	// the asset, if any.
+	MetaHash []byte `json:"meta_hash,omitempty"`
+
+	// MetaType is the type of the metadata, see asset.MetaType.
+	MetaType uint8 `json:"meta_type,omitempty"`
+
+	// MetaData is the raw metadata revealed in the genesis proof.
+	MetaData []byte `json:"meta_data,omitempty"`
 }
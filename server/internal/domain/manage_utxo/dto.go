@@ -0,0 +1,7 @@
+package manageutxo
+
+import "github.com/quocky/taproot-asset/server/internal/domain/common"
+
+type ManagedUtxoFilter struct {
+	TxID *common.ID `json:"tx_id,omitempty"`
+}
@@ -2,7 +2,6 @@ package transfer
 
 import (
 	"github.com/btcsuite/btcd/wire"
-	"github.com/quocky/taproot-asset/taproot/model/asset"
 	assetoutpointmodel "github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
 	"github.com/quocky/taproot-asset/taproot/model/proof"
 	"github.com/quocky/taproot-asset/taproot/onchain"
@@ -12,7 +11,6 @@ import (
 type UseCaseInterface interface {
 	TransferAsset(
 		ctx context.Context,
-		genesisAsset *asset.GenesisAsset,
 		anchorTx *wire.MsgTx,
 		amtSats int32,
 		btcOutputInfos []*onchain.BtcOutputInfo,
@@ -0,0 +1,10 @@
+package universe
+
+import "github.com/quocky/taproot-asset/server/internal/domain/common"
+
+type LeafFilter struct {
+	AssetID       []byte             `json:"asset_id,omitempty"`
+	TreeType      *uint8             `json:"tree_type,omitempty"`
+	LeafKey       []byte             `json:"leaf_key,omitempty"`
+	ProofLocators *common.InOperator `json:"proof_locator,omitempty"`
+}
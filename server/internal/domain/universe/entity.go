@@ -0,0 +1,16 @@
+package universe
+
+import "github.com/quocky/taproot-asset/server/internal/domain/common"
+
+// Leaf is a leaf of the universe tree of an asset. The leaf value, the proof
+// file, is kept in the proof archive under the proof locator.
+type Leaf struct {
+	common.Entity `json:",inline"`
+	AssetID       []byte `json:"asset_id"`
+	TreeType      uint8  `json:"tree_type"`
+	LeafKey       []byte `json:"leaf_key"`
+	OutPoint      string `json:"outpoint"`
+	ScriptKey     []byte `json:"script_key"`
+	Amount        uint64 `json:"amount"`
+	ProofLocator  []byte `json:"proof_locator"`
+}
@@ -0,0 +1,12 @@
+package universe
+
+import (
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	"golang.org/x/net/context"
+)
+
+type RepoInterface interface {
+	common.RepoInterface
+	FindAssetIDs(ctx context.Context) ([][]byte, error)
+	DeleteMany(ctx context.Context, filter any) error
+}
@@ -0,0 +1,58 @@
+package universe
+
+import (
+	"errors"
+	"time"
+
+	universesdk "github.com/quocky/taproot-asset/taproot/http_model/universe"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	"github.com/quocky/taproot-asset/taproot/universe"
+	"golang.org/x/net/context"
+)
+
+var (
+	// ErrInvalidAssetID is returned for an asset ID that isn't a hex
+	// encoded 32 byte ID.
+	ErrInvalidAssetID = errors.New("invalid asset id")
+
+	// ErrInvalidPeerLeaf is returned when a peer server hands out a
+	// universe leaf with a proof file that doesn't prove the leaf.
+	ErrInvalidPeerLeaf = errors.New("invalid universe leaf of peer")
+)
+
+type UseCaseInterface interface {
+	// RegisterLeaves adds the assets of the last proofs of the confirmed
+	// proof files to the universe trees of the given type. The leaves only
+	// commit to the files, which must be imported into the proof archive
+	// first.
+	RegisterLeaves(ctx context.Context, treeType universe.TreeType, files []*proof.File) error
+
+	// RemoveLeaves removes the leaves of the proof files with the given
+	// locator hashes from the universe trees. It joins the database
+	// transaction of the context, so the leaves come back if it is rolled
+	// back.
+	RemoveLeaves(ctx context.Context, locators [][32]byte) error
+
+	Roots(ctx context.Context, assetID string) (*universesdk.RootsResp, error)
+	AllRoots(ctx context.Context) ([]*universesdk.RootsResp, error)
+	LeafKeys(ctx context.Context, assetID string, treeType universe.TreeType) (universesdk.LeafKeysResp, error)
+	Leaf(ctx context.Context, assetID string, treeType universe.TreeType, key universe.LeafKey) (*universesdk.LeafResp, error)
+	InclusionProof(ctx context.Context, assetID string, treeType universe.TreeType, key universe.LeafKey) (*universesdk.InclusionProofResp, error)
+}
+
+// SyncStats counts the leaves a sync with a peer added.
+type SyncStats struct {
+	NumAssets         int
+	NumIssuanceLeaves int
+	NumTransferLeaves int
+}
+
+type SyncUseCaseInterface interface {
+	// SyncPeer fetches the universe leaves the peer server has and this
+	// server is missing. Every fetched proof file is verified before its
+	// leaf is added.
+	SyncPeer(ctx context.Context, peerURL string) (*SyncStats, error)
+
+	// Run syncs with all peers every interval until the context is done.
+	Run(ctx context.Context, peerURLs []string, interval time.Duration)
+}
@@ -1,16 +1,29 @@
 package utxoasset
 
 import (
+	"errors"
+
 	utxoassetsdk "github.com/quocky/taproot-asset/taproot/http_model/utxo_asset"
 	"golang.org/x/net/context"
 )
 
+var (
+	// ErrAssetMetaNotFound is returned when an asset doesn't exist or
+	// wasn't minted with metadata.
+	ErrAssetMetaNotFound = errors.New("asset metadata not found")
+
+	// ErrInvalidAssetID is returned when an asset ID isn't a hex encoded
+	// 32-byte ID.
+	ErrInvalidAssetID = errors.New("invalid asset id")
+)
+
 type UseCaseInterface interface {
 	GetUnspentAssetsById(
 		ctx context.Context,
 		assetID string,
-		amount int32,
+		amount uint64,
 		pubKey []byte,
 	) (*utxoassetsdk.UnspentAssetResp, error)
 	ListAllAssetsWithAmount(ctx context.Context, pubkey []byte) (utxoassetsdk.ListAssetsResp, error)
+	GetAssetMeta(ctx context.Context, assetID string) (*utxoassetsdk.AssetMetaResp, error)
 }
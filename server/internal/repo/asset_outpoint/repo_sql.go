@@ -0,0 +1,99 @@
+package assetoutpoint
+
+import (
+	"sort"
+
+	assetoutpoint "github.com/quocky/taproot-asset/server/internal/domain/asset_outpoint"
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	manageutxo "github.com/quocky/taproot-asset/server/internal/domain/manage_utxo"
+	cmrepo "github.com/quocky/taproot-asset/server/internal/repo/common"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	"golang.org/x/net/context"
+)
+
+type RepoSQL struct {
+	*cmrepo.RepoSQL
+	managedUtxos *cmrepo.RepoSQL
+}
+
+// FindManyWithManagedUTXO returns the outpoints matching the filter joined
+// with their anchor UTXO and the other assets anchored in it, the largest
+// amount first. Outpoints without an anchor UTXO are left out.
+func (r *RepoSQL) FindManyWithManagedUTXO(
+	ctx context.Context,
+	filter any,
+) ([]*assetoutpoint.UnspentOutpoint, error) {
+	outpoints := make([]*assetoutpoint.AssetOutpoint, 0)
+	if err := r.FindMany(ctx, filter, &outpoints); err != nil {
+		return nil, err
+	}
+
+	anchorIDs := make([]any, len(outpoints))
+	for i, outpoint := range outpoints {
+		anchorIDs[i] = outpoint.AnchorUtxoID
+	}
+
+	anchorUtxos := make([]*manageutxo.ManagedUtxo, 0)
+	err := r.managedUtxos.FindMany(ctx, map[string]any{
+		"id": common.InOperator{Values: anchorIDs},
+	}, &anchorUtxos)
+	if err != nil {
+		return nil, err
+	}
+
+	anchoredAssets := make([]*assetoutpoint.AssetOutpoint, 0)
+	err = r.FindMany(ctx, map[string]any{
+		"anchor_utxo_id": common.InOperator{Values: anchorIDs},
+	}, &anchoredAssets)
+	if err != nil {
+		return nil, err
+	}
+
+	utxoByID := make(map[common.ID]*manageutxo.ManagedUtxo, len(anchorUtxos))
+	for _, utxo := range anchorUtxos {
+		utxoByID[utxo.ID] = utxo
+	}
+
+	assetsByAnchor := make(map[common.ID][]*assetoutpoint.AssetOutpoint)
+	for _, anchored := range anchoredAssets {
+		assetsByAnchor[anchored.AnchorUtxoID] = append(
+			assetsByAnchor[anchored.AnchorUtxoID], anchored,
+		)
+	}
+
+	unspentUtxos := make([]*assetoutpoint.UnspentOutpoint, 0, len(outpoints))
+	for _, outpoint := range outpoints {
+		utxo, ok := utxoByID[outpoint.AnchorUtxoID]
+		if !ok {
+			continue
+		}
+
+		relatedAssets := make([]*assetoutpoint.AssetOutpoint, 0)
+		for _, anchored := range assetsByAnchor[outpoint.AnchorUtxoID] {
+			if anchored.ID != outpoint.ID {
+				relatedAssets = append(relatedAssets, anchored)
+			}
+		}
+
+		unspentUtxos = append(unspentUtxos, &assetoutpoint.UnspentOutpoint{
+			AssetOutpoint: *outpoint,
+			ManagedUtxo:   *utxo,
+			RelatedAssets: relatedAssets,
+		})
+	}
+
+	sort.SliceStable(unspentUtxos, func(i, j int) bool {
+		return unspentUtxos[i].AssetOutpoint.Amount > unspentUtxos[j].AssetOutpoint.Amount
+	})
+
+	return unspentUtxos, nil
+}
+
+func NewRepoSQL(
+	db *database.SQLDatabase,
+) assetoutpoint.RepoInterface {
+	return &RepoSQL{
+		RepoSQL:      cmrepo.NewRepoSQL(db, "asset_outpoints"),
+		managedUtxos: cmrepo.NewRepoSQL(db, "managed_utxos"),
+	}
+}
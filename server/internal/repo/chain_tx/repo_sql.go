@@ -0,0 +1,19 @@
+package chaintx
+
+import (
+	"github.com/quocky/taproot-asset/server/internal/domain/chain_tx"
+	cmrepo "github.com/quocky/taproot-asset/server/internal/repo/common"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+)
+
+type RepoSQL struct {
+	*cmrepo.RepoSQL
+}
+
+func NewRepoSQL(
+	db *database.SQLDatabase,
+) chaintx.RepoInterface {
+	return &RepoSQL{
+		cmrepo.NewRepoSQL(db, "chain_txs"),
+	}
+}
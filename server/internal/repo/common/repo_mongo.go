@@ -26,6 +26,10 @@ type RepoMongo struct {
 }
 
 func (r *RepoMongo) InsertOne(ctx context.Context, doc any) (common.ID, error) {
+	if err := CheckEntityIntRange(doc); err != nil {
+		return "", err
+	}
+
 	writeResult, err := r.Collection().InsertOne(ctx, doc)
 	if err != nil {
 		if strings.Contains(err.Error(), ErrorCodeDuplicateIndexedKey) {
@@ -175,6 +179,23 @@ func (r *RepoMongo) UpdateMany(ctx context.Context, filterM, update any) error {
 	return nil
 }
 
+// DeleteMany deletes the documents of collection matching the filter.
+func (r *RepoMongo) DeleteMany(ctx context.Context, filterM any) error {
+	_, err := r.Collection().DeleteMany(ctx, filterM)
+	if err != nil {
+		logger.Errorw(
+			"DeleteMany: delete documents fail",
+			"collection", r.collName,
+			"filterM", filterM,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
 func (r *RepoMongo) RunTransactions(ctx context.Context, txs []common.TransactionCallbackFunc) error {
 	client := r.db.Client()
 
@@ -0,0 +1,444 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+)
+
+// sqlTxKey is the context key of the SQL transaction run by RunTransactions.
+type sqlTxKey struct{}
+
+// Querier runs queries on a SQL database, either directly or in a
+// transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// RepoSQL define common SQL repo with common function and implement function
+// of common.RepoInterface. The rows of the table are mapped to the entities by
+// the json tags of their fields, like the documents of RepoMongo. Filters and
+// updates are the same dto structs or maps as for RepoMongo, with equality
+// and $in conditions and $set updates.
+type RepoSQL struct {
+	db    *database.SQLDatabase
+	table string
+}
+
+func (r *RepoSQL) InsertOne(ctx context.Context, doc any) (common.ID, error) {
+	docVal := reflect.Indirect(reflect.ValueOf(doc))
+
+	fields, err := entityFields(docVal.Type())
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		id      = common.NewID()
+		columns = make([]string, 0, len(fields))
+		values  = make([]string, 0, len(fields))
+		args    = make([]any, 0, len(fields))
+	)
+
+	for _, f := range fields {
+		value := docVal.FieldByIndex(f.index).Interface()
+
+		if f.column == "id" {
+			if docID, ok := value.(common.ID); ok && !docID.IsEmpty() {
+				id = docID
+			}
+
+			value = id
+		}
+
+		if err := CheckIntRange(f.column, value); err != nil {
+			return "", err
+		}
+
+		columns = append(columns, f.column)
+		args = append(args, value)
+		values = append(values, r.db.Dialect.Placeholder(len(args)))
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		r.table, strings.Join(columns, ", "), strings.Join(values, ", "),
+	)
+
+	if _, err := r.Querier(ctx).ExecContext(ctx, query, args...); err != nil {
+		if isDuplicateKeyErr(err) {
+			return "", common.ErrDatabaseDuplicateIndexedKey
+		}
+
+		logger.Errorw(
+			"insert row into table err",
+			"table", r.table,
+			"doc", doc,
+			"err", err,
+		)
+
+		return "", common.ErrKeySystemInternalServer
+	}
+
+	return id, nil
+}
+
+// FindOne find a row by filter in table and assign it to dest.
+func (r *RepoSQL) FindOne(ctx context.Context, filter, dest any) error {
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Pointer || destVal.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a pointer to a struct, got %T", dest)
+	}
+
+	fields, err := entityFields(destVal.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	where, args, err := r.whereClause(filter, 0)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s%s LIMIT 1",
+		columnList(fields), r.table, where,
+	)
+
+	row := r.Querier(ctx).QueryRowContext(ctx, query, args...)
+
+	err = row.Scan(scanTargets(destVal.Elem(), fields)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return common.ErrDatabaseNotFound
+	}
+
+	if err != nil {
+		logger.Errorw(
+			"find one row fail",
+			"table", r.table,
+			"field", filter,
+			"err", err,
+		)
+
+		return common.ErrKeySystemInternalServer
+	}
+
+	return nil
+}
+
+// FindOneByID find a row by id and assign it to dest.
+func (r *RepoSQL) FindOneByID(ctx context.Context, id common.ID, dest any) error {
+	return r.FindOne(ctx, map[string]any{"id": id}, dest)
+}
+
+// FindMany get rows based on filter, in the order they were inserted.
+func (r *RepoSQL) FindMany(ctx context.Context, filter, dest any) error {
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Pointer || destVal.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest must be a pointer to a slice, got %T", dest)
+	}
+
+	var (
+		sliceVal = destVal.Elem()
+		elemType = sliceVal.Type().Elem()
+		isPtr    = elemType.Kind() == reflect.Pointer
+	)
+
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	fields, err := entityFields(elemType)
+	if err != nil {
+		return err
+	}
+
+	where, args, err := r.whereClause(filter, 0)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s%s ORDER BY created_at, id",
+		columnList(fields), r.table, where,
+	)
+
+	rows, err := r.Querier(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Errorw("FindMany: get rows by Filter err", "table", r.table, "Filter", filter, "err", err)
+
+		return common.ErrKeySystemInternalServer
+	}
+	defer rows.Close()
+
+	result := reflect.MakeSlice(sliceVal.Type(), 0, 0)
+	for rows.Next() {
+		elem := reflect.New(elemType)
+
+		if err := rows.Scan(scanTargets(elem.Elem(), fields)...); err != nil {
+			logger.Errorw("FindMany: scan rows to struct err", "table", r.table, "Filter", filter, "err", err)
+
+			return common.ErrKeySystemInternalServer
+		}
+
+		if isPtr {
+			result = reflect.Append(result, elem)
+		} else {
+			result = reflect.Append(result, elem.Elem())
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorw("FindMany: read rows err", "table", r.table, "Filter", filter, "err", err)
+
+		return common.ErrKeySystemInternalServer
+	}
+
+	sliceVal.Set(result)
+
+	return nil
+}
+
+// UpdateMany update rows of table by filter. The update sets the columns of
+// its $set field.
+func (r *RepoSQL) UpdateMany(ctx context.Context, filter, update any) error {
+	updateFields, err := jsonFields(update)
+	if err != nil {
+		return err
+	}
+
+	var setFields []namedValue
+	for _, f := range updateFields {
+		if f.name != "$set" {
+			return fmt.Errorf("unsupported update operator %q", f.name)
+		}
+
+		setFields, err = jsonFields(f.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(setFields) == 0 {
+		return nil
+	}
+
+	var (
+		sets = make([]string, len(setFields))
+		args = make([]any, len(setFields))
+	)
+
+	for i, f := range setFields {
+		column, err := columnName(f.name)
+		if err != nil {
+			return err
+		}
+
+		if err := CheckIntRange(column, f.value); err != nil {
+			return err
+		}
+
+		sets[i] = fmt.Sprintf("%s = %s", column, r.db.Dialect.Placeholder(i+1))
+		args[i] = f.value
+	}
+
+	where, whereArgs, err := r.whereClause(filter, len(args))
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s%s", r.table, strings.Join(sets, ", "), where)
+
+	_, err = r.Querier(ctx).ExecContext(ctx, query, append(args, whereArgs...)...)
+	if err != nil {
+		logger.Errorw(
+			"UpdateMany: update rows fail",
+			"table", r.table,
+			"filter", filter,
+			"update", update,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// DeleteMany deletes the rows of table matching the filter.
+func (r *RepoSQL) DeleteMany(ctx context.Context, filter any) error {
+	where, args, err := r.whereClause(filter, 0)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s%s", r.table, where)
+
+	if _, err := r.Querier(ctx).ExecContext(ctx, query, args...); err != nil {
+		logger.Errorw(
+			"DeleteMany: delete rows fail",
+			"table", r.table,
+			"filter", filter,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// RunTransactions runs the callbacks in one SQL transaction, which is passed
+// in the context to the repos the callbacks call. Transactions run in an
+// already running transaction join it.
+func (r *RepoSQL) RunTransactions(ctx context.Context, txs []common.TransactionCallbackFunc) error {
+	if _, ok := ctx.Value(sqlTxKey{}).(*sql.Tx); ok {
+		for _, tx := range txs {
+			if err := tx(ctx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	sqlTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Errorw(
+			"begin sql transaction fail",
+			"table", r.table,
+			"err", err,
+		)
+
+		return err
+	}
+
+	txCtx := context.WithValue(ctx, sqlTxKey{}, sqlTx)
+	for _, tx := range txs {
+		if err := tx(txCtx); err != nil {
+			if errRollback := sqlTx.Rollback(); errRollback != nil {
+				logger.Errorw(
+					"rollback sql transaction fail",
+					"table", r.table,
+					"err", errRollback,
+				)
+			}
+
+			return err
+		}
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		logger.Errorw(
+			"commit sql transaction fail",
+			"table", r.table,
+			"err", err,
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// SQLTx returns the SQL transaction run by RunTransactions of the context, if
+// any. Stores running their own transactions join it.
+func SQLTx(ctx context.Context) (*sql.Tx, bool) {
+	sqlTx, ok := ctx.Value(sqlTxKey{}).(*sql.Tx)
+
+	return sqlTx, ok
+}
+
+// Querier returns the transaction of the context, or the database if the
+// context has no transaction.
+func (r *RepoSQL) Querier(ctx context.Context) Querier {
+	if sqlTx, ok := ctx.Value(sqlTxKey{}).(*sql.Tx); ok {
+		return sqlTx
+	}
+
+	return r.db
+}
+
+// Dialect returns the dialect of the database.
+func (r *RepoSQL) Dialect() database.SQLDialect {
+	return r.db.Dialect
+}
+
+// whereClause builds the WHERE clause of the filter, numbering its
+// placeholders after numArgs preceding arguments.
+func (r *RepoSQL) whereClause(filter any, numArgs int) (string, []any, error) {
+	fields, err := jsonFields(filter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(fields) == 0 {
+		return "", nil, nil
+	}
+
+	var (
+		conds = make([]string, 0, len(fields))
+		args  = make([]any, 0, len(fields))
+	)
+
+	for _, f := range fields {
+		column, err := columnName(f.name)
+		if err != nil {
+			return "", nil, err
+		}
+
+		in, ok := inValues(f.value)
+		if !ok {
+			args = append(args, f.value)
+			conds = append(conds, fmt.Sprintf(
+				"%s = %s", column, r.db.Dialect.Placeholder(numArgs+len(args)),
+			))
+
+			continue
+		}
+
+		// Like $in, an empty list matches no row.
+		if len(in) == 0 {
+			conds = append(conds, "1 = 0")
+
+			continue
+		}
+
+		placeholders := make([]string, len(in))
+		for i, value := range in {
+			args = append(args, value)
+			placeholders[i] = r.db.Dialect.Placeholder(numArgs + len(args))
+		}
+
+		conds = append(conds, fmt.Sprintf(
+			"%s IN (%s)", column, strings.Join(placeholders, ", "),
+		))
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), args, nil
+}
+
+// isDuplicateKeyErr reports whether the error is a violation of a unique
+// constraint, by the messages of SQLite and Postgres.
+func isDuplicateKeyErr(err error) bool {
+	msg := err.Error()
+
+	return strings.Contains(msg, "UNIQUE constraint failed") ||
+		strings.Contains(msg, "SQLSTATE 23505")
+}
+
+func NewRepoSQL(db *database.SQLDatabase, table string) *RepoSQL {
+	return &RepoSQL{
+		db:    db,
+		table: table,
+	}
+}
@@ -0,0 +1,338 @@
+package common_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+
+	config "github.com/quocky/taproot-asset/server/config/core"
+	assetoutpoint "github.com/quocky/taproot-asset/server/internal/domain/asset_outpoint"
+	chaintx "github.com/quocky/taproot-asset/server/internal/domain/chain_tx"
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	genesisasset "github.com/quocky/taproot-asset/server/internal/domain/genesis_asset"
+	manageutxo "github.com/quocky/taproot-asset/server/internal/domain/manage_utxo"
+	cmrepo "github.com/quocky/taproot-asset/server/internal/repo/common"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	"github.com/quocky/taproot-asset/taproot/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDB creates a SQLite database with the schema of the server.
+func newTestDB(t *testing.T) *database.SQLDatabase {
+	t.Helper()
+
+	logger.Init()
+
+	db, err := database.NewSQLDatabase(&config.Config{
+		Database: config.Database{
+			Backend: string(database.SQLite),
+			DSN:     "file:" + filepath.Join(t.TempDir(), "taproot.db"),
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// insertChainTxs inserts a chain tx per status and returns their ids.
+func insertChainTxs(t *testing.T, repo *cmrepo.RepoSQL,
+	statuses ...chaintx.Status) []common.ID {
+
+	t.Helper()
+
+	ids := make([]common.ID, len(statuses))
+	for i, status := range statuses {
+		var err error
+		ids[i], err = repo.InsertOne(context.Background(), chaintx.ChainTx{
+			TxID:     []byte{byte(i)},
+			AnchorTx: []byte{byte(i)},
+			Status:   status,
+		})
+		require.NoError(t, err)
+	}
+
+	return ids
+}
+
+// TestRepoSQLFilters makes sure rows are found and updated by the equality
+// and $in conditions of the filters.
+func TestRepoSQLFilters(t *testing.T) {
+	ctx := context.Background()
+	repo := cmrepo.NewRepoSQL(newTestDB(t), "chain_txs")
+
+	ids := insertChainTxs(
+		t, repo, chaintx.StatusPending, chaintx.StatusBroadcast,
+		chaintx.StatusBroadcast,
+	)
+
+	testCases := []struct {
+		name   string
+		filter any
+		want   []common.ID
+	}{{
+		name:   "no filter",
+		filter: chaintx.ChainTxFilter{},
+		want:   ids,
+	}, {
+		name: "equality",
+		filter: chaintx.ChainTxFilter{
+			Status: utils.ToPtr(chaintx.StatusBroadcast),
+		},
+		want: ids[1:],
+	}, {
+		name: "bytes equality",
+		filter: chaintx.ChainTxFilter{
+			TxID: []byte{0},
+		},
+		want: ids[:1],
+	}, {
+		name: "in",
+		filter: chaintx.ChainTxFilter{
+			IDs: &common.InOperator{Values: []any{ids[0], ids[2]}},
+		},
+		want: []common.ID{ids[0], ids[2]},
+	}, {
+		name: "empty in",
+		filter: chaintx.ChainTxFilter{
+			IDs: &common.InOperator{Values: []any{}},
+		},
+	}, {
+		name: "in and equality",
+		filter: chaintx.ChainTxFilter{
+			IDs:    &common.InOperator{Values: []any{ids[0], ids[1]}},
+			Status: utils.ToPtr(chaintx.StatusBroadcast),
+		},
+		want: ids[1:2],
+	}, {
+		name:   "map",
+		filter: map[string]any{"status": chaintx.StatusPending},
+		want:   ids[:1],
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var chainTxs []*chaintx.ChainTx
+			require.NoError(t, repo.FindMany(ctx, testCase.filter, &chainTxs))
+
+			found := make([]common.ID, len(chainTxs))
+			for i, chainTx := range chainTxs {
+				found[i] = chainTx.ID
+			}
+			require.Equal(t, len(testCase.want), len(found))
+			if len(testCase.want) > 0 {
+				require.Equal(t, testCase.want, found)
+			}
+		})
+	}
+
+	err := repo.UpdateMany(
+		ctx,
+		chaintx.ChainTxFilter{
+			IDs: &common.InOperator{Values: []any{ids[0], ids[1]}},
+		},
+		chaintx.ChainTxUpdate{
+			Set: &chaintx.ChainTxSetUpdate{
+				Status:      utils.ToPtr(chaintx.StatusConfirmed),
+				BlockHeight: 7,
+				BlockHash:   []byte{7},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	for i, id := range ids {
+		var chainTx chaintx.ChainTx
+		require.NoError(t, repo.FindOneByID(ctx, id, &chainTx))
+
+		if i == 2 {
+			require.Equal(t, chaintx.StatusBroadcast, chainTx.Status)
+			require.Zero(t, chainTx.BlockHeight)
+
+			continue
+		}
+
+		require.Equal(t, chaintx.StatusConfirmed, chainTx.Status)
+		require.EqualValues(t, 7, chainTx.BlockHeight)
+		require.Equal(t, []byte{7}, chainTx.BlockHash)
+	}
+
+	var chainTx chaintx.ChainTx
+	err = repo.FindOne(ctx, chaintx.ChainTxFilter{TxID: []byte{9}}, &chainTx)
+	require.ErrorIs(t, err, common.ErrDatabaseNotFound)
+}
+
+// TestRepoSQLForeignKey makes sure rows referencing a missing row are
+// rejected.
+func TestRepoSQLForeignKey(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	chainTxRepo := cmrepo.NewRepoSQL(db, "chain_txs")
+	utxoRepo := cmrepo.NewRepoSQL(db, "managed_utxos")
+
+	ids := insertChainTxs(t, chainTxRepo, chaintx.StatusPending)
+
+	_, err := utxoRepo.InsertOne(ctx, manageutxo.ManagedUtxo{
+		Outpoint: "outpoint",
+		TxID:     ids[0],
+	})
+	require.NoError(t, err)
+
+	_, err = utxoRepo.InsertOne(ctx, manageutxo.ManagedUtxo{
+		Outpoint: "outpoint",
+		TxID:     common.NewID(),
+	})
+	require.ErrorIs(t, err, common.ErrKeySystemInternalServer)
+
+	var utxos []*manageutxo.ManagedUtxo
+	require.NoError(t, utxoRepo.FindMany(ctx, manageutxo.ManagedUtxoFilter{}, &utxos))
+	require.Len(t, utxos, 1)
+}
+
+// TestRepoSQLRunTransactions makes sure the callbacks are committed together
+// or not at all, also when a transaction joins a running one.
+func TestRepoSQLRunTransactions(t *testing.T) {
+	errCallback := errors.New("callback failed")
+
+	testCases := []struct {
+		name   string
+		nested bool
+		err    error
+	}{{
+		name: "commit",
+	}, {
+		name: "rollback",
+		err:  errCallback,
+	}, {
+		name:   "nested commit",
+		nested: true,
+	}, {
+		name:   "nested rollback",
+		nested: true,
+		err:    errCallback,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := cmrepo.NewRepoSQL(newTestDB(t), "chain_txs")
+
+			insert := func(ctx context.Context) error {
+				_, err := repo.InsertOne(ctx, chaintx.ChainTx{
+					TxID:     []byte{1},
+					AnchorTx: []byte{1},
+				})
+
+				return err
+			}
+
+			txs := []common.TransactionCallbackFunc{insert}
+			if testCase.nested {
+				txs = []common.TransactionCallbackFunc{
+					func(ctx context.Context) error {
+						return repo.RunTransactions(
+							ctx, []common.TransactionCallbackFunc{insert},
+						)
+					},
+				}
+			}
+
+			txs = append(txs, insert, func(context.Context) error {
+				return testCase.err
+			})
+
+			err := repo.RunTransactions(ctx, txs)
+			require.ErrorIs(t, err, testCase.err)
+
+			var chainTxs []*chaintx.ChainTx
+			require.NoError(t, repo.FindMany(ctx, chaintx.ChainTxFilter{}, &chainTxs))
+
+			if testCase.err != nil {
+				require.Empty(t, chainTxs)
+
+				return
+			}
+
+			require.Len(t, chainTxs, 2)
+		})
+	}
+}
+
+// TestRepoSQLIntRange makes sure unsigned values up to math.MaxInt64 round
+// trip and larger ones are rejected.
+func TestRepoSQLIntRange(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	genesisRepo := cmrepo.NewRepoSQL(db, "genesis_assets")
+	outpointRepo := cmrepo.NewRepoSQL(db, "asset_outpoints")
+
+	testCases := []struct {
+		name   string
+		amount uint64
+		err    error
+	}{{
+		name:   "max int64",
+		amount: math.MaxInt64,
+	}, {
+		name:   "high bit set",
+		amount: math.MaxInt64 + 1,
+		err:    common.ErrDatabaseValueOutOfRange,
+	}, {
+		name:   "max uint64",
+		amount: math.MaxUint64,
+		err:    common.ErrDatabaseValueOutOfRange,
+	}}
+
+	for i, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			genesisID, err := genesisRepo.InsertOne(ctx, genesisasset.GenesisAsset{
+				AssetID:   []byte{byte(i)},
+				AssetName: "asset",
+				Supply:    testCase.amount,
+			})
+			require.ErrorIs(t, err, testCase.err)
+
+			outpointID, err := outpointRepo.InsertOne(ctx, assetoutpoint.AssetOutpoint{
+				ScriptKey:    []byte{2},
+				Amount:       testCase.amount,
+				ProofLocator: []byte{1},
+			})
+			require.ErrorIs(t, err, testCase.err)
+
+			if testCase.err != nil {
+				return
+			}
+
+			var genesis genesisasset.GenesisAsset
+			require.NoError(t, genesisRepo.FindOneByID(ctx, genesisID, &genesis))
+			require.Equal(t, testCase.amount, genesis.Supply)
+
+			var outpoint assetoutpoint.AssetOutpoint
+			require.NoError(t, outpointRepo.FindOneByID(ctx, outpointID, &outpoint))
+			require.Equal(t, testCase.amount, outpoint.Amount)
+		})
+	}
+}
+
+// TestCheckEntityIntRange makes sure the entities stored by both backends are
+// checked for unsigned values beyond math.MaxInt64, also in embedded structs.
+func TestCheckEntityIntRange(t *testing.T) {
+	require.NoError(t, cmrepo.CheckEntityIntRange(&assetoutpoint.AssetOutpoint{
+		Amount:                   math.MaxInt64,
+		SplitCommitmentRootValue: math.MaxInt64,
+	}))
+
+	err := cmrepo.CheckEntityIntRange(assetoutpoint.AssetOutpoint{
+		SplitCommitmentRootValue: math.MaxInt64 + 1,
+	})
+	require.ErrorIs(t, err, common.ErrDatabaseValueOutOfRange)
+	require.ErrorContains(t, err, "split_commitment_root_value")
+
+	err = cmrepo.CheckEntityIntRange(&assetoutpoint.UnspentOutpoint{
+		AssetOutpoint: assetoutpoint.AssetOutpoint{Amount: math.MaxUint64},
+	})
+	require.ErrorIs(t, err, common.ErrDatabaseValueOutOfRange)
+}
@@ -0,0 +1,349 @@
+package common
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+
+	columnNameRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+	// entityFieldsCache caches the fields of the entity types.
+	entityFieldsCache sync.Map
+)
+
+// entityField is a field of an entity stored in a column named after its
+// json tag.
+type entityField struct {
+	column string
+	index  []int
+}
+
+// entityFields returns the fields of the entity type which are stored in
+// columns. Embedded structs without a json name are flattened, like inline
+// documents, and fields of other struct or slice types, like joined
+// documents, are skipped.
+func entityFields(t reflect.Type) ([]entityField, error) {
+	if cached, ok := entityFieldsCache.Load(t); ok {
+		return cached.([]entityField), nil
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("entity must be a struct, got %v", t)
+	}
+
+	var (
+		fields []entityField
+		seen   = make(map[string]bool)
+	)
+
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, _ := parseJSONTag(field)
+			if name == "-" {
+				continue
+			}
+
+			fieldIndex := append(append([]int{}, index...), i)
+
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				walk(field.Type, fieldIndex)
+
+				continue
+			}
+
+			if name == "" {
+				name = field.Name
+			}
+
+			if !isColumnType(field.Type) || seen[name] {
+				continue
+			}
+
+			seen[name] = true
+			fields = append(fields, entityField{
+				column: name,
+				index:  fieldIndex,
+			})
+		}
+	}
+
+	walk(t, nil)
+
+	entityFieldsCache.Store(t, fields)
+
+	return fields, nil
+}
+
+// isColumnType reports whether values of the type are stored in a column.
+func isColumnType(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(scannerType) || t == timeType || t == bytesType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+
+		return true
+
+	default:
+		return false
+	}
+}
+
+// columnList returns the comma separated columns of the fields.
+func columnList(fields []entityField) string {
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.column
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// scanTargets returns the addresses of the fields of the entity to scan a row
+// into.
+func scanTargets(entity reflect.Value, fields []entityField) []any {
+	targets := make([]any, len(fields))
+	for i, f := range fields {
+		targets[i] = entity.FieldByIndex(f.index).Addr().Interface()
+	}
+
+	return targets
+}
+
+// CheckIntRange returns common.ErrDatabaseValueOutOfRange if the value of the
+// column is an unsigned integer beyond the signed 64-bit range of the integer
+// columns and of the integers of Mongo documents. database/sql and the BSON
+// encoder reject these values too, but without telling which column overflows.
+func CheckIntRange(column string, value any) error {
+	val := reflect.ValueOf(value)
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil
+		}
+
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Uint, reflect.Uint64:
+		if val.Uint() > math.MaxInt64 {
+			return fmt.Errorf("%w: %s = %d",
+				common.ErrDatabaseValueOutOfRange, column, val.Uint())
+		}
+	}
+
+	return nil
+}
+
+// CheckEntityIntRange checks the values of the fields of the entity stored in
+// columns or document fields with CheckIntRange.
+func CheckEntityIntRange(doc any) error {
+	docVal := reflect.Indirect(reflect.ValueOf(doc))
+
+	fields, err := entityFields(docVal.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		err := CheckIntRange(f.column, docVal.FieldByIndex(f.index).Interface())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// namedValue is a field of a filter or an update.
+type namedValue struct {
+	name  string
+	value any
+}
+
+// jsonFields returns the fields of a filter or an update, a map with string
+// keys or a struct with json tags whose empty omitempty fields are left out.
+func jsonFields(v any) ([]namedValue, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil, nil
+		}
+
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported filter %T", v)
+		}
+
+		fields := make([]namedValue, 0, val.Len())
+
+		iter := val.MapRange()
+		for iter.Next() {
+			fields = append(fields, namedValue{
+				name:  iter.Key().String(),
+				value: derefValue(iter.Value()),
+			})
+		}
+
+		sort.Slice(fields, func(i, j int) bool {
+			return fields[i].name < fields[j].name
+		})
+
+		return fields, nil
+
+	case reflect.Struct:
+		var fields []namedValue
+
+		t := val.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, omitEmpty := parseJSONTag(field)
+			if name == "-" {
+				continue
+			}
+
+			fieldVal := val.Field(i)
+
+			if field.Anonymous && name == "" {
+				embedded, err := jsonFields(fieldVal.Interface())
+				if err != nil {
+					return nil, err
+				}
+
+				fields = append(fields, embedded...)
+
+				continue
+			}
+
+			if name == "" {
+				name = field.Name
+			}
+
+			if omitEmpty && isEmptyValue(fieldVal) {
+				continue
+			}
+
+			fields = append(fields, namedValue{
+				name:  name,
+				value: derefValue(fieldVal),
+			})
+		}
+
+		return fields, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported filter %T", v)
+	}
+}
+
+// columnName returns the column of a filter or update field. The _id field of
+// the Mongo documents is the id column.
+func columnName(name string) (string, error) {
+	if name == "_id" {
+		return "id", nil
+	}
+
+	if !columnNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid column name %q", name)
+	}
+
+	return name, nil
+}
+
+// inValues returns the values of an $in condition, given as a
+// common.InOperator or a map with an $in key.
+func inValues(v any) ([]any, bool) {
+	switch in := v.(type) {
+	case common.InOperator:
+		return in.Values, true
+
+	case map[string]any:
+		values, ok := in["$in"].([]any)
+		if !ok || len(in) != 1 {
+			return nil, false
+		}
+
+		return values, true
+
+	default:
+		return nil, false
+	}
+}
+
+// parseJSONTag returns the name and whether the omitempty option is set in
+// the json tag of the field.
+func parseJSONTag(field reflect.StructField) (string, bool) {
+	name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" {
+			return name, true
+		}
+	}
+
+	return name, false
+}
+
+// derefValue returns the value pointed to by the pointer value, or nil for a
+// nil pointer.
+func derefValue(val reflect.Value) any {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+
+		val = val.Elem()
+	}
+
+	return val.Interface()
+}
+
+// isEmptyValue reports whether the value is empty as defined by the omitempty
+// option of encoding/json.
+func isEmptyValue(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return val.Len() == 0
+
+	case reflect.Pointer, reflect.Interface:
+		return val.IsNil()
+
+	default:
+		return val.IsZero()
+	}
+}
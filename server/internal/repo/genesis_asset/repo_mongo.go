@@ -47,6 +47,8 @@ func (r *RepoMongo) FindAvailableAssetsWithAmount(ctx context.Context, pubkey []
 				"asset_id":   1,
 				"asset_name": 1,
 				"amount":     1,
+				"group_key":  1,
+				"asset_type": 1,
 			},
 		}},
 	}
@@ -0,0 +1,65 @@
+package genesisasset
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	genesisasset "github.com/quocky/taproot-asset/server/internal/domain/genesis_asset"
+	cmrepo "github.com/quocky/taproot-asset/server/internal/repo/common"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	utxoasset "github.com/quocky/taproot-asset/taproot/http_model/utxo_asset"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"golang.org/x/net/context"
+)
+
+type RepoSQL struct {
+	*cmrepo.RepoSQL
+}
+
+func (r *RepoSQL) FindAvailableAssetsWithAmount(ctx context.Context, pubkey []byte) (utxoasset.ListAssetsResp, error) {
+	query := fmt.Sprintf(`
+		SELECT g.asset_id, g.asset_name, g.asset_type, g.group_key,
+			CAST(COALESCE(SUM(o.amount), 0) AS BIGINT)
+		FROM genesis_assets g
+		LEFT JOIN asset_outpoints o
+			ON o.genesis_id = g.id AND o.spent = %s AND o.script_key = %s
+		GROUP BY g.id, g.asset_id, g.asset_name, g.asset_type, g.group_key
+		ORDER BY g.created_at, g.id`,
+		r.Dialect().Placeholder(1), r.Dialect().Placeholder(2),
+	)
+
+	rows, err := r.Querier(ctx).QueryContext(ctx, query, false, pubkey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assets := make(utxoasset.ListAssetsResp, 0)
+	for rows.Next() {
+		var (
+			assetID   []byte
+			assetType uint8
+			resp      utxoasset.ListAssetResp
+		)
+
+		err := rows.Scan(&assetID, &resp.Name, &assetType, &resp.GroupKey, &resp.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.AssetID = hex.EncodeToString(assetID)
+		resp.AssetType = asset.Type(assetType)
+
+		assets = append(assets, &resp)
+	}
+
+	return assets, rows.Err()
+}
+
+func NewRepoSQL(
+	db *database.SQLDatabase,
+) genesisasset.RepoInterface {
+	return &RepoSQL{
+		cmrepo.NewRepoSQL(db, "genesis_assets"),
+	}
+}
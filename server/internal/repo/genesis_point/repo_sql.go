@@ -0,0 +1,19 @@
+package genesispoint
+
+import (
+	"github.com/quocky/taproot-asset/server/internal/domain/genesis"
+	cmrepo "github.com/quocky/taproot-asset/server/internal/repo/common"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+)
+
+type RepoSQL struct {
+	*cmrepo.RepoSQL
+}
+
+func NewRepoSQL(
+	db *database.SQLDatabase,
+) genesis.RepoInterface {
+	return &RepoSQL{
+		cmrepo.NewRepoSQL(db, "genesis_points"),
+	}
+}
@@ -0,0 +1,158 @@
+package mssmtstore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+)
+
+// The types of the stored nodes.
+const (
+	nodeTypeBranch        uint8 = 0
+	nodeTypeLeaf          uint8 = 1
+	nodeTypeCompactedLeaf uint8 = 2
+)
+
+// ErrNodeNotFound is returned when a node referenced by a branch isn't stored.
+var ErrNodeNotFound = errors.New("mssmt node not found")
+
+// nodeDoc is a stored node of a tree. Branches reference their children by
+// node hash, leaves and compacted leaves hold their value.
+type nodeDoc struct {
+	Namespace string `json:"namespace"`
+	NodeHash  []byte `json:"node_hash"`
+	Type      uint8  `json:"type"`
+	Sum       uint64 `json:"sum"`
+	LeftHash  []byte `json:"left_hash,omitempty"`
+	RightHash []byte `json:"right_hash,omitempty"`
+	Key       []byte `json:"key,omitempty"`
+	Value     []byte `json:"value,omitempty"`
+}
+
+func newBranchDoc(branch *mssmt.BranchNode) nodeDoc {
+	leftHash := branch.Left.NodeHash()
+	rightHash := branch.Right.NodeHash()
+
+	return nodeDoc{
+		Type:      nodeTypeBranch,
+		Sum:       branch.NodeSum(),
+		LeftHash:  leftHash[:],
+		RightHash: rightHash[:],
+	}
+}
+
+func newLeafDoc(leaf *mssmt.LeafNode) nodeDoc {
+	return nodeDoc{
+		Type:  nodeTypeLeaf,
+		Sum:   leaf.NodeSum(),
+		Value: leaf.Value,
+	}
+}
+
+func newCompactedLeafDoc(leaf *mssmt.CompactedLeafNode) nodeDoc {
+	key := leaf.Key()
+
+	return nodeDoc{
+		Type:  nodeTypeCompactedLeaf,
+		Sum:   leaf.NodeSum(),
+		Key:   key[:],
+		Value: leaf.Value,
+	}
+}
+
+// node returns the tree node of the document at the given height.
+func (d *nodeDoc) node(height int) (mssmt.Node, error) {
+	if len(d.NodeHash) != len(mssmt.NodeHash{}) {
+		return nil, fmt.Errorf("invalid node hash length %d", len(d.NodeHash))
+	}
+
+	switch d.Type {
+	case nodeTypeBranch:
+		return mssmt.NewComputedBranch(mssmt.NodeHash(d.NodeHash), d.Sum), nil
+
+	case nodeTypeLeaf:
+		return mssmt.NewLeafNode(d.Value, d.Sum), nil
+
+	case nodeTypeCompactedLeaf:
+		if len(d.Key) != len(mssmt.NodeHash{}) {
+			return nil, fmt.Errorf("invalid compacted leaf key length %d", len(d.Key))
+		}
+
+		key := [32]byte(d.Key)
+
+		return mssmt.NewCompactedLeafNode(height, &key, mssmt.NewLeafNode(d.Value, d.Sum)), nil
+
+	default:
+		return nil, fmt.Errorf("unknown node type %d", d.Type)
+	}
+}
+
+// getChildren returns the children of the branch with the given hash at the
+// given height, looking the stored nodes up with findNodes. Empty subtrees
+// aren't stored, they are returned as the nodes of mssmt.EmptyTree which the
+// tree compares against.
+func getChildren(
+	height int,
+	key mssmt.NodeHash,
+	findNodes func(nodeHashes ...[]byte) ([]*nodeDoc, error),
+) (mssmt.Node, mssmt.Node, error) {
+
+	if key == mssmt.EmptyTree[height].NodeHash() {
+		return mssmt.EmptyTree[height+1], mssmt.EmptyTree[height+1], nil
+	}
+
+	branch, err := findNodes(key[:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(branch) != 1 || branch[0].Type != nodeTypeBranch {
+		return nil, nil, fmt.Errorf("%w: branch %v", ErrNodeNotFound, key)
+	}
+
+	emptyHash := mssmt.EmptyTree[height+1].NodeHash()
+
+	var childHashes [][]byte
+	for _, childHash := range [][]byte{branch[0].LeftHash, branch[0].RightHash} {
+		if mssmt.NodeHash(childHash) != emptyHash {
+			childHashes = append(childHashes, childHash)
+		}
+	}
+
+	children, err := findNodes(childHashes...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	childByHash := make(map[mssmt.NodeHash]*nodeDoc, len(children))
+	for _, child := range children {
+		childByHash[mssmt.NodeHash(child.NodeHash)] = child
+	}
+
+	child := func(childHash []byte) (mssmt.Node, error) {
+		nodeHash := mssmt.NodeHash(childHash)
+		if nodeHash == emptyHash {
+			return mssmt.EmptyTree[height+1], nil
+		}
+
+		doc, ok := childByHash[nodeHash]
+		if !ok {
+			return nil, fmt.Errorf("%w: child %v", ErrNodeNotFound, nodeHash)
+		}
+
+		return doc.node(height + 1)
+	}
+
+	left, err := child(branch[0].LeftHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	right, err := child(branch[0].RightHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return left, right, nil
+}
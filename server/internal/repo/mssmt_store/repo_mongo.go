@@ -0,0 +1,285 @@
+package mssmtstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cmrepo "github.com/quocky/taproot-asset/server/internal/repo/common"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// MongoDriverName is the name the MongoDB tree store driver is registered
+// with.
+const MongoDriverName = "mongo"
+
+const (
+	nodesCollName = "mssmt_nodes"
+	rootsCollName = "mssmt_roots"
+)
+
+// rootDoc points to the root branch of a tree.
+type rootDoc struct {
+	Namespace string `json:"namespace"`
+	NodeHash  []byte `json:"node_hash"`
+	Sum       uint64 `json:"sum"`
+}
+
+// RepoMongo is a mssmt.TreeStore keeping the nodes of a tree in MongoDB. The
+// nodes of all trees share one collection, each tree has its own namespace.
+// Update and View run in a MongoDB transaction, so a tree is never seen half
+// updated.
+type RepoMongo struct {
+	db        *mongo.Database
+	namespace string
+}
+
+// A compile time check to ensure RepoMongo implements mssmt.TreeStore.
+var _ mssmt.TreeStore = (*RepoMongo)(nil)
+
+func init() {
+	err := mssmt.RegisterTreeStore(&mssmt.TreeStoreDriver{
+		Name: MongoDriverName,
+		New:  newMongoFromArgs,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// newMongoFromArgs creates a store from the driver arguments, the database
+// and the namespace of the tree.
+func newMongoFromArgs(args ...any) (mssmt.TreeStore, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("invalid number of arguments %d, "+
+			"expected database and namespace", len(args))
+	}
+
+	db, ok := args[0].(*mongo.Database)
+	if !ok {
+		return nil, fmt.Errorf("invalid database argument %T", args[0])
+	}
+
+	namespace, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid namespace argument %T", args[1])
+	}
+
+	return NewRepoMongo(db, namespace), nil
+}
+
+// EnsureIndexes creates the indexes of the node and root collections.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(nodesCollName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "namespace", Value: 1}, {Key: "node_hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(rootsCollName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "namespace", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+func (r *RepoMongo) Update(ctx context.Context, update func(tx mssmt.TreeStoreUpdateTx) error) error {
+	return r.runTx(ctx, func(tx *mongoTreeTx) error {
+		return update(tx)
+	})
+}
+
+func (r *RepoMongo) View(ctx context.Context, view func(tx mssmt.TreeStoreViewTx) error) error {
+	return r.runTx(ctx, func(tx *mongoTreeTx) error {
+		return view(tx)
+	})
+}
+
+// runTx runs the closure in a MongoDB transaction. The session of the context,
+// e.g. the transaction of the repos, is joined instead.
+func (r *RepoMongo) runTx(ctx context.Context, f func(tx *mongoTreeTx) error) error {
+	if session := mongo.SessionFromContext(ctx); session != nil {
+		return f(&mongoTreeTx{
+			ctx:       mongo.NewSessionContext(ctx, session),
+			nodes:     r.db.Collection(nodesCollName),
+			roots:     r.db.Collection(rootsCollName),
+			namespace: r.namespace,
+		})
+	}
+
+	session, err := r.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// Reads in a transaction must go to the primary, while the client
+	// defaults to reading from secondaries.
+	txnOptions := options.Transaction().
+		SetReadPreference(readpref.Primary()).
+		SetWriteConcern(writeconcern.Majority())
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (any, error) {
+		return nil, f(&mongoTreeTx{
+			ctx:       sessCtx,
+			nodes:     r.db.Collection(nodesCollName),
+			roots:     r.db.Collection(rootsCollName),
+			namespace: r.namespace,
+		})
+	}, txnOptions)
+
+	return err
+}
+
+// mongoTreeTx implements the tree store transactions on the collections, all
+// operations run in the context of the MongoDB session.
+type mongoTreeTx struct {
+	ctx       mongo.SessionContext
+	nodes     *mongo.Collection
+	roots     *mongo.Collection
+	namespace string
+}
+
+func (t *mongoTreeTx) GetChildren(height int, key mssmt.NodeHash) (mssmt.Node, mssmt.Node, error) {
+	return getChildren(height, key, t.findNodes)
+}
+
+func (t *mongoTreeTx) RootNode() (mssmt.Node, error) {
+	var root rootDoc
+	err := t.roots.FindOne(t.ctx, bson.M{"namespace": t.namespace}).Decode(&root)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return mssmt.EmptyTree[0], nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(root.NodeHash) != len(mssmt.NodeHash{}) {
+		return nil, fmt.Errorf("invalid root of tree %s", t.namespace)
+	}
+
+	return mssmt.NewComputedBranch(mssmt.NodeHash(root.NodeHash), root.Sum), nil
+}
+
+func (t *mongoTreeTx) UpdateRoot(root *mssmt.BranchNode) error {
+	if err := cmrepo.CheckIntRange("sum", root.NodeSum()); err != nil {
+		return err
+	}
+
+	nodeHash := root.NodeHash()
+
+	_, err := t.roots.UpdateOne(
+		t.ctx,
+		bson.M{"namespace": t.namespace},
+		bson.M{"$set": rootDoc{
+			Namespace: t.namespace,
+			NodeHash:  nodeHash[:],
+			Sum:       root.NodeSum(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+func (t *mongoTreeTx) InsertBranch(branch *mssmt.BranchNode) error {
+	return t.upsertNode(branch.NodeHash(), newBranchDoc(branch))
+}
+
+func (t *mongoTreeTx) InsertLeaf(leaf *mssmt.LeafNode) error {
+	return t.upsertNode(leaf.NodeHash(), newLeafDoc(leaf))
+}
+
+func (t *mongoTreeTx) InsertCompactedLeaf(leaf *mssmt.CompactedLeafNode) error {
+	return t.upsertNode(leaf.NodeHash(), newCompactedLeafDoc(leaf))
+}
+
+func (t *mongoTreeTx) DeleteBranch(key mssmt.NodeHash) error {
+	return t.deleteNode(key)
+}
+
+func (t *mongoTreeTx) DeleteLeaf(key mssmt.NodeHash) error {
+	return t.deleteNode(key)
+}
+
+func (t *mongoTreeTx) DeleteCompactedLeaf(key mssmt.NodeHash) error {
+	return t.deleteNode(key)
+}
+
+func (t *mongoTreeTx) DeleteRoot() error {
+	_, err := t.roots.DeleteOne(t.ctx, bson.M{"namespace": t.namespace})
+
+	return err
+}
+
+func (t *mongoTreeTx) DeleteAllNodes() error {
+	_, err := t.nodes.DeleteMany(t.ctx, bson.M{"namespace": t.namespace})
+
+	return err
+}
+
+// findNodes returns the nodes of the namespace with the given hashes.
+func (t *mongoTreeTx) findNodes(nodeHashes ...[]byte) ([]*nodeDoc, error) {
+	docs := make([]*nodeDoc, 0, len(nodeHashes))
+	if len(nodeHashes) == 0 {
+		return docs, nil
+	}
+
+	cursor, err := t.nodes.Find(t.ctx, bson.M{
+		"namespace": t.namespace,
+		"node_hash": bson.M{"$in": nodeHashes},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(t.ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+// upsertNode stores the node under its hash.
+func (t *mongoTreeTx) upsertNode(nodeHash mssmt.NodeHash, doc nodeDoc) error {
+	if err := cmrepo.CheckIntRange("sum", doc.Sum); err != nil {
+		return err
+	}
+
+	doc.Namespace = t.namespace
+	doc.NodeHash = nodeHash[:]
+
+	_, err := t.nodes.ReplaceOne(
+		t.ctx,
+		bson.M{"namespace": t.namespace, "node_hash": nodeHash[:]},
+		doc,
+		options.Replace().SetUpsert(true),
+	)
+
+	return err
+}
+
+// deleteNode deletes the node with the given hash.
+func (t *mongoTreeTx) deleteNode(nodeHash mssmt.NodeHash) error {
+	_, err := t.nodes.DeleteOne(t.ctx, bson.M{
+		"namespace": t.namespace,
+		"node_hash": nodeHash[:],
+	})
+
+	return err
+}
+
+func NewRepoMongo(db *mongo.Database, namespace string) *RepoMongo {
+	return &RepoMongo{
+		db:        db,
+		namespace: namespace,
+	}
+}
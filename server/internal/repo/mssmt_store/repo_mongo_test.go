@@ -0,0 +1,286 @@
+package mssmtstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mssmtstore "github.com/quocky/taproot-asset/server/internal/repo/mssmt_store"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const testNamespace = "test"
+
+// startedCommands returns the names of the commands sent to the mock
+// deployment, and the commands by name in the order they were sent.
+func startedCommands(mt *mtest.T) ([]string, map[string][]bson.Raw) {
+	var (
+		names    []string
+		commands = make(map[string][]bson.Raw)
+	)
+
+	for e := mt.GetStartedEvent(); e != nil; e = mt.GetStartedEvent() {
+		names = append(names, e.CommandName)
+		commands[e.CommandName] = append(commands[e.CommandName], e.Command)
+	}
+
+	return names, commands
+}
+
+// storedNode is a node document as sent to MongoDB.
+type storedNode struct {
+	Namespace string `bson:"namespace"`
+	NodeHash  []byte `bson:"node_hash"`
+	Type      uint8  `bson:"type"`
+	Sum       uint64 `bson:"sum"`
+	LeftHash  []byte `bson:"left_hash,omitempty"`
+	RightHash []byte `bson:"right_hash,omitempty"`
+	Key       []byte `bson:"key,omitempty"`
+	Value     []byte `bson:"value,omitempty"`
+}
+
+// requireUpsert checks that the update command upserts the node document
+// under the hash of the node.
+func requireUpsert(mt *mtest.T, command bson.Raw, expected storedNode) {
+	mt.Helper()
+
+	update := command.Lookup("updates").Array().Index(0).Value().Document()
+	require.True(mt, update.Lookup("upsert").Boolean())
+
+	var filter, doc storedNode
+	require.NoError(mt, bson.Unmarshal(update.Lookup("q").Document(), &filter))
+	require.NoError(mt, bson.Unmarshal(update.Lookup("u").Document(), &doc))
+
+	require.Equal(mt, storedNode{Namespace: testNamespace, NodeHash: expected.NodeHash}, filter)
+	require.Equal(mt, expected, doc)
+}
+
+// nodeResponse returns the cursor response of a find of the node documents.
+func nodeResponse(docs ...bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, "test.mssmt_nodes", mtest.FirstBatch, docs...)
+}
+
+// TestRepoMongo makes sure the tree operations are sent to MongoDB as one
+// transaction per update or view, with the nodes stored under their hashes.
+func TestRepoMongo(t *testing.T) {
+	ctx := context.Background()
+	mt := mtest.New(t, mtest.NewOptions().
+		ClientType(mtest.Mock).
+		ClientOptions(database.MongoClientOptions()),
+	)
+
+	var (
+		leafKey   = [32]byte{1}
+		leaf      = mssmt.NewLeafNode([]byte{1}, 1)
+		compacted = mssmt.NewCompactedLeafNode(1, &leafKey, mssmt.NewLeafNode([]byte{2}, 2))
+		branch    = mssmt.NewBranch(compacted, mssmt.EmptyTree[1])
+	)
+
+	mt.Run("insert", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		repo := mssmtstore.NewRepoMongo(mt.DB, testNamespace)
+		err := repo.Update(ctx, func(tx mssmt.TreeStoreUpdateTx) error {
+			return errors.Join(
+				tx.InsertLeaf(leaf),
+				tx.InsertCompactedLeaf(compacted),
+				tx.InsertBranch(branch),
+				tx.UpdateRoot(branch),
+			)
+		})
+		require.NoError(mt, err)
+
+		names, commands := startedCommands(mt)
+		require.Equal(mt, []string{"update", "update", "update", "update", "commitTransaction"}, names)
+		require.True(mt, commands["update"][0].Lookup("startTransaction").Boolean())
+
+		var (
+			leafHash      = leaf.NodeHash()
+			compactedHash = compacted.NodeHash()
+			branchHash    = branch.NodeHash()
+			emptyHash     = mssmt.EmptyTree[1].NodeHash()
+		)
+
+		requireUpsert(mt, commands["update"][0], storedNode{
+			Namespace: testNamespace,
+			NodeHash:  leafHash[:],
+			Type:      1,
+			Sum:       1,
+			Value:     []byte{1},
+		})
+		requireUpsert(mt, commands["update"][1], storedNode{
+			Namespace: testNamespace,
+			NodeHash:  compactedHash[:],
+			Type:      2,
+			Sum:       2,
+			Key:       leafKey[:],
+			Value:     []byte{2},
+		})
+		requireUpsert(mt, commands["update"][2], storedNode{
+			Namespace: testNamespace,
+			NodeHash:  branchHash[:],
+			Type:      0,
+			Sum:       2,
+			LeftHash:  compactedHash[:],
+			RightHash: emptyHash[:],
+		})
+
+		root := commands["update"][3].Lookup("updates").Array().Index(0).Value().Document()
+		require.Equal(mt, "mssmt_roots", commands["update"][3].Lookup("update").StringValue())
+		require.True(mt, root.Lookup("upsert").Boolean())
+	})
+
+	mt.Run("delete", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		repo := mssmtstore.NewRepoMongo(mt.DB, testNamespace)
+		err := repo.Update(ctx, func(tx mssmt.TreeStoreUpdateTx) error {
+			return errors.Join(
+				tx.DeleteLeaf(leaf.NodeHash()),
+				tx.DeleteRoot(),
+				tx.DeleteAllNodes(),
+			)
+		})
+		require.NoError(mt, err)
+
+		names, commands := startedCommands(mt)
+		require.Equal(mt, []string{"delete", "delete", "delete", "commitTransaction"}, names)
+
+		filters := make([]bson.M, len(commands["delete"]))
+		for i, command := range commands["delete"] {
+			deletion := command.Lookup("deletes").Array().Index(0).Value().Document()
+			require.NoError(mt, bson.Unmarshal(deletion.Lookup("q").Document(), &filters[i]))
+		}
+
+		leafHash := leaf.NodeHash()
+		require.Equal(mt, bson.M{"namespace": testNamespace, "node_hash": primitive.Binary{Data: leafHash[:]}}, filters[0])
+		require.Equal(mt, "mssmt_roots", commands["delete"][1].Lookup("delete").StringValue())
+		require.Equal(mt, bson.M{"namespace": testNamespace}, filters[1])
+		require.Equal(mt, "mssmt_nodes", commands["delete"][2].Lookup("delete").StringValue())
+		require.Equal(mt, bson.M{"namespace": testNamespace}, filters[2])
+	})
+
+	branchHash := branch.NodeHash()
+	leftHash := compacted.NodeHash()
+	rightHash := mssmt.EmptyTree[1].NodeHash()
+
+	branchDoc := bson.D{
+		{Key: "namespace", Value: testNamespace},
+		{Key: "node_hash", Value: branchHash[:]},
+		{Key: "type", Value: 0},
+		{Key: "sum", Value: int64(2)},
+		{Key: "left_hash", Value: leftHash[:]},
+		{Key: "right_hash", Value: rightHash[:]},
+	}
+	compactedDoc := bson.D{
+		{Key: "namespace", Value: testNamespace},
+		{Key: "node_hash", Value: leftHash[:]},
+		{Key: "type", Value: 2},
+		{Key: "sum", Value: int64(2)},
+		{Key: "key", Value: leafKey[:]},
+		{Key: "value", Value: []byte{2}},
+	}
+
+	mt.Run("children", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			nodeResponse(branchDoc),
+			nodeResponse(compactedDoc),
+			mtest.CreateSuccessResponse(),
+		)
+
+		repo := mssmtstore.NewRepoMongo(mt.DB, testNamespace)
+		err := repo.View(ctx, func(tx mssmt.TreeStoreViewTx) error {
+			left, right, err := tx.GetChildren(0, branchHash)
+			if err != nil {
+				return err
+			}
+
+			require.Equal(mt, compacted.NodeHash(), left.NodeHash())
+			require.EqualValues(mt, 2, left.NodeSum())
+			require.Equal(mt, rightHash, right.NodeHash())
+
+			return nil
+		})
+		require.NoError(mt, err)
+	})
+
+	missingTests := []struct {
+		name      string
+		responses []bson.D
+	}{
+		{
+			name:      "missing branch",
+			responses: []bson.D{nodeResponse()},
+		},
+		{
+			name:      "missing child",
+			responses: []bson.D{nodeResponse(branchDoc), nodeResponse()},
+		},
+	}
+
+	for _, test := range missingTests {
+		mt.Run(test.name, func(mt *mtest.T) {
+			mt.AddMockResponses(test.responses...)
+			mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+			repo := mssmtstore.NewRepoMongo(mt.DB, testNamespace)
+			err := repo.View(ctx, func(tx mssmt.TreeStoreViewTx) error {
+				_, _, err := tx.GetChildren(0, branchHash)
+
+				return err
+			})
+			require.ErrorIs(mt, err, mssmtstore.ErrNodeNotFound)
+
+			names, _ := startedCommands(mt)
+			require.Equal(mt, "abortTransaction", names[len(names)-1])
+		})
+	}
+
+	mt.Run("transaction retry", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code:    112,
+				Name:    "WriteConflict",
+				Message: "write conflict",
+				Labels:  []string{"TransientTransactionError"},
+			}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		numRuns := 0
+		repo := mssmtstore.NewRepoMongo(mt.DB, testNamespace)
+		err := repo.Update(ctx, func(tx mssmt.TreeStoreUpdateTx) error {
+			numRuns++
+
+			return tx.InsertLeaf(leaf)
+		})
+		require.NoError(mt, err)
+		require.Equal(mt, 2, numRuns)
+
+		names, commands := startedCommands(mt)
+		require.Equal(mt, []string{"update", "abortTransaction", "update", "commitTransaction"}, names)
+
+		for i, command := range commands["update"] {
+			require.True(mt, command.Lookup("startTransaction").Boolean())
+			require.EqualValues(mt, i+1, command.Lookup("txnNumber").Int64())
+		}
+	})
+}
@@ -0,0 +1,316 @@
+package mssmtstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	cmrepo "github.com/quocky/taproot-asset/server/internal/repo/common"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+)
+
+// SQLDriverName is the name the SQL tree store driver is registered with.
+const SQLDriverName = "sql"
+
+// RepoSQL is a mssmt.TreeStore keeping the nodes of a tree in the mssmt_nodes
+// table of a SQL database, each tree in its own namespace. Update and View
+// run in a SQL transaction, or in the transaction of the repos in the context.
+type RepoSQL struct {
+	db        *database.SQLDatabase
+	namespace string
+}
+
+// A compile time check to ensure RepoSQL implements mssmt.TreeStore.
+var _ mssmt.TreeStore = (*RepoSQL)(nil)
+
+func init() {
+	err := mssmt.RegisterTreeStore(&mssmt.TreeStoreDriver{
+		Name: SQLDriverName,
+		New:  newSQLFromArgs,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// newSQLFromArgs creates a store from the driver arguments, the database and
+// the namespace of the tree.
+func newSQLFromArgs(args ...any) (mssmt.TreeStore, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("invalid number of arguments %d, "+
+			"expected database and namespace", len(args))
+	}
+
+	db, ok := args[0].(*database.SQLDatabase)
+	if !ok {
+		return nil, fmt.Errorf("invalid database argument %T", args[0])
+	}
+
+	namespace, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid namespace argument %T", args[1])
+	}
+
+	return NewRepoSQL(db, namespace), nil
+}
+
+func (r *RepoSQL) Update(ctx context.Context, update func(tx mssmt.TreeStoreUpdateTx) error) error {
+	return r.runTx(ctx, func(tx *sqlTreeTx) error {
+		return update(tx)
+	})
+}
+
+func (r *RepoSQL) View(ctx context.Context, view func(tx mssmt.TreeStoreViewTx) error) error {
+	return r.runTx(ctx, func(tx *sqlTreeTx) error {
+		return view(tx)
+	})
+}
+
+// runTx runs the closure in a SQL transaction, which is committed if the
+// closure succeeds and rolled back otherwise. A transaction run by the repos in
+// the context is joined instead, it is committed or rolled back with the
+// writes of the repos.
+func (r *RepoSQL) runTx(ctx context.Context, f func(tx *sqlTreeTx) error) error {
+	if sqlTx, ok := cmrepo.SQLTx(ctx); ok {
+		return f(&sqlTreeTx{
+			ctx:       ctx,
+			tx:        sqlTx,
+			dialect:   r.db.Dialect,
+			namespace: r.namespace,
+		})
+	}
+
+	sqlTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = f(&sqlTreeTx{
+		ctx:       ctx,
+		tx:        sqlTx,
+		dialect:   r.db.Dialect,
+		namespace: r.namespace,
+	})
+	if err != nil {
+		return errors.Join(err, sqlTx.Rollback())
+	}
+
+	return sqlTx.Commit()
+}
+
+// sqlTreeTx implements the tree store transactions on the tables.
+type sqlTreeTx struct {
+	ctx       context.Context
+	tx        *sql.Tx
+	dialect   database.SQLDialect
+	namespace string
+}
+
+func (t *sqlTreeTx) GetChildren(height int, key mssmt.NodeHash) (mssmt.Node, mssmt.Node, error) {
+	return getChildren(height, key, t.findNodes)
+}
+
+func (t *sqlTreeTx) RootNode() (mssmt.Node, error) {
+	var (
+		nodeHash []byte
+		sum      uint64
+	)
+
+	err := t.tx.QueryRowContext(
+		t.ctx,
+		fmt.Sprintf(
+			"SELECT node_hash, sum FROM mssmt_roots WHERE namespace = %s",
+			t.dialect.Placeholder(1),
+		),
+		t.namespace,
+	).Scan(&nodeHash, &sum)
+	if errors.Is(err, sql.ErrNoRows) {
+		return mssmt.EmptyTree[0], nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodeHash) != len(mssmt.NodeHash{}) {
+		return nil, fmt.Errorf("invalid root of tree %s", t.namespace)
+	}
+
+	return mssmt.NewComputedBranch(mssmt.NodeHash(nodeHash), sum), nil
+}
+
+func (t *sqlTreeTx) UpdateRoot(root *mssmt.BranchNode) error {
+	if err := cmrepo.CheckIntRange("sum", root.NodeSum()); err != nil {
+		return err
+	}
+
+	nodeHash := root.NodeHash()
+
+	_, err := t.tx.ExecContext(
+		t.ctx,
+		fmt.Sprintf(`
+			INSERT INTO mssmt_roots (namespace, node_hash, sum)
+			VALUES (%s, %s, %s)
+			ON CONFLICT (namespace) DO UPDATE
+			SET node_hash = excluded.node_hash, sum = excluded.sum`,
+			t.dialect.Placeholder(1), t.dialect.Placeholder(2),
+			t.dialect.Placeholder(3),
+		),
+		t.namespace, nodeHash[:], root.NodeSum(),
+	)
+
+	return err
+}
+
+func (t *sqlTreeTx) InsertBranch(branch *mssmt.BranchNode) error {
+	return t.upsertNode(branch.NodeHash(), newBranchDoc(branch))
+}
+
+func (t *sqlTreeTx) InsertLeaf(leaf *mssmt.LeafNode) error {
+	return t.upsertNode(leaf.NodeHash(), newLeafDoc(leaf))
+}
+
+func (t *sqlTreeTx) InsertCompactedLeaf(leaf *mssmt.CompactedLeafNode) error {
+	return t.upsertNode(leaf.NodeHash(), newCompactedLeafDoc(leaf))
+}
+
+func (t *sqlTreeTx) DeleteBranch(key mssmt.NodeHash) error {
+	return t.deleteNode(key)
+}
+
+func (t *sqlTreeTx) DeleteLeaf(key mssmt.NodeHash) error {
+	return t.deleteNode(key)
+}
+
+func (t *sqlTreeTx) DeleteCompactedLeaf(key mssmt.NodeHash) error {
+	return t.deleteNode(key)
+}
+
+func (t *sqlTreeTx) DeleteRoot() error {
+	_, err := t.tx.ExecContext(
+		t.ctx,
+		fmt.Sprintf(
+			"DELETE FROM mssmt_roots WHERE namespace = %s",
+			t.dialect.Placeholder(1),
+		),
+		t.namespace,
+	)
+
+	return err
+}
+
+func (t *sqlTreeTx) DeleteAllNodes() error {
+	_, err := t.tx.ExecContext(
+		t.ctx,
+		fmt.Sprintf(
+			"DELETE FROM mssmt_nodes WHERE namespace = %s",
+			t.dialect.Placeholder(1),
+		),
+		t.namespace,
+	)
+
+	return err
+}
+
+// findNodes returns the nodes of the namespace with the given hashes.
+func (t *sqlTreeTx) findNodes(nodeHashes ...[]byte) ([]*nodeDoc, error) {
+	docs := make([]*nodeDoc, 0, len(nodeHashes))
+	if len(nodeHashes) == 0 {
+		return docs, nil
+	}
+
+	var (
+		args         = []any{t.namespace}
+		placeholders = make([]string, len(nodeHashes))
+	)
+
+	for i, nodeHash := range nodeHashes {
+		args = append(args, nodeHash)
+		placeholders[i] = t.dialect.Placeholder(len(args))
+	}
+
+	rows, err := t.tx.QueryContext(
+		t.ctx,
+		fmt.Sprintf(`
+			SELECT node_hash, type, sum, left_hash, right_hash, key, value
+			FROM mssmt_nodes
+			WHERE namespace = %s AND node_hash IN (%s)`,
+			t.dialect.Placeholder(1), strings.Join(placeholders, ", "),
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		doc := &nodeDoc{Namespace: t.namespace}
+
+		err := rows.Scan(
+			&doc.NodeHash, &doc.Type, &doc.Sum, &doc.LeftHash,
+			&doc.RightHash, &doc.Key, &doc.Value,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, rows.Err()
+}
+
+// upsertNode stores the node under its hash.
+func (t *sqlTreeTx) upsertNode(nodeHash mssmt.NodeHash, doc nodeDoc) error {
+	if err := cmrepo.CheckIntRange("sum", doc.Sum); err != nil {
+		return err
+	}
+
+	placeholders := make([]string, 8)
+	for i := range placeholders {
+		placeholders[i] = t.dialect.Placeholder(i + 1)
+	}
+
+	_, err := t.tx.ExecContext(
+		t.ctx,
+		fmt.Sprintf(`
+			INSERT INTO mssmt_nodes (namespace, node_hash, type, sum,
+				left_hash, right_hash, key, value)
+			VALUES (%s)
+			ON CONFLICT (namespace, node_hash) DO UPDATE
+			SET type = excluded.type, sum = excluded.sum,
+				left_hash = excluded.left_hash,
+				right_hash = excluded.right_hash,
+				key = excluded.key, value = excluded.value`,
+			strings.Join(placeholders, ", "),
+		),
+		t.namespace, nodeHash[:], doc.Type, doc.Sum, doc.LeftHash,
+		doc.RightHash, doc.Key, doc.Value,
+	)
+
+	return err
+}
+
+// deleteNode deletes the node with the given hash.
+func (t *sqlTreeTx) deleteNode(nodeHash mssmt.NodeHash) error {
+	_, err := t.tx.ExecContext(
+		t.ctx,
+		fmt.Sprintf(
+			"DELETE FROM mssmt_nodes WHERE namespace = %s AND node_hash = %s",
+			t.dialect.Placeholder(1), t.dialect.Placeholder(2),
+		),
+		t.namespace, nodeHash[:],
+	)
+
+	return err
+}
+
+func NewRepoSQL(db *database.SQLDatabase, namespace string) *RepoSQL {
+	return &RepoSQL{
+		db:        db,
+		namespace: namespace,
+	}
+}
@@ -0,0 +1,48 @@
+package mssmtstore_test
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+
+	config "github.com/quocky/taproot-asset/server/config/core"
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	mssmtstore "github.com/quocky/taproot-asset/server/internal/repo/mssmt_store"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRepoSQLSumRange makes sure sums up to math.MaxInt64 are stored and
+// larger ones are rejected instead of overflowing the column.
+func TestRepoSQLSumRange(t *testing.T) {
+	logger.Init()
+
+	db, err := database.NewSQLDatabase(&config.Config{
+		Database: config.Database{
+			Backend: string(database.SQLite),
+			DSN:     "file:" + filepath.Join(t.TempDir(), "taproot.db"),
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	tree := mssmt.NewCompactedTree(mssmtstore.NewRepoSQL(db, "test"))
+
+	_, err = tree.Insert(
+		ctx, [32]byte{1}, mssmt.NewLeafNode([]byte{1}, math.MaxInt64),
+	)
+	require.NoError(t, err)
+
+	root, err := tree.Root(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, math.MaxInt64, root.NodeSum())
+
+	_, err = tree.Insert(
+		ctx, [32]byte{2}, mssmt.NewLeafNode([]byte{2}, math.MaxInt64+1),
+	)
+	require.ErrorIs(t, err, common.ErrDatabaseValueOutOfRange)
+}
@@ -0,0 +1,170 @@
+package proofarchive
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// ProofsBucket is the name of the GridFS bucket the proof files are
+	// kept in.
+	ProofsBucket = "proofs"
+
+	// DeliveriesBucket is the name of the GridFS bucket the proof files
+	// delivered to the proof courier are kept in.
+	DeliveriesBucket = "proof_deliveries"
+)
+
+// RepoGridFS is a proof.Archiver that keeps the proof files in a GridFS bucket,
+// named after the hex encoded hash of their locator. Each revision of a proof
+// file is a GridFS file of that name, the latest upload is the latest
+// revision.
+type RepoGridFS struct {
+	bucket *gridfs.Bucket
+}
+
+// A compile time check to ensure RepoGridFS implements proof.Archiver.
+var _ proof.Archiver = (*RepoGridFS)(nil)
+
+func (r *RepoGridFS) FetchProof(ctx context.Context,
+	loc proof.Locator) (proof.Blob, error) {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FetchProofByHash(ctx, locHash)
+}
+
+// FetchProofByHash returns the latest revision of the proof file of the
+// locator hash.
+func (r *RepoGridFS) FetchProofByHash(_ context.Context,
+	locHash [32]byte) (proof.Blob, error) {
+
+	var buf bytes.Buffer
+	_, err := r.bucket.DownloadToStreamByName(
+		hex.EncodeToString(locHash[:]), &buf,
+	)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, proof.ErrProofNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FetchProofRevisions returns the revisions of the proof file of the locator in
+// the order they were uploaded.
+func (r *RepoGridFS) FetchProofRevisions(ctx context.Context,
+	loc proof.Locator) ([]proof.Blob, error) {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.bucket.FindContext(
+		ctx,
+		bson.M{"filename": hex.EncodeToString(locHash[:])},
+		options.GridFSFind().SetSort(bson.D{{Key: "uploadDate", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var files []gridfs.File
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, proof.ErrProofNotFound
+	}
+
+	revisions := make([]proof.Blob, len(files))
+	for i, file := range files {
+		var buf bytes.Buffer
+		if _, err := r.bucket.DownloadToStream(file.ID, &buf); err != nil {
+			return nil, err
+		}
+
+		revisions[i] = buf.Bytes()
+	}
+
+	return revisions, nil
+}
+
+func (r *RepoGridFS) HasProof(ctx context.Context,
+	loc proof.Locator) (bool, error) {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return false, err
+	}
+
+	numFiles, err := r.bucket.GetFilesCollection().CountDocuments(
+		ctx, bson.M{"filename": hex.EncodeToString(locHash[:])},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return numFiles > 0, nil
+}
+
+// ImportProofs uploads the proof files as new revisions, unless they already
+// are the latest revision. A file only becomes visible once all of its chunks
+// are uploaded.
+func (r *RepoGridFS) ImportProofs(ctx context.Context,
+	proofs ...*proof.AnnotatedProof) error {
+
+	for _, p := range proofs {
+		locHash, err := p.Locator.Hash()
+		if err != nil {
+			return err
+		}
+
+		latest, err := r.FetchProofByHash(ctx, locHash)
+		switch {
+		case err == nil && bytes.Equal(latest, p.Blob):
+			continue
+
+		case err != nil && !errors.Is(err, proof.ErrProofNotFound):
+			return err
+		}
+
+		_, err = r.bucket.UploadFromStream(
+			hex.EncodeToString(locHash[:]), bytes.NewReader(p.Blob),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func NewRepoGridFS(db *mongo.Database, bucketName string) (proof.Archiver, error) {
+	bucket, err := gridfs.NewBucket(
+		db, options.GridFSBucket().SetName(bucketName),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RepoGridFS{
+		bucket: bucket,
+	}, nil
+}
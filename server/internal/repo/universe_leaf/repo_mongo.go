@@ -0,0 +1,49 @@
+package universeleaf
+
+import (
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	cmrepo "github.com/quocky/taproot-asset/server/internal/repo/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/net/context"
+)
+
+type RepoMongo struct {
+	*cmrepo.RepoMongo
+}
+
+// FindAssetIDs returns the IDs of all assets with universe leaves.
+func (r *RepoMongo) FindAssetIDs(ctx context.Context) ([][]byte, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{
+			Key:   "$group",
+			Value: bson.M{"_id": "$asset_id"},
+		}},
+		bson.D{{
+			Key:   "$sort",
+			Value: bson.M{"_id": 1},
+		}},
+	}
+
+	groups := make([]struct {
+		AssetID []byte `json:"_id"`
+	}, 0)
+	if err := r.FindAggregate(ctx, pipeline, &groups); err != nil {
+		return nil, err
+	}
+
+	assetIDs := make([][]byte, len(groups))
+	for i, group := range groups {
+		assetIDs[i] = group.AssetID
+	}
+
+	return assetIDs, nil
+}
+
+func NewRepoMongo(
+	db *mongo.Database,
+) universe.RepoInterface {
+	return &RepoMongo{
+		cmrepo.NewRepoMongo(db, "universe_leaves"),
+	}
+}
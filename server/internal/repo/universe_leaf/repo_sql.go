@@ -0,0 +1,44 @@
+package universeleaf
+
+import (
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	cmrepo "github.com/quocky/taproot-asset/server/internal/repo/common"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	"golang.org/x/net/context"
+)
+
+type RepoSQL struct {
+	*cmrepo.RepoSQL
+}
+
+// FindAssetIDs returns the IDs of all assets with universe leaves.
+func (r *RepoSQL) FindAssetIDs(ctx context.Context) ([][]byte, error) {
+	rows, err := r.Querier(ctx).QueryContext(
+		ctx,
+		"SELECT DISTINCT asset_id FROM universe_leaves ORDER BY asset_id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assetIDs := make([][]byte, 0)
+	for rows.Next() {
+		var assetID []byte
+		if err := rows.Scan(&assetID); err != nil {
+			return nil, err
+		}
+
+		assetIDs = append(assetIDs, assetID)
+	}
+
+	return assetIDs, rows.Err()
+}
+
+func NewRepoSQL(
+	db *database.SQLDatabase,
+) universe.RepoInterface {
+	return &RepoSQL{
+		cmrepo.NewRepoSQL(db, "universe_leaves"),
+	}
+}
@@ -0,0 +1,359 @@
+package chaintx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+	assetoutpoint "github.com/quocky/taproot-asset/server/internal/domain/asset_outpoint"
+	"github.com/quocky/taproot-asset/server/internal/domain/chain_tx"
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	manageutxo "github.com/quocky/taproot-asset/server/internal/domain/manage_utxo"
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	chaintxsdk "github.com/quocky/taproot-asset/taproot/http_model/chain_tx"
+	"github.com/quocky/taproot-asset/taproot/utils"
+)
+
+type UseCase struct {
+	chainTxRepo       chaintx.RepoInterface
+	manageUtxoRepo    manageutxo.RepoInterface
+	assetOutpointRepo assetoutpoint.RepoInterface
+	universeUseCase   universe.UseCaseInterface
+	rpcClient         chainClient
+}
+
+func (u *UseCase) RecordPending(
+	ctx context.Context,
+	tx *wire.MsgTx,
+	writeAssets chaintx.WriteAssetsFunc,
+) (common.ID, error) {
+	var (
+		txBytes   bytes.Buffer
+		txHash    = tx.TxHash()
+		chainTxID common.ID
+	)
+
+	if err := tx.Serialize(&txBytes); err != nil {
+		logger.Errorw("serialize anchor tx fail", "tx_hash", txHash, "err", err)
+
+		return "", err
+	}
+
+	err := u.chainTxRepo.RunTransactions(ctx, []common.TransactionCallbackFunc{
+		func(ctx context.Context) error {
+			var err error
+			chainTxID, err = u.chainTxRepo.InsertOne(ctx, chaintx.ChainTx{
+				TxID:     txHash[:],
+				AnchorTx: txBytes.Bytes(),
+				Status:   chaintx.StatusPending,
+			})
+
+			return err
+		},
+		func(ctx context.Context) error {
+			return writeAssets(ctx, chainTxID)
+		},
+	})
+	if err != nil {
+		logger.Errorw("record pending anchor tx fail", "tx_hash", txHash, "err", err)
+
+		return "", err
+	}
+
+	return chainTxID, nil
+}
+
+func (u *UseCase) Broadcast(ctx context.Context, chainTxID common.ID, tx *wire.MsgTx) error {
+	_, err := u.rpcClient.SendRawTransaction(tx, true)
+	if err != nil {
+		logger.Errorw("SendRawTransaction fail", "tx_hash", tx.TxHash(), "err", err)
+
+		if errDrop := u.Drop(ctx, chainTxID, err.Error()); errDrop != nil {
+			logger.Errorw("roll back rejected anchor tx fail", "chain_tx_id", chainTxID, "err", errDrop)
+		}
+
+		return err
+	}
+
+	// The assets are already written, so the transaction is complete even
+	// if it can't be moved into the broadcast state here. The watcher moves
+	// it once it finds it in the mempool or a block.
+	if err := u.MarkBroadcast(ctx, chainTxID); err != nil {
+		logger.Errorw("mark anchor tx broadcast fail", "chain_tx_id", chainTxID, "err", err)
+	}
+
+	return nil
+}
+
+func (u *UseCase) MarkBroadcast(ctx context.Context, chainTxID common.ID) error {
+	return u.chainTxRepo.UpdateMany(
+		ctx,
+		chaintx.ChainTxFilter{
+			IDs:    &common.InOperator{Values: []any{chainTxID}},
+			Status: utils.ToPtr(chaintx.StatusPending),
+		},
+		chaintx.ChainTxUpdate{
+			Set: &chaintx.ChainTxSetUpdate{
+				Status: utils.ToPtr(chaintx.StatusBroadcast),
+			},
+		},
+	)
+}
+
+// Drop rolls back the assets of the transaction. An outpoint which was deleted
+// by the drop of the transaction that created it stays spent.
+func (u *UseCase) Drop(ctx context.Context, chainTxID common.ID, reason string) error {
+	utxoIDs, err := anchorUtxoIDs(ctx, u.manageUtxoRepo, chainTxID)
+	if err != nil {
+		return err
+	}
+
+	locators, err := u.createdLocators(ctx, chainTxID)
+	if err != nil {
+		return err
+	}
+
+	logger.Infow("Roll back dropped anchor tx", "chain_tx_id", chainTxID, "reason", reason)
+
+	return u.chainTxRepo.RunTransactions(ctx, []common.TransactionCallbackFunc{
+		func(ctx context.Context) error {
+			return u.setStatus(ctx, chainTxID, &chaintx.ChainTxSetUpdate{
+				Status:     utils.ToPtr(chaintx.StatusFailed),
+				FailReason: utils.ToPtr(reason),
+			})
+		},
+		func(ctx context.Context) error {
+			return u.assetOutpointRepo.UpdateMany(
+				ctx,
+				assetoutpoint.UnspentOutpointFilter{
+					AnchorUtxoIDs: &common.InOperator{Values: utils.ToSliceAny(utxoIDs)},
+				},
+				assetoutpoint.UnspentOutpointUpdate{
+					Set: &assetoutpoint.UnspentOutpointSetUpdate{
+						Spent:     utils.ToPtr(true),
+						IsDeleted: utils.ToPtr(true),
+					},
+				},
+			)
+		},
+		func(ctx context.Context) error {
+			return u.assetOutpointRepo.UpdateMany(
+				ctx,
+				assetoutpoint.UnspentOutpointFilter{
+					SpentByTxID: utils.ToPtr(chainTxID),
+					IsDeleted:   utils.ToPtr(false),
+				},
+				assetoutpoint.UnspentOutpointUpdate{
+					Set: &assetoutpoint.UnspentOutpointSetUpdate{
+						Spent: utils.ToPtr(false),
+					},
+				},
+			)
+		},
+		func(ctx context.Context) error {
+			return u.universeUseCase.RemoveLeaves(ctx, locators)
+		},
+	})
+}
+
+func (u *UseCase) Unconfirm(ctx context.Context, chainTxIDs []common.ID) error {
+	var locators [][32]byte
+	for _, chainTxID := range chainTxIDs {
+		created, err := u.createdLocators(ctx, chainTxID)
+		if err != nil {
+			return err
+		}
+
+		locators = append(locators, created...)
+	}
+
+	return u.chainTxRepo.RunTransactions(ctx, []common.TransactionCallbackFunc{
+		func(ctx context.Context) error {
+			return u.chainTxRepo.UpdateMany(
+				ctx,
+				chaintx.ChainTxFilter{
+					IDs:    &common.InOperator{Values: utils.ToSliceAny(chainTxIDs)},
+					Status: utils.ToPtr(chaintx.StatusConfirmed),
+				},
+				chaintx.ChainTxUpdate{
+					Set: &chaintx.ChainTxSetUpdate{
+						Status: utils.ToPtr(chaintx.StatusBroadcast),
+					},
+				},
+			)
+		},
+		func(ctx context.Context) error {
+			return u.universeUseCase.RemoveLeaves(ctx, locators)
+		},
+	})
+}
+
+// createdLocators returns the proof locator hashes of the asset outpoints the
+// transaction created, the locators of their universe leaves.
+func (u *UseCase) createdLocators(ctx context.Context, chainTxID common.ID) ([][32]byte, error) {
+	outpoints, err := createdOutpoints(ctx, u.manageUtxoRepo, u.assetOutpointRepo, chainTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	locators := make([][32]byte, 0, len(outpoints))
+	for _, outpoint := range outpoints {
+		if len(outpoint.ProofLocator) != sha256.Size {
+			return nil, fmt.Errorf("invalid proof locator length %d", len(outpoint.ProofLocator))
+		}
+
+		locators = append(locators, [32]byte(outpoint.ProofLocator))
+	}
+
+	return locators, nil
+}
+
+func (u *UseCase) Confirm(
+	ctx context.Context,
+	chainTxID common.ID,
+	block *wire.MsgBlock,
+	height uint32,
+) error {
+	blockHash := block.BlockHash()
+
+	return u.setStatus(ctx, chainTxID, &chaintx.ChainTxSetUpdate{
+		Status:      utils.ToPtr(chaintx.StatusConfirmed),
+		BlockHeight: int32(height),
+		BlockHash:   blockHash[:],
+	})
+}
+
+func (u *UseCase) Status(ctx context.Context, txHash chainhash.Hash) (*chaintxsdk.StatusResp, error) {
+	var chainTx chaintx.ChainTx
+
+	err := u.chainTxRepo.FindOne(ctx, chaintx.ChainTxFilter{TxID: txHash[:]}, &chainTx)
+	if errors.Is(err, common.ErrDatabaseNotFound) {
+		return nil, chaintx.ErrChainTxNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &chaintxsdk.StatusResp{
+		TxHash:     txHash.String(),
+		Status:     string(chainTx.Status),
+		FailReason: chainTx.FailReason,
+	}
+
+	// Transactions recorded before the state machine were confirmed by
+	// mining a block right after the broadcast.
+	if chainTx.Status == "" && len(chainTx.BlockHash) != 0 {
+		resp.Status = string(chaintx.StatusConfirmed)
+	}
+
+	if resp.Status != string(chaintx.StatusConfirmed) {
+		return resp, nil
+	}
+
+	blockHash, err := chainhash.NewHash(chainTx.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	bestHeight, err := u.rpcClient.GetBlockCount()
+	if err != nil {
+		logger.Errorw("GetBlockCount fail", "err", err)
+
+		return nil, err
+	}
+
+	resp.BlockHeight = chainTx.BlockHeight
+	resp.BlockHash = blockHash.String()
+	resp.NumConfirmations = int32(bestHeight) - chainTx.BlockHeight + 1
+
+	return resp, nil
+}
+
+// createdOutpoints returns the asset outpoints anchored in the outputs of the
+// transaction.
+func createdOutpoints(
+	ctx context.Context,
+	manageUtxoRepo manageutxo.RepoInterface,
+	assetOutpointRepo assetoutpoint.RepoInterface,
+	chainTxID common.ID,
+) ([]*assetoutpoint.AssetOutpoint, error) {
+	utxoIDs, err := anchorUtxoIDs(ctx, manageUtxoRepo, chainTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outpoints []*assetoutpoint.AssetOutpoint
+
+	err = assetOutpointRepo.FindMany(ctx, assetoutpoint.UnspentOutpointFilter{
+		AnchorUtxoIDs: &common.InOperator{Values: utils.ToSliceAny(utxoIDs)},
+		IsDeleted:     utils.ToPtr(false),
+	}, &outpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return outpoints, nil
+}
+
+// anchorUtxoIDs returns the ids of the managed utxos of the transaction.
+func anchorUtxoIDs(
+	ctx context.Context,
+	manageUtxoRepo manageutxo.RepoInterface,
+	chainTxID common.ID,
+) ([]common.ID, error) {
+	var utxos []*manageutxo.ManagedUtxo
+
+	err := manageUtxoRepo.FindMany(ctx, manageutxo.ManagedUtxoFilter{
+		TxID: utils.ToPtr(chainTxID),
+	}, &utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]common.ID, len(utxos))
+	for i, utxo := range utxos {
+		ids[i] = utxo.ID
+	}
+
+	return ids, nil
+}
+
+// setStatus updates the chain tx with the given state fields.
+func (u *UseCase) setStatus(
+	ctx context.Context,
+	chainTxID common.ID,
+	set *chaintx.ChainTxSetUpdate,
+) error {
+	return u.chainTxRepo.UpdateMany(
+		ctx,
+		chaintx.ChainTxFilter{
+			IDs: &common.InOperator{Values: []any{chainTxID}},
+		},
+		chaintx.ChainTxUpdate{
+			Set: set,
+		},
+	)
+}
+
+func NewUseCase(
+	chainTxRepo chaintx.RepoInterface,
+	manageUtxoRepo manageutxo.RepoInterface,
+	assetOutpointRepo assetoutpoint.RepoInterface,
+	universeUseCase universe.UseCaseInterface,
+	rpcClient *rpcclient.Client,
+) chaintx.UseCaseInterface {
+	return &UseCase{
+		chainTxRepo:       chainTxRepo,
+		manageUtxoRepo:    manageUtxoRepo,
+		assetOutpointRepo: assetOutpointRepo,
+		universeUseCase:   universeUseCase,
+		rpcClient:         rpcClient,
+	}
+}
@@ -0,0 +1,554 @@
+package chaintx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+	assetoutpoint "github.com/quocky/taproot-asset/server/internal/domain/asset_outpoint"
+	"github.com/quocky/taproot-asset/server/internal/domain/chain_tx"
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	manageutxo "github.com/quocky/taproot-asset/server/internal/domain/manage_utxo"
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	"github.com/quocky/taproot-asset/server/pkg/chain"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	universemodel "github.com/quocky/taproot-asset/taproot/universe"
+	"github.com/quocky/taproot-asset/taproot/utils"
+)
+
+// failReasonDropped is the fail reason of a broadcast transaction the node
+// no longer knows, neither in its mempool nor in the chain.
+const failReasonDropped = "dropped by the node"
+
+// maxBlockTimeOffset is how far before its parent or the time it was received
+// a block may be timestamped. The catch up looks for transactions down to the
+// blocks timestamped that long before the oldest transaction was recorded.
+const maxBlockTimeOffset = 2 * time.Hour
+
+// rebroadcastDelay is how long a pending transaction is left to the request
+// which recorded it before the watcher broadcasts it again.
+const rebroadcastDelay = time.Minute
+
+// chainClient is the part of the RPC interface of the node the chain tx use
+// case and the watcher use. None of the calls needs the transaction index of
+// the node.
+type chainClient interface {
+	GetBestBlockHash() (*chainhash.Hash, error)
+	GetBlockCount() (int64, error)
+	GetBlockHash(blockHeight int64) (*chainhash.Hash, error)
+	GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+	GetRawMempool() ([]*chainhash.Hash, error)
+	SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error)
+}
+
+// minedBlock is the block of the best chain a transaction was mined in.
+type minedBlock struct {
+	hash   chainhash.Hash
+	height int32
+}
+
+type Watcher struct {
+	chainTxRepo       chaintx.RepoInterface
+	chainTxUseCase    chaintx.UseCaseInterface
+	manageUtxoRepo    manageutxo.RepoInterface
+	assetOutpointRepo assetoutpoint.RepoInterface
+	chainClient       chainClient
+	notifier          *chain.BlockNotifier
+	headerVerifier    proof.HeaderVerifier
+	archiver          proof.Archiver
+	universeUseCase   universe.UseCaseInterface
+
+	// caughtUp is set once the blocks mined while the watcher wasn't
+	// running are looked up.
+	caughtUp bool
+
+	// tip is the last connected block the watcher processed, zero after a
+	// disconnected block until the next block is connected.
+	tip chainhash.Hash
+
+	// mined holds the blocks the pending and broadcast transactions were
+	// mined in until they are confirmed.
+	mined map[chainhash.Hash]minedBlock
+
+	// rebroadcastDelay is how old a pending transaction the node doesn't
+	// know has to be before it is broadcast again.
+	rebroadcastDelay time.Duration
+}
+
+func (w *Watcher) Run(ctx context.Context) {
+	// Blocks mined while the server was down were not notified, the
+	// transactions they confirmed are found by the catch up of the sweep.
+	w.sweep(ctx)
+
+	for {
+		select {
+		case <-w.notifier.Signal():
+
+		case <-ctx.Done():
+			return
+		}
+
+		for _, event := range w.notifier.Events() {
+			var err error
+			if event.Connected {
+				err = w.blockConnected(ctx, event)
+			} else {
+				err = w.blockDisconnected(ctx, event)
+			}
+
+			if err != nil {
+				logger.Errorw("process block event fail", "block_hash", event.Hash, "height", event.Height, "connected", event.Connected, "err", err)
+			}
+		}
+
+		w.sweep(ctx)
+	}
+}
+
+// blockConnected records the pending and broadcast transactions mined in the
+// block, for the sweep to confirm them.
+func (w *Watcher) blockConnected(ctx context.Context, event chain.BlockEvent) error {
+	chainTxs, err := w.unconfirmed(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(chainTxs) > 0 {
+		block, err := w.chainClient.GetBlock(&event.Hash)
+		if err != nil {
+			return err
+		}
+
+		w.recordMined(chainTxs, block, minedBlock{
+			hash:   event.Hash,
+			height: event.Height,
+		})
+	}
+
+	w.tip = event.Hash
+
+	return nil
+}
+
+// blockDisconnected moves the transactions confirmed in the block back to the
+// broadcast state and removes their universe leaves. Their proofs keep the
+// stale block until they are confirmed again, which overwrites it and
+// registers the leaves again.
+func (w *Watcher) blockDisconnected(ctx context.Context, event chain.BlockEvent) error {
+	w.tip = chainhash.Hash{}
+
+	for txHash, mined := range w.mined {
+		if mined.hash == event.Hash {
+			delete(w.mined, txHash)
+		}
+	}
+
+	var confirmedTxs []*chaintx.ChainTx
+
+	err := w.chainTxRepo.FindMany(ctx, chaintx.ChainTxFilter{
+		Status:    utils.ToPtr(chaintx.StatusConfirmed),
+		BlockHash: event.Hash[:],
+	}, &confirmedTxs)
+	if err != nil || len(confirmedTxs) == 0 {
+		return err
+	}
+
+	ids := make([]common.ID, len(confirmedTxs))
+	for i, chainTx := range confirmedTxs {
+		ids[i] = chainTx.ID
+	}
+
+	logger.Infow("Unconfirm anchor txs of disconnected block", "block_hash", event.Hash, "num_txs", len(ids))
+
+	return w.chainTxUseCase.Unconfirm(ctx, ids)
+}
+
+// catchUp records the pending and broadcast transactions mined while the
+// watcher wasn't running, from the tip of the node back to the blocks
+// timestamped before the oldest of them was recorded.
+func (w *Watcher) catchUp(ctx context.Context) error {
+	chainTxs, err := w.unconfirmed(ctx)
+	if err != nil {
+		return err
+	}
+
+	bestHeight, err := w.chainClient.GetBlockCount()
+	if err != nil {
+		return err
+	}
+
+	var (
+		tip    chainhash.Hash
+		oldest = time.Now()
+	)
+
+	for _, chainTx := range chainTxs {
+		if createdAt := chainTx.CreatedAt.Time(); createdAt.Before(oldest) {
+			oldest = createdAt
+		}
+	}
+
+	for height := bestHeight; height >= 0; height-- {
+		blockHash, err := w.chainClient.GetBlockHash(height)
+		if err != nil {
+			return err
+		}
+
+		if height == bestHeight {
+			tip = *blockHash
+		}
+
+		if len(chainTxs) == 0 {
+			break
+		}
+
+		block, err := w.chainClient.GetBlock(blockHash)
+		if err != nil {
+			return err
+		}
+
+		w.recordMined(chainTxs, block, minedBlock{
+			hash:   *blockHash,
+			height: int32(height),
+		})
+
+		if block.Header.Timestamp.Before(oldest.Add(-maxBlockTimeOffset)) {
+			break
+		}
+	}
+
+	w.tip = tip
+	w.caughtUp = true
+
+	return nil
+}
+
+// recordMined records the block of the transactions mined in it.
+func (w *Watcher) recordMined(chainTxs []*chaintx.ChainTx, block *wire.MsgBlock, mined minedBlock) {
+	minedTxs := make(map[chainhash.Hash]bool, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		minedTxs[tx.TxHash()] = true
+	}
+
+	for _, chainTx := range chainTxs {
+		txHash, err := chainhash.NewHash(chainTx.TxID)
+		if err == nil && minedTxs[*txHash] {
+			w.mined[*txHash] = mined
+		}
+	}
+}
+
+// sweep confirms the pending and broadcast transactions mined in a block and
+// handles those the node doesn't know: broadcast transactions are rolled back,
+// e.g. because a reorg made them conflict, and pending ones, which the server
+// may have stopped before broadcasting, are broadcast again. A transaction is
+// only taken as unknown if it is neither in a block the watcher processed nor
+// in the mempool while the watcher is at the tip of the node, as it may be
+// mined in a block whose event isn't processed yet.
+func (w *Watcher) sweep(ctx context.Context) {
+	if !w.caughtUp {
+		if err := w.catchUp(ctx); err != nil {
+			logger.Errorw("catch up with the chain fail", "err", err)
+
+			return
+		}
+	}
+
+	chainTxs, err := w.unconfirmed(ctx)
+	if err != nil {
+		logger.Errorw("find unconfirmed anchor txs fail", "err", err)
+
+		return
+	}
+
+	w.forgetResolved(chainTxs)
+
+	var unmined []*chaintx.ChainTx
+	for _, chainTx := range chainTxs {
+		txHash, err := chainhash.NewHash(chainTx.TxID)
+		if err != nil {
+			logger.Errorw("invalid anchor tx hash", "chain_tx_id", chainTx.ID, "err", err)
+
+			continue
+		}
+
+		mined, ok := w.mined[*txHash]
+		if !ok {
+			unmined = append(unmined, chainTx)
+
+			continue
+		}
+
+		if err := w.confirmMined(ctx, chainTx, mined); err != nil {
+			logger.Errorw("confirm anchor tx fail", "tx_hash", txHash, "block_hash", mined.hash, "err", err)
+
+			continue
+		}
+
+		delete(w.mined, *txHash)
+	}
+
+	if len(unmined) == 0 {
+		return
+	}
+
+	unknown, err := w.notInMempool(ctx, unmined)
+	if err != nil {
+		logger.Errorw("look up anchor txs in mempool fail", "err", err)
+
+		return
+	}
+
+	if len(unknown) == 0 {
+		return
+	}
+
+	bestHash, err := w.chainClient.GetBestBlockHash()
+	if err != nil {
+		logger.Errorw("GetBestBlockHash fail", "err", err)
+
+		return
+	}
+
+	if *bestHash != w.tip {
+		return
+	}
+
+	for _, chainTx := range unknown {
+		if chainTx.Status == chaintx.StatusPending {
+			w.rebroadcast(ctx, chainTx)
+
+			continue
+		}
+
+		if err := w.drop(ctx, chainTx, failReasonDropped); err != nil {
+			logger.Errorw("roll back dropped anchor tx fail", "chain_tx_id", chainTx.ID, "err", err)
+		}
+	}
+}
+
+// rebroadcast broadcasts the pending transaction again once the request which
+// recorded it had the time to broadcast it. The transaction is dropped if the
+// node rejects it.
+func (w *Watcher) rebroadcast(ctx context.Context, chainTx *chaintx.ChainTx) {
+	if time.Since(chainTx.CreatedAt.Time()) < w.rebroadcastDelay {
+		return
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(chainTx.AnchorTx)); err != nil {
+		logger.Errorw("decode pending anchor tx fail", "chain_tx_id", chainTx.ID, "err", err)
+
+		return
+	}
+
+	logger.Infow("Broadcast pending anchor tx again", "chain_tx_id", chainTx.ID, "tx_hash", tx.TxHash())
+
+	if err := w.chainTxUseCase.Broadcast(ctx, chainTx.ID, &tx); err != nil {
+		logger.Errorw("broadcast pending anchor tx fail", "chain_tx_id", chainTx.ID, "err", err)
+	}
+}
+
+// notInMempool returns the transactions missing from the mempool of the node.
+// The pending transactions found in the mempool are moved into the broadcast
+// state, the request which broadcast them failed to do so.
+func (w *Watcher) notInMempool(ctx context.Context, chainTxs []*chaintx.ChainTx) ([]*chaintx.ChainTx, error) {
+	mempool, err := w.chainClient.GetRawMempool()
+	if err != nil {
+		return nil, err
+	}
+
+	inMempool := make(map[chainhash.Hash]bool, len(mempool))
+	for _, txHash := range mempool {
+		inMempool[*txHash] = true
+	}
+
+	var missing []*chaintx.ChainTx
+	for _, chainTx := range chainTxs {
+		txHash, err := chainhash.NewHash(chainTx.TxID)
+		if err != nil {
+			continue
+		}
+
+		if !inMempool[*txHash] {
+			missing = append(missing, chainTx)
+
+			continue
+		}
+
+		if chainTx.Status != chaintx.StatusPending {
+			continue
+		}
+
+		if err := w.chainTxUseCase.MarkBroadcast(ctx, chainTx.ID); err != nil {
+			logger.Errorw("mark anchor tx broadcast fail", "chain_tx_id", chainTx.ID, "err", err)
+		}
+	}
+
+	return missing, nil
+}
+
+// forgetResolved forgets the blocks of the transactions which are no longer
+// pending or broadcast.
+func (w *Watcher) forgetResolved(chainTxs []*chaintx.ChainTx) {
+	unresolved := make(map[chainhash.Hash]bool, len(chainTxs))
+	for _, chainTx := range chainTxs {
+		if txHash, err := chainhash.NewHash(chainTx.TxID); err == nil {
+			unresolved[*txHash] = true
+		}
+	}
+
+	for txHash := range w.mined {
+		if !unresolved[txHash] {
+			delete(w.mined, txHash)
+		}
+	}
+}
+
+// confirmMined confirms the transaction with the block it was mined in.
+func (w *Watcher) confirmMined(ctx context.Context, chainTx *chaintx.ChainTx, mined minedBlock) error {
+	block, err := w.chainClient.GetBlock(&mined.hash)
+	if err != nil {
+		return err
+	}
+
+	return w.confirm(ctx, chainTx, block, uint32(mined.height))
+}
+
+// confirm adds the block to the proofs of the assets the transaction created,
+// registers the proofs in the universe and moves the transaction into the
+// confirmed state. A transaction whose proofs fail to confirm stays
+// unconfirmed and is tried again by the next sweep.
+func (w *Watcher) confirm(
+	ctx context.Context,
+	chainTx *chaintx.ChainTx,
+	block *wire.MsgBlock,
+	height uint32,
+) error {
+	outpoints, err := createdOutpoints(ctx, w.manageUtxoRepo, w.assetOutpointRepo, chainTx.ID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		files         = make([]*proof.File, 0, len(outpoints))
+		issuanceFiles []*proof.File
+		transferFiles []*proof.File
+		seenLocators  = make(map[[sha256.Size]byte]bool, len(outpoints))
+	)
+
+	for _, outpoint := range outpoints {
+		if len(outpoint.ProofLocator) != sha256.Size {
+			return fmt.Errorf("invalid proof locator length %d", len(outpoint.ProofLocator))
+		}
+
+		locator := [sha256.Size]byte(outpoint.ProofLocator)
+		if seenLocators[locator] {
+			continue
+		}
+
+		seenLocators[locator] = true
+
+		blob, err := w.archiver.FetchProofByHash(ctx, locator)
+		if err != nil {
+			return err
+		}
+
+		var file proof.File
+		if err := file.Decode(blob); err != nil {
+			return err
+		}
+
+		lastProof, err := file.LastProof()
+		if err != nil {
+			return err
+		}
+
+		if lastProof.GenesisReveal != nil {
+			issuanceFiles = append(issuanceFiles, &file)
+		} else {
+			transferFiles = append(transferFiles, &file)
+		}
+
+		files = append(files, &file)
+	}
+
+	err = chain.ConfirmProofFiles(ctx, files, block, height, w.headerVerifier, w.archiver)
+	if err != nil {
+		return err
+	}
+
+	if len(issuanceFiles) > 0 {
+		err := w.universeUseCase.RegisterLeaves(ctx, universemodel.IssuanceTree, issuanceFiles)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(transferFiles) > 0 {
+		err := w.universeUseCase.RegisterLeaves(ctx, universemodel.TransferTree, transferFiles)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infow("Confirmed anchor tx", "chain_tx_id", chainTx.ID, "height", height, "num_proofs", len(files))
+
+	return w.chainTxUseCase.Confirm(ctx, chainTx.ID, block, height)
+}
+
+// drop fails the transaction and rolls back its assets.
+func (w *Watcher) drop(ctx context.Context, chainTx *chaintx.ChainTx, reason string) error {
+	return w.chainTxUseCase.Drop(ctx, chainTx.ID, reason)
+}
+
+// unconfirmed returns the pending and broadcast chain txs.
+func (w *Watcher) unconfirmed(ctx context.Context) ([]*chaintx.ChainTx, error) {
+	var chainTxs []*chaintx.ChainTx
+
+	for _, status := range []chaintx.Status{chaintx.StatusPending, chaintx.StatusBroadcast} {
+		var found []*chaintx.ChainTx
+
+		err := w.chainTxRepo.FindMany(ctx, chaintx.ChainTxFilter{
+			Status: utils.ToPtr(status),
+		}, &found)
+		if err != nil {
+			return nil, err
+		}
+
+		chainTxs = append(chainTxs, found...)
+	}
+
+	return chainTxs, nil
+}
+
+func NewWatcher(
+	chainTxRepo chaintx.RepoInterface,
+	chainTxUseCase chaintx.UseCaseInterface,
+	manageUtxoRepo manageutxo.RepoInterface,
+	assetOutpointRepo assetoutpoint.RepoInterface,
+	rpcClient *rpcclient.Client,
+	notifier *chain.BlockNotifier,
+	archiver proof.Archiver,
+	universeUseCase universe.UseCaseInterface,
+) chaintx.WatcherInterface {
+	return &Watcher{
+		chainTxRepo:       chainTxRepo,
+		chainTxUseCase:    chainTxUseCase,
+		manageUtxoRepo:    manageUtxoRepo,
+		assetOutpointRepo: assetOutpointRepo,
+		chainClient:       rpcClient,
+		notifier:          notifier,
+		headerVerifier:    proof.NewRPCHeaderVerifier(rpcClient),
+		archiver:          archiver,
+		universeUseCase:   universeUseCase,
+		mined:             make(map[chainhash.Hash]minedBlock),
+		rebroadcastDelay:  rebroadcastDelay,
+	}
+}
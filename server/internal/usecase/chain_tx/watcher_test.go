@@ -0,0 +1,730 @@
+package chaintx
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	config "github.com/quocky/taproot-asset/server/config/core"
+	assetoutpoint "github.com/quocky/taproot-asset/server/internal/domain/asset_outpoint"
+	"github.com/quocky/taproot-asset/server/internal/domain/chain_tx"
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	manageutxo "github.com/quocky/taproot-asset/server/internal/domain/manage_utxo"
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	assetoutpointrepo "github.com/quocky/taproot-asset/server/internal/repo/asset_outpoint"
+	chaintxrepo "github.com/quocky/taproot-asset/server/internal/repo/chain_tx"
+	manageutxorepo "github.com/quocky/taproot-asset/server/internal/repo/manage_utxo"
+	universeleafrepo "github.com/quocky/taproot-asset/server/internal/repo/universe_leaf"
+	"github.com/quocky/taproot-asset/server/pkg/chain"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	"github.com/quocky/taproot-asset/taproot/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain is a node whose best chain and mempool are driven by the test.
+type fakeChain struct {
+	bestChain []*wire.MsgBlock
+	blocks    map[chainhash.Hash]*wire.MsgBlock
+	mempool   map[chainhash.Hash]bool
+
+	// rejected holds the transactions the node doesn't accept.
+	rejected map[chainhash.Hash]bool
+}
+
+func newFakeChain() *fakeChain {
+	c := &fakeChain{
+		blocks:   make(map[chainhash.Hash]*wire.MsgBlock),
+		mempool:  make(map[chainhash.Hash]bool),
+		rejected: make(map[chainhash.Hash]bool),
+	}
+
+	// The genesis block is older than any transaction, so it ends the
+	// catch up.
+	c.addBlock(&wire.MsgBlock{
+		Header: wire.BlockHeader{Timestamp: time.Unix(0, 0)},
+	})
+
+	return c
+}
+
+func (c *fakeChain) addBlock(block *wire.MsgBlock) {
+	c.bestChain = append(c.bestChain, block)
+	c.blocks[block.BlockHash()] = block
+}
+
+// mine connects a block with the transactions, which leave the mempool.
+func (c *fakeChain) mine(txs ...*wire.MsgTx) chain.BlockEvent {
+	height := len(c.bestChain)
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			PrevBlock: c.bestChain[height-1].BlockHash(),
+			Timestamp: time.Now(),
+			Nonce:     uint32(height),
+		},
+		Transactions: txs,
+	}
+
+	for _, tx := range txs {
+		delete(c.mempool, tx.TxHash())
+	}
+
+	c.addBlock(block)
+
+	return chain.BlockEvent{
+		Hash:      block.BlockHash(),
+		Height:    int32(height),
+		Connected: true,
+	}
+}
+
+// disconnect disconnects the best block, its transactions go back to the
+// mempool.
+func (c *fakeChain) disconnect() chain.BlockEvent {
+	height := len(c.bestChain) - 1
+	block := c.bestChain[height]
+	c.bestChain = c.bestChain[:height]
+
+	for _, tx := range block.Transactions {
+		c.mempool[tx.TxHash()] = true
+	}
+
+	return chain.BlockEvent{
+		Hash:   block.BlockHash(),
+		Height: int32(height),
+	}
+}
+
+func (c *fakeChain) GetBestBlockHash() (*chainhash.Hash, error) {
+	hash := c.bestChain[len(c.bestChain)-1].BlockHash()
+
+	return &hash, nil
+}
+
+func (c *fakeChain) GetBlockCount() (int64, error) {
+	return int64(len(c.bestChain) - 1), nil
+}
+
+func (c *fakeChain) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	if blockHeight < 0 || blockHeight >= int64(len(c.bestChain)) {
+		return nil, fmt.Errorf("no block at height %d", blockHeight)
+	}
+
+	hash := c.bestChain[blockHeight].BlockHash()
+
+	return &hash, nil
+}
+
+func (c *fakeChain) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	block, ok := c.blocks[*blockHash]
+	if !ok {
+		return nil, fmt.Errorf("unknown block %v", blockHash)
+	}
+
+	return block, nil
+}
+
+func (c *fakeChain) GetRawMempool() ([]*chainhash.Hash, error) {
+	txHashes := make([]*chainhash.Hash, 0, len(c.mempool))
+	for txHash := range c.mempool {
+		txHash := txHash
+		txHashes = append(txHashes, &txHash)
+	}
+
+	return txHashes, nil
+}
+
+func (c *fakeChain) SendRawTransaction(tx *wire.MsgTx, _ bool) (*chainhash.Hash, error) {
+	txHash := tx.TxHash()
+	if c.rejected[txHash] {
+		return nil, fmt.Errorf("tx %v rejected", txHash)
+	}
+
+	c.mempool[txHash] = true
+
+	return &txHash, nil
+}
+
+// fakeUniverse removes universe leaves from the leaf repo only, in the
+// transaction of the context like the universe use case.
+type fakeUniverse struct {
+	universe.UseCaseInterface
+
+	leafRepo universe.RepoInterface
+
+	// err fails the removal of leaves.
+	err error
+}
+
+func (f *fakeUniverse) RemoveLeaves(ctx context.Context, locators [][32]byte) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	values := make([]any, len(locators))
+	for i, locator := range locators {
+		values[i] = locator[:]
+	}
+
+	return f.leafRepo.DeleteMany(ctx, universe.LeafFilter{
+		ProofLocators: &common.InOperator{Values: values},
+	})
+}
+
+// watcherHarness is a watcher on a fresh SQLite database and a fake chain.
+type watcherHarness struct {
+	ctx               context.Context
+	chain             *fakeChain
+	watcher           *Watcher
+	chainTxUseCase    chaintx.UseCaseInterface
+	chainTxRepo       chaintx.RepoInterface
+	manageUtxoRepo    manageutxo.RepoInterface
+	assetOutpointRepo assetoutpoint.RepoInterface
+	universe          *fakeUniverse
+}
+
+func newWatcherHarness(t *testing.T) *watcherHarness {
+	t.Helper()
+
+	logger.Init()
+
+	db, err := database.NewSQLDatabase(&config.Config{
+		Database: config.Database{
+			Backend: string(database.SQLite),
+			DSN:     "file:" + filepath.Join(t.TempDir(), "taproot.db"),
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	h := &watcherHarness{
+		ctx:               context.Background(),
+		chain:             newFakeChain(),
+		chainTxRepo:       chaintxrepo.NewRepoSQL(db),
+		manageUtxoRepo:    manageutxorepo.NewRepoSQL(db),
+		assetOutpointRepo: assetoutpointrepo.NewRepoSQL(db),
+		universe: &fakeUniverse{
+			leafRepo: universeleafrepo.NewRepoSQL(db),
+		},
+	}
+	h.chainTxUseCase = &UseCase{
+		chainTxRepo:       h.chainTxRepo,
+		manageUtxoRepo:    h.manageUtxoRepo,
+		assetOutpointRepo: h.assetOutpointRepo,
+		universeUseCase:   h.universe,
+		rpcClient:         h.chain,
+	}
+	h.watcher = &Watcher{
+		chainTxRepo:       h.chainTxRepo,
+		chainTxUseCase:    h.chainTxUseCase,
+		manageUtxoRepo:    h.manageUtxoRepo,
+		assetOutpointRepo: h.assetOutpointRepo,
+		chainClient:       h.chain,
+		mined:             make(map[chainhash.Hash]minedBlock),
+		rebroadcastDelay:  rebroadcastDelay,
+	}
+
+	return h
+}
+
+// newTx records a new anchor transaction without assets as pending.
+func (h *watcherHarness) newTx(t *testing.T) (*wire.MsgTx, common.ID) {
+	t.Helper()
+
+	return h.recordPending(t, func(context.Context, common.ID) error {
+		return nil
+	})
+}
+
+func (h *watcherHarness) recordPending(t *testing.T,
+	writeAssets chaintx.WriteAssetsFunc) (*wire.MsgTx, common.ID) {
+
+	t.Helper()
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{
+		Hash:  chainhash.Hash{1},
+		Index: uint32(len(h.chain.blocks) + len(h.chain.mempool) + len(h.chain.rejected)),
+	}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+
+	chainTxID, err := h.chainTxUseCase.RecordPending(h.ctx, tx, writeAssets)
+	require.NoError(t, err)
+
+	return tx, chainTxID
+}
+
+// newAssetTx records a new anchor transaction as pending with an asset
+// outpoint it creates, which it returns. The given outpoint is spent by the
+// transaction.
+func (h *watcherHarness) newAssetTx(t *testing.T,
+	spent common.ID) (*wire.MsgTx, common.ID, common.ID) {
+
+	t.Helper()
+
+	var outpointID common.ID
+	tx, chainTxID := h.recordPending(t, func(ctx context.Context, chainTxID common.ID) error {
+		utxoID, err := h.manageUtxoRepo.InsertOne(ctx, manageutxo.ManagedUtxo{
+			Outpoint: wire.NewOutPoint(&chainhash.Hash{}, 0).String(),
+			AmtSats:  1000,
+			TxID:     chainTxID,
+		})
+		if err != nil {
+			return err
+		}
+
+		locator := sha256.Sum256([]byte(chainTxID))
+		outpointID, err = h.assetOutpointRepo.InsertOne(ctx, assetoutpoint.AssetOutpoint{
+			ScriptKey:    []byte{2},
+			Amount:       100,
+			AnchorUtxoID: utxoID,
+			ProofLocator: locator[:],
+		})
+		if err != nil || spent.IsEmpty() {
+			return err
+		}
+
+		return h.assetOutpointRepo.UpdateMany(ctx,
+			assetoutpoint.UnspentOutpointFilter{
+				IDs: &common.InOperator{Values: []any{spent}},
+			},
+			assetoutpoint.UnspentOutpointUpdate{
+				Set: &assetoutpoint.UnspentOutpointSetUpdate{
+					Spent:       utils.ToPtr(true),
+					SpentByTxID: &chainTxID,
+				},
+			},
+		)
+	})
+
+	return tx, chainTxID, outpointID
+}
+
+// broadcast broadcasts the pending transaction.
+func (h *watcherHarness) broadcast(t *testing.T, tx *wire.MsgTx, chainTxID common.ID) {
+	t.Helper()
+
+	require.NoError(t, h.chainTxUseCase.Broadcast(h.ctx, chainTxID, tx))
+}
+
+// process runs the watcher on the block events like Run does.
+func (h *watcherHarness) process(t *testing.T, events ...chain.BlockEvent) {
+	t.Helper()
+
+	for _, event := range events {
+		if event.Connected {
+			require.NoError(t, h.watcher.blockConnected(h.ctx, event))
+		} else {
+			require.NoError(t, h.watcher.blockDisconnected(h.ctx, event))
+		}
+	}
+
+	h.watcher.sweep(h.ctx)
+}
+
+func (h *watcherHarness) chainTx(t *testing.T, chainTxID common.ID) *chaintx.ChainTx {
+	t.Helper()
+
+	var chainTx chaintx.ChainTx
+	require.NoError(t, h.chainTxRepo.FindOneByID(h.ctx, chainTxID, &chainTx))
+
+	return &chainTx
+}
+
+// addLeaf records a universe leaf of the proof file of the outpoint.
+func (h *watcherHarness) addLeaf(t *testing.T, outpointID common.ID) {
+	t.Helper()
+
+	_, err := h.universe.leafRepo.InsertOne(h.ctx, universe.Leaf{
+		AssetID:      make([]byte, 32),
+		LeafKey:      []byte(outpointID),
+		ScriptKey:    []byte{2},
+		ProofLocator: h.outpoint(t, outpointID).ProofLocator,
+	})
+	require.NoError(t, err)
+}
+
+// hasLeaf returns whether the universe leaf of the outpoint is recorded.
+func (h *watcherHarness) hasLeaf(t *testing.T, outpointID common.ID) bool {
+	t.Helper()
+
+	var leaves []*universe.Leaf
+	err := h.universe.leafRepo.FindMany(h.ctx, universe.LeafFilter{
+		LeafKey: []byte(outpointID),
+	}, &leaves)
+	require.NoError(t, err)
+
+	return len(leaves) > 0
+}
+
+func (h *watcherHarness) outpoint(t *testing.T, outpointID common.ID) *assetoutpoint.AssetOutpoint {
+	t.Helper()
+
+	var outpoint assetoutpoint.AssetOutpoint
+	require.NoError(t, h.assetOutpointRepo.FindOneByID(h.ctx, outpointID, &outpoint))
+
+	return &outpoint
+}
+
+// TestWatcherBlocks makes sure transactions are confirmed with the block they
+// were mined in, also if they were mined while pending or while the watcher
+// wasn't running, and unconfirmed when the block is disconnected.
+func TestWatcherBlocks(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		// run drives the chain and the watcher and returns the best
+		// block the transaction is expected to be confirmed in, nil
+		// if it is expected to stay unconfirmed.
+		run func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) *chain.BlockEvent
+
+		status chaintx.Status
+	}{{
+		name: "broadcast tx mined",
+		run: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) *chain.BlockEvent {
+
+			h.broadcast(t, tx, chainTxID)
+			h.process(t)
+
+			event := h.chain.mine(tx)
+			h.process(t, event)
+
+			return &event
+		},
+		status: chaintx.StatusConfirmed,
+	}, {
+		name: "pending tx mined",
+		run: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) *chain.BlockEvent {
+
+			h.process(t)
+			require.Equal(t, chaintx.StatusPending, h.chainTx(t, chainTxID).Status)
+
+			event := h.chain.mine(tx)
+			h.process(t, event)
+
+			return &event
+		},
+		status: chaintx.StatusConfirmed,
+	}, {
+		name: "broadcast tx mined while not running",
+		run: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) *chain.BlockEvent {
+
+			h.broadcast(t, tx, chainTxID)
+
+			event := h.chain.mine(tx)
+			h.chain.mine()
+			h.process(t)
+
+			return &event
+		},
+		status: chaintx.StatusConfirmed,
+	}, {
+		name: "block disconnected",
+		run: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) *chain.BlockEvent {
+
+			h.broadcast(t, tx, chainTxID)
+			h.process(t)
+			h.process(t, h.chain.mine(tx))
+			require.Equal(t, chaintx.StatusConfirmed, h.chainTx(t, chainTxID).Status)
+
+			h.process(t, h.chain.disconnect())
+
+			return nil
+		},
+		status: chaintx.StatusBroadcast,
+	}, {
+		name: "tx mined again after a reorg",
+		run: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) *chain.BlockEvent {
+
+			h.broadcast(t, tx, chainTxID)
+			h.process(t)
+			h.process(t, h.chain.mine(tx))
+
+			disconnected := h.chain.disconnect()
+			empty := h.chain.mine()
+			event := h.chain.mine(tx)
+			h.process(t, disconnected, empty, event)
+
+			return &event
+		},
+		status: chaintx.StatusConfirmed,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			h := newWatcherHarness(t)
+			tx, chainTxID := h.newTx(t)
+
+			event := testCase.run(t, h, tx, chainTxID)
+
+			chainTx := h.chainTx(t, chainTxID)
+			require.Equal(t, testCase.status, chainTx.Status)
+
+			if event == nil {
+				return
+			}
+
+			require.Equal(t, event.Hash[:], chainTx.BlockHash)
+			require.Equal(t, event.Height, chainTx.BlockHeight)
+		})
+	}
+}
+
+// TestWatcherDrop makes sure a broadcast transaction the node dropped is
+// failed and its assets and universe leaves rolled back, and that it is kept
+// while it may still be mined.
+func TestWatcherDrop(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		// prepare changes the mempool after the child transaction
+		// spending the outpoint of the parent is broadcast. A block is
+		// mined afterwards.
+		prepare func(h *watcherHarness, parent, child *wire.MsgTx)
+
+		parentStatus chaintx.Status
+		childStatus  chaintx.Status
+
+		// parentOutSpent is whether the outpoint created by the
+		// parent and spent by the child is spent.
+		parentOutSpent bool
+
+		// parentOutDeleted is whether that outpoint is deleted.
+		parentOutDeleted bool
+
+		// childOutDeleted is whether the outpoint created by the child
+		// is deleted.
+		childOutDeleted bool
+	}{{
+		name:           "both in mempool",
+		prepare:        func(h *watcherHarness, parent, child *wire.MsgTx) {},
+		parentStatus:   chaintx.StatusBroadcast,
+		childStatus:    chaintx.StatusBroadcast,
+		parentOutSpent: true,
+	}, {
+		name: "child dropped",
+		prepare: func(h *watcherHarness, parent, child *wire.MsgTx) {
+			delete(h.chain.mempool, child.TxHash())
+		},
+		parentStatus:    chaintx.StatusBroadcast,
+		childStatus:     chaintx.StatusFailed,
+		childOutDeleted: true,
+	}, {
+		name: "parent and child dropped",
+		prepare: func(h *watcherHarness, parent, child *wire.MsgTx) {
+			delete(h.chain.mempool, parent.TxHash())
+			delete(h.chain.mempool, child.TxHash())
+		},
+		parentStatus:     chaintx.StatusFailed,
+		childStatus:      chaintx.StatusFailed,
+		parentOutSpent:   true,
+		parentOutDeleted: true,
+		childOutDeleted:  true,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			h := newWatcherHarness(t)
+			h.process(t)
+
+			parent, parentID, parentOut := h.newAssetTx(t, "")
+			h.broadcast(t, parent, parentID)
+
+			child, childID, childOut := h.newAssetTx(t, parentOut)
+			h.broadcast(t, child, childID)
+
+			h.addLeaf(t, parentOut)
+			h.addLeaf(t, childOut)
+
+			testCase.prepare(h, parent, child)
+			h.chain.mine()
+
+			// The block isn't processed yet, so nothing is
+			// dropped.
+			h.watcher.sweep(h.ctx)
+			require.Equal(t, chaintx.StatusBroadcast, h.chainTx(t, parentID).Status)
+			require.Equal(t, chaintx.StatusBroadcast, h.chainTx(t, childID).Status)
+
+			bestHash, err := h.chain.GetBestBlockHash()
+			require.NoError(t, err)
+			h.process(t, chain.BlockEvent{
+				Hash:      *bestHash,
+				Height:    int32(len(h.chain.bestChain) - 1),
+				Connected: true,
+			})
+
+			require.Equal(t, testCase.parentStatus, h.chainTx(t, parentID).Status)
+			require.Equal(t, testCase.childStatus, h.chainTx(t, childID).Status)
+
+			parentOutpoint := h.outpoint(t, parentOut)
+			require.Equal(t, testCase.parentOutSpent, parentOutpoint.Spent)
+			require.Equal(t, testCase.parentOutDeleted, parentOutpoint.IsDeleted)
+
+			childOutpoint := h.outpoint(t, childOut)
+			require.Equal(t, testCase.childOutDeleted, childOutpoint.IsDeleted)
+			require.Equal(t, testCase.childOutDeleted, childOutpoint.Spent)
+
+			require.Equal(t, !testCase.parentOutDeleted, h.hasLeaf(t, parentOut))
+			require.Equal(t, !testCase.childOutDeleted, h.hasLeaf(t, childOut))
+		})
+	}
+}
+
+// TestWatcherPending makes sure a pending transaction whose assets are written
+// is finished by the watcher if the request which recorded it stopped before
+// or after broadcasting it, and rolled back if the node rejects it.
+func TestWatcherPending(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		// prepare stands for the request which recorded the
+		// transaction.
+		prepare func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID)
+
+		// rebroadcastDelay is how old the pending transaction has to
+		// be before the watcher broadcasts it again.
+		rebroadcastDelay time.Duration
+
+		status    chaintx.Status
+		inMempool bool
+
+		// rolledBack is whether the outpoint created by the
+		// transaction is deleted and the one it spent unspent again.
+		rolledBack bool
+	}{{
+		name: "broadcast rejected",
+		prepare: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) {
+
+			h.chain.rejected[tx.TxHash()] = true
+
+			err := h.chainTxUseCase.Broadcast(h.ctx, chainTxID, tx)
+			require.Error(t, err)
+		},
+		status:     chaintx.StatusFailed,
+		rolledBack: true,
+	}, {
+		name: "stopped before the broadcast",
+		prepare: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) {
+		},
+		status:    chaintx.StatusBroadcast,
+		inMempool: true,
+	}, {
+		name: "stopped before the broadcast, recently recorded",
+		prepare: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) {
+		},
+		rebroadcastDelay: time.Hour,
+		status:           chaintx.StatusPending,
+	}, {
+		name: "stopped before the broadcast, rejected",
+		prepare: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) {
+
+			h.chain.rejected[tx.TxHash()] = true
+		},
+		status:     chaintx.StatusFailed,
+		rolledBack: true,
+	}, {
+		name: "stopped after the broadcast",
+		prepare: func(t *testing.T, h *watcherHarness, tx *wire.MsgTx,
+			chainTxID common.ID) {
+
+			h.chain.mempool[tx.TxHash()] = true
+		},
+		rebroadcastDelay: time.Hour,
+		status:           chaintx.StatusBroadcast,
+		inMempool:        true,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			h := newWatcherHarness(t)
+			h.watcher.rebroadcastDelay = testCase.rebroadcastDelay
+			h.process(t)
+
+			parent, parentID, parentOut := h.newAssetTx(t, "")
+			h.broadcast(t, parent, parentID)
+
+			tx, chainTxID, out := h.newAssetTx(t, parentOut)
+			require.True(t, h.outpoint(t, parentOut).Spent)
+
+			testCase.prepare(t, h, tx, chainTxID)
+			h.process(t)
+
+			require.Equal(t, testCase.status, h.chainTx(t, chainTxID).Status)
+			require.Equal(t, testCase.inMempool, h.chain.mempool[tx.TxHash()])
+			require.Equal(t, chaintx.StatusBroadcast, h.chainTx(t, parentID).Status)
+
+			require.Equal(t, !testCase.rolledBack, h.outpoint(t, parentOut).Spent)
+			require.Equal(t, testCase.rolledBack, h.outpoint(t, out).IsDeleted)
+		})
+	}
+}
+
+// TestWatcherUnconfirmLeaves makes sure the universe leaves of the transactions
+// of a disconnected block are removed in the transaction moving them back to
+// the broadcast state.
+func TestWatcherUnconfirmLeaves(t *testing.T) {
+	errRemove := errors.New("remove leaves failed")
+
+	testCases := []struct {
+		name      string
+		removeErr error
+		status    chaintx.Status
+	}{{
+		name:   "removed",
+		status: chaintx.StatusBroadcast,
+	}, {
+		name:      "rolled back",
+		removeErr: errRemove,
+		status:    chaintx.StatusConfirmed,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			h := newWatcherHarness(t)
+			h.universe.err = testCase.removeErr
+			h.process(t)
+
+			tx, chainTxID, out := h.newAssetTx(t, "")
+			h.broadcast(t, tx, chainTxID)
+
+			other, otherID, otherOut := h.newAssetTx(t, "")
+			h.broadcast(t, other, otherID)
+
+			// The proofs of the outpoints are confirmed and
+			// registered by the watcher, which needs valid proof
+			// files.
+			event := h.chain.mine(tx)
+			err := h.chainTxUseCase.Confirm(
+				h.ctx, chainTxID, h.chain.blocks[event.Hash], uint32(event.Height),
+			)
+			require.NoError(t, err)
+
+			h.addLeaf(t, out)
+			h.addLeaf(t, otherOut)
+
+			err = h.watcher.blockDisconnected(h.ctx, h.chain.disconnect())
+			require.ErrorIs(t, err, testCase.removeErr)
+
+			require.Equal(t, testCase.status, h.chainTx(t, chainTxID).Status)
+			require.Equal(t, testCase.removeErr != nil, h.hasLeaf(t, out))
+			require.True(t, h.hasLeaf(t, otherOut))
+		})
+	}
+}
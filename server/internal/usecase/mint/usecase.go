@@ -1,7 +1,6 @@
 package mint
 
 import (
-	"bytes"
 	"context"
 	"errors"
 
@@ -21,11 +20,13 @@ import (
 
 type UseCase struct {
 	genesisPointRepo  genesis.RepoInterface
-	chainTxRepo       chaintx.RepoInterface
+	chainTxUseCase    chaintx.UseCaseInterface
 	assetRepo         genesisasset.RepoInterface
 	assetOutpointRepo assetoutpoint.RepoInterface
 	manageUtxoRepo    manageutxo.RepoInterface
 	rpcClient         *rpcclient.Client
+	headerVerifier    proof.HeaderVerifier
+	archiver          proof.Archiver
 }
 
 func (u *UseCase) MintAsset(
@@ -38,79 +39,99 @@ func (u *UseCase) MintAsset(
 		return errors.New("mint proof empty")
 	}
 
-	locatorHash, err := generateLocatorHash(ctx, mintProof)
-	if err != nil {
-		logger.Errorw("create new locator hash fail", "err", err.Error())
+	locatorHashes := make([][32]byte, len(mintProof))
+	for i, p := range mintProof {
+		if err := common.CheckAmountRange("amount", p.Asset.Amount); err != nil {
+			logger.Errorw("mint amount out of range", "err", err.Error())
 
-		return err
-	}
+			return err
+		}
 
-	chainTxID, genesisPointID, manageUtxoID, err := u.insertCommonComp(ctx, amountSats, tapScriptRootHash, mintProof[0])
-	if err != nil {
-		return err
-	}
+		locatorHash, err := u.generateLocatorHash(ctx, p)
+		if err != nil {
+			logger.Errorw("create new locator hash fail", "err", err.Error())
 
-	for _, p := range mintProof {
-		data := mint.InsertMintTxParams{
-			Asset:             &p.Asset,
-			OutputIdx:         int32(p.GenesisReveal.OutputIndex),
-			AnchorTx:          &p.AnchorTx,
-			AmountSats:        amountSats,
-			AddressInfoPubkey: p.Asset.ScriptPubkey,
-			TapScriptRootHash: tapScriptRootHash,
-			ProofLocator:      locatorHash,
-			MintProof:         p,
+			return err
 		}
 
-		// insert to db
-		_, err := u.insertDiffCompTxMint(ctx, data, *chainTxID, *genesisPointID, *manageUtxoID)
+		locatorHashes[i] = locatorHash
+	}
+
+	anchorTx := &mintProof[0].AnchorTx
+
+	// The minted assets are written with the pending anchor transaction,
+	// before it is broadcast, and rolled back if the node rejects it.
+	chainTxID, err := u.chainTxUseCase.RecordPending(ctx, anchorTx, func(ctx context.Context, chainTxID common.ID) error {
+		genesisPointID, manageUtxoID, err := u.insertCommonComp(ctx, chainTxID, amountSats, tapScriptRootHash, mintProof[0])
 		if err != nil {
 			return err
 		}
-	}
 
-	// send raw tx
-	_, err = u.rpcClient.SendRawTransaction(&mintProof[0].AnchorTx, true)
+		for i, p := range mintProof {
+			data := mint.InsertMintTxParams{
+				Asset:             &p.Asset,
+				OutputIdx:         int32(p.GenesisReveal.OutputIndex),
+				AnchorTx:          &p.AnchorTx,
+				AmountSats:        amountSats,
+				AddressInfoPubkey: p.Asset.ScriptPubkey,
+				TapScriptRootHash: tapScriptRootHash,
+				ProofLocator:      locatorHashes[i],
+				MintProof:         p,
+			}
+
+			_, err := u.insertDiffCompTxMint(ctx, data, chainTxID, *genesisPointID, *manageUtxoID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		logger.Errorw("SendRawTransaction fail", err)
+		logger.Errorw("record mint fail", "tx_hash", anchorTx.TxHash(), "err", err)
 
 		return err
 	}
 
-	_, err = u.rpcClient.Generate(1)
-	if err != nil {
-		logger.Errorw("Generate fail", err)
-
+	if err := u.chainTxUseCase.Broadcast(ctx, chainTxID, anchorTx); err != nil {
 		return err
 	}
 
+	// The chain watcher confirms the proof files once the anchor
+	// transaction is mined and registers them in the issuance trees.
 	return nil
 }
 
-func generateLocatorHash(ctx context.Context, mintProof proof.AssetProofs) ([32]byte, error) {
-	proofs := make([]proof.Proof, 0)
+// generateLocatorHash stores the genesis proof of a minted asset in its own
+// proof file, which is the start of the asset's proof chain. The proof is
+// stored before the minting transaction is confirmed.
+func (u *UseCase) generateLocatorHash(
+	ctx context.Context,
+	mintProof *proof.Proof,
+) ([32]byte, error) {
+	file, err := proof.NewFile(*mintProof)
+	if err != nil {
+		logger.Errorw("create new file fail", "err", err.Error())
 
-	for _, p := range mintProof {
-		_, err := p.Verify(ctx, nil)
-		if err != nil {
-			logger.Errorw("verify fail", "err", err.Error())
+		return [32]byte{}, err
+	}
 
-			return [32]byte{}, err
-		}
+	if _, err := file.VerifyUnconfirmed(ctx, u.headerVerifier); err != nil {
+		logger.Errorw("verify fail", "err", err.Error())
 
-		proofs = append(proofs, *p)
+		return [32]byte{}, err
 	}
 
-	file, err := proof.NewFile(proofs...)
+	locatorHash, err := proof.ImportFile(ctx, u.archiver, file)
 	if err != nil {
-		logger.Errorw("create new file fail", "err", err.Error())
-
 		return [32]byte{}, err
 	}
 
-	return file.Store()
+	return locatorHash, nil
 }
 
+// insertDiffCompTxMint inserts the genesis asset and the outpoint of a minted
+// asset. It runs in the database transaction of the mint.
 func (u *UseCase) insertDiffCompTxMint(
 	ctx context.Context,
 	data mint.InsertMintTxParams,
@@ -119,84 +140,75 @@ func (u *UseCase) insertDiffCompTxMint(
 	var (
 		result  = mint.InsertMintTxResult{}
 		assetID = data.Asset.ID()
-
-		dbTxs = make([]common.TransactionCallbackFunc, 0)
 	)
 
 	result.AnchorTx.ID = chainTxID
 	result.GenesisPoint.ID = genesisPointID
+	result.ManagedUTXO.ID = manageUtxoID
+
+	result.GenesisAsset = genesisasset.GenesisAsset{
+		AssetID:        assetID[:],
+		AssetName:      data.Asset.Name,
+		Supply:         data.Asset.Amount,
+		AssetType:      uint8(data.Asset.Type),
+		OutputIndex:    data.OutputIdx,
+		GenesisPointID: result.GenesisPoint.ID,
+	}
 
-	dbTxs = append(dbTxs,
-		func(ctx context.Context) error {
-			result.GenesisAsset = genesisasset.GenesisAsset{
-				AssetID:        assetID[:],
-				AssetName:      data.Asset.Name,
-				Supply:         data.Asset.Amount,
-				OutputIndex:    data.OutputIdx,
-				GenesisPointID: result.GenesisPoint.ID,
-			}
-
-			docID, err := u.assetRepo.InsertOne(ctx, result.GenesisAsset)
+	if data.Asset.GroupKey != nil {
+		result.GenesisAsset.GroupKey = data.Asset.GroupKey.GroupPubKey[:]
+	}
 
-			result.GenesisAsset.ID = docID
+	if meta := data.MintProof.MetaReveal; meta != nil {
+		metaHash := meta.MetaHash()
 
-			return err
-		},
-		func(ctx context.Context) error {
-			result.AssetOutpoint = assetoutpoint.AssetOutpoint{
-				GenesisID:    result.GenesisAsset.ID,
-				ScriptKey:    data.Asset.ScriptPubkey[:],
-				Amount:       data.Asset.Amount,
-				AnchorUtxoID: manageUtxoID,
-				ProofLocator: data.ProofLocator[:],
-			}
+		result.GenesisAsset.MetaHash = metaHash[:]
+		result.GenesisAsset.MetaType = uint8(meta.Type)
+		result.GenesisAsset.MetaData = meta.Data
+	}
 
-			docID, err := u.assetOutpointRepo.InsertOne(ctx, result.AssetOutpoint)
+	genesisAssetID, err := u.assetRepo.InsertOne(ctx, result.GenesisAsset)
+	if err != nil {
+		return nil, err
+	}
 
-			result.AssetOutpoint.ID = docID
+	result.GenesisAsset.ID = genesisAssetID
 
-			return err
-		},
-	)
+	result.AssetOutpoint = assetoutpoint.AssetOutpoint{
+		GenesisID:    result.GenesisAsset.ID,
+		ScriptKey:    data.Asset.ScriptPubkey[:],
+		Amount:       data.Asset.Amount,
+		AnchorUtxoID: manageUtxoID,
+		ProofLocator: data.ProofLocator[:],
+	}
 
-	if err := u.assetRepo.RunTransactions(ctx, dbTxs); err != nil {
+	assetOutpointID, err := u.assetOutpointRepo.InsertOne(ctx, result.AssetOutpoint)
+	if err != nil {
 		return nil, err
 	}
 
+	result.AssetOutpoint.ID = assetOutpointID
+
 	return &result, nil
 }
 
+// insertCommonComp inserts the genesis point and the managed UTXO shared by
+// all assets of a mint. It runs in the database transaction of the mint.
 func (u *UseCase) insertCommonComp(
 	ctx context.Context,
+	chainTxID common.ID,
 	amountSats int32,
 	tapScriptRootHash *chainhash.Hash,
 	p *proof.Proof,
-) (*common.ID, *common.ID, *common.ID, error) {
-	var (
-		txHash  = p.AnchorTx.TxHash()
-		txBytes bytes.Buffer
-	)
-
-	if err := p.AnchorTx.Serialize(&txBytes); err != nil {
-		logger.Errorw("p.AnchorTx.Serialize fail", err.Error())
-
-		return nil, nil, nil, err
-	}
-
-	chainTxID, err := u.chainTxRepo.InsertOne(ctx, chaintx.ChainTx{
-		TxID:     txHash[:],
-		AnchorTx: txBytes.Bytes(),
-	})
-	if err != nil {
-		return nil, nil, nil, err
-	}
+) (*common.ID, *common.ID, error) {
+	txHash := p.AnchorTx.TxHash()
 
 	genesisPointID, err := u.genesisPointRepo.InsertOne(ctx, genesis.GenesisPoint{
 		PrevOut:    p.Asset.FirstPrevOut.String(),
 		AnchorTxID: chainTxID,
 	})
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 
 	utxoOutpoint := wire.NewOutPoint(&txHash, p.Asset.OutputIndex)
@@ -209,26 +221,29 @@ func (u *UseCase) insertCommonComp(
 		TxID:             chainTxID,
 	})
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 
-	return &chainTxID, &genesisPointID, &manageUtxoID, nil
+	return &genesisPointID, &manageUtxoID, nil
 }
 
 func NewUseCase(
 	assetRepo genesisasset.RepoInterface,
 	assetOutpointRepo assetoutpoint.RepoInterface,
-	chainTxRepo chaintx.RepoInterface,
+	chainTxUseCase chaintx.UseCaseInterface,
 	genesisPointRepo genesis.RepoInterface,
 	manageUtxoRepo manageutxo.RepoInterface,
 	rpcClient *rpcclient.Client,
+	archiver proof.Archiver,
 ) mint.UseCaseInterface {
 	return &UseCase{
 		assetOutpointRepo: assetOutpointRepo,
 		genesisPointRepo:  genesisPointRepo,
-		chainTxRepo:       chainTxRepo,
+		chainTxUseCase:    chainTxUseCase,
 		assetRepo:         assetRepo,
 		manageUtxoRepo:    manageUtxoRepo,
 		rpcClient:         rpcClient,
+		headerVerifier:    proof.NewRPCHeaderVerifier(rpcClient),
+		archiver:          archiver,
 	}
 }
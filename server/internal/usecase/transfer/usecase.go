@@ -1,15 +1,14 @@
 package transfer
 
 import (
-	"bytes"
 	"fmt"
-	"os"
 
 	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/btcsuite/btcd/wire"
 	assetoutpoint "github.com/quocky/taproot-asset/server/internal/domain/asset_outpoint"
 	chaintx "github.com/quocky/taproot-asset/server/internal/domain/chain_tx"
 	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	genesisasset "github.com/quocky/taproot-asset/server/internal/domain/genesis_asset"
 	manageutxo "github.com/quocky/taproot-asset/server/internal/domain/manage_utxo"
 	"github.com/quocky/taproot-asset/server/internal/domain/transfer"
 	"github.com/quocky/taproot-asset/server/pkg/logger"
@@ -22,90 +21,183 @@ import (
 )
 
 type UseCase struct {
+	genesisAssetRepo  genesisasset.RepoInterface
 	assetOutpointRepo assetoutpoint.RepoInterface
-	chainTXRepo       chaintx.RepoInterface
+	chainTxUseCase    chaintx.UseCaseInterface
 	manageUtxoRepo    manageutxo.RepoInterface
 	rpcClient         *rpcclient.Client
+	headerVerifier    proof.HeaderVerifier
+	archiver          proof.Archiver
 }
 
 func (u *UseCase) TransferAsset(
 	ctx context.Context,
-	genesisAsset *asset.GenesisAsset,
 	anchorTx *wire.MsgTx,
 	amtSats int32,
 	btcOutputInfos []*onchain.BtcOutputInfo,
 	unspentOutpoints []*assetoutpointmodel.UnspentOutpoint,
 	files []*proof.File,
 ) error {
-	if err := u.insertDBTransferTx(
-		ctx,
-		genesisAsset,
-		anchorTx,
-		amtSats,
-		btcOutputInfos,
-		unspentOutpoints,
-		files,
-	); err != nil {
-		return err
+	for _, file := range files {
+		if _, err := file.VerifyUnconfirmed(ctx, u.headerVerifier); err != nil {
+			logger.Errorw("verify proof file fail", "err", err)
+
+			return err
+		}
 	}
 
-	_, err := u.rpcClient.SendRawTransaction(anchorTx, true)
+	outputs, err := u.transferOutputs(ctx, anchorTx, btcOutputInfos, files)
 	if err != nil {
-		logger.Errorw("rpcClient.SendRawTransaction fail", "tx_hash", anchorTx.TxHash(), "err", err)
+		logger.Errorw("read transfer outputs fail", "tx_hash", anchorTx.TxHash(), "err", err)
 
 		return err
 	}
 
-	for _, unspentOutpoint := range unspentOutpoints {
-		filename := fmt.Sprintf(proof.LocatorFilePath, unspentOutpoint.ProofLocator)
-
-		if err := os.Remove(filename); err != nil {
-			logger.Errorw("remove proof file fail", "filename", filename, "err", err)
+	locators := make([][32]byte, len(files))
+	for i, file := range files {
+		locator, err := proof.ImportFile(ctx, u.archiver, file)
+		if err != nil {
+			logger.Errorw("import proof file fail", "err", err)
 
 			return err
 		}
+
+		locators[i] = locator
+	}
+
+	// The new outpoints are written and the inputs marked spent with the
+	// pending anchor transaction, before it is broadcast, and rolled back
+	// if the node rejects it.
+	chainTxID, err := u.chainTxUseCase.RecordPending(ctx, anchorTx, func(ctx context.Context, chainTxID common.ID) error {
+		return u.insertDBTransferTx(
+			ctx,
+			chainTxID,
+			anchorTx,
+			amtSats,
+			btcOutputInfos,
+			outputs,
+			unspentOutpoints,
+			locators,
+		)
+	})
+	if err != nil {
+		logger.Errorw("record transfer fail", "tx_hash", anchorTx.TxHash(), "err", err)
+
+		return err
+	}
+
+	if err := u.chainTxUseCase.Broadcast(ctx, chainTxID, anchorTx); err != nil {
+		return err
 	}
 
+	// The chain watcher confirms the proof files once the anchor
+	// transaction is mined and registers them in the transfer trees. The
+	// proof files of the spent outpoints stay in the archive, they are part
+	// of the provenance of the new outputs.
 	return nil
 }
 
-func (u *UseCase) insertDBTransferTx(
+// transferOutput is a new asset outpoint of a transfer, anchored in the
+// output with the given index.
+type transferOutput struct {
+	outputIndex uint32
+	outpoint    assetoutpoint.AssetOutpoint
+}
+
+// transferOutputs returns the asset outpoints created by the transfer, one for
+// each proof file in the same order. The asset and the anchor output of each
+// outpoint are taken from the last proof of its file, which has to be anchored
+// in the transfer transaction.
+func (u *UseCase) transferOutputs(
 	ctx context.Context,
-	genesisAsset *asset.GenesisAsset,
 	anchorTx *wire.MsgTx,
-	amtSats int32,
 	btcOutputInfos []*onchain.BtcOutputInfo,
-	unspentOutpoints []*assetoutpointmodel.UnspentOutpoint,
 	files []*proof.File,
-) error {
+) ([]transferOutput, error) {
 	var (
-		txBytes bytes.Buffer
-		txID    = anchorTx.TxHash()
-		err     error
+		txHash     = anchorTx.TxHash()
+		genesisIDs = make(map[asset.ID]common.ID)
+		outputs    = make([]transferOutput, len(files))
 	)
 
-	if err := anchorTx.Serialize(&txBytes); err != nil {
-		logger.Errorw("anchorTx.Serialize err", err)
+	for i, file := range files {
+		lastProof, err := file.LastProof()
+		if err != nil {
+			return nil, err
+		}
 
-		return err
-	}
+		outputIndex := lastProof.InclusionProof.OutputIndex
+		if lastProof.AnchorTx.TxHash() != txHash || int(outputIndex) >= len(btcOutputInfos) {
+			return nil, fmt.Errorf("proof file %d isn't anchored in the transfer outputs", i)
+		}
 
-	chainTxID, err := u.chainTXRepo.InsertOne(ctx, &chaintx.ChainTx{
-		TxID:     txID[:],
-		AnchorTx: txBytes.Bytes(),
-	})
-	if err != nil {
-		return err
-	}
+		newAsset := &lastProof.Asset
+		assetID := newAsset.ID()
 
-	for outID, btcOut := range btcOutputInfos {
-		locatorName, err := files[outID].Store()
-		if err != nil {
-			logger.Errorw("locatorName, err := arg.Files[outIndex].Store()", "err", err)
+		if err := common.CheckAmountRange("amount", newAsset.Amount); err != nil {
+			return nil, err
+		}
 
-			return err
+		genesisID, ok := genesisIDs[assetID]
+		if !ok {
+			var genesisAsset genesisasset.GenesisAsset
+
+			err := u.genesisAssetRepo.FindOne(ctx, map[string]any{"asset_id": assetID[:]}, &genesisAsset)
+			if err != nil {
+				return nil, fmt.Errorf("find genesis of asset %x: %w", assetID[:], err)
+			}
+
+			genesisID = genesisAsset.ID
+			genesisIDs[assetID] = genesisID
 		}
 
+		outpoint := assetoutpoint.AssetOutpoint{
+			GenesisID: genesisID,
+			ScriptKey: newAsset.ScriptPubkey[:],
+			Amount:    newAsset.Amount,
+			Spent:     false,
+		}
+
+		if newAsset.SplitCommitmentRoot != nil {
+			var (
+				nodeHash = newAsset.SplitCommitmentRoot.NodeHash()
+				nodeSum  = newAsset.SplitCommitmentRoot.NodeSum()
+			)
+
+			if err := common.CheckAmountRange("split_commitment_root_value", nodeSum); err != nil {
+				return nil, err
+			}
+
+			outpoint.SplitCommitmentRootValue = nodeSum
+			outpoint.SplitCommitmentRootHash = nodeHash[:]
+		}
+
+		outputs[i] = transferOutput{
+			outputIndex: outputIndex,
+			outpoint:    outpoint,
+		}
+	}
+
+	return outputs, nil
+}
+
+// insertDBTransferTx inserts the anchor outputs and asset outpoints of the
+// transfer and marks its inputs spent. It runs in the database transaction of
+// the transfer.
+func (u *UseCase) insertDBTransferTx(
+	ctx context.Context,
+	chainTxID common.ID,
+	anchorTx *wire.MsgTx,
+	amtSats int32,
+	btcOutputInfos []*onchain.BtcOutputInfo,
+	outputs []transferOutput,
+	unspentOutpoints []*assetoutpointmodel.UnspentOutpoint,
+	locators [][32]byte,
+) error {
+	txID := anchorTx.TxHash()
+
+	utxoIDs := make([]common.ID, len(btcOutputInfos))
+	for outID, btcOut := range btcOutputInfos {
 		utxoID, err := u.manageUtxoRepo.InsertOne(ctx, &manageutxo.ManagedUtxo{
 			Outpoint:         wire.NewOutPoint(&txID, uint32(outID)).String(),
 			AmtSats:          amtSats,
@@ -118,28 +210,15 @@ func (u *UseCase) insertDBTransferTx(
 			return err
 		}
 
-		btcOutAssets := btcOut.GetOutputAsset()
-		// little confused
-		curAsset := btcOutAssets[0]
-
-		insertAssetOutpointParam := assetoutpoint.AssetOutpoint{
-			GenesisID:    common.ID(genesisAsset.AssetID),
-			ScriptKey:    curAsset.ScriptPubkey[:],
-			Amount:       curAsset.Amount,
-			AnchorUtxoID: utxoID,
-			ProofLocator: locatorName[:],
-			Spent:        false,
-		}
-
-		if curAsset.SplitCommitmentRoot != nil {
-			nodeHash := curAsset.SplitCommitmentRoot.NodeHash()
+		utxoIDs[outID] = utxoID
+	}
 
-			insertAssetOutpointParam.SplitCommitmentRootValue = int32(curAsset.SplitCommitmentRoot.NodeSum())
-			insertAssetOutpointParam.SplitCommitmentRootHash = nodeHash[:]
-		}
+	for i, output := range outputs {
+		outpoint := output.outpoint
+		outpoint.AnchorUtxoID = utxoIDs[output.outputIndex]
+		outpoint.ProofLocator = locators[i][:]
 
-		_, err = u.assetOutpointRepo.InsertOne(ctx, insertAssetOutpointParam)
-		if err != nil {
+		if _, err := u.assetOutpointRepo.InsertOne(ctx, outpoint); err != nil {
 			return err
 		}
 	}
@@ -149,31 +228,42 @@ func (u *UseCase) insertDBTransferTx(
 		unspentIDs[i] = common.ID(uo.ID)
 	}
 
-	err = u.assetOutpointRepo.UpdateMany(
+	err := u.assetOutpointRepo.UpdateMany(
 		ctx,
 		assetoutpoint.UnspentOutpointFilter{
 			IDs: &common.InOperator{Values: utils.ToSliceAny(unspentIDs)},
 		},
 		assetoutpoint.UnspentOutpointUpdate{
 			Set: &assetoutpoint.UnspentOutpointSetUpdate{
-				Spent: utils.ToPtr(true),
+				Spent:       utils.ToPtr(true),
+				SpentByTxID: utils.ToPtr(chainTxID),
 			},
 		},
 	)
+	if err != nil {
+		logger.Errorw("mark spent outpoints fail", "err", err)
+
+		return err
+	}
 
 	return nil
 }
 
 func NewUseCase(
+	genesisAssetRepo genesisasset.RepoInterface,
 	assetOutpointRepo assetoutpoint.RepoInterface,
-	chainTXRepo chaintx.RepoInterface,
+	chainTxUseCase chaintx.UseCaseInterface,
 	manageUtxoRepo manageutxo.RepoInterface,
 	rpcClient *rpcclient.Client,
+	archiver proof.Archiver,
 ) transfer.UseCaseInterface {
 	return &UseCase{
+		genesisAssetRepo:  genesisAssetRepo,
 		assetOutpointRepo: assetOutpointRepo,
-		chainTXRepo:       chainTXRepo,
+		chainTxUseCase:    chainTxUseCase,
 		manageUtxoRepo:    manageUtxoRepo,
 		rpcClient:         rpcClient,
+		headerVerifier:    proof.NewRPCHeaderVerifier(rpcClient),
+		archiver:          archiver,
 	}
 }
@@ -0,0 +1,254 @@
+package universe
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	universesdk "github.com/quocky/taproot-asset/taproot/http_model/universe"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	universemodel "github.com/quocky/taproot-asset/taproot/universe"
+	"golang.org/x/net/context"
+)
+
+// syncTimeout is the timeout of a single request to a peer server.
+const syncTimeout = 30 * time.Second
+
+// SyncUseCase syncs the universe trees of this server with the ones of peer
+// servers. Only the roots of the trees are compared first, the leaf keys and
+// leaves are only fetched for trees whose roots differ.
+type SyncUseCase struct {
+	universeUseCase universe.UseCaseInterface
+	archiver        proof.Archiver
+	headerVerifier  proof.HeaderVerifier
+	httpClient      *http.Client
+}
+
+func (u *SyncUseCase) SyncPeer(ctx context.Context, peerURL string) (*universe.SyncStats, error) {
+	var (
+		stats       universe.SyncStats
+		remoteRoots []*universesdk.RootsResp
+	)
+
+	if err := u.get(ctx, peerURL+"/universe/roots", nil, &remoteRoots); err != nil {
+		return nil, err
+	}
+
+	for _, remoteRoot := range remoteRoots {
+		localRoot, err := u.universeUseCase.Roots(ctx, remoteRoot.AssetID)
+		if err != nil {
+			return &stats, err
+		}
+
+		if *localRoot == *remoteRoot {
+			continue
+		}
+
+		stats.NumAssets++
+
+		trees := []struct {
+			treeType   universemodel.TreeType
+			localRoot  universesdk.Node
+			remoteRoot universesdk.Node
+			numLeaves  *int
+		}{
+			{universemodel.IssuanceTree, localRoot.Issuance, remoteRoot.Issuance, &stats.NumIssuanceLeaves},
+			{universemodel.TransferTree, localRoot.Transfer, remoteRoot.Transfer, &stats.NumTransferLeaves},
+		}
+
+		for _, tree := range trees {
+			if tree.localRoot == tree.remoteRoot {
+				continue
+			}
+
+			numLeaves, err := u.syncTree(ctx, peerURL, remoteRoot.AssetID, tree.treeType)
+			*tree.numLeaves += numLeaves
+			if err != nil {
+				return &stats, err
+			}
+		}
+	}
+
+	return &stats, nil
+}
+
+// syncTree fetches the leaves of the remote tree that are missing locally or
+// whose leaf hash differs from the local leaf with the same key. A differing
+// local leaf is replaced by the remote one once it's verified.
+func (u *SyncUseCase) syncTree(
+	ctx context.Context,
+	peerURL string,
+	assetID string,
+	treeType universemodel.TreeType,
+) (int, error) {
+	var remoteKeys universesdk.LeafKeysResp
+	err := u.get(ctx, fmt.Sprintf("%s/universe/leaves/%s/%s", peerURL, assetID, treeType), nil, &remoteKeys)
+	if err != nil {
+		return 0, err
+	}
+
+	localKeys, err := u.universeUseCase.LeafKeys(ctx, assetID, treeType)
+	if err != nil {
+		return 0, err
+	}
+
+	localHashes := make(map[universesdk.LeafKey]string, len(localKeys))
+	for _, key := range localKeys {
+		localHashes[key.LeafKey] = key.LeafHash
+	}
+
+	numLeaves := 0
+	for _, key := range remoteKeys {
+		localHash, ok := localHashes[key.LeafKey]
+		if ok && localHash == key.LeafHash {
+			continue
+		}
+
+		var leafResp universesdk.LeafResp
+		err := u.get(ctx, fmt.Sprintf("%s/universe/leaf/%s/%s", peerURL, assetID, treeType), url.Values{
+			"outpoint":   {key.OutPoint},
+			"script_key": {key.ScriptKey},
+		}, &leafResp)
+		if err != nil {
+			return numLeaves, err
+		}
+
+		file, leaf, err := u.verifyLeaf(ctx, assetID, treeType, &leafResp)
+		if err != nil {
+			logger.Errorw("verify universe leaf of peer fail", "peer", peerURL, "asset_id", assetID, "outpoint", key.OutPoint, "err", err)
+
+			return numLeaves, err
+		}
+
+		// The leaf hash listed by the peer is only a hint, the local leaf
+		// is kept if it matches the verified leaf.
+		leafHash := leaf.SMTLeaf().NodeHash()
+		if ok && localHash == hex.EncodeToString(leafHash[:]) {
+			continue
+		}
+
+		if _, err := proof.ImportFile(ctx, u.archiver, file); err != nil {
+			return numLeaves, err
+		}
+
+		if err := u.universeUseCase.RegisterLeaves(ctx, treeType, []*proof.File{file}); err != nil {
+			return numLeaves, err
+		}
+
+		numLeaves++
+	}
+
+	return numLeaves, nil
+}
+
+// verifyLeaf verifies the proof file of the leaf of a peer and checks that it
+// proves the asset of the leaf. It returns the file and the leaf built from it.
+func (u *SyncUseCase) verifyLeaf(
+	ctx context.Context,
+	assetID string,
+	treeType universemodel.TreeType,
+	leafResp *universesdk.LeafResp,
+) (*proof.File, *universemodel.Leaf, error) {
+	leaf, err := leafResp.UniverseLeaf()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", universe.ErrInvalidPeerLeaf, err)
+	}
+
+	var file proof.File
+	if err := file.Decode(leaf.ProofFile); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", universe.ErrInvalidPeerLeaf, err)
+	}
+
+	snapshot, err := file.Verify(ctx, u.headerVerifier)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", universe.ErrInvalidPeerLeaf, err)
+	}
+
+	expected, err := universemodel.NewLeaf(&file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case expected.AssetID != leaf.AssetID || leafResp.AssetID != assetID:
+		return nil, nil, fmt.Errorf("%w: asset id mismatch", universe.ErrInvalidPeerLeaf)
+
+	case expected.Key != leaf.Key || expected.Amount != leaf.Amount:
+		return nil, nil, fmt.Errorf("%w: leaf mismatch", universe.ErrInvalidPeerLeaf)
+
+	case treeType == universemodel.IssuanceTree && !snapshot.Asset.IsGenesisAsset():
+		return nil, nil, fmt.Errorf("%w: issuance leaf isn't a genesis asset", universe.ErrInvalidPeerLeaf)
+	}
+
+	return &file, expected, nil
+}
+
+// get fetches the JSON response of a peer.
+func (u *SyncUseCase) get(ctx context.Context, rawURL string, query url.Values, dest any) error {
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s failed with status %d", rawURL, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+func (u *SyncUseCase) Run(ctx context.Context, peerURLs []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, peerURL := range peerURLs {
+			stats, err := u.SyncPeer(ctx, peerURL)
+			if err != nil {
+				logger.Errorw("universe sync fail", "peer", peerURL, "err", err)
+
+				continue
+			}
+
+			logger.Infow("universe sync done", "peer", peerURL, "num_assets", stats.NumAssets, "num_issuance_leaves", stats.NumIssuanceLeaves, "num_transfer_leaves", stats.NumTransferLeaves)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+func NewSyncUseCase(
+	universeUseCase universe.UseCaseInterface,
+	archiver proof.Archiver,
+	headerVerifier proof.HeaderVerifier,
+) universe.SyncUseCaseInterface {
+	return &SyncUseCase{
+		universeUseCase: universeUseCase,
+		archiver:        archiver,
+		headerVerifier:  headerVerifier,
+		httpClient:      &http.Client{},
+	}
+}
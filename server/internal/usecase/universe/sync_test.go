@@ -0,0 +1,215 @@
+package universe
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gin-gonic/gin"
+	v1 "github.com/quocky/taproot-asset/server/internal/core/api/v1"
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	universesdk "github.com/quocky/taproot-asset/taproot/http_model/universe"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	universemodel "github.com/quocky/taproot-asset/taproot/universe"
+	"github.com/stretchr/testify/require"
+)
+
+// dishonestUseCase serves the leaves of the embedded use case after passing
+// them through tamper.
+type dishonestUseCase struct {
+	universe.UseCaseInterface
+
+	tamper func(leaf *universesdk.LeafResp)
+}
+
+func (d *dishonestUseCase) Leaf(
+	ctx context.Context,
+	assetID string,
+	treeType universemodel.TreeType,
+	key universemodel.LeafKey,
+) (*universesdk.LeafResp, error) {
+	leaf, err := d.UseCaseInterface.Leaf(ctx, assetID, treeType, key)
+	if err != nil {
+		return nil, err
+	}
+
+	d.tamper(leaf)
+
+	return leaf, nil
+}
+
+// newTestPeer serves the universe trees of the use case and returns the URL
+// of the peer.
+func newTestPeer(t *testing.T, useCase universe.UseCaseInterface) string {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	v1.NewUniverseController(useCase).RegisterRoutes(router)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// confirmFile returns a copy of the file whose last proof is confirmed in a
+// block at the height, and adds the block header to the verifier.
+func confirmFile(
+	t *testing.T,
+	file *proof.File,
+	verifier *proof.MemHeaderVerifier,
+	height uint32,
+) *proof.File {
+	t.Helper()
+
+	blob, err := file.Encode()
+	require.NoError(t, err)
+
+	var confirmed proof.File
+	require.NoError(t, confirmed.Decode(blob))
+
+	lastProof, err := confirmed.LastProof()
+	require.NoError(t, err)
+
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    1,
+			MerkleRoot: lastProof.AnchorTx.TxHash(),
+			Nonce:      height,
+		},
+		Transactions: []*wire.MsgTx{&lastProof.AnchorTx},
+	}
+	require.NoError(t, lastProof.SetBlockInfo(block, height))
+	require.NoError(t, confirmed.ReplaceLastProof(*lastProof))
+
+	verifier.AddHeader(block.Header, height)
+
+	return &confirmed
+}
+
+// TestSyncPeer makes sure the leaves missing locally are fetched, and local
+// leaves whose hash differs from the leaf of the peer are replaced.
+func TestSyncPeer(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		verifier   = proof.NewMemHeaderVerifier()
+		genesisOut = wire.OutPoint{Hash: chainhash.Hash{1}}
+
+		stale     = genesisFile(t, genesisOut)
+		confirmed = confirmFile(t, stale, verifier, 1)
+		missing   = confirmFile(t, genesisFile(t, genesisOut), verifier, 2)
+	)
+
+	local, _ := newTestUseCase(t)
+	remote, _ := newTestUseCase(t)
+
+	registerLeaves(t, local, universemodel.IssuanceTree, stale)
+	registerLeaves(t, remote, universemodel.IssuanceTree, confirmed, missing)
+
+	syncUseCase := NewSyncUseCase(local, local.archiver, verifier)
+	peerURL := newTestPeer(t, remote)
+
+	stats, err := syncUseCase.SyncPeer(ctx, peerURL)
+	require.NoError(t, err)
+	require.Equal(t, universe.SyncStats{NumAssets: 1, NumIssuanceLeaves: 2}, *stats)
+
+	leaf, locator := fileLeaf(t, confirmed)
+	assetID := hex.EncodeToString(leaf.AssetID[:])
+
+	localRoots, err := local.Roots(ctx, assetID)
+	require.NoError(t, err)
+	remoteRoots, err := remote.Roots(ctx, assetID)
+	require.NoError(t, err)
+	require.Equal(t, remoteRoots, localRoots)
+
+	blob, err := local.archiver.FetchProofByHash(ctx, locator)
+	require.NoError(t, err)
+	require.Equal(t, leaf.ProofFile, blob)
+
+	stats, err = syncUseCase.SyncPeer(ctx, peerURL)
+	require.NoError(t, err)
+	require.Zero(t, *stats)
+}
+
+// TestSyncPeerDishonest makes sure leaves of a peer that don't verify are
+// rejected and leave the local trees untouched.
+func TestSyncPeerDishonest(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		verifier   = proof.NewMemHeaderVerifier()
+		genesisOut = wire.OutPoint{Hash: chainhash.Hash{1}}
+		file       = confirmFile(t, genesisFile(t, genesisOut), verifier, 1)
+		otherAsset = confirmFile(t, genesisFile(t, wire.OutPoint{Hash: chainhash.Hash{2}}), verifier, 2)
+		otherChain = confirmFile(t, genesisFile(t, genesisOut), proof.NewMemHeaderVerifier(), 3)
+	)
+
+	otherAssetBlob, err := otherAsset.Encode()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		file   *proof.File
+		tamper func(leaf *universesdk.LeafResp)
+	}{
+		{
+			name: "amount",
+			file: file,
+			tamper: func(leaf *universesdk.LeafResp) {
+				leaf.Amount++
+			},
+		},
+		{
+			name: "proof file of other asset",
+			file: file,
+			tamper: func(leaf *universesdk.LeafResp) {
+				leaf.ProofFile = otherAssetBlob
+			},
+		},
+		{
+			name: "corrupt proof file",
+			file: file,
+			tamper: func(leaf *universesdk.LeafResp) {
+				leaf.ProofFile = leaf.ProofFile[:len(leaf.ProofFile)/2]
+			},
+		},
+		{
+			name:   "unknown block",
+			file:   otherChain,
+			tamper: func(*universesdk.LeafResp) {},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			local, leafRepo := newTestUseCase(t)
+			remote, _ := newTestUseCase(t)
+
+			registerLeaves(t, remote, universemodel.IssuanceTree, test.file)
+
+			syncUseCase := NewSyncUseCase(local, local.archiver, verifier)
+			peerURL := newTestPeer(t, &dishonestUseCase{
+				UseCaseInterface: remote,
+				tamper:           test.tamper,
+			})
+
+			stats, err := syncUseCase.SyncPeer(ctx, peerURL)
+			require.ErrorIs(t, err, universe.ErrInvalidPeerLeaf)
+			require.Zero(t, stats.NumIssuanceLeaves)
+
+			var leaves []*universe.Leaf
+			require.NoError(t, leafRepo.FindMany(ctx, universe.LeafFilter{}, &leaves))
+			require.Empty(t, leaves)
+
+			_, locator := fileLeaf(t, test.file)
+			_, err = local.archiver.FetchProofByHash(ctx, locator)
+			require.ErrorIs(t, err, proof.ErrProofNotFound)
+		})
+	}
+}
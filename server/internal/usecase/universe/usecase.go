@@ -0,0 +1,511 @@
+package universe
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	universesdk "github.com/quocky/taproot-asset/taproot/http_model/universe"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	universemodel "github.com/quocky/taproot-asset/taproot/universe"
+	"github.com/quocky/taproot-asset/taproot/utils"
+	"golang.org/x/net/context"
+)
+
+// TreeStoreFactory returns the tree store of the universe tree with the given
+// namespace.
+type TreeStoreFactory func(namespace string) (mssmt.TreeStore, error)
+
+// UseCase maintains an MS-SMT per asset and tree type. The leaves are
+// recorded in the universe leaf repo, so a tree whose store is empty, like an
+// in-memory store after a restart, is rebuilt from the recorded leaves.
+type UseCase struct {
+	leafRepo     universe.RepoInterface
+	archiver     proof.Archiver
+	newTreeStore TreeStoreFactory
+
+	// mu guards the trees and serializes all tree operations.
+	mu    sync.Mutex
+	trees map[string]*mssmt.CompactedTree
+}
+
+func (u *UseCase) RegisterLeaves(
+	ctx context.Context,
+	treeType universemodel.TreeType,
+	files []*proof.File,
+) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, file := range files {
+		leaf, err := universemodel.NewLeaf(file)
+		if err != nil {
+			return err
+		}
+
+		loc, err := file.Locator()
+		if err != nil {
+			return err
+		}
+
+		locHash, err := loc.Hash()
+		if err != nil {
+			return err
+		}
+
+		tree, err := u.tree(ctx, leaf.AssetID, treeType)
+		if err != nil {
+			return err
+		}
+
+		leafKey := leaf.Key.UniverseKey()
+
+		var existing universe.Leaf
+		err = u.leafRepo.FindOne(ctx, universe.LeafFilter{
+			AssetID:  leaf.AssetID[:],
+			TreeType: utils.ToPtr(uint8(treeType)),
+			LeafKey:  leafKey[:],
+		}, &existing)
+		switch {
+		case errors.Is(err, common.ErrDatabaseNotFound):
+			_, err = u.leafRepo.InsertOne(ctx, universe.Leaf{
+				AssetID:      leaf.AssetID[:],
+				TreeType:     uint8(treeType),
+				LeafKey:      leafKey[:],
+				OutPoint:     leaf.Key.OutPoint.String(),
+				ScriptKey:    leaf.Key.ScriptKey[:],
+				Amount:       leaf.Amount,
+				ProofLocator: locHash[:],
+			})
+			if err != nil {
+				return err
+			}
+
+		case err != nil:
+			return err
+		}
+
+		if _, err := tree.Insert(ctx, leafKey, leaf.SMTLeaf()); err != nil {
+			logger.Errorw("insert universe leaf fail", "asset_id", hex.EncodeToString(leaf.AssetID[:]), "tree_type", treeType.String(), "err", err)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveLeaves deletes the leaves from their trees and the leaf repo. The trees
+// are reopened afterwards, so a tree in a store outside the database
+// transaction is rebuilt from the recorded leaves once the transaction is
+// done, and follows it if it's rolled back.
+func (u *UseCase) RemoveLeaves(ctx context.Context, locators [][32]byte) error {
+	if len(locators) == 0 {
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	values := make([]any, len(locators))
+	for i, locator := range locators {
+		values[i] = locator[:]
+	}
+
+	filter := universe.LeafFilter{
+		ProofLocators: &common.InOperator{Values: values},
+	}
+
+	var leaves []*universe.Leaf
+	if err := u.leafRepo.FindMany(ctx, filter, &leaves); err != nil {
+		return err
+	}
+
+	if len(leaves) == 0 {
+		return nil
+	}
+
+	for _, leaf := range leaves {
+		if len(leaf.AssetID) != len(asset.ID{}) || len(leaf.LeafKey) != sha256.Size {
+			return fmt.Errorf("invalid universe leaf %s", leaf.ID)
+		}
+
+		var (
+			assetID  = asset.ID(leaf.AssetID)
+			treeType = universemodel.TreeType(leaf.TreeType)
+		)
+
+		tree, err := u.tree(ctx, assetID, treeType)
+		if err != nil {
+			return err
+		}
+
+		// The tree is reopened by the next operation, whether the
+		// delete succeeds or not.
+		delete(u.trees, treeNamespace(assetID, treeType))
+
+		if _, err := tree.Delete(ctx, [32]byte(leaf.LeafKey)); err != nil {
+			logger.Errorw("delete universe leaf fail", "asset_id", hex.EncodeToString(leaf.AssetID), "tree_type", treeType.String(), "err", err)
+
+			return err
+		}
+	}
+
+	return u.leafRepo.DeleteMany(ctx, filter)
+}
+
+func (u *UseCase) Roots(ctx context.Context, assetID string) (*universesdk.RootsResp, error) {
+	id, err := parseAssetID(assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	issuanceRoot, err := u.root(ctx, id, universemodel.IssuanceTree)
+	if err != nil {
+		return nil, err
+	}
+
+	transferRoot, err := u.root(ctx, id, universemodel.TransferTree)
+	if err != nil {
+		return nil, err
+	}
+
+	return &universesdk.RootsResp{
+		AssetID:  assetID,
+		Issuance: universesdk.NewNode(issuanceRoot),
+		Transfer: universesdk.NewNode(transferRoot),
+	}, nil
+}
+
+func (u *UseCase) AllRoots(ctx context.Context) ([]*universesdk.RootsResp, error) {
+	assetIDs, err := u.leafRepo.FindAssetIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]*universesdk.RootsResp, len(assetIDs))
+	for i, assetID := range assetIDs {
+		roots[i], err = u.Roots(ctx, hex.EncodeToString(assetID))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return roots, nil
+}
+
+func (u *UseCase) LeafKeys(
+	ctx context.Context,
+	assetID string,
+	treeType universemodel.TreeType,
+) (universesdk.LeafKeysResp, error) {
+	id, err := parseAssetID(assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	tree, err := u.tree(ctx, id, treeType)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := u.leaves(ctx, id, treeType)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(universesdk.LeafKeysResp, len(leaves))
+	for i, leaf := range leaves {
+		if len(leaf.LeafKey) != sha256.Size {
+			return nil, fmt.Errorf("invalid universe leaf %s", leaf.ID)
+		}
+
+		leafNode, err := tree.Get(ctx, [32]byte(leaf.LeafKey))
+		if err != nil {
+			return nil, err
+		}
+
+		leafHash := leafNode.NodeHash()
+
+		keys[i] = universesdk.LeafKeyResp{
+			LeafKey: universesdk.LeafKey{
+				OutPoint:  leaf.OutPoint,
+				ScriptKey: hex.EncodeToString(leaf.ScriptKey),
+			},
+			LeafHash: hex.EncodeToString(leafHash[:]),
+		}
+	}
+
+	return keys, nil
+}
+
+func (u *UseCase) Leaf(
+	ctx context.Context,
+	assetID string,
+	treeType universemodel.TreeType,
+	key universemodel.LeafKey,
+) (*universesdk.LeafResp, error) {
+	id, err := parseAssetID(assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	leaf, err := u.leaf(ctx, id, treeType, key)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := universesdk.NewLeafResp(leaf)
+
+	return &resp, nil
+}
+
+func (u *UseCase) InclusionProof(
+	ctx context.Context,
+	assetID string,
+	treeType universemodel.TreeType,
+	key universemodel.LeafKey,
+) (*universesdk.InclusionProofResp, error) {
+	id, err := parseAssetID(assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	leaf, err := u.leaf(ctx, id, treeType, key)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := u.tree(ctx, id, treeType)
+	if err != nil {
+		return nil, err
+	}
+
+	merkleProof, err := tree.MerkleProof(ctx, key.UniverseKey())
+	if err != nil {
+		return nil, err
+	}
+
+	merkleProofBytes, err := universemodel.EncodeMerkleProof(merkleProof)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := tree.Root(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &universesdk.InclusionProofResp{
+		Leaf:        universesdk.NewLeafResp(leaf),
+		MerkleProof: merkleProofBytes,
+		Root:        universesdk.NewNode(root),
+	}, nil
+}
+
+// leaf returns the leaf of the tree at the key.
+//
+// NOTE: The caller must hold the mutex.
+func (u *UseCase) leaf(
+	ctx context.Context,
+	assetID asset.ID,
+	treeType universemodel.TreeType,
+	key universemodel.LeafKey,
+) (*universemodel.Leaf, error) {
+	tree, err := u.tree(ctx, assetID, treeType)
+	if err != nil {
+		return nil, err
+	}
+
+	leafNode, err := tree.Get(ctx, key.UniverseKey())
+	if err != nil {
+		return nil, err
+	}
+
+	if leafNode.IsEmpty() {
+		return nil, universemodel.ErrLeafNotFound
+	}
+
+	blob, err := u.proofFile(ctx, assetID, key, leafNode.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &universemodel.Leaf{
+		Key:       key,
+		AssetID:   assetID,
+		Amount:    leafNode.NodeSum(),
+		ProofFile: blob,
+	}, nil
+}
+
+// proofFile returns the revision of the archived proof file of the leaf key
+// whose hash is the value of the leaf node. The latest revision is tried
+// first, it's the one the leaf was registered with unless a newer revision was
+// imported since.
+func (u *UseCase) proofFile(
+	ctx context.Context,
+	assetID asset.ID,
+	key universemodel.LeafKey,
+	fileHash []byte,
+) (proof.Blob, error) {
+	revisions, err := u.archiver.FetchProofRevisions(ctx, proof.Locator{
+		AssetID:   &assetID,
+		ScriptKey: key.ScriptKey,
+		OutPoint:  &key.OutPoint,
+	})
+	if err != nil {
+		logger.Errorw("fetch universe leaf proof fail", "outpoint", key.OutPoint.String(), "err", err)
+
+		return nil, err
+	}
+
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if bytes.Equal(universemodel.ProofFileHash(revisions[i]), fileHash) {
+			return revisions[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no revision of the proof file of universe leaf %s matches the leaf",
+		proof.ErrProofNotFound, key.OutPoint)
+}
+
+// root returns the root of the tree.
+//
+// NOTE: The caller must hold the mutex.
+func (u *UseCase) root(
+	ctx context.Context,
+	assetID asset.ID,
+	treeType universemodel.TreeType,
+) (*mssmt.BranchNode, error) {
+	tree, err := u.tree(ctx, assetID, treeType)
+	if err != nil {
+		return nil, err
+	}
+
+	return tree.Root(ctx)
+}
+
+// tree returns the tree of the asset and tree type. A tree with an empty store
+// is rebuilt from the recorded leaves and their archived proof files.
+//
+// NOTE: The caller must hold the mutex.
+func (u *UseCase) tree(
+	ctx context.Context,
+	assetID asset.ID,
+	treeType universemodel.TreeType,
+) (*mssmt.CompactedTree, error) {
+	namespace := treeNamespace(assetID, treeType)
+	if tree, ok := u.trees[namespace]; ok {
+		return tree, nil
+	}
+
+	store, err := u.newTreeStore(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := mssmt.NewCompactedTree(store)
+
+	root, err := tree.Root(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if mssmt.IsEqualNode(root, mssmt.EmptyTree[0]) {
+		leaves, err := u.leaves(ctx, assetID, treeType)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, leaf := range leaves {
+			if len(leaf.ProofLocator) != sha256.Size || len(leaf.LeafKey) != sha256.Size {
+				return nil, fmt.Errorf("invalid universe leaf %s", leaf.ID)
+			}
+
+			blob, err := u.archiver.FetchProofByHash(ctx, [32]byte(leaf.ProofLocator))
+			if err != nil {
+				logger.Errorw("fetch universe leaf proof fail", "outpoint", leaf.OutPoint, "err", err)
+
+				return nil, err
+			}
+
+			leafNode := mssmt.NewLeafNode(universemodel.ProofFileHash(blob), leaf.Amount)
+
+			_, err = tree.Insert(ctx, [32]byte(leaf.LeafKey), leafNode)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	u.trees[namespace] = tree
+
+	return tree, nil
+}
+
+// treeNamespace returns the namespace of the tree store of the asset and tree
+// type.
+func treeNamespace(assetID asset.ID, treeType universemodel.TreeType) string {
+	return fmt.Sprintf("%x-%s", assetID[:], treeType)
+}
+
+// leaves returns the recorded leaves of the tree.
+func (u *UseCase) leaves(
+	ctx context.Context,
+	assetID asset.ID,
+	treeType universemodel.TreeType,
+) ([]*universe.Leaf, error) {
+	leaves := make([]*universe.Leaf, 0)
+	err := u.leafRepo.FindMany(ctx, universe.LeafFilter{
+		AssetID:  assetID[:],
+		TreeType: utils.ToPtr(uint8(treeType)),
+	}, &leaves)
+	if err != nil {
+		return nil, err
+	}
+
+	return leaves, nil
+}
+
+func parseAssetID(assetID string) (asset.ID, error) {
+	assetIDBytes, err := hex.DecodeString(assetID)
+	if err != nil || len(assetIDBytes) != len(asset.ID{}) {
+		return asset.ID{}, fmt.Errorf("%w: %s", universe.ErrInvalidAssetID, assetID)
+	}
+
+	return asset.ID(assetIDBytes), nil
+}
+
+func NewUseCase(
+	leafRepo universe.RepoInterface,
+	archiver proof.Archiver,
+	newTreeStore TreeStoreFactory,
+) universe.UseCaseInterface {
+	return &UseCase{
+		leafRepo:     leafRepo,
+		archiver:     archiver,
+		newTreeStore: newTreeStore,
+		trees:        make(map[string]*mssmt.CompactedTree),
+	}
+}
@@ -0,0 +1,242 @@
+package universe
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	config "github.com/quocky/taproot-asset/server/config/core"
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
+	"github.com/quocky/taproot-asset/server/internal/domain/universe"
+	mssmtstore "github.com/quocky/taproot-asset/server/internal/repo/mssmt_store"
+	universeleafrepo "github.com/quocky/taproot-asset/server/internal/repo/universe_leaf"
+	"github.com/quocky/taproot-asset/server/pkg/database"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	universemodel "github.com/quocky/taproot-asset/taproot/universe"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// genesisFile returns the proof file of a newly minted asset of the genesis
+// outpoint whose minting transaction isn't confirmed yet. Files of the same
+// genesis outpoint are of the same asset, but anchored in different outputs.
+func genesisFile(t *testing.T, genesisOut wire.OutPoint) *proof.File {
+	t.Helper()
+
+	owner, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	a := asset.New(
+		genesisOut, "ticker", 0, 100,
+		asset.ToSerialized(owner.PubKey()), nil,
+	)
+
+	assetCommitment, err := commitment.NewAssetCommitment(context.Background(), a)
+	require.NoError(t, err)
+	tapCommitment, err := commitment.NewTapCommitment(assetCommitment)
+	require.NoError(t, err)
+
+	internalKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	tapscriptRoot := txscript.AssembleTaprootScriptTree(
+		tapCommitment.TapLeaf(),
+	).RootNode.TapHash()
+	pkScript, err := txscript.PayToTaprootScript(
+		txscript.ComputeTaprootOutputKey(internalKey.PubKey(), tapscriptRoot[:]),
+	)
+	require.NoError(t, err)
+
+	anchorTx := wire.NewMsgTx(2)
+	anchorTx.AddTxIn(wire.NewTxIn(&genesisOut, nil, nil))
+	anchorTx.AddTxOut(wire.NewTxOut(1000, pkScript))
+
+	proofs, err := proof.NewMintingBlobs(zap.NewNop(), &proof.MintParams{
+		BaseProofParams: proof.BaseProofParams{
+			Tx:            anchorTx,
+			InternalKey:   asset.ToSerialized(internalKey.PubKey()),
+			TapCommitment: tapCommitment,
+		},
+		GenesisPoint: genesisOut,
+	})
+	require.NoError(t, err)
+
+	file, err := proof.NewFile(*proofs[0])
+	require.NoError(t, err)
+
+	return file
+}
+
+// newTestUseCase returns a universe use case on a fresh SQLite database with
+// the trees stored in the database.
+func newTestUseCase(t *testing.T) (*UseCase, universe.RepoInterface) {
+	t.Helper()
+
+	logger.Init()
+
+	db, err := database.NewSQLDatabase(&config.Config{
+		Database: config.Database{
+			Backend: string(database.SQLite),
+			DSN:     "file:" + filepath.Join(t.TempDir(), "taproot.db"),
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	leafRepo := universeleafrepo.NewRepoSQL(db)
+	useCase := NewUseCase(leafRepo, proof.NewMemArchiver(), func(namespace string) (mssmt.TreeStore, error) {
+		return mssmtstore.NewRepoSQL(db, namespace), nil
+	})
+
+	return useCase.(*UseCase), leafRepo
+}
+
+// registerLeaves imports the proof files into the archive of the use case and
+// registers their leaves.
+func registerLeaves(
+	t *testing.T,
+	useCase *UseCase,
+	treeType universemodel.TreeType,
+	files ...*proof.File,
+) {
+	t.Helper()
+
+	ctx := context.Background()
+	for _, file := range files {
+		_, err := proof.ImportFile(ctx, useCase.archiver, file)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, useCase.RegisterLeaves(ctx, treeType, files))
+}
+
+// fileLeaf returns the leaf and the locator hash of the proof file.
+func fileLeaf(t *testing.T, file *proof.File) (*universemodel.Leaf, [32]byte) {
+	t.Helper()
+
+	leaf, err := universemodel.NewLeaf(file)
+	require.NoError(t, err)
+
+	loc, err := file.Locator()
+	require.NoError(t, err)
+
+	locHash, err := loc.Hash()
+	require.NoError(t, err)
+
+	return leaf, locHash
+}
+
+// TestRemoveLeaves makes sure removed leaves leave both their tree and the
+// leaf repo, and that both are kept if the surrounding transaction fails.
+func TestRemoveLeaves(t *testing.T) {
+	ctx := context.Background()
+	useCase, leafRepo := newTestUseCase(t)
+
+	var (
+		genesisOut = wire.OutPoint{Hash: chainhash.Hash{1}}
+		removed    = genesisFile(t, genesisOut)
+		kept       = genesisFile(t, genesisOut)
+
+		issuanceTree = universemodel.IssuanceTree
+		errRollback  = errors.New("rollback")
+	)
+
+	removedLeaf, locator := fileLeaf(t, removed)
+	keptLeaf, _ := fileLeaf(t, kept)
+	assetIDStr := hex.EncodeToString(removedLeaf.AssetID[:])
+
+	registerLeaves(t, useCase, issuanceTree, removed, kept)
+
+	rootsBefore, err := useCase.Roots(ctx, assetIDStr)
+	require.NoError(t, err)
+
+	numLeaves := func() int {
+		var leaves []*universe.Leaf
+		require.NoError(t, leafRepo.FindMany(ctx, universe.LeafFilter{}, &leaves))
+
+		return len(leaves)
+	}
+	require.Equal(t, 2, numLeaves())
+
+	err = leafRepo.RunTransactions(ctx, []common.TransactionCallbackFunc{
+		func(ctx context.Context) error {
+			return useCase.RemoveLeaves(ctx, [][32]byte{locator})
+		},
+		func(context.Context) error {
+			return errRollback
+		},
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	roots, err := useCase.Roots(ctx, assetIDStr)
+	require.NoError(t, err)
+	require.Equal(t, rootsBefore, roots)
+	require.Equal(t, 2, numLeaves())
+
+	_, err = useCase.Leaf(ctx, assetIDStr, issuanceTree, removedLeaf.Key)
+	require.NoError(t, err)
+
+	require.NoError(t, useCase.RemoveLeaves(ctx, [][32]byte{locator}))
+
+	roots, err = useCase.Roots(ctx, assetIDStr)
+	require.NoError(t, err)
+	require.NotEqual(t, rootsBefore.Issuance, roots.Issuance)
+	require.EqualValues(t, keptLeaf.Amount, roots.Issuance.Sum)
+	require.Equal(t, 1, numLeaves())
+
+	_, err = useCase.Leaf(ctx, assetIDStr, issuanceTree, removedLeaf.Key)
+	require.ErrorIs(t, err, universemodel.ErrLeafNotFound)
+
+	_, err = useCase.Leaf(ctx, assetIDStr, issuanceTree, keptLeaf.Key)
+	require.NoError(t, err)
+}
+
+// TestLeafProofFile makes sure the trees only hold the hashes of the proof
+// files, and leaves are served with the archived revision they commit to.
+func TestLeafProofFile(t *testing.T) {
+	ctx := context.Background()
+	useCase, _ := newTestUseCase(t)
+
+	var (
+		file      = genesisFile(t, wire.OutPoint{Hash: chainhash.Hash{1}})
+		confirmed = confirmFile(t, file, proof.NewMemHeaderVerifier(), 1)
+	)
+
+	registerLeaves(t, useCase, universemodel.IssuanceTree, file)
+
+	leaf, _ := fileLeaf(t, file)
+	assetID := hex.EncodeToString(leaf.AssetID[:])
+
+	tree, err := useCase.tree(ctx, leaf.AssetID, universemodel.IssuanceTree)
+	require.NoError(t, err)
+
+	leafNode, err := tree.Get(ctx, leaf.Key.UniverseKey())
+	require.NoError(t, err)
+	require.Equal(t, universemodel.ProofFileHash(leaf.ProofFile), leafNode.Value)
+
+	// A newer revision that isn't registered doesn't change the leaf.
+	_, err = proof.ImportFile(ctx, useCase.archiver, confirmed)
+	require.NoError(t, err)
+
+	leafResp, err := useCase.Leaf(ctx, assetID, universemodel.IssuanceTree, leaf.Key)
+	require.NoError(t, err)
+	require.Equal(t, []byte(leaf.ProofFile), leafResp.ProofFile)
+
+	registerLeaves(t, useCase, universemodel.IssuanceTree, confirmed)
+
+	confirmedLeaf, _ := fileLeaf(t, confirmed)
+
+	leafResp, err = useCase.Leaf(ctx, assetID, universemodel.IssuanceTree, leaf.Key)
+	require.NoError(t, err)
+	require.Equal(t, []byte(confirmedLeaf.ProofFile), leafResp.ProofFile)
+}
@@ -1,12 +1,15 @@
 package utxo
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 
 	assetoutpoint "github.com/quocky/taproot-asset/server/internal/domain/asset_outpoint"
+	chaintx "github.com/quocky/taproot-asset/server/internal/domain/chain_tx"
+	"github.com/quocky/taproot-asset/server/internal/domain/common"
 	"github.com/quocky/taproot-asset/server/internal/domain/genesis"
 	genesisasset "github.com/quocky/taproot-asset/server/internal/domain/genesis_asset"
 	utxoasset "github.com/quocky/taproot-asset/server/internal/domain/utxo_asset"
@@ -14,6 +17,7 @@ import (
 	utxoassetsdk "github.com/quocky/taproot-asset/taproot/http_model/utxo_asset"
 	assetsdk "github.com/quocky/taproot-asset/taproot/model/asset"
 	"github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
 	"github.com/quocky/taproot-asset/taproot/model/proof"
 	"github.com/quocky/taproot-asset/taproot/utils"
 	"golang.org/x/net/context"
@@ -23,6 +27,8 @@ type UseCase struct {
 	genesisAssetRepo  genesisasset.RepoInterface
 	assetOutpointRepo assetoutpoint.RepoInterface
 	genesisPointRepo  genesis.RepoInterface
+	chainTxRepo       chaintx.RepoInterface
+	archiver          proof.Archiver
 }
 
 func (u *UseCase) ListAllAssetsWithAmount(
@@ -35,7 +41,7 @@ func (u *UseCase) ListAllAssetsWithAmount(
 func (u *UseCase) GetUnspentAssetsById(
 	ctx context.Context,
 	assetID string,
-	amount int32,
+	amount uint64,
 	pubKey []byte,
 ) (*utxoassetsdk.UnspentAssetResp, error) {
 	var (
@@ -43,7 +49,7 @@ func (u *UseCase) GetUnspentAssetsById(
 		unspentOutpoints []*assetoutpointmodel.UnspentOutpoint
 		genesisPoint     genesis.GenesisPoint
 		inputFilesBytes  [][]byte
-		actualAmount     int32 = 0
+		actualAmount     uint64 = 0
 	)
 
 	assetIdBytes, err := hex.DecodeString(assetID)
@@ -75,7 +81,15 @@ func (u *UseCase) GetUnspentAssetsById(
 		return nil, err
 	}
 
-	unspentOutpoints, actualAmount = extractFromAllUnspentOutpoints(allUnspentOutpoints, amount)
+	allUnspentOutpoints, err = u.filterConfirmed(ctx, allUnspentOutpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	unspentOutpoints, actualAmount, err = u.extractFromAllUnspentOutpoints(ctx, allUnspentOutpoints, amount)
+	if err != nil {
+		return nil, err
+	}
 
 	if actualAmount < amount {
 		logger.Errorw("not enough amount", "actual_amount", actualAmount, "required_amount", amount)
@@ -85,11 +99,9 @@ func (u *UseCase) GetUnspentAssetsById(
 
 	inputFilesBytes = make([][]byte, len(unspentOutpoints))
 	for i, unspentOutpoint := range unspentOutpoints {
-		filename := fmt.Sprintf(proof.LocatorFilePath, unspentOutpoint.ProofLocator)
-
-		fileBytes, err := proof.FileBytesFromName(filename)
+		fileBytes, err := u.fetchProof(ctx, unspentOutpoint.ProofLocator)
 		if err != nil {
-			logger.Errorw("get file bytes fail", "filename", filename, "err", err.Error())
+			logger.Errorw("get file bytes fail", "proof_locator", hex.EncodeToString(unspentOutpoint.ProofLocator), "err", err.Error())
 
 			return nil, err
 		}
@@ -102,6 +114,7 @@ func (u *UseCase) GetUnspentAssetsById(
 			AssetID:        genesisAsset.ID.String(),
 			AssetName:      genesisAsset.AssetName,
 			Supply:         genesisAsset.Supply,
+			AssetType:      assetsdk.Type(genesisAsset.AssetType),
 			OutputIndex:    genesisAsset.OutputIndex,
 			GenesisPointID: genesisAsset.GenesisPointID.String(),
 		},
@@ -114,44 +127,124 @@ func (u *UseCase) GetUnspentAssetsById(
 	}, nil
 }
 
-func extractFromAllUnspentOutpoints(allUnspentOutpoints []*assetoutpoint.UnspentOutpoint, amount int32) ([]*assetoutpointmodel.UnspentOutpoint, int32) {
+func (u *UseCase) GetAssetMeta(
+	ctx context.Context,
+	assetID string,
+) (*utxoassetsdk.AssetMetaResp, error) {
+	var genesisAsset genesisasset.GenesisAsset
+
+	assetIdBytes, err := hex.DecodeString(assetID)
+	if err != nil || len(assetIdBytes) != len(assetsdk.ID{}) {
+		return nil, fmt.Errorf("%w: %s", utxoasset.ErrInvalidAssetID, assetID)
+	}
+
+	err = u.genesisAssetRepo.FindOne(ctx, map[string]any{"asset_id": assetIdBytes}, &genesisAsset)
+	if errors.Is(err, common.ErrDatabaseNotFound) {
+		return nil, utxoasset.ErrAssetMetaNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(genesisAsset.MetaHash) == 0 {
+		return nil, utxoasset.ErrAssetMetaNotFound
+	}
+
+	return &utxoassetsdk.AssetMetaResp{
+		AssetID:  assetID,
+		MetaHash: hex.EncodeToString(genesisAsset.MetaHash),
+		MetaType: assetsdk.MetaType(genesisAsset.MetaType),
+		Data:     genesisAsset.MetaData,
+	}, nil
+}
+
+// filterConfirmed keeps the outpoints whose anchor transaction is confirmed.
+// The proof of an unconfirmed outpoint can't be verified by the receiver of a
+// transfer spending it, so it isn't spendable yet.
+func (u *UseCase) filterConfirmed(
+	ctx context.Context,
+	unspentOutpoints []*assetoutpoint.UnspentOutpoint,
+) ([]*assetoutpoint.UnspentOutpoint, error) {
+	chainTxIDs := make([]common.ID, 0, len(unspentOutpoints))
+	for _, uo := range unspentOutpoints {
+		chainTxIDs = append(chainTxIDs, uo.TxID)
+	}
+
+	var chainTxs []*chaintx.ChainTx
+
+	err := u.chainTxRepo.FindMany(ctx, chaintx.ChainTxFilter{
+		IDs: &common.InOperator{Values: utils.ToSliceAny(chainTxIDs)},
+	}, &chainTxs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transactions recorded before the state machine have no status and
+	// were confirmed right after their broadcast.
+	confirmed := make(map[common.ID]bool, len(chainTxs))
+	for _, chainTx := range chainTxs {
+		confirmed[chainTx.ID] = chainTx.Status == chaintx.StatusConfirmed || chainTx.Status == ""
+	}
+
+	confirmedOutpoints := make([]*assetoutpoint.UnspentOutpoint, 0, len(unspentOutpoints))
+	for _, uo := range unspentOutpoints {
+		if confirmed[uo.TxID] {
+			confirmedOutpoints = append(confirmedOutpoints, uo)
+		}
+	}
+
+	return confirmedOutpoints, nil
+}
+
+// fetchProof fetches the proof file of the given locator hash from the archive.
+func (u *UseCase) fetchProof(ctx context.Context, proofLocator []byte) ([]byte, error) {
+	if len(proofLocator) != sha256.Size {
+		return nil, fmt.Errorf("invalid proof locator length %d", len(proofLocator))
+	}
+
+	return u.archiver.FetchProofByHash(ctx, [32]byte(proofLocator))
+}
+
+// extractFromAllUnspentOutpoints collects the unspent outpoints with their
+// proof files until their amounts sum up to amount, or all of them if amount
+// is zero. It returns the outpoints and the sum of their amounts.
+func (u *UseCase) extractFromAllUnspentOutpoints(ctx context.Context, allUnspentOutpoints []*assetoutpoint.UnspentOutpoint, amount uint64) ([]*assetoutpointmodel.UnspentOutpoint, uint64, error) {
 	var (
-		actualAmount     int32 = 0
-		unspentOutpoints       = make([]*assetoutpointmodel.UnspentOutpoint, 0)
+		actualAmount     uint64 = 0
+		unspentOutpoints        = make([]*assetoutpointmodel.UnspentOutpoint, 0)
 	)
 
 	for _, uo := range allUnspentOutpoints {
 		fmt.Println("unspentOutpoint", uo.TxID, uo.Amount)
 
-		filename := fmt.Sprintf(proof.LocatorFilePath, uo.ProofLocator)
-
-		fileBytes, err := proof.FileBytesFromName(filename)
+		fileBytes, err := u.fetchProof(ctx, uo.ProofLocator)
 		if err != nil {
-			logger.Errorw("get file bytes fail", "filename", filename, "err", err.Error())
+			logger.Errorw("get file bytes fail", "proof_locator", hex.EncodeToString(uo.ProofLocator), "err", err.Error())
 
-			return nil, 0
+			return nil, 0, err
 		}
 
-		relatedAnchorAssets := make([][]byte, len(uo.RelatedAssets))
-		relatedAnchorAssetProofs := make([][]byte, len(uo.RelatedAssets))
+		// The related assets and their proof files are paired by index, the
+		// client re-anchors them when it spends the anchor output.
+		relatedAnchorAssets := make([][]byte, 0, len(uo.RelatedAssets))
+		relatedAnchorAssetProofs := make([][]byte, 0, len(uo.RelatedAssets))
 
 		for _, ra := range uo.RelatedAssets {
 			raBytes, err := json.Marshal(ra)
 			if err != nil {
 				logger.Errorw("marshal related genesis_asset fail", "related_asset_id", ra.ID.String(), "err", err.Error())
 
-				return nil, 0
+				return nil, 0, err
 			}
 
 			relatedAnchorAssets = append(relatedAnchorAssets, raBytes)
 
-			filenameRa := fmt.Sprintf(proof.LocatorFilePath, ra.ProofLocator)
-
-			fileByteRas, err := proof.FileBytesFromName(filenameRa)
+			fileByteRas, err := u.fetchProof(ctx, ra.ProofLocator)
 			if err != nil {
-				logger.Errorw("get file bytes fail", "filename", filename, "err", err.Error())
+				logger.Errorw("get file bytes fail", "proof_locator", hex.EncodeToString(ra.ProofLocator), "err", err.Error())
 
-				return nil, 0
+				return nil, 0, err
 			}
 
 			relatedAnchorAssetProofs = append(relatedAnchorAssetProofs, fileByteRas)
@@ -178,23 +271,33 @@ func extractFromAllUnspentOutpoints(allUnspentOutpoints []*assetoutpoint.Unspent
 			RelatedAnchorAssetProofs: relatedAnchorAssetProofs,
 		})
 
+		if err := mssmt.CheckSumOverflowUint64(actualAmount, uo.Amount); err != nil {
+			logger.Errorw("sum unspent amount fail", "asset_outpoint_id", uo.AssetOutpoint.ID.String(), "err", err.Error())
+
+			return nil, 0, err
+		}
+
 		actualAmount += uo.Amount
 		if amount > 0 && actualAmount >= amount {
 			break
 		}
 	}
 
-	return unspentOutpoints, actualAmount
+	return unspentOutpoints, actualAmount, nil
 }
 
 func NewUseCase(
 	genesisAssetRepo genesisasset.RepoInterface,
 	assetOutpointRepo assetoutpoint.RepoInterface,
 	genesisPointRepo genesis.RepoInterface,
+	chainTxRepo chaintx.RepoInterface,
+	archiver proof.Archiver,
 ) utxoasset.UseCaseInterface {
 	return &UseCase{
 		genesisAssetRepo:  genesisAssetRepo,
 		assetOutpointRepo: assetOutpointRepo,
 		genesisPointRepo:  genesisPointRepo,
+		chainTxRepo:       chainTxRepo,
+		archiver:          archiver,
 	}
 }
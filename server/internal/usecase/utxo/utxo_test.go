@@ -0,0 +1,107 @@
+package utxo
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	assetoutpoint "github.com/quocky/taproot-asset/server/internal/domain/asset_outpoint"
+	utxoasset "github.com/quocky/taproot-asset/server/internal/domain/utxo_asset"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExtractFromAllUnspentOutpoints makes sure the outpoints are collected
+// until they cover the amount and that an overflowing sum is an error.
+func TestExtractFromAllUnspentOutpoints(t *testing.T) {
+	logger.Init()
+
+	ctx := context.Background()
+	archiver := proof.NewMemArchiver()
+	u := &UseCase{archiver: archiver}
+
+	newOutpoint := func(i byte, amount uint64) *assetoutpoint.UnspentOutpoint {
+		loc := proof.Locator{ScriptKey: asset.SerializedKey{i}}
+		err := archiver.ImportProofs(ctx, &proof.AnnotatedProof{
+			Locator: loc,
+			Blob:    proof.Blob{i},
+		})
+		require.NoError(t, err)
+
+		locHash, err := loc.Hash()
+		require.NoError(t, err)
+
+		outpoint := &assetoutpoint.UnspentOutpoint{}
+		outpoint.Amount = amount
+		outpoint.ProofLocator = locHash[:]
+
+		return outpoint
+	}
+
+	testCases := []struct {
+		name      string
+		amounts   []uint64
+		amount    uint64
+		numPicked int
+		sum       uint64
+		err       error
+	}{{
+		name:      "covered",
+		amounts:   []uint64{3, 4, 5},
+		amount:    6,
+		numPicked: 2,
+		sum:       7,
+	}, {
+		name:      "all",
+		amounts:   []uint64{3, 4, 5},
+		numPicked: 3,
+		sum:       12,
+	}, {
+		name:      "not enough",
+		amounts:   []uint64{3, 4},
+		amount:    10,
+		numPicked: 2,
+		sum:       7,
+	}, {
+		name:    "overflow",
+		amounts: []uint64{math.MaxUint64, 1},
+		err:     mssmt.ErrIntegerOverflow,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			outpoints := make([]*assetoutpoint.UnspentOutpoint, len(testCase.amounts))
+			for i, amount := range testCase.amounts {
+				outpoints[i] = newOutpoint(byte(i), amount)
+			}
+
+			picked, sum, err := u.extractFromAllUnspentOutpoints(ctx, outpoints, testCase.amount)
+			require.ErrorIs(t, err, testCase.err)
+
+			if testCase.err != nil {
+				return
+			}
+
+			require.Len(t, picked, testCase.numPicked)
+			require.Equal(t, testCase.sum, sum)
+
+			for i, p := range picked {
+				require.Equal(t, proof.Blob{byte(i)}, proof.Blob(p.Proof))
+			}
+		})
+	}
+}
+
+// TestGetAssetMetaInvalidAssetID makes sure malformed asset IDs are rejected
+// before the repo is queried.
+func TestGetAssetMetaInvalidAssetID(t *testing.T) {
+	u := &UseCase{}
+
+	for _, assetID := range []string{"zz", "abcd", ""} {
+		_, err := u.GetAssetMeta(context.Background(), assetID)
+		require.ErrorIs(t, err, utxoasset.ErrInvalidAssetID, assetID)
+	}
+}
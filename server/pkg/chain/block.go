@@ -0,0 +1,36 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+)
+
+// ConfirmProofFiles adds the block the anchor transaction was confirmed in to
+// the last proof of each file, verifies the now confirmed files and imports
+// them into the archive again under their unchanged locators.
+func ConfirmProofFiles(
+	ctx context.Context,
+	files []*proof.File,
+	block *wire.MsgBlock,
+	height uint32,
+	headerVerifier proof.HeaderVerifier,
+	archiver proof.Archiver,
+) error {
+	for _, file := range files {
+		if err := file.ConfirmLastProof(block, height); err != nil {
+			return err
+		}
+
+		if _, err := file.Verify(ctx, headerVerifier); err != nil {
+			return err
+		}
+
+		if _, err := proof.ImportFile(ctx, archiver, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package chain
+
+import (
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// BlockEvent is a block connected to or disconnected from the best chain.
+type BlockEvent struct {
+	Hash      chainhash.Hash
+	Height    int32
+	Connected bool
+}
+
+// BlockNotifier receives the block notifications of the node and queues
+// them for a consumer. The rpcclient calls the notification handlers from
+// the goroutine reading the websocket, which also reads the responses of
+// RPC calls, so the handlers only queue the events and never block.
+type BlockNotifier struct {
+	mu     sync.Mutex
+	events []BlockEvent
+	signal chan struct{}
+}
+
+// NewBlockNotifier creates a notifier with an empty queue.
+func NewBlockNotifier() *BlockNotifier {
+	return &BlockNotifier{
+		signal: make(chan struct{}, 1),
+	}
+}
+
+// Handlers returns the notification handlers to create the rpcclient with.
+func (n *BlockNotifier) Handlers() *rpcclient.NotificationHandlers {
+	return &rpcclient.NotificationHandlers{
+		OnFilteredBlockConnected: func(height int32, header *wire.BlockHeader, _ []*btcutil.Tx) {
+			n.push(BlockEvent{
+				Hash:      header.BlockHash(),
+				Height:    height,
+				Connected: true,
+			})
+		},
+		OnFilteredBlockDisconnected: func(height int32, header *wire.BlockHeader) {
+			n.push(BlockEvent{
+				Hash:   header.BlockHash(),
+				Height: height,
+			})
+		},
+	}
+}
+
+// Signal returns a channel which receives a value when events were queued.
+func (n *BlockNotifier) Signal() <-chan struct{} {
+	return n.signal
+}
+
+// Events removes and returns the queued events, in the order they were
+// received.
+func (n *BlockNotifier) Events() []BlockEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	events := n.events
+	n.events = nil
+
+	return events
+}
+
+func (n *BlockNotifier) push(event BlockEvent) {
+	n.mu.Lock()
+	n.events = append(n.events, event)
+	n.mu.Unlock()
+
+	select {
+	case n.signal <- struct{}{}:
+	default:
+	}
+}
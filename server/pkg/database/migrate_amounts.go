@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNegativeAmount is returned by MigrateAmounts when a stored asset amount
+// is negative and can't be converted to an unsigned amount.
+var ErrNegativeAmount = errors.New("negative asset amount in database")
+
+// amountField is an asset amount field of a collection.
+type amountField struct {
+	collection string
+	field      string
+}
+
+// amountFields are the asset amount fields that used to be stored as 32-bit
+// integers.
+var amountFields = []amountField{
+	{collection: "genesis_assets", field: "supply"},
+	{collection: "asset_outpoints", field: "amount"},
+	{collection: "asset_outpoints", field: "split_commitment_root_value"},
+}
+
+// MigrateAmounts converts the asset amounts stored as 32-bit integers to 64-bit
+// integers, so they are stored like the amounts written since amounts are
+// unsigned 64-bit integers. It returns the number of migrated documents, and is
+// a no-op once all documents are migrated.
+func MigrateAmounts(ctx context.Context, db *mongo.Database) (int64, error) {
+	var numMigrated int64
+
+	for _, f := range amountFields {
+		coll := db.Collection(f.collection)
+
+		numNegative, err := coll.CountDocuments(ctx, bson.M{
+			f.field: bson.M{"$lt": 0},
+		})
+		if err != nil {
+			return numMigrated, err
+		}
+
+		if numNegative > 0 {
+			return numMigrated, fmt.Errorf("%w: %d documents in %s.%s",
+				ErrNegativeAmount, numNegative, f.collection, f.field)
+		}
+
+		result, err := coll.UpdateMany(
+			ctx,
+			bson.M{f.field: bson.M{"$type": "int"}},
+			mongo.Pipeline{{{
+				Key: "$set",
+				Value: bson.M{
+					f.field: bson.M{"$toLong": "$" + f.field},
+				},
+			}}},
+		)
+		if err != nil {
+			return numMigrated, err
+		}
+
+		numMigrated += result.ModifiedCount
+	}
+
+	return numMigrated, nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 
 	"github.com/quocky/taproot-asset/server/config/core"
 	"github.com/quocky/taproot-asset/server/pkg/logger"
@@ -11,17 +12,26 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
+// MongoClientOptions returns the options mapping the documents of the MongoDB
+// client by the json tags of the entities, like the columns of the SQL repos.
+func MongoClientOptions() *options.ClientOptions {
+	return options.Client().
+		SetRegistry(mgocompat.Registry).
+		SetBSONOptions(&options.BSONOptions{UseJSONStructTags: true})
+}
+
 func NewMongoDatabase(cfg *config.Config) (*mongo.Database, error) {
-	ctx := context.Background()
+	if cfg.Mongo.ConnURI == "" || cfg.Mongo.DBName == "" {
+		return nil, errors.New("MONGO_CONN_URI and MONGO_DB_NAME are required")
+	}
 
-	bsonOpts := &options.BSONOptions{UseJSONStructTags: true}
+	ctx := context.Background()
 
 	client, err := mongo.Connect(
 		ctx,
-		options.Client().SetRegistry(mgocompat.Registry),
+		MongoClientOptions(),
 		options.Client().SetReadPreference(readpref.Secondary()),
 		options.Client().ApplyURI(cfg.Mongo.ConnURI),
-		options.Client().SetBSONOptions(bsonOpts),
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/quocky/taproot-asset/server/config/core"
+	"github.com/quocky/taproot-asset/server/pkg/logger"
+	_ "modernc.org/sqlite"
+)
+
+// SQLDialect is the flavor of the SQL database the server runs on.
+type SQLDialect string
+
+const (
+	SQLite   SQLDialect = "sqlite"
+	Postgres SQLDialect = "postgres"
+)
+
+// Placeholder returns the placeholder of the n-th (1-based) query argument.
+func (d SQLDialect) Placeholder(n int) string {
+	if d == Postgres {
+		return "$" + strconv.Itoa(n)
+	}
+
+	return "?"
+}
+
+// SQLDatabase is a SQL database with the dialect to build queries for.
+type SQLDatabase struct {
+	*sql.DB
+	Dialect SQLDialect
+}
+
+// sqliteDefaultPragmas are applied to every SQLite connection: foreign keys
+// are enforced, concurrent writers wait for each other instead of failing and
+// transactions take the write lock when they begin.
+var sqliteDefaultPragmas = []string{
+	"_pragma=foreign_keys(1)",
+	"_pragma=busy_timeout(5000)",
+	"_pragma=journal_mode(WAL)",
+	"_txlock=immediate",
+}
+
+func NewSQLDatabase(cfg *config.Config) (*SQLDatabase, error) {
+	var (
+		dialect    = SQLDialect(cfg.Database.Backend)
+		driverName string
+		dsn        = cfg.Database.DSN
+	)
+
+	switch dialect {
+	case SQLite:
+		driverName = "sqlite"
+
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + strings.Join(sqliteDefaultPragmas, "&")
+
+	case Postgres:
+		driverName = "pgx"
+
+	default:
+		return nil, fmt.Errorf("unknown SQL database backend %q", cfg.Database.Backend)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+
+	sqlDB := &SQLDatabase{
+		DB:      db,
+		Dialect: dialect,
+	}
+
+	if err := sqlDB.createSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	logger.Infow("Connect to SQL database successfully", "backend", dialect)
+
+	return sqlDB, nil
+}
+
+// createSchema creates the tables which don't exist yet.
+func (db *SQLDatabase) createSchema(ctx context.Context) error {
+	blobType := "BLOB"
+	if db.Dialect == Postgres {
+		blobType = "BYTEA"
+	}
+
+	for _, stmt := range sqlSchema {
+		stmt = strings.ReplaceAll(stmt, "BLOB", blobType)
+
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("create schema fail: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sqlSchema holds the tables of the server. The columns are named after the
+// json tags of the entities, like the fields of the Mongo documents. BLOB is
+// replaced by the binary type of the dialect.
+var sqlSchema = []string{
+	`CREATE TABLE IF NOT EXISTS chain_txs (
+		id TEXT PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+		tx_id BLOB NOT NULL,
+		anchor_tx BLOB NOT NULL,
+		chain_fees INTEGER NOT NULL DEFAULT 0,
+		block_height INTEGER NOT NULL DEFAULT 0,
+		block_hash BLOB,
+		status TEXT NOT NULL DEFAULT '',
+		fail_reason TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE TABLE IF NOT EXISTS genesis_points (
+		id TEXT PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+		prev_out TEXT NOT NULL,
+		anchor_tx_id TEXT REFERENCES chain_txs (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS genesis_assets (
+		id TEXT PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+		asset_id BLOB NOT NULL UNIQUE,
+		asset_name TEXT NOT NULL,
+		supply BIGINT NOT NULL,
+		asset_type SMALLINT NOT NULL,
+		output_index INTEGER NOT NULL,
+		genesis_point_id TEXT REFERENCES genesis_points (id),
+		group_key BLOB,
+		meta_hash BLOB,
+		meta_type SMALLINT NOT NULL DEFAULT 0,
+		meta_data BLOB
+	)`,
+	`CREATE TABLE IF NOT EXISTS managed_utxos (
+		id TEXT PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+		outpoint TEXT NOT NULL,
+		amt_sats INTEGER NOT NULL,
+		internal_key BLOB,
+		taproot_asset_root BLOB,
+		script_output BLOB,
+		tx_id TEXT REFERENCES chain_txs (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS asset_outpoints (
+		id TEXT PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+		genesis_id TEXT REFERENCES genesis_assets (id),
+		script_key BLOB NOT NULL,
+		amount BIGINT NOT NULL,
+		split_commitment_root_hash BLOB,
+		split_commitment_root_value BIGINT NOT NULL DEFAULT 0,
+		anchor_utxo_id TEXT REFERENCES managed_utxos (id),
+		proof_locator BLOB NOT NULL,
+		spent BOOLEAN NOT NULL DEFAULT FALSE,
+		spent_by_tx_id TEXT REFERENCES chain_txs (id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS asset_outpoints_genesis_id_idx
+		ON asset_outpoints (genesis_id)`,
+	`CREATE INDEX IF NOT EXISTS asset_outpoints_anchor_utxo_id_idx
+		ON asset_outpoints (anchor_utxo_id)`,
+	`CREATE INDEX IF NOT EXISTS asset_outpoints_spent_by_tx_id_idx
+		ON asset_outpoints (spent_by_tx_id)`,
+	`CREATE INDEX IF NOT EXISTS managed_utxos_tx_id_idx
+		ON managed_utxos (tx_id)`,
+	`CREATE INDEX IF NOT EXISTS chain_txs_status_idx
+		ON chain_txs (status)`,
+	`CREATE TABLE IF NOT EXISTS universe_leaves (
+		id TEXT PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+		asset_id BLOB NOT NULL,
+		tree_type SMALLINT NOT NULL,
+		leaf_key BLOB NOT NULL,
+		outpoint TEXT NOT NULL,
+		script_key BLOB NOT NULL,
+		amount BIGINT NOT NULL,
+		proof_locator BLOB NOT NULL,
+		UNIQUE (asset_id, tree_type, leaf_key)
+	)`,
+	`CREATE TABLE IF NOT EXISTS mssmt_nodes (
+		namespace TEXT NOT NULL,
+		node_hash BLOB NOT NULL,
+		type SMALLINT NOT NULL,
+		sum BIGINT NOT NULL,
+		left_hash BLOB,
+		right_hash BLOB,
+		key BLOB,
+		value BLOB,
+		PRIMARY KEY (namespace, node_hash)
+	)`,
+	`CREATE TABLE IF NOT EXISTS mssmt_roots (
+		namespace TEXT PRIMARY KEY,
+		node_hash BLOB NOT NULL,
+		sum BIGINT NOT NULL
+	)`,
+}
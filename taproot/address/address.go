@@ -10,6 +10,8 @@ import (
 
 type TapAddrMaker interface {
 	CreateTapAddr(asset.SerializedKey, *commitment.TapCommitment) (*TapAddress, error)
+	NewAddress(assetID asset.ID, amount uint64, scriptKey, internalKey asset.SerializedKey) (*Tap, error)
+	DecodeAddress(addr string) (*Tap, error)
 }
 
 type TapAddr struct {
@@ -29,3 +31,15 @@ func New(params *chaincfg.Params) *TapAddr {
 		NetWork: params,
 	}
 }
+
+// NewAddress creates a new Taproot Asset address on the network of the maker.
+func (tap *TapAddr) NewAddress(assetID asset.ID, amount uint64,
+	scriptKey, internalKey asset.SerializedKey) (*Tap, error) {
+
+	return NewAddress(assetID, amount, scriptKey, internalKey, tap.NetWork)
+}
+
+// DecodeAddress decodes a Taproot Asset address of the network of the maker.
+func (tap *TapAddr) DecodeAddress(addr string) (*Tap, error) {
+	return DecodeAddress(addr, tap.NetWork)
+}
@@ -0,0 +1,262 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/tlv"
+)
+
+// Version is the version of the Taproot Asset address encoding.
+type Version uint8
+
+const (
+	// V0 is the initial Taproot Asset address version.
+	V0 Version = 0
+)
+
+// The human-readable parts of Taproot Asset addresses per network.
+const (
+	MainNetHRP  = "tap"
+	TestNet3HRP = "taptb"
+	RegTestHRP  = "taprt"
+	SigNetHRP   = "taptb"
+	SimNetHRP   = "tapsb"
+)
+
+// The TLV types of the address encoding.
+const (
+	addrVersionType     tlv.Type = 0
+	addrAssetIDType     tlv.Type = 2
+	addrScriptKeyType   tlv.Type = 4
+	addrInternalKeyType tlv.Type = 6
+	addrAmountType      tlv.Type = 8
+)
+
+var (
+	// ErrUnsupportedNetwork is returned when creating an address for a
+	// network without a Taproot Asset address HRP.
+	ErrUnsupportedNetwork = errors.New("address: unsupported network")
+
+	// ErrMismatchedHRP is returned when decoding an address that belongs
+	// to another network.
+	ErrMismatchedHRP = errors.New("address: network mismatch")
+
+	// ErrInvalidChecksum is returned when decoding an address that isn't
+	// bech32m encoded.
+	ErrInvalidChecksum = errors.New("address: invalid bech32m checksum")
+
+	// ErrUnknownVersion is returned for an address version this
+	// implementation doesn't understand.
+	ErrUnknownVersion = errors.New("address: unknown version")
+
+	// ErrInvalidAmountZero is returned for an address without an amount.
+	ErrInvalidAmountZero = errors.New("address: amount must be greater " +
+		"than 0")
+)
+
+// Tap is a Taproot Asset address. It tells the sender which asset and how much
+// of it to send, and the keys of the receiver to lock the asset and its anchor
+// output to.
+type Tap struct {
+	// ChainParams are the parameters of the network of the address.
+	ChainParams *chaincfg.Params
+
+	// Version is the version of the address.
+	Version Version
+
+	// AssetID is the ID of the asset to receive.
+	AssetID asset.ID
+
+	// ScriptKey is the key the received asset is locked to.
+	ScriptKey asset.SerializedKey
+
+	// InternalKey is the internal key of the anchor output of the
+	// received asset.
+	InternalKey asset.SerializedKey
+
+	// Amount is the amount of the asset to receive.
+	Amount uint64
+}
+
+// NewAddress creates a new Taproot Asset address for the given network.
+func NewAddress(assetID asset.ID, amount uint64, scriptKey,
+	internalKey asset.SerializedKey, params *chaincfg.Params) (*Tap, error) {
+
+	if amount == 0 {
+		return nil, ErrInvalidAmountZero
+	}
+
+	if _, err := hrpForNetwork(params); err != nil {
+		return nil, err
+	}
+
+	if _, err := scriptKey.ToPubKey(); err != nil {
+		return nil, fmt.Errorf("address: invalid script key: %w", err)
+	}
+
+	if _, err := internalKey.ToPubKey(); err != nil {
+		return nil, fmt.Errorf("address: invalid internal key: %w", err)
+	}
+
+	return &Tap{
+		ChainParams: params,
+		Version:     V0,
+		AssetID:     assetID,
+		ScriptKey:   scriptKey,
+		InternalKey: internalKey,
+		Amount:      amount,
+	}, nil
+}
+
+// hrpForNetwork returns the human-readable part of addresses of the network.
+func hrpForNetwork(params *chaincfg.Params) (string, error) {
+	if params == nil {
+		return "", ErrUnsupportedNetwork
+	}
+
+	switch params.Net {
+	case chaincfg.MainNetParams.Net:
+		return MainNetHRP, nil
+
+	case chaincfg.TestNet3Params.Net:
+		return TestNet3HRP, nil
+
+	case chaincfg.RegressionNetParams.Net:
+		return RegTestHRP, nil
+
+	case chaincfg.SigNetParams.Net:
+		return SigNetHRP, nil
+
+	case chaincfg.SimNetParams.Net:
+		return SimNetHRP, nil
+
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedNetwork,
+			params.Name)
+	}
+}
+
+// Encode returns the TLV encoding of the address.
+func (a *Tap) Encode() ([]byte, error) {
+	return tlv.Encode(
+		tlv.NewRecord(addrVersionType, []byte{byte(a.Version)}),
+		tlv.NewRecord(addrAssetIDType, a.AssetID[:]),
+		tlv.NewRecord(addrScriptKeyType, a.ScriptKey[:]),
+		tlv.NewRecord(addrInternalKeyType, a.InternalKey[:]),
+		tlv.NewRecord(addrAmountType, tlv.Uint64(a.Amount)),
+	)
+}
+
+// Decode decodes the TLV encoded address within blob.
+func (a *Tap) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		addrVersionType, addrAssetIDType, addrScriptKeyType,
+		addrInternalKeyType, addrAmountType,
+	})
+	if err != nil {
+		return fmt.Errorf("address: %w", err)
+	}
+
+	versionBytes := stream[addrVersionType]
+	if len(versionBytes) != 1 || Version(versionBytes[0]) != V0 {
+		return fmt.Errorf("%w: %x", ErrUnknownVersion, versionBytes)
+	}
+	a.Version = Version(versionBytes[0])
+
+	if err := tlv.ReadFixed(a.AssetID[:], stream[addrAssetIDType]); err != nil {
+		return err
+	}
+
+	err = tlv.ReadFixed(a.ScriptKey[:], stream[addrScriptKeyType])
+	if err != nil {
+		return err
+	}
+
+	err = tlv.ReadFixed(a.InternalKey[:], stream[addrInternalKeyType])
+	if err != nil {
+		return err
+	}
+
+	a.Amount, err = tlv.ReadUint64(stream[addrAmountType])
+
+	return err
+}
+
+// EncodeAddress returns the bech32m encoding of the address.
+func (a *Tap) EncodeAddress() (string, error) {
+	hrp, err := hrpForNetwork(a.ChainParams)
+	if err != nil {
+		return "", err
+	}
+
+	addrBytes, err := a.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	converted, err := bech32.ConvertBits(addrBytes, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	return bech32.EncodeM(hrp, converted)
+}
+
+// String returns the bech32m encoding of the address, or an empty string if
+// the address can't be encoded.
+func (a *Tap) String() string {
+	addr, err := a.EncodeAddress()
+	if err != nil {
+		return ""
+	}
+
+	return addr
+}
+
+// DecodeAddress decodes a bech32m encoded address of the given network.
+func DecodeAddress(addr string, params *chaincfg.Params) (*Tap, error) {
+	expectedHRP, err := hrpForNetwork(params)
+	if err != nil {
+		return nil, err
+	}
+
+	hrp, data, err := bech32.DecodeNoLimit(addr)
+	if err != nil {
+		return nil, fmt.Errorf("address: %w", err)
+	}
+
+	if hrp != expectedHRP {
+		return nil, fmt.Errorf("%w: expected %s, got %s",
+			ErrMismatchedHRP, expectedHRP, hrp)
+	}
+
+	// The decoder accepts both checksum variants, so make sure this is a
+	// bech32m address by encoding it again.
+	reEncoded, err := bech32.EncodeM(hrp, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if reEncoded != strings.ToLower(addr) {
+		return nil, ErrInvalidChecksum
+	}
+
+	addrBytes, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return nil, fmt.Errorf("address: %w", err)
+	}
+
+	var tap Tap
+	if err := tap.Decode(addrBytes); err != nil {
+		return nil, err
+	}
+
+	return NewAddress(
+		tap.AssetID, tap.Amount, tap.ScriptKey, tap.InternalKey, params,
+	)
+}
@@ -0,0 +1,50 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddressEncoding makes sure addresses survive a bech32m round trip and are
+// bound to their network.
+func TestAddressEncoding(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	key := asset.ToSerialized(privKey.PubKey())
+	addr, err := NewAddress(
+		asset.ID{1, 2, 3}, 3_000_000_000, key, key,
+		&chaincfg.RegressionNetParams,
+	)
+	require.NoError(t, err)
+
+	encoded, err := addr.EncodeAddress()
+	require.NoError(t, err)
+	require.Regexp(t, "^"+RegTestHRP+"1", encoded)
+
+	decoded, err := DecodeAddress(encoded, &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+	require.Equal(t, addr, decoded)
+
+	_, err = DecodeAddress(encoded, &chaincfg.SimNetParams)
+	require.ErrorIs(t, err, ErrMismatchedHRP)
+
+	// The same data with a bech32 checksum isn't a valid address.
+	hrp, data, err := bech32.DecodeNoLimit(encoded)
+	require.NoError(t, err)
+	bech32Encoded, err := bech32.Encode(hrp, data)
+	require.NoError(t, err)
+
+	_, err = DecodeAddress(bech32Encoded, &chaincfg.RegressionNetParams)
+	require.ErrorIs(t, err, ErrInvalidChecksum)
+
+	_, err = NewAddress(
+		asset.ID{}, 0, key, key, &chaincfg.RegressionNetParams,
+	)
+	require.ErrorIs(t, err, ErrInvalidAmountZero)
+}
@@ -10,12 +10,12 @@ import (
 	utxoasset "github.com/quocky/taproot-asset/taproot/http_model/utxo_asset"
 )
 
-func (t *Taproot) GetAssetUTXOs(ctx context.Context, assetID string, amount int32) (*utxoasset.UnspentAssetResp, error) {
+func (t *Taproot) GetAssetUTXOs(ctx context.Context, assetID string, amount uint64) (*utxoasset.UnspentAssetResp, error) {
 	resp, err := t.httpClient.R().
 		SetContext(ctx).SetBody(map[string]any{
 		"asset_id": assetID,
 		"amount":   amount,
-		"pub_key":  t.wif.PrivKey.PubKey().SerializeCompressed(),
+		"pub_key":  t.walletKey.SerializeCompressed(),
 	}).Post(os.Getenv("SERVER_BASE_URL") + "/unspent-asset-id")
 
 	if err != nil {
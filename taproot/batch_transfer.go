@@ -0,0 +1,244 @@
+package taproot
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/quocky/taproot-asset/taproot/address"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	assetoutpointmodel "github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
+	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/quocky/taproot-asset/taproot/model/packet"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	"github.com/quocky/taproot-asset/taproot/onchain"
+)
+
+// Recipient receives an amount of an asset in a batch transfer.
+type Recipient struct {
+	AssetID asset.ID
+	Amount  uint64
+
+	// ScriptKey is the key the received asset is locked to.
+	ScriptKey asset.SerializedKey
+
+	// InternalKey is the internal key of the anchor output. Recipients
+	// sharing an internal key receive their assets in the same output.
+	InternalKey asset.SerializedKey
+}
+
+// BatchTransferToAddresses sends the assets requested by the given Taproot
+// Asset addresses in a single anchor transaction. The addresses may request
+// different assets.
+func (t *Taproot) BatchTransferToAddresses(ctx context.Context, addrs []*address.Tap,
+	feeRate onchain.SatPerKVByte) error {
+
+	return t.BatchTransfer(ctx, addrRecipients(addrs), feeRate)
+}
+
+// addrRecipients returns the recipients of the given Taproot Asset addresses.
+func addrRecipients(addrs []*address.Tap) []*Recipient {
+	recipients := make([]*Recipient, len(addrs))
+	for idx, addr := range addrs {
+		recipients[idx] = &Recipient{
+			AssetID:     addr.AssetID,
+			Amount:      addr.Amount,
+			ScriptKey:   addr.ScriptKey,
+			InternalKey: addr.InternalKey,
+		}
+	}
+
+	return recipients
+}
+
+// BatchTransfer sends several assets to several recipients in a single anchor
+// transaction. Each asset is split on its own, with the change of all assets
+// returned in the first output and each group of recipients sharing an
+// internal key paid in one of the following outputs. Other assets anchored
+// alongside the spent UTXOs are re-anchored in the first output too. A zero fee
+// rate is estimated.
+func (t *Taproot) BatchTransfer(ctx context.Context, recipients []*Recipient,
+	feeRate onchain.SatPerKVByte) error {
+
+	feeRate, err := t.resolveFeeRate(feeRate)
+	if err != nil {
+		return err
+	}
+
+	vPacket, spentOutpoints, err := t.batchPacket(ctx, recipients)
+	if err != nil {
+		return err
+	}
+
+	return t.anchorTransfer(ctx, vPacket, spentOutpoints, feeRate)
+}
+
+// batchPacket returns the unsigned virtual packet of a batch transfer to the
+// recipients, see BatchTransfer, and the asset outpoints it spends.
+func (t *Taproot) batchPacket(ctx context.Context, recipients []*Recipient) (
+	*packet.VPacket, []*assetoutpointmodel.UnspentOutpoint, error) {
+
+	if len(recipients) == 0 {
+		return nil, nil, errors.New("no recipients to transfer to")
+	}
+
+	var (
+		assetIDs        = make([]asset.ID, 0, len(recipients))
+		assetRecipients = make(map[asset.ID][]*Recipient, len(recipients))
+		outputIndexes   = make(map[asset.SerializedKey]uint32, len(recipients))
+		internalKeys    = make([]asset.SerializedKey, 0, len(recipients))
+	)
+
+	for _, r := range recipients {
+		if _, ok := assetRecipients[r.AssetID]; !ok {
+			assetIDs = append(assetIDs, r.AssetID)
+		}
+		assetRecipients[r.AssetID] = append(assetRecipients[r.AssetID], r)
+
+		if _, ok := outputIndexes[r.InternalKey]; !ok {
+			outputIndexes[r.InternalKey] = uint32(DEFAULT_TRANSFER_OUTPUT_INDEX + len(internalKeys))
+			internalKeys = append(internalKeys, r.InternalKey)
+		}
+	}
+
+	var (
+		headerVerifier   = proof.NewRPCHeaderVerifier(t.btcClient)
+		unspentOutpoints = make([]*assetoutpointmodel.UnspentOutpoint, 0)
+		vPacket          = &packet.VPacket{
+			Outputs: make([]*packet.VOutput, DEFAULT_TRANSFER_OUTPUT_INDEX+len(internalKeys)),
+		}
+	)
+
+	for idx := range vPacket.Outputs {
+		internalKey := asset.ToSerialized(t.walletKey)
+		if idx != DEFAULT_RETURN_OUTPUT_INDEX {
+			internalKey = internalKeys[idx-DEFAULT_TRANSFER_OUTPUT_INDEX]
+		}
+
+		vPacket.Outputs[idx] = &packet.VOutput{InternalKey: internalKey}
+	}
+
+	for _, assetID := range assetIDs {
+		assetOutpoints, err := t.splitForRecipients(
+			ctx, headerVerifier, vPacket, assetRecipients[assetID], outputIndexes,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("split asset %x: %w", assetID[:], err)
+		}
+
+		unspentOutpoints = append(unspentOutpoints, assetOutpoints...)
+	}
+
+	activeIDs := make(map[asset.ID]struct{}, len(assetIDs))
+	for _, assetID := range assetIDs {
+		activeIDs[assetID] = struct{}{}
+	}
+
+	passive, err := t.collectPassiveAssets(ctx, unspentOutpoints, activeIDs, headerVerifier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	returnOutput := vPacket.Outputs[DEFAULT_RETURN_OUTPUT_INDEX]
+	returnOutput.Assets = append(returnOutput.Assets, passive.assets...)
+	vPacket.Inputs = append(vPacket.Inputs, passive.inputs...)
+
+	log.Println("[Batch Transfer] Transfer", len(assetIDs), "assets to", len(internalKeys), "outputs")
+
+	return vPacket, passive.spentWith(unspentOutpoints), nil
+}
+
+// splitForRecipients splits the UTXOs of the wallet holding the asset of the
+// recipients between them, each paid in the output of its internal key, and
+// adds the split to the virtual packet. The change is returned to the wallet in
+// the root asset of the split, which still has to be signed. The spent asset
+// outpoints are returned.
+func (t *Taproot) splitForRecipients(
+	ctx context.Context,
+	headerVerifier proof.HeaderVerifier,
+	vPacket *packet.VPacket,
+	recipients []*Recipient,
+	outputIndexes map[asset.SerializedKey]uint32,
+) ([]*assetoutpointmodel.UnspentOutpoint, error) {
+	amounts := make([]uint64, len(recipients))
+	for idx, r := range recipients {
+		amounts[idx] = r.Amount
+	}
+
+	transferAmount, err := sumAmounts(amounts)
+	if err != nil {
+		return nil, err
+	}
+
+	assetID := recipients[0].AssetID
+	assetUTXOs, err := t.GetAssetUTXOs(ctx, hex.EncodeToString(assetID[:]), transferAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, err := creatSplitCommitmentInputs(ctx, assetUTXOs, headerVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	inputAmounts := make([]uint64, len(inputs))
+	for idx, input := range inputs {
+		inputAmounts[idx] = input.Asset.Amount
+	}
+
+	inputAmount, err := sumAmounts(inputAmounts)
+	if err != nil {
+		return nil, err
+	}
+
+	if inputAmount < transferAmount {
+		return nil, fmt.Errorf("not enough amount, have %d, need %d", inputAmount, transferAmount)
+	}
+
+	var (
+		assetGenesis = inputs[0].Asset.Genesis
+		change       = inputAmount - transferAmount
+		rootAsset    = asset.NewAsset(assetGenesis, change, t.returnScriptKey(change), nil)
+		rootLocator  = commitment.NewLocatorByAsset(DEFAULT_RETURN_OUTPUT_INDEX, rootAsset)
+		locators     = make([]*commitment.SplitLocator, len(recipients))
+		seen         = make(map[commitment.SplitLocator]struct{}, len(recipients))
+	)
+
+	for idx, r := range recipients {
+		locators[idx] = commitment.NewLocatorByAsset(
+			outputIndexes[r.InternalKey],
+			asset.NewAsset(assetGenesis, r.Amount, r.ScriptKey, nil),
+		)
+
+		// Two recipients of the same asset and script key can't share an
+		// output, their assets would take the same leaf.
+		key := *locators[idx]
+		key.Amount = 0
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("recipient %x receives the asset twice in output %d",
+				r.ScriptKey[:], key.OutputIndex)
+		}
+		seen[key] = struct{}{}
+	}
+
+	splitCommitment, err := commitment.NewSplitCommitment(ctx, inputs, rootLocator, locators...)
+	if err != nil {
+		return nil, err
+	}
+
+	returnOutput := vPacket.Outputs[DEFAULT_RETURN_OUTPUT_INDEX]
+	returnOutput.Assets = append(returnOutput.Assets, splitCommitment.RootAsset)
+
+	for _, locator := range locators {
+		splitAsset := splitCommitment.SplitAssets[*locator]
+
+		output := vPacket.Outputs[splitAsset.OutputIndex]
+		output.Assets = append(output.Assets, &splitAsset.Asset)
+	}
+
+	vPacket.Inputs = append(vPacket.Inputs, newVInputs(inputs, assetUTXOs.InputFilesBytes)...)
+
+	return assetUTXOs.UnspentOutpoints, nil
+}
@@ -44,7 +44,7 @@ func TestMarshalAssetCommitment(t *testing.T) {
 
 	outpoint := wire.OutPoint{Hash: chainhash.Hash{}, Index: 1}
 
-	mintAssets := asset2.NewGenesis(outpoint, "name", 0)
+	mintAssets := asset2.NewGenesis(outpoint, "name", 0, asset2.Normal)
 
 	asset := asset2.NewAsset(mintAssets, 30, a, nil)
 
@@ -0,0 +1,13 @@
+package chaintx
+
+// StatusResp is the state of an anchor transaction as tracked by the server.
+// Status is one of "pending", "broadcast", "confirmed" or "failed", the
+// block fields are only set for a confirmed transaction.
+type StatusResp struct {
+	TxHash           string `json:"tx_hash"`
+	Status           string `json:"status"`
+	BlockHeight      int32  `json:"block_height,omitempty"`
+	BlockHash        string `json:"block_hash,omitempty"`
+	NumConfirmations int32  `json:"num_confirmations"`
+	FailReason       string `json:"fail_reason,omitempty"`
+}
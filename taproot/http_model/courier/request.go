@@ -0,0 +1,79 @@
+package courier
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+)
+
+// Locator is the hex encoded form of a proof.Locator. It is sent as JSON when
+// delivering a proof and as query parameters when receiving one.
+type Locator struct {
+	AssetID   string `json:"asset_id,omitempty" form:"asset_id"`
+	ScriptKey string `json:"script_key" form:"script_key" binding:"required"`
+	OutPoint  string `json:"outpoint,omitempty" form:"outpoint"`
+}
+
+type DeliverProofReq struct {
+	Locator Locator     `json:"locator"`
+	File    *proof.File `json:"file"`
+}
+
+// NewLocator encodes the proof locator.
+func NewLocator(loc proof.Locator) Locator {
+	l := Locator{
+		ScriptKey: hex.EncodeToString(loc.ScriptKey[:]),
+	}
+
+	if loc.AssetID != nil {
+		l.AssetID = hex.EncodeToString(loc.AssetID[:])
+	}
+
+	if loc.OutPoint != nil {
+		l.OutPoint = loc.OutPoint.String()
+	}
+
+	return l
+}
+
+// ProofLocator decodes the proof locator.
+func (l Locator) ProofLocator() (proof.Locator, error) {
+	var loc proof.Locator
+
+	scriptKey, err := hex.DecodeString(l.ScriptKey)
+	if err != nil {
+		return loc, fmt.Errorf("invalid script key: %w", err)
+	}
+
+	if len(scriptKey) != len(loc.ScriptKey) {
+		return loc, fmt.Errorf("invalid script key length %d",
+			len(scriptKey))
+	}
+	copy(loc.ScriptKey[:], scriptKey)
+
+	if l.AssetID != "" {
+		assetID, err := hex.DecodeString(l.AssetID)
+		if err != nil {
+			return loc, fmt.Errorf("invalid asset id: %w", err)
+		}
+
+		if len(assetID) != len(asset.ID{}) {
+			return loc, fmt.Errorf("invalid asset id length %d",
+				len(assetID))
+		}
+
+		loc.AssetID = (*asset.ID)(assetID)
+	}
+
+	if l.OutPoint != "" {
+		loc.OutPoint, err = wire.NewOutPointFromString(l.OutPoint)
+		if err != nil {
+			return loc, fmt.Errorf("invalid outpoint: %w", err)
+		}
+	}
+
+	return loc, nil
+}
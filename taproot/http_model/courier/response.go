@@ -0,0 +1,7 @@
+package courier
+
+import "github.com/quocky/taproot-asset/taproot/model/proof"
+
+type ReceiveProofResp struct {
+	File *proof.File `json:"file"`
+}
@@ -7,5 +7,5 @@ type MintAssetsResp []MintAssetResp
 type MintAssetResp struct {
 	ID     asset.ID `json:"asset_id"`
 	Name   string   `json:"asset_name"`
-	Amount int32    `json:"amount"`
+	Amount uint64   `json:"amount"`
 }
@@ -2,14 +2,15 @@ package transfer
 
 import (
 	"github.com/btcsuite/btcd/wire"
-	"github.com/quocky/taproot-asset/taproot/model/asset"
 	"github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
 	"github.com/quocky/taproot-asset/taproot/model/proof"
 	"github.com/quocky/taproot-asset/taproot/onchain"
 )
 
+// TransferReq is a transfer anchored in AnchorTx. Files holds a proof file for
+// each new asset outpoint, which tells the asset and the output it is anchored
+// in, so a transfer may move several assets.
 type TransferReq struct {
-	GenesisAsset     *asset.GenesisAsset                   `json:"genesis_asset"`
 	AnchorTx         *wire.MsgTx                           `json:"anchor_tx"`
 	AmtSats          int32                                 `json:"amt_sats"`
 	BtcOutputInfos   []*onchain.BtcOutputInfo              `json:"btc_output_infos"`
@@ -0,0 +1,147 @@
+package universe
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/quocky/taproot-asset/taproot/universe"
+)
+
+// Node is the hex encoded hash and the sum of a MS-SMT node.
+type Node struct {
+	Hash string `json:"hash"`
+	Sum  uint64 `json:"sum"`
+}
+
+type RootsResp struct {
+	AssetID  string `json:"asset_id"`
+	Issuance Node   `json:"issuance"`
+	Transfer Node   `json:"transfer"`
+}
+
+// LeafKey is the hex encoded form of a universe.LeafKey.
+type LeafKey struct {
+	OutPoint  string `json:"outpoint" form:"outpoint" binding:"required"`
+	ScriptKey string `json:"script_key" form:"script_key" binding:"required"`
+}
+
+// LeafKeyResp is the key of a leaf of a universe tree with the hex encoded
+// hash of its MS-SMT leaf node, so leaves with the same key but a different
+// value can be told apart without fetching them.
+type LeafKeyResp struct {
+	LeafKey
+	LeafHash string `json:"leaf_hash"`
+}
+
+type LeafKeysResp []LeafKeyResp
+
+type LeafResp struct {
+	Key       LeafKey `json:"key"`
+	AssetID   string  `json:"asset_id"`
+	Amount    uint64  `json:"amount"`
+	ProofFile []byte  `json:"proof_file"`
+}
+
+type InclusionProofResp struct {
+	Leaf        LeafResp `json:"leaf"`
+	MerkleProof []byte   `json:"merkle_proof"`
+	Root        Node     `json:"root"`
+}
+
+// NewNode encodes the MS-SMT node.
+func NewNode(node mssmt.Node) Node {
+	nodeHash := node.NodeHash()
+
+	return Node{
+		Hash: hex.EncodeToString(nodeHash[:]),
+		Sum:  node.NodeSum(),
+	}
+}
+
+// SMTNode decodes the MS-SMT node.
+func (n Node) SMTNode() (mssmt.Node, error) {
+	var nodeHash mssmt.NodeHash
+
+	hashBytes, err := hex.DecodeString(n.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hashBytes) != len(nodeHash) {
+		return nil, fmt.Errorf("invalid node hash length %d",
+			len(hashBytes))
+	}
+	copy(nodeHash[:], hashBytes)
+
+	return mssmt.NewComputedNode(nodeHash, n.Sum), nil
+}
+
+// NewLeafKey encodes the universe leaf key.
+func NewLeafKey(key universe.LeafKey) LeafKey {
+	return LeafKey{
+		OutPoint:  key.OutPoint.String(),
+		ScriptKey: hex.EncodeToString(key.ScriptKey[:]),
+	}
+}
+
+// UniverseLeafKey decodes the universe leaf key.
+func (k LeafKey) UniverseLeafKey() (universe.LeafKey, error) {
+	var key universe.LeafKey
+
+	outPoint, err := wire.NewOutPointFromString(k.OutPoint)
+	if err != nil {
+		return key, fmt.Errorf("invalid outpoint: %w", err)
+	}
+	key.OutPoint = *outPoint
+
+	scriptKey, err := hex.DecodeString(k.ScriptKey)
+	if err != nil {
+		return key, fmt.Errorf("invalid script key: %w", err)
+	}
+
+	if len(scriptKey) != len(key.ScriptKey) {
+		return key, fmt.Errorf("invalid script key length %d",
+			len(scriptKey))
+	}
+	copy(key.ScriptKey[:], scriptKey)
+
+	return key, nil
+}
+
+// NewLeafResp encodes the universe leaf.
+func NewLeafResp(leaf *universe.Leaf) LeafResp {
+	return LeafResp{
+		Key:       NewLeafKey(leaf.Key),
+		AssetID:   hex.EncodeToString(leaf.AssetID[:]),
+		Amount:    leaf.Amount,
+		ProofFile: leaf.ProofFile,
+	}
+}
+
+// UniverseLeaf decodes the universe leaf.
+func (l LeafResp) UniverseLeaf() (*universe.Leaf, error) {
+	key, err := l.Key.UniverseLeafKey()
+	if err != nil {
+		return nil, err
+	}
+
+	assetID, err := hex.DecodeString(l.AssetID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset id: %w", err)
+	}
+
+	if len(assetID) != len(asset.ID{}) {
+		return nil, fmt.Errorf("invalid asset id length %d",
+			len(assetID))
+	}
+
+	return &universe.Leaf{
+		Key:       key,
+		AssetID:   asset.ID(assetID),
+		Amount:    l.Amount,
+		ProofFile: l.ProofFile,
+	}, nil
+}
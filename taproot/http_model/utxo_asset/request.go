@@ -1,8 +1,10 @@
 package utxoasset
 
+import "github.com/quocky/taproot-asset/taproot/model/asset"
+
 type UnspentAssetReq struct {
 	AssetID string `json:"asset_id"`
-	Amount  int32  `json:"amount"`
+	Amount  uint64 `json:"amount"`
 	PubKey  []byte `json:"pub_key"`
 }
 
@@ -11,9 +13,16 @@ type ListAssetReq struct {
 }
 
 type ListAssetResp struct {
-	Amount  int32  `json:"amount"`
+	Amount  uint64 `json:"amount"`
 	Name    string `json:"name"`
 	AssetID string `json:"asset_id"`
+
+	// AssetType is the type of the asset, see asset.Type.
+	AssetType asset.Type `json:"asset_type"`
+
+	// GroupKey is the group key of the asset, if it was issued into a
+	// group.
+	GroupKey []byte `json:"group_key,omitempty"`
 }
 
 type ListAssetsResp []*ListAssetResp
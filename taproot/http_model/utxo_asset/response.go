@@ -5,6 +5,14 @@ import (
 	assetoutpointmodel "github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
 )
 
+// AssetMetaResp is the metadata of an asset as revealed in its genesis proof.
+type AssetMetaResp struct {
+	AssetID  string         `json:"asset_id"`
+	MetaHash string         `json:"meta_hash"`
+	MetaType asset.MetaType `json:"meta_type"`
+	Data     []byte         `json:"data"`
+}
+
 type UnspentAssetResp struct {
 	GenesisAsset     asset.GenesisAsset
 	UnspentOutpoints []*assetoutpointmodel.UnspentOutpoint
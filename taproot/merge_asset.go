@@ -0,0 +1,76 @@
+package taproot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/quocky/taproot-asset/taproot/model/packet"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	"github.com/quocky/taproot-asset/taproot/onchain"
+)
+
+// MergeAssets consolidates all UTXOs of the asset held by the wallet into a
+// single output locked to the wallet key. The merged asset spends every input
+// with its own witness, so its proof is verified back to each input proof.
+// Other assets anchored alongside the UTXOs are re-anchored in the same output.
+// A zero fee rate is estimated.
+func (t *Taproot) MergeAssets(ctx context.Context, assetID string,
+	feeRate onchain.SatPerKVByte) error {
+
+	feeRate, err := t.resolveFeeRate(feeRate)
+	if err != nil {
+		return err
+	}
+
+	// Without an amount the server returns all UTXOs of the asset.
+	assetUTXOs, err := t.GetAssetUTXOs(ctx, assetID, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(assetUTXOs.UnspentOutpoints) < 2 {
+		return errors.New("nothing to merge, the asset is held in less than two UTXOs")
+	}
+
+	headerVerifier := proof.NewRPCHeaderVerifier(t.btcClient)
+
+	inputs, err := creatSplitCommitmentInputs(ctx, assetUTXOs, headerVerifier)
+	if err != nil {
+		return err
+	}
+
+	walletKey := asset.ToSerialized(t.walletKey)
+
+	merge, err := commitment.NewMerge(inputs, walletKey)
+	if err != nil {
+		return err
+	}
+
+	activeIDs := map[asset.ID]struct{}{merge.Asset.ID(): {}}
+
+	passive, err := t.collectPassiveAssets(ctx, assetUTXOs.UnspentOutpoints, activeIDs, headerVerifier)
+	if err != nil {
+		return err
+	}
+
+	vPacket := &packet.VPacket{
+		Inputs: append(newVInputs(inputs, assetUTXOs.InputFilesBytes), passive.inputs...),
+		Outputs: []*packet.VOutput{{
+			InternalKey: walletKey,
+			Assets:      append([]*asset.Asset{merge.Asset}, passive.assets...),
+		}},
+	}
+
+	log.Println("[Merge Asset] Merge", len(inputs), "inputs into", merge.Asset.Amount)
+
+	err = t.anchorTransfer(ctx, vPacket, passive.spentWith(assetUTXOs.UnspentOutpoints), feeRate)
+	if err != nil {
+		return fmt.Errorf("anchor merge: %w", err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package taproot
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -9,7 +10,7 @@ import (
 	"log"
 	"os"
 
-	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/quocky/taproot-asset/taproot/http_model/mint"
 	"github.com/quocky/taproot-asset/taproot/model/asset"
@@ -18,37 +19,50 @@ import (
 	"github.com/quocky/taproot-asset/taproot/onchain"
 )
 
-func (t *Taproot) MintAsset(ctx context.Context, assetNames []string, assetAmounts []int32) error {
-	err := preCheckAssets(assetNames, assetAmounts)
+func (t *Taproot) MintAsset(ctx context.Context, assetNames []string, assetAmounts []uint64, assetType asset.Type, meta *asset.MetaReveal, grouped bool, feeRate onchain.SatPerKVByte) error {
+	err := preCheckAssets(assetNames, assetAmounts, assetType, meta)
 	if err != nil {
 		return err
 	}
 
 	t.logger.Info("[Mint Asset] Precheck assets success!")
 
-	var (
-		expectBtcAmount = int32(DEFAULT_OUTPUT_AMOUNT + DEFAULT_FEE)
-		userPubKey      = asset.ToSerialized(t.wif.PrivKey.PubKey())
-	)
+	userPubKey := asset.ToSerialized(t.walletKey)
 
-	btcUTXOs, err := t.btcClient.ListUTXOs()
+	feeRate, err = t.resolveFeeRate(feeRate)
 	if err != nil {
 		return err
 	}
 
-	if len(btcUTXOs) == 0 {
-		return errors.New("utxos is empty")
+	// The assets commit to the first input of the minting transaction, so
+	// the UTXOs are selected before the anchor output is known. Any taproot
+	// output has the same size.
+	anchorScript, err := txscript.PayToTaprootScript(t.walletKey)
+	if err != nil {
+		return err
 	}
 
-	bestUTXOs, err := chooseBestUTXOs(btcUTXOs, expectBtcAmount)
+	bestUTXOs, err := t.selectUTXOs(nil, []*wire.TxOut{
+		wire.NewTxOut(int64(anchorOutputValue), anchorScript),
+	}, feeRate)
 	if err != nil {
 		return err
 	}
 
+	if len(bestUTXOs) == 0 {
+		return errors.New("utxos is empty")
+	}
+
 	t.logger.Debug("[Mint Asset] Choose best utxos success!")
 
 	firstPrevOut := bestUTXOs[0].Outpoint
-	mintAssets := genAssets(assetNames, assetAmounts, firstPrevOut, userPubKey)
+	mintAssets := genAssets(assetNames, assetAmounts, assetType, meta, firstPrevOut, userPubKey)
+
+	if grouped {
+		if err := t.addGroupKeys(ctx, mintAssets); err != nil {
+			return err
+		}
+	}
 
 	t.logger.Debug("[Mint Asset] Generate assets success!", zap.Reflect("mint-assets", mintAssets))
 
@@ -74,11 +88,11 @@ func (t *Taproot) MintAsset(ctx context.Context, assetNames []string, assetAmoun
 	t.logger.Info("[Mint Asset] Generate tap address success!")
 
 	btcOutputInfos := []*onchain.BtcOutputInfo{
-		onchain.NewBtcOutputInfo(mintTapAddress, DEFAULT_OUTPUT_AMOUNT, mintAssets...),
+		onchain.NewBtcOutputInfo(mintTapAddress, anchorOutputValue, mintAssets...),
 	}
 
-	txIncludeOutPubKey, err := t.createTxOnChain(bestUTXOs, nil,
-		btcOutputInfos, btcutil.Amount(DEFAULT_FEE), true)
+	txIncludeOutPubKey, err := t.createTxOnChain(ctx, bestUTXOs, nil,
+		btcOutputInfos, feeRate, true)
 	if err != nil {
 		return err
 	}
@@ -89,12 +103,16 @@ func (t *Taproot) MintAsset(ctx context.Context, assetNames []string, assetAmoun
 		txIncludeOutPubKey,
 		DEFAULT_MINTING_OUTPUT_INDEX,
 		tapCommitment,
+		metaReveals(mintAssets, meta),
 	)
+	if err != nil {
+		return err
+	}
 
 	t.logger.Info("[Mint Asset] Create mint proof success!")
 
 	data := mint.MintAssetReq{
-		AmountSats:        DEFAULT_OUTPUT_AMOUNT,
+		AmountSats:        anchorOutputValue,
 		TapScriptRootHash: mintTapAddress.TapScriptRootHash,
 		MintProof:         mintProof,
 	}
@@ -120,29 +138,17 @@ func (t *Taproot) MintAsset(ctx context.Context, assetNames []string, assetAmoun
 	return nil
 }
 
-func chooseBestUTXOs(utxos []*onchain.UnspentTXOut, expectAmount int32) ([]*onchain.UnspentTXOut, error) {
-	var (
-		bestUTXOs                      = make([]*onchain.UnspentTXOut, 0)
-		expectSatAmount                = btcutil.Amount(expectAmount)
-		totalAmount     btcutil.Amount = 0
-	)
-	for _, utxo := range utxos {
-		bestUTXOs = append(bestUTXOs, utxo)
-		totalAmount += utxo.Amount
-
-		if totalAmount >= expectSatAmount {
-			return bestUTXOs, nil
-		}
-	}
-
-	return nil, errors.New("not enough utxos")
-}
-
-func preCheckAssets(assetNames []string, assetAmounts []int32) error {
+func preCheckAssets(assetNames []string, assetAmounts []uint64, assetType asset.Type, meta *asset.MetaReveal) error {
 	if len(assetNames) != len(assetAmounts) {
 		return errors.New("len assetNames and amount is different")
 	}
 
+	if meta != nil {
+		if err := meta.Validate(); err != nil {
+			return err
+		}
+	}
+
 	for idx, assetName := range assetNames {
 		assetAmount := assetAmounts[idx]
 
@@ -153,12 +159,16 @@ func preCheckAssets(assetNames []string, assetAmounts []int32) error {
 		if assetAmount == 0 {
 			return fmt.Errorf("expected assetAmount greater than 0 (but assetAmount = %d)", len(assetName))
 		}
+
+		if assetType == asset.Collectible && assetAmount != 1 {
+			return fmt.Errorf("expected assetAmount of collectible equal 1 (but assetAmount = %d)", assetAmount)
+		}
 	}
 
 	return nil
 }
 
-func genAssets(assetNames []string, assetAmounts []int32, prevOut *wire.OutPoint, userPubKey asset.SerializedKey) []*asset.Asset {
+func genAssets(assetNames []string, assetAmounts []uint64, assetType asset.Type, meta *asset.MetaReveal, prevOut *wire.OutPoint, userPubKey asset.SerializedKey) []*asset.Asset {
 
 	var assets = make([]*asset.Asset, len(assetNames))
 
@@ -167,23 +177,72 @@ func genAssets(assetNames []string, assetAmounts []int32, prevOut *wire.OutPoint
 
 		log.Printf("[Mint Asset] mintint asset! assetName : %v, assetAmount : %v ! \n", assetName, assetAmount)
 
-		genesis := asset.NewGenesis(*prevOut, assetName, DEFAULT_MINTING_OUTPUT_INDEX)
+		genesis := asset.NewGenesis(*prevOut, assetName, DEFAULT_MINTING_OUTPUT_INDEX, assetType)
+		if meta != nil {
+			genesis.MetaHash = meta.MetaHash()
+		}
+
 		assets[idx] = asset.NewAsset(genesis, assetAmount, userPubKey, nil)
 	}
 
 	return assets
 }
 
+// metaReveals returns the metadata of the given minted assets keyed by asset ID,
+// or nil if the assets are minted without metadata.
+func metaReveals(assets []*asset.Asset, meta *asset.MetaReveal) map[asset.ID]*asset.MetaReveal {
+	if meta == nil {
+		return nil
+	}
+
+	reveals := make(map[asset.ID]*asset.MetaReveal, len(assets))
+	for _, a := range assets {
+		reveals[a.ID()] = meta
+	}
+
+	return reveals
+}
+
+// addGroupKeys issues the given assets into the group of their name. The group
+// key of a name is derived from the wallet key, so minting the same name again
+// adds to the supply of the same asset group.
+func (t *Taproot) addGroupKeys(ctx context.Context, assets []*asset.Asset) error {
+	for _, a := range assets {
+		groupSigner, err := t.bindSigner(ctx, groupKeyTweak(a.Name))
+		if err != nil {
+			return err
+		}
+
+		groupKey, err := asset.NewGroupKey(groupSigner, a.Genesis)
+		if err != nil {
+			return err
+		}
+
+		a.GroupKey = groupKey
+	}
+
+	return nil
+}
+
+// groupKeyTweak returns the tweak deriving the key of the asset group with the
+// given name from the wallet key, which is the hash of the name.
+func groupKeyTweak(name string) []byte {
+	nameHash := sha256.Sum256([]byte(name))
+
+	return nameHash[:]
+}
+
 func genAssetCommitments(ctx context.Context, assets []*asset.Asset) ([]*commitment.AssetCommitment, error) {
-	var assetCommitments = make([]*commitment.AssetCommitment, len(assets))
+	var assetCommitments = make([]*commitment.AssetCommitment, 0, len(assets))
 
-	for idx, a := range assets {
-		assetCommitment, err := commitment.NewAssetCommitment(ctx, a)
+	// Assets of the same group share an asset commitment.
+	for _, groupAssets := range classifyAsset(assets) {
+		assetCommitment, err := commitment.NewAssetCommitment(ctx, groupAssets...)
 		if err != nil {
 			return nil, err
 		}
 
-		assetCommitments[idx] = assetCommitment
+		assetCommitments = append(assetCommitments, assetCommitment)
 	}
 
 	return assetCommitments, nil
@@ -193,6 +252,7 @@ func (t *Taproot) createMintProof(
 	txIncludeOutPubKey *onchain.TxIncludeOutPubKey,
 	outputIndex int32,
 	tapCommitment *commitment.TapCommitment,
+	metaReveals map[asset.ID]*asset.MetaReveal,
 ) (proof.AssetProofs, error) {
 
 	if len(txIncludeOutPubKey.Tx.TxIn) == 0 {
@@ -207,6 +267,7 @@ func (t *Taproot) createMintProof(
 			TapCommitment: tapCommitment,
 		},
 		GenesisPoint: txIncludeOutPubKey.Tx.TxIn[0].PreviousOutPoint,
+		MetaReveals:  metaReveals,
 	}
 
 	t.logger.Debug("base proof: ", zap.Reflect("base proof", baseProof))
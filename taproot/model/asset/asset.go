@@ -2,7 +2,6 @@ package asset
 
 import (
 	"crypto/sha256"
-	"encoding/json"
 	"reflect"
 
 	"github.com/btcsuite/btcd/wire"
@@ -13,21 +12,42 @@ var (
 	ZeroPrevID PrevID
 )
 
+// Version is the version of the asset encoding. The version is committed to
+// in the asset leaf, so a new version is required for any change of the leaf
+// encoding.
+type Version uint8
+
+const (
+	// V0 is the initial asset version.
+	V0 Version = 0
+
+	// V1 adds the type and the meta hash to the genesis, for collectibles
+	// and assets with metadata. Normal assets without metadata stay V0, so
+	// their IDs and leaves don't change.
+	V1 Version = 1
+)
+
 type Asset struct {
+	Version Version
 	Genesis
-	Amount              int32
+	Amount              uint64
 	ScriptPubkey        SerializedKey
 	SplitCommitmentRoot *mssmt.ComputedNode
 	PrevWitnesses       []Witness
+
+	// GroupKey is the optional key of the group the asset was issued
+	// into.
+	GroupKey *GroupKey
 }
 
 func NewAsset(
-	genesis Genesis, amount int32,
+	genesis Genesis, amount uint64,
 	scriptPubkey SerializedKey,
 	splitCommitmentRoot *mssmt.ComputedNode,
 ) *Asset {
 
 	return &Asset{
+		Version:             genesis.MinVersion(),
 		Genesis:             genesis,
 		Amount:              amount,
 		ScriptPubkey:        scriptPubkey,
@@ -52,17 +72,22 @@ func (a *Asset) AssetCommitmentKey() [32]byte {
 	return [32]byte(h.Sum(nil))
 }
 
+// Leaf returns the MS-SMT leaf of the asset. The leaf value is the canonical
+// binary encoding of the asset, so the commitment root only changes when the
+// committed data or the asset version changes.
 func (a *Asset) Leaf() (*mssmt.LeafNode, error) {
-	assetBytes, err := json.Marshal(a)
+	assetBytes, err := a.Encode()
 	if err != nil {
 		return nil, err
 	}
 
-	return mssmt.NewLeafNode(assetBytes, uint64(a.Amount)), nil
+	return mssmt.NewLeafNode(assetBytes, a.Amount), nil
 }
 
+// TapCommitmentKey returns the key of the asset commitment the asset is
+// committed to, see TapCommitmentKey.
 func (a *Asset) TapCommitmentKey() [32]byte {
-	return a.ID()
+	return TapCommitmentKey(a.ID(), a.GroupKey)
 }
 
 func (a *Asset) Copy() *Asset {
@@ -82,6 +107,17 @@ func (a *Asset) Copy() *Asset {
 				}
 			}
 
+			if len(witness.TxWitness) > 0 {
+				witnessCopy.TxWitness = make(
+					wire.TxWitness, len(witness.TxWitness),
+				)
+				for i := range witness.TxWitness {
+					witnessCopy.TxWitness[i] = append(
+						[]byte(nil), witness.TxWitness[i]...,
+					)
+				}
+			}
+
 			if witness.SplitCommitment != nil {
 				witnessCopy.SplitCommitment = &SplitCommitment{
 					Proof:     *witness.SplitCommitment.Proof.Copy(),
@@ -98,6 +134,11 @@ func (a *Asset) Copy() *Asset {
 			a.SplitCommitmentRoot.NodeSum(),
 		)
 	}
+
+	if a.GroupKey != nil {
+		assetCopy.GroupKey = a.GroupKey.Copy()
+	}
+
 	return &assetCopy
 }
 
@@ -125,7 +166,9 @@ func (a *Asset) HasGenesisWitness() bool {
 	}
 
 	witness := a.PrevWitnesses[0]
-	if witness.PrevID == nil || witness.SplitCommitment != nil {
+	if witness.PrevID == nil || len(witness.TxWitness) != 0 ||
+		witness.SplitCommitment != nil {
+
 		return false
 	}
 
@@ -135,6 +178,10 @@ func (a *Asset) HasGenesisWitness() bool {
 // DeepEqual returns true if this asset is equal with the given asset.
 func (a *Asset) DeepEqual(o *Asset) bool {
 
+	if a.Version != o.Version {
+		return false
+	}
+
 	// The ID commits to everything in the Genesis, including the type.
 	if a.ID() != o.ID() {
 		return false
@@ -144,7 +191,15 @@ func (a *Asset) DeepEqual(o *Asset) bool {
 		return false
 	}
 
-	if !mssmt.IsEqualNode(a.SplitCommitmentRoot, o.SplitCommitmentRoot) {
+	// The split commitment roots are typed pointers, so we need to check
+	// for nil before handing them to IsEqualNode as interfaces.
+	switch {
+	case a.SplitCommitmentRoot == nil || o.SplitCommitmentRoot == nil:
+		if a.SplitCommitmentRoot != o.SplitCommitmentRoot {
+			return false
+		}
+
+	case !mssmt.IsEqualNode(a.SplitCommitmentRoot, o.SplitCommitmentRoot):
 		return false
 	}
 
@@ -152,6 +207,10 @@ func (a *Asset) DeepEqual(o *Asset) bool {
 		return false
 	}
 
+	if !a.GroupKey.IsEqual(o.GroupKey) {
+		return false
+	}
+
 	if len(a.PrevWitnesses) != len(o.PrevWitnesses) {
 		return false
 	}
@@ -165,14 +224,15 @@ func (a *Asset) DeepEqual(o *Asset) bool {
 	return true
 }
 
+// New creates a new normal asset with a fresh genesis.
 func New(
 	firstPrevOut wire.OutPoint,
 	name string, outputIndex uint32,
-	amount int32, scriptPubkey SerializedKey,
+	amount uint64, scriptPubkey SerializedKey,
 	splitCommitmentRoot *mssmt.ComputedNode,
 ) *Asset {
 
-	genesis := NewGenesis(firstPrevOut, name, outputIndex)
+	genesis := NewGenesis(firstPrevOut, name, outputIndex, Normal)
 	asset := NewAsset(genesis, amount, scriptPubkey, splitCommitmentRoot)
 
 	return asset
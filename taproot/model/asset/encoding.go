@@ -0,0 +1,507 @@
+package asset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/quocky/taproot-asset/taproot/model/tlv"
+)
+
+// The TLV types of the asset encoding. Even types are mandatory for a reader
+// to understand, odd types may be ignored.
+const (
+	genesisFirstPrevOutType tlv.Type = 0
+	genesisNameType         tlv.Type = 2
+	genesisOutputIndexType  tlv.Type = 4
+	genesisTypeType         tlv.Type = 6
+	genesisMetaHashType     tlv.Type = 8
+
+	prevIDOutPointType  tlv.Type = 0
+	prevIDAssetIDType   tlv.Type = 2
+	prevIDScriptKeyType tlv.Type = 4
+
+	witnessPrevIDType          tlv.Type = 0
+	witnessSplitCommitmentType tlv.Type = 2
+	witnessTxWitnessType       tlv.Type = 4
+
+	splitCommitmentProofType     tlv.Type = 0
+	splitCommitmentRootAssetType tlv.Type = 2
+
+	assetVersionType             tlv.Type = 0
+	assetGenesisType             tlv.Type = 2
+	assetAmountType              tlv.Type = 4
+	assetScriptKeyType           tlv.Type = 6
+	assetSplitCommitmentRootType tlv.Type = 8
+	assetPrevWitnessesType       tlv.Type = 10
+	assetGroupKeyType            tlv.Type = 12
+
+	groupKeyPubKeyType  tlv.Type = 0
+	groupKeyWitnessType tlv.Type = 2
+
+	metaRevealTypeType tlv.Type = 0
+	metaRevealDataType tlv.Type = 2
+)
+
+var (
+	// ErrUnknownVersion is returned when encoding or decoding an asset with
+	// a version this implementation does not understand.
+	ErrUnknownVersion = errors.New("asset: unknown version")
+
+	// ErrGenesisVersion is returned when encoding or decoding an asset whose
+	// genesis needs a newer asset version, see Genesis.MinVersion.
+	ErrGenesisVersion = errors.New("asset: genesis needs a newer asset " +
+		"version")
+
+	// ErrNonCanonicalGenesis is returned when decoding a genesis whose V1
+	// records don't match what Genesis.Encode writes, e.g. a V0 genesis
+	// carrying the type and meta hash records.
+	ErrNonCanonicalGenesis = errors.New("asset: non-canonical genesis " +
+		"encoding")
+)
+
+// Encode returns the TLV encoding of the genesis. The type and the meta hash are
+// only written for a V1 genesis, so the encoding of a V0 genesis never changes.
+func (g *Genesis) Encode() ([]byte, error) {
+	records := []tlv.Record{
+		tlv.NewRecord(
+			genesisFirstPrevOutType, tlv.OutPoint(&g.FirstPrevOut),
+		),
+		tlv.NewRecord(genesisNameType, []byte(g.Name)),
+		tlv.NewRecord(genesisOutputIndexType, tlv.Uint32(g.OutputIndex)),
+	}
+
+	if g.MinVersion() >= V1 {
+		records = append(
+			records,
+			tlv.NewRecord(genesisTypeType, []byte{byte(g.Type)}),
+			tlv.NewRecord(genesisMetaHashType, g.MetaHash[:]),
+		)
+	}
+
+	return tlv.Encode(records...)
+}
+
+// Decode decodes the TLV encoded genesis within blob.
+func (g *Genesis) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		genesisFirstPrevOutType, genesisNameType,
+		genesisOutputIndexType,
+	}, genesisTypeType, genesisMetaHashType)
+	if err != nil {
+		return fmt.Errorf("genesis: %w", err)
+	}
+
+	g.FirstPrevOut, err = tlv.ReadOutPoint(stream[genesisFirstPrevOutType])
+	if err != nil {
+		return err
+	}
+
+	g.Name = string(stream[genesisNameType])
+
+	g.OutputIndex, err = tlv.ReadUint32(stream[genesisOutputIndexType])
+	if err != nil {
+		return err
+	}
+
+	// The type and the meta hash are only encoded together, and only for
+	// a V1 genesis, so every genesis has a single encoding.
+	typeBytes, hasType := stream[genesisTypeType]
+	metaHashBytes, hasMetaHash := stream[genesisMetaHashType]
+	if hasType != hasMetaHash {
+		return fmt.Errorf("%w: type and meta hash records must be "+
+			"encoded together", ErrNonCanonicalGenesis)
+	}
+
+	g.Type = Normal
+	if hasType {
+		if len(typeBytes) != 1 {
+			return tlv.ErrInvalidLength
+		}
+
+		g.Type = Type(typeBytes[0])
+		if g.Type != Normal && g.Type != Collectible {
+			return fmt.Errorf("%w: %d", ErrUnknownType, g.Type)
+		}
+	}
+
+	g.MetaHash = [32]byte{}
+	if hasMetaHash {
+		err := tlv.ReadFixed(g.MetaHash[:], metaHashBytes)
+		if err != nil {
+			return err
+		}
+
+		if g.MinVersion() < V1 {
+			return fmt.Errorf("%w: V0 genesis with type and meta "+
+				"hash records", ErrNonCanonicalGenesis)
+		}
+	}
+
+	return nil
+}
+
+// Encode returns the TLV encoding of the metadata.
+func (m *MetaReveal) Encode() ([]byte, error) {
+	return tlv.Encode(
+		tlv.NewRecord(metaRevealTypeType, []byte{byte(m.Type)}),
+		tlv.NewRecord(metaRevealDataType, m.Data),
+	)
+}
+
+// Decode decodes the TLV encoded metadata within blob.
+func (m *MetaReveal) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		metaRevealTypeType, metaRevealDataType,
+	})
+	if err != nil {
+		return fmt.Errorf("meta reveal: %w", err)
+	}
+
+	typeBytes := stream[metaRevealTypeType]
+	if len(typeBytes) != 1 {
+		return tlv.ErrInvalidLength
+	}
+
+	m.Type = MetaType(typeBytes[0])
+	m.Data = stream[metaRevealDataType]
+
+	return nil
+}
+
+// Encode returns the TLV encoding of the previous asset ID.
+func (id *PrevID) Encode() ([]byte, error) {
+	return tlv.Encode(
+		tlv.NewRecord(prevIDOutPointType, tlv.OutPoint(&id.OutPoint)),
+		tlv.NewRecord(prevIDAssetIDType, id.ID[:]),
+		tlv.NewRecord(prevIDScriptKeyType, id.ScriptKey[:]),
+	)
+}
+
+// Decode decodes the TLV encoded previous asset ID within blob.
+func (id *PrevID) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		prevIDOutPointType, prevIDAssetIDType, prevIDScriptKeyType,
+	})
+	if err != nil {
+		return fmt.Errorf("prev id: %w", err)
+	}
+
+	id.OutPoint, err = tlv.ReadOutPoint(stream[prevIDOutPointType])
+	if err != nil {
+		return err
+	}
+
+	if err := tlv.ReadFixed(id.ID[:], stream[prevIDAssetIDType]); err != nil {
+		return err
+	}
+
+	return tlv.ReadFixed(id.ScriptKey[:], stream[prevIDScriptKeyType])
+}
+
+// Encode returns the TLV encoding of the witness. All fields are optional and
+// omitted when nil.
+func (w *Witness) Encode() ([]byte, error) {
+	var records []tlv.Record
+
+	if w.PrevID != nil {
+		prevIDBytes, err := w.PrevID.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(
+			records, tlv.NewRecord(witnessPrevIDType, prevIDBytes),
+		)
+	}
+
+	if w.SplitCommitment != nil {
+		splitBytes, err := w.SplitCommitment.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, tlv.NewRecord(
+			witnessSplitCommitmentType, splitBytes,
+		))
+	}
+
+	if len(w.TxWitness) > 0 {
+		records = append(records, tlv.NewRecord(
+			witnessTxWitnessType, tlv.List(w.TxWitness),
+		))
+	}
+
+	return tlv.Encode(records...)
+}
+
+// Decode decodes the TLV encoded witness within blob.
+func (w *Witness) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(
+		blob, nil, witnessPrevIDType, witnessSplitCommitmentType,
+		witnessTxWitnessType,
+	)
+	if err != nil {
+		return fmt.Errorf("witness: %w", err)
+	}
+
+	w.PrevID = nil
+	if prevIDBytes, ok := stream[witnessPrevIDType]; ok {
+		w.PrevID = &PrevID{}
+		if err := w.PrevID.Decode(prevIDBytes); err != nil {
+			return err
+		}
+	}
+
+	w.SplitCommitment = nil
+	if splitBytes, ok := stream[witnessSplitCommitmentType]; ok {
+		w.SplitCommitment = &SplitCommitment{}
+		if err := w.SplitCommitment.Decode(splitBytes); err != nil {
+			return err
+		}
+	}
+
+	w.TxWitness = nil
+	if txWitnessBytes, ok := stream[witnessTxWitnessType]; ok {
+		txWitness, err := tlv.ReadList(txWitnessBytes)
+		if err != nil {
+			return err
+		}
+
+		w.TxWitness = txWitness
+	}
+
+	return nil
+}
+
+// Encode returns the TLV encoding of the split commitment.
+func (s *SplitCommitment) Encode() ([]byte, error) {
+	proofBytes, err := EncodeMerkleProof(&s.Proof)
+	if err != nil {
+		return nil, err
+	}
+
+	rootAssetBytes, err := s.RootAsset.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return tlv.Encode(
+		tlv.NewRecord(splitCommitmentProofType, proofBytes),
+		tlv.NewRecord(splitCommitmentRootAssetType, rootAssetBytes),
+	)
+}
+
+// Decode decodes the TLV encoded split commitment within blob.
+func (s *SplitCommitment) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		splitCommitmentProofType, splitCommitmentRootAssetType,
+	})
+	if err != nil {
+		return fmt.Errorf("split commitment: %w", err)
+	}
+
+	proof, err := DecodeMerkleProof(stream[splitCommitmentProofType])
+	if err != nil {
+		return err
+	}
+	s.Proof = *proof
+
+	return s.RootAsset.Decode(stream[splitCommitmentRootAssetType])
+}
+
+// Encode returns the canonical TLV encoding of the asset. The encoding is the
+// value of the asset's MS-SMT leaf, so it must never change for a given asset
+// version: every record is written in increasing type order, integers are big
+// endian, optional fields are omitted when nil and the witnesses are written
+// in order as a length prefixed list.
+func (a *Asset) Encode() ([]byte, error) {
+	if a.Version > V1 {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, a.Version)
+	}
+
+	if a.Version < a.Genesis.MinVersion() {
+		return nil, fmt.Errorf("%w: %d", ErrGenesisVersion, a.Version)
+	}
+
+	genesisBytes, err := a.Genesis.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	records := []tlv.Record{
+		tlv.NewRecord(assetVersionType, []byte{byte(a.Version)}),
+		tlv.NewRecord(assetGenesisType, genesisBytes),
+		tlv.NewRecord(assetAmountType, tlv.Uint64(a.Amount)),
+		tlv.NewRecord(assetScriptKeyType, a.ScriptPubkey[:]),
+	}
+
+	if a.SplitCommitmentRoot != nil {
+		records = append(records, tlv.NewRecord(
+			assetSplitCommitmentRootType,
+			EncodeNode(a.SplitCommitmentRoot),
+		))
+	}
+
+	witnesses := make([][]byte, len(a.PrevWitnesses))
+	for idx := range a.PrevWitnesses {
+		witnesses[idx], err = a.PrevWitnesses[idx].Encode()
+		if err != nil {
+			return nil, err
+		}
+	}
+	records = append(records, tlv.NewRecord(
+		assetPrevWitnessesType, tlv.List(witnesses),
+	))
+
+	if a.GroupKey != nil {
+		groupKeyBytes, err := a.GroupKey.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, tlv.NewRecord(
+			assetGroupKeyType, groupKeyBytes,
+		))
+	}
+
+	return tlv.Encode(records...)
+}
+
+// Decode decodes the TLV encoded asset within blob.
+func (a *Asset) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		assetVersionType, assetGenesisType, assetAmountType,
+		assetScriptKeyType, assetPrevWitnessesType,
+	}, assetSplitCommitmentRootType, assetGroupKeyType)
+	if err != nil {
+		return fmt.Errorf("asset: %w", err)
+	}
+
+	versionBytes := stream[assetVersionType]
+	if len(versionBytes) != 1 || Version(versionBytes[0]) > V1 {
+		return fmt.Errorf("%w: %x", ErrUnknownVersion, versionBytes)
+	}
+	a.Version = Version(versionBytes[0])
+
+	if err := a.Genesis.Decode(stream[assetGenesisType]); err != nil {
+		return err
+	}
+
+	if a.Version < a.Genesis.MinVersion() {
+		return fmt.Errorf("%w: %d", ErrGenesisVersion, a.Version)
+	}
+
+	a.Amount, err = tlv.ReadUint64(stream[assetAmountType])
+	if err != nil {
+		return err
+	}
+
+	err = tlv.ReadFixed(a.ScriptPubkey[:], stream[assetScriptKeyType])
+	if err != nil {
+		return err
+	}
+
+	a.SplitCommitmentRoot = nil
+	if rootBytes, ok := stream[assetSplitCommitmentRootType]; ok {
+		a.SplitCommitmentRoot, err = DecodeNode(rootBytes)
+		if err != nil {
+			return err
+		}
+	}
+
+	witnesses, err := tlv.ReadList(stream[assetPrevWitnessesType])
+	if err != nil {
+		return err
+	}
+
+	a.PrevWitnesses = make([]Witness, len(witnesses))
+	for idx := range witnesses {
+		if err := a.PrevWitnesses[idx].Decode(witnesses[idx]); err != nil {
+			return err
+		}
+	}
+
+	a.GroupKey = nil
+	if groupKeyBytes, ok := stream[assetGroupKeyType]; ok {
+		a.GroupKey = &GroupKey{}
+		if err := a.GroupKey.Decode(groupKeyBytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Encode returns the TLV encoding of the group key.
+func (g *GroupKey) Encode() ([]byte, error) {
+	return tlv.Encode(
+		tlv.NewRecord(groupKeyPubKeyType, g.GroupPubKey[:]),
+		tlv.NewRecord(groupKeyWitnessType, g.Witness[:]),
+	)
+}
+
+// Decode decodes the TLV encoded group key within blob.
+func (g *GroupKey) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		groupKeyPubKeyType, groupKeyWitnessType,
+	})
+	if err != nil {
+		return fmt.Errorf("group key: %w", err)
+	}
+
+	err = tlv.ReadFixed(g.GroupPubKey[:], stream[groupKeyPubKeyType])
+	if err != nil {
+		return err
+	}
+
+	return tlv.ReadFixed(g.Witness[:], stream[groupKeyWitnessType])
+}
+
+// EncodeNode returns the encoding of a MS-SMT node as its 32 byte hash followed
+// by its big endian sum.
+func EncodeNode(node mssmt.Node) []byte {
+	hash := node.NodeHash()
+
+	return append(hash[:], tlv.Uint64(node.NodeSum())...)
+}
+
+// DecodeNode decodes a MS-SMT node encoded with EncodeNode.
+func DecodeNode(b []byte) (*mssmt.ComputedNode, error) {
+	if len(b) != 40 {
+		return nil, fmt.Errorf("%w: expected 40 bytes, got %d",
+			tlv.ErrInvalidLength, len(b))
+	}
+
+	var hash mssmt.NodeHash
+	copy(hash[:], b[:32])
+
+	sum, err := tlv.ReadUint64(b[32:])
+	if err != nil {
+		return nil, err
+	}
+
+	return mssmt.NewComputedNode(hash, sum), nil
+}
+
+// EncodeMerkleProof returns the compressed encoding of a MS-SMT merkle proof.
+func EncodeMerkleProof(proof *mssmt.Proof) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := proof.Compress().Encode(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeMerkleProof decodes a compressed MS-SMT merkle proof encoded with
+// EncodeMerkleProof.
+func DecodeMerkleProof(b []byte) (*mssmt.Proof, error) {
+	var compressed mssmt.CompressedProof
+	if err := compressed.Decode(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+
+	return compressed.Decompress()
+}
@@ -0,0 +1,241 @@
+package asset
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/quocky/taproot-asset/taproot/model/tlv"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testScriptKey = SerializedKey{
+		0x02, 0x49, 0x8e, 0xcf, 0x86, 0xfb, 0x26, 0x1f, 0x38, 0x0e,
+		0x46, 0x95, 0x24, 0x53, 0x8b, 0x9b, 0x53, 0x6a, 0x9e, 0xb1,
+		0xda, 0xa7, 0x63, 0x00, 0x1a, 0x1d, 0xda, 0xec, 0x7b, 0x71,
+		0x27, 0x92, 0x71,
+	}
+
+	testOutPoint = wire.OutPoint{
+		Hash:  chainhash.Hash{1, 2, 3, 4},
+		Index: 1,
+	}
+)
+
+// TestAssetLeafStable makes sure the leaf of an asset never changes for the
+// same asset, as it is committed to on-chain.
+func TestAssetLeafStable(t *testing.T) {
+	a := New(testOutPoint, "ticker", 0, 1000, testScriptKey, nil)
+
+	leaf, err := a.Leaf()
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, leaf.NodeSum())
+
+	leafHash := leaf.NodeHash()
+	require.Equal(
+		t, "236e809b30f8fff4c98fc8f73c5c61ab665192095cc704c286a1e57524d8ef66",
+		hex.EncodeToString(leafHash[:]),
+	)
+}
+
+// TestAssetEncodingRoundTrip makes sure an asset with a split commitment
+// witness and an asset with a large amount survive an encoding round trip.
+func TestAssetEncodingRoundTrip(t *testing.T) {
+	root := New(testOutPoint, "ticker", 0, 600, testScriptKey, nil)
+	root.SplitCommitmentRoot = mssmt.NewComputedNode(
+		mssmt.NodeHash{9, 9, 9}, 1000,
+	)
+	root.PrevWitnesses = []Witness{{
+		PrevID: &PrevID{
+			OutPoint:  testOutPoint,
+			ID:        root.ID(),
+			ScriptKey: testScriptKey,
+		},
+	}}
+
+	split := New(testOutPoint, "ticker", 0, 400, testScriptKey, nil)
+	split.PrevWitnesses = []Witness{{
+		PrevID: &ZeroPrevID,
+		SplitCommitment: &SplitCommitment{
+			Proof:     *mssmt.NewProof(mssmt.EmptyTree[1:]),
+			RootAsset: *root,
+		},
+	}}
+
+	// Amounts beyond the 32-bit range must survive the round trip.
+	large := New(testOutPoint, "ticker", 0, 3_000_000_000, testScriptKey, nil)
+
+	for _, a := range []*Asset{root, split, large} {
+		assetBytes, err := a.Encode()
+		require.NoError(t, err)
+
+		var decoded Asset
+		require.NoError(t, decoded.Decode(assetBytes))
+		require.True(t, a.DeepEqual(&decoded))
+
+		decodedBytes, err := decoded.Encode()
+		require.NoError(t, err)
+		require.Equal(t, assetBytes, decodedBytes)
+	}
+}
+
+// TestAssetTypeCommitted makes sure the asset type is committed to in the asset
+// ID of V1 assets and that collectibles are only valid with an amount of one.
+func TestAssetTypeCommitted(t *testing.T) {
+	normal := NewGenesis(testOutPoint, "ticker", 0, Normal)
+	collectible := NewGenesis(testOutPoint, "ticker", 0, Collectible)
+	require.NotEqual(t, normal.ID(), collectible.ID())
+	require.Equal(t, V0, normal.MinVersion())
+	require.Equal(t, V1, collectible.MinVersion())
+
+	genesisBytes, err := collectible.Encode()
+	require.NoError(t, err)
+
+	var decoded Genesis
+	require.NoError(t, decoded.Decode(genesisBytes))
+	require.Equal(t, collectible, decoded)
+
+	require.NoError(t, NewAsset(collectible, 1, testScriptKey, nil).Validate())
+	require.ErrorIs(
+		t, NewAsset(collectible, 2, testScriptKey, nil).Validate(),
+		ErrInvalidCollectibleAmount,
+	)
+	require.NoError(t, NewAsset(collectible, 0, NUMSKey, nil).Validate())
+
+	_, err = NUMSKey.ToPubKey()
+	require.NoError(t, err)
+}
+
+// TestMetaHashCommitted makes sure the meta hash is committed to in the asset
+// ID of V1 assets only and that meta reveals survive an encoding round trip.
+func TestMetaHashCommitted(t *testing.T) {
+	meta, err := NewIssuerMetaReveal(IssuerMeta{
+		Ticker:      "TKR",
+		Decimals:    2,
+		Description: "test asset",
+	})
+	require.NoError(t, err)
+	require.NoError(t, meta.Validate())
+
+	plain := NewGenesis(testOutPoint, "ticker", 0, Normal)
+	withMeta := plain
+	withMeta.MetaHash = meta.MetaHash()
+	require.False(t, plain.HasMeta())
+	require.True(t, withMeta.HasMeta())
+	require.NotEqual(t, plain.ID(), withMeta.ID())
+
+	require.Equal(t, V0, NewAsset(plain, 1, testScriptKey, nil).Version)
+	withMetaAsset := NewAsset(withMeta, 1, testScriptKey, nil)
+	require.Equal(t, V1, withMetaAsset.Version)
+
+	withMetaAsset.Version = V0
+	_, err = withMetaAsset.Encode()
+	require.ErrorIs(t, err, ErrGenesisVersion)
+
+	genesisBytes, err := withMeta.Encode()
+	require.NoError(t, err)
+
+	var decodedGenesis Genesis
+	require.NoError(t, decodedGenesis.Decode(genesisBytes))
+	require.Equal(t, withMeta, decodedGenesis)
+
+	metaBytes, err := meta.Encode()
+	require.NoError(t, err)
+
+	var decodedMeta MetaReveal
+	require.NoError(t, decodedMeta.Decode(metaBytes))
+	require.Equal(t, *meta, decodedMeta)
+
+	invalid := MetaReveal{Type: MetaJSON, Data: []byte("{")}
+	require.ErrorIs(t, invalid.Validate(), ErrInvalidMetaJSON)
+}
+
+// TestGenesisCanonical makes sure a genesis only decodes from the encoding
+// Genesis.Encode writes, so a V0 genesis can't carry the V1 records.
+func TestGenesisCanonical(t *testing.T) {
+	encode := func(g Genesis, typeRecord, metaHashRecord bool) []byte {
+		records := []tlv.Record{
+			tlv.NewRecord(
+				genesisFirstPrevOutType,
+				tlv.OutPoint(&g.FirstPrevOut),
+			),
+			tlv.NewRecord(genesisNameType, []byte(g.Name)),
+			tlv.NewRecord(
+				genesisOutputIndexType, tlv.Uint32(g.OutputIndex),
+			),
+		}
+		if typeRecord {
+			records = append(records, tlv.NewRecord(
+				genesisTypeType, []byte{byte(g.Type)},
+			))
+		}
+		if metaHashRecord {
+			records = append(records, tlv.NewRecord(
+				genesisMetaHashType, g.MetaHash[:],
+			))
+		}
+
+		blob, err := tlv.Encode(records...)
+		require.NoError(t, err)
+
+		return blob
+	}
+
+	normal := NewGenesis(testOutPoint, "ticker", 0, Normal)
+	collectible := NewGenesis(testOutPoint, "ticker", 0, Collectible)
+
+	testCases := []struct {
+		name           string
+		genesis        Genesis
+		typeRecord     bool
+		metaHashRecord bool
+		err            error
+	}{{
+		name:    "v0",
+		genesis: normal,
+	}, {
+		name:           "v1",
+		genesis:        collectible,
+		typeRecord:     true,
+		metaHashRecord: true,
+	}, {
+		name:           "v0 with v1 records",
+		genesis:        normal,
+		typeRecord:     true,
+		metaHashRecord: true,
+		err:            ErrNonCanonicalGenesis,
+	}, {
+		name:       "type record only",
+		genesis:    collectible,
+		typeRecord: true,
+		err:        ErrNonCanonicalGenesis,
+	}, {
+		name:           "meta hash record only",
+		genesis:        normal,
+		metaHashRecord: true,
+		err:            ErrNonCanonicalGenesis,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			blob := encode(tc.genesis, tc.typeRecord, tc.metaHashRecord)
+
+			var decoded Genesis
+			err := decoded.Decode(blob)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.genesis, decoded)
+
+			encoded, err := tc.genesis.Encode()
+			require.NoError(t, err)
+			require.Equal(t, encoded, blob)
+		})
+	}
+}
@@ -2,22 +2,30 @@ package asset
 
 import (
 	"crypto/sha256"
-	"encoding/binary"
 
 	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/tlv"
 )
 
 type Genesis struct {
 	FirstPrevOut wire.OutPoint
 	Name         string
 	OutputIndex  uint32
+
+	// Type is the type of the asset.
+	Type Type
+
+	// MetaHash is the hash of the metadata of the asset, see
+	// MetaReveal.MetaHash. It is all zeroes if the asset has no metadata.
+	MetaHash [32]byte
 }
 
 type GenesisAsset struct {
 	// AssetID have to use hex.Encoder to convert to []byte
 	AssetID        string `json:"asset_id"`
 	AssetName      string `json:"asset_name"`
-	Supply         int32  `json:"supply"`
+	Supply         uint64 `json:"supply"`
+	AssetType      Type   `json:"asset_type"`
 	OutputIndex    int32  `json:"output_index"`
 	GenesisPointID string `json:"genesis_point_id"`
 }
@@ -27,20 +35,51 @@ type GenesisPoint struct {
 	AnchorTxID string `json:"anchor_tx_id"`
 }
 
-func NewGenesis(firstPrevOut wire.OutPoint, name string, outputIndex uint32) Genesis {
+func NewGenesis(firstPrevOut wire.OutPoint, name string, outputIndex uint32,
+	assetType Type) Genesis {
+
 	return Genesis{
 		FirstPrevOut: firstPrevOut,
 		Name:         name,
 		OutputIndex:  outputIndex,
+		Type:         assetType,
 	}
 }
 
+// ID returns the asset ID of the genesis. The ID is the hash of a fixed binary
+// preimage that does not depend on any encoding library:
+//
+//	sha256(first_prev_out || sha256(name) || output_index)
+//
+// where the outpoint is serialized as on the wire (32 byte hash, little endian
+// index) and the output index is big endian. The genesis of a V1 asset appends
+// its type as a single byte and its meta hash to the preimage.
 func (g Genesis) ID() ID {
 	tagHash := sha256.Sum256([]byte(g.Name))
 
 	h := sha256.New()
-	_ = wire.WriteOutPoint(h, 0, 0, &g.FirstPrevOut)
+	_, _ = h.Write(tlv.OutPoint(&g.FirstPrevOut))
 	_, _ = h.Write(tagHash[:])
-	_ = binary.Write(h, binary.BigEndian, g.OutputIndex)
+	_, _ = h.Write(tlv.Uint32(g.OutputIndex))
+	if g.MinVersion() >= V1 {
+		_, _ = h.Write([]byte{byte(g.Type)})
+		_, _ = h.Write(g.MetaHash[:])
+	}
 	return *(*ID)(h.Sum(nil))
 }
+
+// MinVersion returns the lowest asset version able to encode the genesis. The
+// fields a version adds are only committed to by the assets of that version,
+// so the genesis of an asset not using them encodes as before.
+func (g Genesis) MinVersion() Version {
+	if g.Type != Normal || g.HasMeta() {
+		return V1
+	}
+
+	return V0
+}
+
+// HasMeta returns true if the genesis commits to metadata.
+func (g Genesis) HasMeta() bool {
+	return g.MetaHash != [32]byte{}
+}
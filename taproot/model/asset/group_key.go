@@ -0,0 +1,92 @@
+package asset
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+var (
+	// ErrInvalidGroupWitness is returned when the group witness of an
+	// asset isn't a valid signature of the group key over the genesis.
+	ErrInvalidGroupWitness = errors.New("asset: invalid group witness")
+)
+
+// GroupKey is the key of an asset group. Assets issued under the same group key
+// share a single identity, so an asset can be issued in multiple tranches.
+type GroupKey struct {
+	// GroupPubKey is the public key identifying the group.
+	GroupPubKey SerializedKey
+
+	// Witness is the Schnorr signature of the group key over the genesis
+	// ID of the asset. It proves that the issuer of the asset controls
+	// the group key.
+	Witness [schnorr.SignatureSize]byte
+}
+
+// NewGroupKey creates the group key of the asset with the given genesis by
+// signing the genesis with the signer of the group key.
+func NewGroupKey(groupSigner Signer, genesis Genesis) (*GroupKey, error) {
+	genesisID := genesis.ID()
+	sig, err := groupSigner.SignSchnorr(genesisID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	groupKey := &GroupKey{
+		GroupPubKey: ToSerialized(groupSigner.PubKey()),
+	}
+	copy(groupKey.Witness[:], sig.Serialize())
+
+	return groupKey, nil
+}
+
+// Verify checks that the group witness is a valid signature of the group key
+// over the given genesis.
+func (g *GroupKey) Verify(genesis Genesis) error {
+	groupPubKey, err := g.GroupPubKey.ToPubKey()
+	if err != nil {
+		return err
+	}
+
+	sig, err := schnorr.ParseSignature(g.Witness[:])
+	if err != nil {
+		return ErrInvalidGroupWitness
+	}
+
+	genesisID := genesis.ID()
+	if !sig.Verify(genesisID[:], groupPubKey) {
+		return ErrInvalidGroupWitness
+	}
+
+	return nil
+}
+
+// Copy returns a deep copy of the group key.
+func (g *GroupKey) Copy() *GroupKey {
+	groupKeyCopy := *g
+
+	return &groupKeyCopy
+}
+
+// IsEqual returns true if this group key is equal to the given group key.
+func (g *GroupKey) IsEqual(o *GroupKey) bool {
+	if g == nil || o == nil {
+		return g == o
+	}
+
+	return *g == *o
+}
+
+// TapCommitmentKey returns the key of the asset commitment an asset with the
+// given ID and group key is committed to in a Taproot Asset commitment. Assets
+// of the same group share the commitment keyed by the hash of the group key,
+// all other assets are committed to under their asset ID.
+func TapCommitmentKey(id ID, groupKey *GroupKey) [32]byte {
+	if groupKey == nil {
+		return id
+	}
+
+	return sha256.Sum256(groupKey.GroupPubKey.SchnorrSerialized())
+}
@@ -0,0 +1,44 @@
+package asset
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGroupKey makes sure tranches issued under the same group key share a
+// Taproot Asset commitment key and that a group witness only verifies for the
+// genesis it was created for.
+func TestGroupKey(t *testing.T) {
+	groupPrivKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	groupSigner := NewKeySigner(groupPrivKey)
+
+	first := New(testOutPoint, "ticker", 0, 1000, testScriptKey, nil)
+	first.GroupKey, err = NewGroupKey(groupSigner, first.Genesis)
+	require.NoError(t, err)
+	require.NoError(t, first.GroupKey.Verify(first.Genesis))
+
+	secondOutPoint := testOutPoint
+	secondOutPoint.Index++
+	second := New(secondOutPoint, "ticker", 0, 500, testScriptKey, nil)
+	second.GroupKey, err = NewGroupKey(groupSigner, second.Genesis)
+	require.NoError(t, err)
+
+	require.NotEqual(t, first.ID(), second.ID())
+	require.Equal(t, first.TapCommitmentKey(), second.TapCommitmentKey())
+	require.NotEqual(t, first.ID(), first.TapCommitmentKey())
+
+	// The witness of one tranche can't be used for another genesis.
+	require.ErrorIs(
+		t, first.GroupKey.Verify(second.Genesis), ErrInvalidGroupWitness,
+	)
+
+	assetBytes, err := first.Encode()
+	require.NoError(t, err)
+
+	var decoded Asset
+	require.NoError(t, decoded.Decode(assetBytes))
+	require.True(t, first.DeepEqual(&decoded))
+}
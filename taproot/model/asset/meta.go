@@ -0,0 +1,146 @@
+package asset
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MetaType is the type of the metadata of an asset, which tells how the
+// metadata should be interpreted.
+type MetaType uint8
+
+const (
+	// MetaOpaque is metadata of opaque bytes.
+	MetaOpaque MetaType = 0
+
+	// MetaJSON is metadata that is a JSON document.
+	MetaJSON MetaType = 1
+)
+
+const (
+	// MaxMetaDataSize is the maximum size of the metadata of an asset.
+	MaxMetaDataSize = 1 << 20
+)
+
+var (
+	// ErrUnknownMetaType is returned for a metadata type this
+	// implementation does not understand.
+	ErrUnknownMetaType = errors.New("asset: unknown meta type")
+
+	// ErrEmptyMeta is returned for metadata without any data.
+	ErrEmptyMeta = errors.New("asset: meta data is empty")
+
+	// ErrMetaTooLarge is returned for metadata larger than
+	// MaxMetaDataSize.
+	ErrMetaTooLarge = errors.New("asset: meta data too large")
+
+	// ErrInvalidMetaJSON is returned for JSON metadata that isn't valid
+	// JSON.
+	ErrInvalidMetaJSON = errors.New("asset: meta data is not valid JSON")
+)
+
+// String returns the name of the metadata type.
+func (t MetaType) String() string {
+	switch t {
+	case MetaOpaque:
+		return "opaque"
+
+	case MetaJSON:
+		return "json"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+// ParseMetaType parses the name of a metadata type as returned by
+// MetaType.String.
+func ParseMetaType(s string) (MetaType, error) {
+	switch strings.ToLower(s) {
+	case MetaOpaque.String():
+		return MetaOpaque, nil
+
+	case MetaJSON.String():
+		return MetaJSON, nil
+
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownMetaType, s)
+	}
+}
+
+// MetaReveal is the metadata of an asset. Only its hash is committed to in the
+// genesis of the asset, the metadata itself is revealed in the genesis proof.
+type MetaReveal struct {
+	// Type is the type of the metadata.
+	Type MetaType
+
+	// Data is the raw metadata.
+	Data []byte
+}
+
+// IssuerMeta is the JSON metadata describing an asset on behalf of its issuer.
+type IssuerMeta struct {
+	// Ticker is the short symbol of the asset.
+	Ticker string `json:"ticker,omitempty"`
+
+	// Decimals is the number of decimal places that should be used to
+	// display amounts of the asset.
+	Decimals uint8 `json:"decimals"`
+
+	// Description is a free form description of the asset and its issuer.
+	Description string `json:"description,omitempty"`
+}
+
+// NewIssuerMetaReveal creates JSON metadata from the given issuer metadata.
+func NewIssuerMetaReveal(meta IssuerMeta) (*MetaReveal, error) {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetaReveal{
+		Type: MetaJSON,
+		Data: data,
+	}, nil
+}
+
+// MetaHash returns the hash of the metadata that is committed to in the
+// genesis of the asset:
+//
+//	sha256(type || data)
+func (m *MetaReveal) MetaHash() [32]byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{byte(m.Type)})
+	_, _ = h.Write(m.Data)
+
+	return *(*[32]byte)(h.Sum(nil))
+}
+
+// Validate checks that the metadata is well-formed for its type.
+func (m *MetaReveal) Validate() error {
+	switch {
+	case len(m.Data) == 0:
+		return ErrEmptyMeta
+
+	case len(m.Data) > MaxMetaDataSize:
+		return fmt.Errorf("%w: %d bytes", ErrMetaTooLarge, len(m.Data))
+	}
+
+	switch m.Type {
+	case MetaOpaque:
+		return nil
+
+	case MetaJSON:
+		if !json.Valid(m.Data) {
+			return ErrInvalidMetaJSON
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %d", ErrUnknownMetaType, m.Type)
+	}
+}
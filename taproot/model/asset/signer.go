@@ -0,0 +1,36 @@
+package asset
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// Signer creates BIP-340 signatures for a public key. The private key may be
+// held elsewhere, so assets can be signed without exporting it.
+type Signer interface {
+	// PubKey returns the public key the signatures are valid for.
+	PubKey() *btcec.PublicKey
+
+	// SignSchnorr returns the BIP-340 signature of the 32 byte digest.
+	SignSchnorr(digest []byte) (*schnorr.Signature, error)
+}
+
+// keySigner is a Signer holding its private key in memory.
+type keySigner struct {
+	privKey *btcec.PrivateKey
+}
+
+// NewKeySigner returns a Signer signing with the given private key.
+func NewKeySigner(privKey *btcec.PrivateKey) Signer {
+	return &keySigner{privKey: privKey}
+}
+
+// PubKey returns the public key of the private key.
+func (k *keySigner) PubKey() *btcec.PublicKey {
+	return k.privKey.PubKey()
+}
+
+// SignSchnorr signs the digest with the private key.
+func (k *keySigner) SignSchnorr(digest []byte) (*schnorr.Signature, error) {
+	return schnorr.Sign(k.privKey, digest)
+}
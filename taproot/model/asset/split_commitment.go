@@ -32,28 +32,24 @@ func (s *SplitCommitment) DeepEqual(o *SplitCommitment) bool {
 		}
 	}
 
-	// We can't directly compare the root assets, as some non-TLV fields
-	// might be different in unit tests. To avoid introducing flakes, we
-	// only compare the encoded TLV data.
-	// var bufA, bufB bytes.Buffer
-
-	// We ignore errors here, these possible errors (incorrect TLV stream
-	// being created) are covered in unit tests.
-	leafS, err := s.RootAsset.Leaf()
+	// We can't directly compare the root assets, as some non-committed
+	// fields might differ. Instead, we compare their canonical encodings,
+	// which is exactly what the split commitment leaf commits to.
+	rootS, err := s.RootAsset.Encode()
 	if err != nil {
-		log.Println("leafS, err := s.RootAsset.Leaf()", err)
+		log.Println("rootS, err := s.RootAsset.Encode()", err)
 
 		return false
 	}
 
-	leadO, err := o.RootAsset.Leaf()
+	rootO, err := o.RootAsset.Encode()
 	if err != nil {
-		log.Println("leadO, err = o.RootAsset.Leaf()", err)
+		log.Println("rootO, err := o.RootAsset.Encode()", err)
 
 		return false
 	}
 
-	return bytes.Equal(leadO.Value, leafS.Value)
+	return bytes.Equal(rootS, rootO)
 }
 
 type SplitCommitmentByte struct {
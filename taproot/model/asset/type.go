@@ -0,0 +1,96 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Type is the type of an asset. The type is committed to in the asset ID.
+type Type uint8
+
+const (
+	// Normal is a fungible asset that can be split and merged freely.
+	Normal Type = 0
+
+	// Collectible is a unique, non-fungible asset with an amount of one.
+	Collectible Type = 1
+)
+
+var (
+	// ErrUnknownType is returned for an asset type this implementation
+	// does not understand.
+	ErrUnknownType = errors.New("asset: unknown type")
+
+	// ErrInvalidCollectibleAmount is returned for a collectible asset with
+	// an amount other than one.
+	ErrInvalidCollectibleAmount = errors.New("asset: collectible amount " +
+		"must be 1")
+
+	// NUMSKey is the NUMS point used as the script key of un-spendable
+	// assets, for example the zero-value root asset of a collectible
+	// transfer. It was generated via a try-and-increment approach using
+	// the phrase "taproot-assets" with SHA2-256, so no one knows its
+	// private key.
+	NUMSKey = SerializedKey{
+		0x02, 0x7c, 0x79, 0xb9, 0xb2, 0x6e, 0x46, 0x38, 0x95, 0xee,
+		0xf5, 0x67, 0x9d, 0x85, 0x58, 0x94, 0x2c, 0x86, 0xc4, 0xad,
+		0x22, 0x33, 0xad, 0xef, 0x01, 0xbc, 0x3e, 0x6d, 0x54, 0x0b,
+		0x36, 0x53, 0xfe,
+	}
+)
+
+// String returns the name of the asset type.
+func (t Type) String() string {
+	switch t {
+	case Normal:
+		return "normal"
+
+	case Collectible:
+		return "collectible"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+// ParseType parses the name of an asset type as returned by Type.String.
+func ParseType(s string) (Type, error) {
+	switch strings.ToLower(s) {
+	case Normal.String():
+		return Normal, nil
+
+	case Collectible.String():
+		return Collectible, nil
+
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownType, s)
+	}
+}
+
+// IsUnSpendable returns true if the asset is a zero-value asset locked to the
+// NUMS key, which can never be spent.
+func (a *Asset) IsUnSpendable() bool {
+	return a.Amount == 0 && a.ScriptPubkey == NUMSKey
+}
+
+// Validate checks that the amount of the asset is valid for its type.
+// Collectibles must have an amount of one, unless they are an un-spendable
+// zero-value asset.
+func (a *Asset) Validate() error {
+	switch a.Type {
+	case Normal:
+		return nil
+
+	case Collectible:
+		if a.Amount != 1 && !a.IsUnSpendable() {
+			return fmt.Errorf("%w: %d", ErrInvalidCollectibleAmount,
+				a.Amount)
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %d", ErrUnknownType, a.Type)
+	}
+}
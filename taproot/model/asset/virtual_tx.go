@@ -0,0 +1,207 @@
+package asset
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// ErrPrevAssetsMismatch is returned when the previous assets of a
+	// virtual transaction don't match the previous witnesses of the new
+	// asset.
+	ErrPrevAssetsMismatch = errors.New("asset: previous assets don't " +
+		"match previous witnesses")
+
+	// ErrMissingTxWitness is returned when an input of a virtual
+	// transaction isn't signed.
+	ErrMissingTxWitness = errors.New("asset: missing tx witness")
+
+	// ErrInvalidTxWitness is returned when the signature of an input of a
+	// virtual transaction isn't valid under the script key of the
+	// previous asset.
+	ErrInvalidTxWitness = errors.New("asset: invalid tx witness")
+)
+
+// virtualTx returns the virtual transaction that spends the given previous
+// assets into the new asset, together with the previous outputs it spends.
+//
+// Each input of the virtual transaction spends an outpoint derived from the
+// previous ID of the witness, and each previous output holds the amount and
+// the script key of the previous asset. The single output holds the amount of
+// the new asset, or the sum of its split commitment, and commits to the new
+// asset without its tx witnesses. A BIP-341 signature over the virtual
+// transaction therefore authorizes spending all inputs into exactly the new
+// asset and its splits.
+func virtualTx(newAsset *Asset, prevAssets []*Asset) (*wire.MsgTx,
+	*txscript.MultiPrevOutFetcher, error) {
+
+	if len(prevAssets) != len(newAsset.PrevWitnesses) {
+		return nil, nil, ErrPrevAssetsMismatch
+	}
+
+	tx := wire.NewMsgTx(2)
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+
+	for idx, witness := range newAsset.PrevWitnesses {
+		prevAsset := prevAssets[idx]
+		if witness.PrevID == nil || prevAsset == nil ||
+			witness.PrevID.ScriptKey != prevAsset.ScriptPubkey {
+
+			return nil, nil, ErrPrevAssetsMismatch
+		}
+
+		prevIDBytes, err := witness.PrevID.Encode()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		prevOutPoint := wire.OutPoint{
+			Hash: chainhash.Hash(sha256.Sum256(prevIDBytes)),
+		}
+
+		pkScript, err := scriptKeyPkScript(prevAsset.ScriptPubkey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Amounts are unsigned, the cast keeps all 64 bits that go into
+		// the sighash.
+		prevOuts.AddPrevOut(
+			prevOutPoint, wire.NewTxOut(int64(prevAsset.Amount), pkScript),
+		)
+		tx.AddTxIn(wire.NewTxIn(&prevOutPoint, nil, nil))
+	}
+
+	unsignedAsset := newAsset.Copy()
+	for idx := range unsignedAsset.PrevWitnesses {
+		unsignedAsset.PrevWitnesses[idx].TxWitness = nil
+	}
+
+	assetBytes, err := unsignedAsset.Encode()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assetHash := sha256.Sum256(assetBytes)
+	outputScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_1).
+		AddData(assetHash[:]).
+		Script()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputAmount := newAsset.Amount
+	if newAsset.SplitCommitmentRoot != nil {
+		outputAmount = newAsset.SplitCommitmentRoot.NodeSum()
+	}
+	tx.AddTxOut(wire.NewTxOut(int64(outputAmount), outputScript))
+
+	return tx, prevOuts, nil
+}
+
+// scriptKeyPkScript returns the taproot output script of the given script key.
+func scriptKeyPkScript(scriptKey SerializedKey) ([]byte, error) {
+	pubKey, err := scriptKey.ToPubKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return txscript.PayToTaprootScript(pubKey)
+}
+
+// VirtualTxSigHash returns the BIP-341 sighash of the input with the given
+// index of the virtual transaction spending the previous assets into the new
+// asset. The previous assets are in the order of the previous witnesses of the
+// new asset.
+func VirtualTxSigHash(newAsset *Asset, prevAssets []*Asset,
+	idx int) ([]byte, error) {
+
+	tx, prevOuts, err := virtualTx(newAsset, prevAssets)
+	if err != nil {
+		return nil, err
+	}
+
+	if idx < 0 || idx >= len(tx.TxIn) {
+		return nil, fmt.Errorf("invalid input index %d", idx)
+	}
+
+	return txscript.CalcTaprootSignatureHash(
+		txscript.NewTxSigHashes(tx, prevOuts), txscript.SigHashDefault,
+		tx, idx, prevOuts,
+	)
+}
+
+// SignVirtualTx signs all inputs of the new asset whose previous asset is
+// locked to the public key of the signer, and stores the signatures in the tx
+// witnesses of the new asset.
+func SignVirtualTx(newAsset *Asset, prevAssets []*Asset, signer Signer) error {
+	scriptKey := ToSerialized(signer.PubKey())
+
+	for idx := range newAsset.PrevWitnesses {
+		if idx >= len(prevAssets) || prevAssets[idx] == nil {
+			return ErrPrevAssetsMismatch
+		}
+
+		if prevAssets[idx].ScriptPubkey != scriptKey {
+			continue
+		}
+
+		sigHash, err := VirtualTxSigHash(newAsset, prevAssets, idx)
+		if err != nil {
+			return err
+		}
+
+		sig, err := signer.SignSchnorr(sigHash)
+		if err != nil {
+			return err
+		}
+
+		newAsset.PrevWitnesses[idx].TxWitness = wire.TxWitness{
+			sig.Serialize(),
+		}
+	}
+
+	return nil
+}
+
+// VerifyVirtualTx checks that every input of the new asset is signed by the
+// script key of its previous asset.
+func VerifyVirtualTx(newAsset *Asset, prevAssets []*Asset) error {
+	for idx, witness := range newAsset.PrevWitnesses {
+		if len(witness.TxWitness) == 0 {
+			return ErrMissingTxWitness
+		}
+
+		if len(witness.TxWitness) != 1 {
+			return ErrInvalidTxWitness
+		}
+
+		sig, err := schnorr.ParseSignature(witness.TxWitness[0])
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTxWitness, err)
+		}
+
+		sigHash, err := VirtualTxSigHash(newAsset, prevAssets, idx)
+		if err != nil {
+			return err
+		}
+
+		pubKey, err := prevAssets[idx].ScriptPubkey.ToPubKey()
+		if err != nil {
+			return err
+		}
+
+		if !sig.Verify(sigHash, pubKey) {
+			return ErrInvalidTxWitness
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package asset
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVirtualTxSignature makes sure the inputs of an asset transfer are only
+// valid when signed by their script keys, and that the signatures commit to
+// the new asset.
+func TestVirtualTxSignature(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	prevAsset := New(
+		testOutPoint, "ticker", 0, 1000,
+		ToSerialized(ownerKey.PubKey()), nil,
+	)
+
+	newAsset := prevAsset.Copy()
+	newAsset.ScriptPubkey = testScriptKey
+	newAsset.PrevWitnesses = []Witness{{
+		PrevID: &PrevID{
+			OutPoint:  testOutPoint,
+			ID:        prevAsset.ID(),
+			ScriptKey: prevAsset.ScriptPubkey,
+		},
+	}}
+	prevAssets := []*Asset{prevAsset}
+
+	require.ErrorIs(
+		t, VerifyVirtualTx(newAsset, prevAssets), ErrMissingTxWitness,
+	)
+
+	// A key that doesn't own the input doesn't sign it.
+	otherKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	require.NoError(t, SignVirtualTx(newAsset, prevAssets, NewKeySigner(otherKey)))
+	require.Empty(t, newAsset.PrevWitnesses[0].TxWitness)
+
+	require.NoError(t, SignVirtualTx(newAsset, prevAssets, NewKeySigner(ownerKey)))
+	require.NoError(t, VerifyVirtualTx(newAsset, prevAssets))
+
+	// The signature survives an encoding round trip.
+	assetBytes, err := newAsset.Encode()
+	require.NoError(t, err)
+
+	var decoded Asset
+	require.NoError(t, decoded.Decode(assetBytes))
+	require.True(t, newAsset.DeepEqual(&decoded))
+	require.NoError(t, VerifyVirtualTx(&decoded, prevAssets))
+
+	// Redirecting the asset to another script key invalidates the
+	// signature.
+	decoded.ScriptPubkey = ToSerialized(otherKey.PubKey())
+	require.ErrorIs(
+		t, VerifyVirtualTx(&decoded, prevAssets), ErrInvalidTxWitness,
+	)
+}
@@ -1,8 +1,9 @@
 package asset
 
 import (
+	"bytes"
+
 	"github.com/btcsuite/btcd/wire"
-	"reflect"
 )
 
 type ID [32]byte
@@ -18,6 +19,10 @@ type PrevID struct {
 type Witness struct {
 	PrevID *PrevID
 
+	// TxWitness is the witness authorizing the spend of the previous
+	// asset by its script key, see SignVirtualTx.
+	TxWitness wire.TxWitness
+
 	SplitCommitment *SplitCommitment
 }
 
@@ -34,10 +39,23 @@ func (w *Witness) DeepEqual(o *Witness) bool {
 		return w == o
 	}
 
-	if !reflect.DeepEqual(w.PrevID, o.PrevID) {
+	if w.PrevID == nil || o.PrevID == nil {
+		if w.PrevID != o.PrevID {
+			return false
+		}
+	} else if *w.PrevID != *o.PrevID {
 		return false
 	}
 
+	if len(w.TxWitness) != len(o.TxWitness) {
+		return false
+	}
+
+	for idx := range w.TxWitness {
+		if !bytes.Equal(w.TxWitness[idx], o.TxWitness[idx]) {
+			return false
+		}
+	}
+
 	return w.SplitCommitment.DeepEqual(o.SplitCommitment)
-	//return w.SplitCommitment.DeepEqual(o.SplitCommitment)
 }
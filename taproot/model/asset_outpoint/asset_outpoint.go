@@ -4,9 +4,9 @@ type UnspentOutpoint struct {
 	ID                       string   `json:"id"`
 	GenesisID                string   `json:"genesis_id"`
 	ScriptKey                []byte   `json:"script_key"`
-	Amount                   int32    `json:"amount"`
+	Amount                   uint64   `json:"amount"`
 	SplitCommitmentRootHash  []byte   `json:"split_commitment_root_hash"`
-	SplitCommitmentRootValue int32    `json:"split_commitment_root_value"`
+	SplitCommitmentRootValue uint64   `json:"split_commitment_root_value"`
 	AnchorUtxoID             string   `json:"anchor_utxo_id"`
 	ProofLocator             []byte   `json:"proof_locator"`
 	Proof                    []byte   `json:"proof"`
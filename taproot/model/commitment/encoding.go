@@ -0,0 +1,109 @@
+package commitment
+
+import (
+	"fmt"
+
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/tlv"
+)
+
+// The TLV types of the commitment proof encoding.
+const (
+	assetProofProofType  tlv.Type = 0
+	assetProofTapKeyType tlv.Type = 2
+
+	commitmentProofAssetProofType tlv.Type = 0
+	commitmentProofTapProofType   tlv.Type = 2
+)
+
+// Encode returns the TLV encoding of the asset proof.
+func (p *AssetProof) Encode() ([]byte, error) {
+	proofBytes, err := asset.EncodeMerkleProof(&p.Proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return tlv.Encode(
+		tlv.NewRecord(assetProofProofType, proofBytes),
+		tlv.NewRecord(assetProofTapKeyType, p.TapKey[:]),
+	)
+}
+
+// Decode decodes the TLV encoded asset proof within blob.
+func (p *AssetProof) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		assetProofProofType, assetProofTapKeyType,
+	})
+	if err != nil {
+		return fmt.Errorf("asset proof: %w", err)
+	}
+
+	proof, err := asset.DecodeMerkleProof(stream[assetProofProofType])
+	if err != nil {
+		return err
+	}
+	p.Proof = *proof
+
+	return tlv.ReadFixed(p.TapKey[:], stream[assetProofTapKeyType])
+}
+
+// Encode returns the TLV encoding of the commitment proof. The asset proof is
+// optional and omitted for asset commitment exclusion proofs.
+func (p *CommitmentProof) Encode() ([]byte, error) {
+	if p.TapProof == nil {
+		return nil, fmt.Errorf("commitment proof: missing tap proof")
+	}
+
+	var records []tlv.Record
+
+	if p.AssetProof != nil {
+		assetProofBytes, err := p.AssetProof.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, tlv.NewRecord(
+			commitmentProofAssetProofType, assetProofBytes,
+		))
+	}
+
+	tapProofBytes, err := asset.EncodeMerkleProof(&p.TapProof.Proof)
+	if err != nil {
+		return nil, err
+	}
+
+	records = append(records, tlv.NewRecord(
+		commitmentProofTapProofType, tapProofBytes,
+	))
+
+	return tlv.Encode(records...)
+}
+
+// Decode decodes the TLV encoded commitment proof within blob.
+func (p *CommitmentProof) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(
+		blob, []tlv.Type{commitmentProofTapProofType},
+		commitmentProofAssetProofType,
+	)
+	if err != nil {
+		return fmt.Errorf("commitment proof: %w", err)
+	}
+
+	p.AssetProof = nil
+	if assetProofBytes, ok := stream[commitmentProofAssetProofType]; ok {
+		p.AssetProof = &AssetProof{}
+		if err := p.AssetProof.Decode(assetProofBytes); err != nil {
+			return err
+		}
+	}
+
+	tapProof, err := asset.DecodeMerkleProof(
+		stream[commitmentProofTapProofType],
+	)
+	if err != nil {
+		return err
+	}
+	p.TapProof = &TapProof{Proof: *tapProof}
+
+	return nil
+}
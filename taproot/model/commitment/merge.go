@@ -0,0 +1,133 @@
+package commitment
+
+import (
+	"errors"
+
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+)
+
+var (
+	// ErrInvalidMergeInputCount is returned if a merge is attempted with
+	// less than two inputs.
+	ErrInvalidMergeInputCount = errors.New(
+		"at least two inputs should be merged",
+	)
+
+	// ErrMixedInputAssets is returned if the inputs of a state transition
+	// are of different assets.
+	ErrMixedInputAssets = errors.New("inputs are of different assets")
+
+	// ErrDuplicateInput is returned if the same asset is spent twice by a
+	// state transition.
+	ErrDuplicateInput = errors.New("duplicate input")
+)
+
+// Merge is a state transition which spends several inputs of the same asset
+// into a single asset holding their whole amount. Unlike a split, the merged
+// asset has a witness for each input, so no split commitment is needed.
+type Merge struct {
+	PrevAssets InputSet
+	Asset      *asset.Asset
+}
+
+// NewMerge creates the merge of the given inputs into a single asset locked to
+// the given script key. The merged asset still has to be signed.
+func NewMerge(inputs []SplitCommitmentInput,
+	scriptKey asset.SerializedKey) (*Merge, error) {
+
+	if len(inputs) < 2 {
+		return nil, ErrInvalidMergeInputCount
+	}
+
+	inputSet, prevWitnesses, err := newInputSet(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	totalInputAmount := uint64(0)
+	for _, input := range inputs {
+		err := mssmt.CheckSumOverflowUint64(
+			totalInputAmount, input.Asset.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		totalInputAmount += input.Asset.Amount
+	}
+
+	mergedAsset := inputs[0].Asset.Copy()
+	mergedAsset.Amount = totalInputAmount
+	mergedAsset.ScriptPubkey = scriptKey
+	mergedAsset.PrevWitnesses = prevWitnesses
+	mergedAsset.SplitCommitmentRoot = nil
+
+	if err := mergedAsset.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Merge{
+		PrevAssets: inputSet,
+		Asset:      mergedAsset,
+	}, nil
+}
+
+// Sign signs the inputs of the merged asset that are locked to the public key
+// of the signer.
+func (m *Merge) Sign(signer asset.Signer) error {
+	return asset.SignVirtualTx(
+		m.Asset, m.PrevAssets.assets(m.Asset.PrevWitnesses), signer,
+	)
+}
+
+// assets returns the previous assets referenced by the given witnesses, in
+// the same order.
+func (s InputSet) assets(witnesses []asset.Witness) []*asset.Asset {
+	prevAssets := make([]*asset.Asset, len(witnesses))
+	for idx, witness := range witnesses {
+		if witness.PrevID != nil {
+			prevAssets[idx] = s[*witness.PrevID]
+		}
+	}
+
+	return prevAssets
+}
+
+// newInputSet returns the inputs keyed by the PrevID referencing them, and the
+// witnesses of a state transition spending them in order. All inputs have to
+// be of the same asset, each spent exactly once.
+func newInputSet(inputs []SplitCommitmentInput) (InputSet,
+	[]asset.Witness, error) {
+
+	var (
+		inputSet      = make(InputSet, len(inputs))
+		prevWitnesses = make([]asset.Witness, len(inputs))
+	)
+
+	for idx := range inputs {
+		inAsset := inputs[idx].Asset
+		if err := inAsset.Validate(); err != nil {
+			return nil, nil, err
+		}
+
+		if inAsset.ID() != inputs[0].Asset.ID() {
+			return nil, nil, ErrMixedInputAssets
+		}
+
+		prevID := &asset.PrevID{
+			OutPoint:  inputs[idx].OutPoint,
+			ID:        inAsset.Genesis.ID(),
+			ScriptKey: inAsset.ScriptPubkey,
+		}
+
+		if _, ok := inputSet[*prevID]; ok {
+			return nil, nil, ErrDuplicateInput
+		}
+		inputSet[*prevID] = inAsset
+
+		prevWitnesses[idx].PrevID = prevID
+	}
+
+	return inputSet, prevWitnesses, nil
+}
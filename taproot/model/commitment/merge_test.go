@@ -0,0 +1,81 @@
+package commitment
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMerge makes sure a merge spends every input with its own signed
+// witness and holds the sum of the input amounts.
+func TestMerge(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	var (
+		ownerScriptKey = asset.ToSerialized(ownerKey.PubKey())
+		genesisOut     = wire.OutPoint{Hash: chainhash.Hash{1}}
+		amounts        = []uint64{10, 20, 30}
+		inputs         = make([]SplitCommitmentInput, len(amounts))
+	)
+
+	for idx, amount := range amounts {
+		inputs[idx] = SplitCommitmentInput{
+			Asset: asset.New(
+				genesisOut, "ticker", 0, amount, ownerScriptKey, nil,
+			),
+			OutPoint: wire.OutPoint{
+				Hash:  chainhash.Hash{2},
+				Index: uint32(idx),
+			},
+		}
+	}
+
+	merge, err := NewMerge(inputs, ownerScriptKey)
+	require.NoError(t, err)
+	require.EqualValues(t, 60, merge.Asset.Amount)
+	require.Equal(t, inputs[0].Asset.ID(), merge.Asset.ID())
+	require.Len(t, merge.Asset.PrevWitnesses, len(inputs))
+	require.False(t, merge.Asset.HasSplitCommitmentWitness())
+	require.False(t, merge.Asset.IsGenesisAsset())
+
+	for idx, witness := range merge.Asset.PrevWitnesses {
+		require.Equal(t, inputs[idx].OutPoint, witness.PrevID.OutPoint)
+		require.Equal(t, inputs[idx].Asset.ID(), witness.PrevID.ID)
+	}
+
+	prevAssets := merge.PrevAssets.assets(merge.Asset.PrevWitnesses)
+	require.ErrorIs(
+		t, asset.VerifyVirtualTx(merge.Asset, prevAssets),
+		asset.ErrMissingTxWitness,
+	)
+
+	require.NoError(t, merge.Sign(asset.NewKeySigner(ownerKey)))
+	require.NoError(t, asset.VerifyVirtualTx(merge.Asset, prevAssets))
+
+	// A single input can't be merged.
+	_, err = NewMerge(inputs[:1], ownerScriptKey)
+	require.ErrorIs(t, err, ErrInvalidMergeInputCount)
+
+	// Spending the same input twice is rejected.
+	_, err = NewMerge(
+		[]SplitCommitmentInput{inputs[0], inputs[0]}, ownerScriptKey,
+	)
+	require.ErrorIs(t, err, ErrDuplicateInput)
+
+	// Inputs of another asset can't be merged in.
+	otherInput := SplitCommitmentInput{
+		Asset: asset.New(
+			genesisOut, "other", 0, 5, ownerScriptKey, nil,
+		),
+		OutPoint: wire.OutPoint{Hash: chainhash.Hash{3}},
+	}
+	_, err = NewMerge(
+		[]SplitCommitmentInput{inputs[0], otherInput}, ownerScriptKey,
+	)
+	require.ErrorIs(t, err, ErrMixedInputAssets)
+}
@@ -0,0 +1,40 @@
+package commitment
+
+import (
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+)
+
+// PassiveAsset is the state transition of an asset which is anchored in an
+// output spent by a transfer without being transferred itself. The asset is
+// re-anchored with its whole amount and script key, spending its previous
+// outpoint, so its provenance continues in the new anchor output.
+type PassiveAsset struct {
+	PrevAsset *asset.Asset
+	Asset     *asset.Asset
+}
+
+// NewPassiveAsset creates the re-anchored asset spending the given input. The
+// re-anchored asset still has to be signed.
+func NewPassiveAsset(input SplitCommitmentInput) (*PassiveAsset, error) {
+	_, prevWitnesses, err := newInputSet([]SplitCommitmentInput{input})
+	if err != nil {
+		return nil, err
+	}
+
+	newAsset := input.Asset.Copy()
+	newAsset.PrevWitnesses = prevWitnesses
+	newAsset.SplitCommitmentRoot = nil
+
+	return &PassiveAsset{
+		PrevAsset: input.Asset,
+		Asset:     newAsset,
+	}, nil
+}
+
+// Sign signs the input of the re-anchored asset if it is locked to the public
+// key of the signer.
+func (p *PassiveAsset) Sign(signer asset.Signer) error {
+	return asset.SignVirtualTx(
+		p.Asset, []*asset.Asset{p.PrevAsset}, signer,
+	)
+}
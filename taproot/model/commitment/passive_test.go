@@ -0,0 +1,48 @@
+package commitment
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPassiveAsset makes sure a re-anchored passive asset keeps its amount and
+// script key and spends its previous outpoint with a signed witness.
+func TestPassiveAsset(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	prevAsset := asset.New(
+		wire.OutPoint{Hash: chainhash.Hash{1}}, "ticker", 0, 42,
+		asset.ToSerialized(ownerKey.PubKey()), nil,
+	)
+	input := SplitCommitmentInput{
+		Asset:    prevAsset,
+		OutPoint: wire.OutPoint{Hash: chainhash.Hash{2}, Index: 1},
+	}
+
+	passive, err := NewPassiveAsset(input)
+	require.NoError(t, err)
+	require.Equal(t, prevAsset.ID(), passive.Asset.ID())
+	require.Equal(t, prevAsset.Amount, passive.Asset.Amount)
+	require.Equal(t, prevAsset.ScriptPubkey, passive.Asset.ScriptPubkey)
+	require.False(t, passive.Asset.IsGenesisAsset())
+	require.Len(t, passive.Asset.PrevWitnesses, 1)
+	require.Equal(t, input.OutPoint, passive.Asset.PrevWitnesses[0].PrevID.OutPoint)
+
+	// The previous asset is left untouched.
+	require.True(t, prevAsset.IsGenesisAsset())
+
+	prevAssets := []*asset.Asset{prevAsset}
+	require.ErrorIs(
+		t, asset.VerifyVirtualTx(passive.Asset, prevAssets),
+		asset.ErrMissingTxWitness,
+	)
+
+	require.NoError(t, passive.Sign(asset.NewKeySigner(ownerKey)))
+	require.NoError(t, asset.VerifyVirtualTx(passive.Asset, prevAssets))
+}
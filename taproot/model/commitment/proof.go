@@ -19,6 +19,12 @@ var (
 	// ErrInvalidTaprootProof is an error returned upon verifying an invalid
 	// Taproot proof.
 	ErrInvalidTaprootProof = errors.New("invalid taproot proof")
+
+	// ErrTapKeyMismatch is an error returned if the asset proof of a
+	// commitment proof is for a different Taproot Asset commitment key
+	// than the one of the asset.
+	ErrTapKeyMismatch = errors.New("asset proof tap commitment key " +
+		"mismatch")
 )
 
 type AssetProof struct {
@@ -136,6 +142,12 @@ func (p CommitmentProof) DeriveByAssetInclusion(asset *asset.Asset) (*TapCommitm
 		return nil, errors.New("missing commitment proof")
 	}
 
+	// The asset commitment has to be the one the asset is committed to,
+	// which depends on the asset ID and group key of the asset.
+	if p.AssetProof.TapKey != asset.TapCommitmentKey() {
+		return nil, ErrTapKeyMismatch
+	}
+
 	// Use the asset proof to arrive at the asset commitment included within
 	// the Taproot Asset commitment.
 	assetCommitmentLeaf, err := asset.Leaf()
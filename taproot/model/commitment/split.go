@@ -23,6 +23,10 @@ var (
 	// invalid (e.g. splits do not fully consume input amount).
 	ErrInvalidSplitAmount = errors.New("invalid split amounts")
 
+	// ErrInvalidSplitInputs is returned if a split is attempted without
+	// inputs.
+	ErrInvalidSplitInputs = errors.New("no inputs to split")
+
 	// ErrInvalidSplitLocator is returned if a new split is attempted to be
 	// created w/o a valid external split locator.
 	ErrInvalidSplitLocator = errors.New(
@@ -58,12 +62,14 @@ type SplitLocator struct {
 	OutputIndex uint32
 	AssetID     asset.ID
 	ScriptKey   asset.SerializedKey
-	Amount      int32
+	Amount      uint64
 }
 
-func NewLocatorByAsset(a *asset.Asset) *SplitLocator {
+// NewLocatorByAsset creates the split locator of the given asset committed to
+// in the anchor output with the given index.
+func NewLocatorByAsset(outputIndex uint32, a *asset.Asset) *SplitLocator {
 	return &SplitLocator{
-		OutputIndex: a.OutputIndex,
+		OutputIndex: outputIndex,
 		AssetID:     a.ID(),
 		ScriptKey:   a.ScriptPubkey,
 		Amount:      a.Amount,
@@ -102,9 +108,26 @@ func NewSplitCommitment(ctx context.Context, inputs []SplitCommitmentInput,
 	rootLocator *SplitLocator,
 	externalLocators ...*SplitLocator) (*SplitCommitment, error) {
 
-	totalInputAmount := int32(0)
+	if len(inputs) == 0 {
+		return nil, ErrInvalidSplitInputs
+	}
+
+	inputSet, prevWitnesses, err := newInputSet(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	totalInputAmount := uint64(0)
 	for idx := range inputs {
 		input := inputs[idx]
+
+		err := mssmt.CheckSumOverflowUint64(
+			totalInputAmount, input.Asset.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+
 		totalInputAmount += input.Asset.Amount
 	}
 
@@ -112,6 +135,24 @@ func NewSplitCommitment(ctx context.Context, inputs []SplitCommitmentInput,
 		return nil, ErrInvalidSplitLocator
 	}
 
+	// A collectible can't be divided, it can only be sent as a whole to a
+	// single external locator.
+	if inputs[0].Asset.Type == asset.Collectible &&
+		len(externalLocators) != 1 {
+
+		return nil, ErrInvalidSplitLocatorCount
+	}
+
+	// A root locator without value must be un-spendable, and only such a
+	// root locator may use the un-spendable NUMS key.
+	switch {
+	case rootLocator.Amount == 0 && rootLocator.ScriptKey != asset.NUMSKey:
+		return nil, ErrInvalidScriptKey
+
+	case rootLocator.Amount != 0 && rootLocator.ScriptKey == asset.NUMSKey:
+		return nil, ErrNonZeroSplitAmount
+	}
+
 	locators := append(externalLocators, rootLocator)
 	splitAssets := make(SplitSet, len(locators))
 
@@ -168,24 +209,9 @@ func NewSplitCommitment(ctx context.Context, inputs []SplitCommitmentInput,
 		return nil, ErrInvalidSplitAmount
 	}
 
-	var err error
+	// The root asset spends all inputs, each with its own witness.
 	rootAsset := splitAssets[*rootLocator].Copy()
-
-	inputSet := make(InputSet)
-	rootAsset.PrevWitnesses = make([]asset.Witness, len(inputs))
-
-	for idx := range inputs {
-		input := inputs[idx]
-		inAsset := input.Asset
-		prevID := &asset.PrevID{
-			OutPoint:  input.OutPoint,
-			ID:        inAsset.Genesis.ID(),
-			ScriptKey: inAsset.ScriptPubkey,
-		}
-		inputSet[*prevID] = inAsset
-
-		rootAsset.PrevWitnesses[idx].PrevID = prevID
-	}
+	rootAsset.PrevWitnesses = prevWitnesses
 
 	splitRoot, err := splitTree.Root(context.TODO())
 	if err != nil {
@@ -221,3 +247,24 @@ func NewSplitCommitment(ctx context.Context, inputs []SplitCommitmentInput,
 		Tree:        splitTree,
 	}, nil
 }
+
+// SignRootAsset signs the inputs of the root asset that are locked to the
+// public key of the signer, and updates the root asset committed to in the split
+// commitment witnesses of the split assets accordingly. The split tree doesn't
+// commit to the witnesses of the root asset, so it stays valid.
+func (s *SplitCommitment) SignRootAsset(signer asset.Signer) error {
+	err := asset.SignVirtualTx(
+		s.RootAsset, s.PrevAssets.assets(s.RootAsset.PrevWitnesses),
+		signer,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, splitAsset := range s.SplitAssets {
+		splitCommitment := splitAsset.PrevWitnesses[0].SplitCommitment
+		splitCommitment.RootAsset = *s.RootAsset.Copy()
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// ErrAnchorSigned is returned when the outputs of an anchor PSBT would
+	// change after one of its asset anchor inputs was signed.
+	ErrAnchorSigned = errors.New("packet: anchor outputs can't change " +
+		"after an anchor input is signed")
+
+	// ErrMissingWitnessUtxo is returned when an input of an anchor PSBT
+	// doesn't tell the output it spends.
+	ErrMissingWitnessUtxo = errors.New("packet: missing witness utxo")
+
+	// ErrAnchorKeyMismatch is returned when the internal key and tapscript
+	// root of an asset anchor input don't commit to its output script.
+	ErrAnchorKeyMismatch = errors.New("packet: anchor internal key and " +
+		"tapscript root don't match output script")
+)
+
+// AnchorSigner signs the taproot key spend of asset anchor inputs whose
+// internal key is its public key.
+type AnchorSigner interface {
+	// PubKey returns the internal key of the inputs the signer signs.
+	PubKey() *btcec.PublicKey
+
+	// SignTaprootInput returns the BIP-341 key spend signature of the
+	// input with the given index, whose output key is the public key
+	// tweaked with the tapscript root.
+	SignTaprootInput(tx *wire.MsgTx, prevOuts txscript.PrevOutputFetcher,
+		idx int, tapscriptRoot []byte) ([]byte, error)
+}
+
+// NewAnchorInput returns the PSBT input spending an asset anchor output through
+// the taproot key path. The internal key is a compressed public key, the root
+// is the tapscript root committing to the assets of the output.
+func NewAnchorInput(prevOut *wire.TxOut, internalKey,
+	tapscriptRoot []byte) (psbt.PInput, error) {
+
+	pubKey, err := btcec.ParsePubKey(internalKey)
+	if err != nil {
+		return psbt.PInput{}, err
+	}
+
+	return psbt.PInput{
+		WitnessUtxo:        prevOut,
+		TaprootInternalKey: schnorr.SerializePubKey(pubKey),
+		TaprootMerkleRoot:  tapscriptRoot,
+	}, nil
+}
+
+// IsAnchorInput returns true if the PSBT input spends an asset anchor output.
+func IsAnchorInput(pInput *psbt.PInput) bool {
+	return len(pInput.TaprootInternalKey) == schnorr.PubKeyBytesLen &&
+		len(pInput.TaprootMerkleRoot) == 32
+}
+
+// SetAnchorOutputs sets the output scripts of the asset anchor outputs, which
+// are the first outputs of the PSBT. The scripts commit to the signed assets,
+// so they can only change as long as no anchor input is signed.
+func SetAnchorOutputs(anchor *psbt.Packet, pkScripts [][]byte) error {
+	if len(pkScripts) > len(anchor.UnsignedTx.TxOut) {
+		return fmt.Errorf("packet: %d anchor outputs for %d tx outputs",
+			len(pkScripts), len(anchor.UnsignedTx.TxOut))
+	}
+
+	changed := false
+	for idx, pkScript := range pkScripts {
+		txOut := anchor.UnsignedTx.TxOut[idx]
+		if !bytes.Equal(txOut.PkScript, pkScript) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	for idx := range anchor.Inputs {
+		pInput := &anchor.Inputs[idx]
+		if IsAnchorInput(pInput) && (len(pInput.TaprootKeySpendSig) > 0 ||
+			len(pInput.FinalScriptWitness) > 0) {
+
+			return ErrAnchorSigned
+		}
+	}
+
+	for idx, pkScript := range pkScripts {
+		anchor.UnsignedTx.TxOut[idx].PkScript = pkScript
+	}
+
+	return nil
+}
+
+// SignAnchorInputs signs the asset anchor inputs of the PSBT whose internal key
+// is the public key of the signer through the taproot key path, and returns the
+// number of inputs signed. The signatures commit to all inputs and
+// outputs, so the PSBT must not be modified afterwards.
+func SignAnchorInputs(anchor *psbt.Packet, signer AnchorSigner) (int, error) {
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for idx, txIn := range anchor.UnsignedTx.TxIn {
+		prevOut := anchor.Inputs[idx].WitnessUtxo
+		if prevOut == nil {
+			return 0, fmt.Errorf("%w: input %d", ErrMissingWitnessUtxo, idx)
+		}
+
+		prevOuts.AddPrevOut(txIn.PreviousOutPoint, prevOut)
+	}
+
+	var (
+		internalKey = schnorr.SerializePubKey(signer.PubKey())
+		signed      = 0
+	)
+
+	for idx := range anchor.Inputs {
+		pInput := &anchor.Inputs[idx]
+		if !IsAnchorInput(pInput) || len(pInput.TaprootKeySpendSig) > 0 ||
+			!bytes.Equal(pInput.TaprootInternalKey, internalKey) {
+
+			continue
+		}
+
+		if err := checkAnchorScript(pInput); err != nil {
+			return 0, fmt.Errorf("input %d: %w", idx, err)
+		}
+
+		sig, err := signer.SignTaprootInput(
+			anchor.UnsignedTx, prevOuts, idx, pInput.TaprootMerkleRoot,
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		pInput.TaprootKeySpendSig = sig
+		signed++
+	}
+
+	return signed, nil
+}
+
+// checkAnchorScript checks that the output script spent by an asset anchor
+// input is the taproot output key derived from its internal key and tapscript
+// root.
+func checkAnchorScript(pInput *psbt.PInput) error {
+	internalKey, err := schnorr.ParsePubKey(pInput.TaprootInternalKey)
+	if err != nil {
+		return err
+	}
+
+	outputKey := txscript.ComputeTaprootOutputKey(
+		internalKey, pInput.TaprootMerkleRoot,
+	)
+
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(pkScript, pInput.WitnessUtxo.PkScript) {
+		return ErrAnchorKeyMismatch
+	}
+
+	return nil
+}
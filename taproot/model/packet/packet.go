@@ -0,0 +1,163 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	assetoutpointmodel "github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
+)
+
+// Packet is a transfer between its funding and its broadcast. It bundles the
+// BIP-174 PSBT of the anchor transaction with the virtual packet of the assets,
+// so both can be signed by parties which don't hold the whole wallet, like a
+// hardware wallet, a co-signer or an offline machine.
+//
+// The asset anchor outputs are the first outputs of the anchor transaction, in
+// the order of the virtual outputs. Their scripts commit to the signed assets,
+// so the anchor inputs are only signed once the virtual packet is complete.
+type Packet struct {
+	Anchor  *psbt.Packet
+	Virtual *VPacket
+
+	// SpentOutpoints are the asset outpoints spent by the transfer, which
+	// the server marks spent once the anchor transaction is broadcast.
+	SpentOutpoints []*assetoutpointmodel.UnspentOutpoint
+}
+
+// encodedPacket is the serialized form of a packet. The anchor is a binary
+// PSBT, the assets are in their TLV encoding.
+type encodedPacket struct {
+	Anchor         []byte                                `json:"anchor"`
+	Inputs         []encodedInput                        `json:"inputs"`
+	Outputs        []encodedOutput                       `json:"outputs"`
+	SpentOutpoints []*assetoutpointmodel.UnspentOutpoint `json:"spent_outpoints"`
+}
+
+type encodedInput struct {
+	PrevID []byte `json:"prev_id"`
+	Asset  []byte `json:"asset"`
+	Proof  []byte `json:"proof"`
+}
+
+type encodedOutput struct {
+	InternalKey []byte   `json:"internal_key"`
+	Assets      [][]byte `json:"assets"`
+}
+
+// Encode serializes the packet.
+func (p *Packet) Encode() ([]byte, error) {
+	var anchor bytes.Buffer
+	if err := p.Anchor.Serialize(&anchor); err != nil {
+		return nil, err
+	}
+
+	encoded := encodedPacket{
+		Anchor:         anchor.Bytes(),
+		Inputs:         make([]encodedInput, len(p.Virtual.Inputs)),
+		Outputs:        make([]encodedOutput, len(p.Virtual.Outputs)),
+		SpentOutpoints: p.SpentOutpoints,
+	}
+
+	for idx, input := range p.Virtual.Inputs {
+		prevID, err := input.PrevID.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		assetBytes, err := input.Asset.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		encoded.Inputs[idx] = encodedInput{
+			PrevID: prevID,
+			Asset:  assetBytes,
+			Proof:  input.Proof,
+		}
+	}
+
+	for idx, output := range p.Virtual.Outputs {
+		assets := make([][]byte, len(output.Assets))
+		for i, a := range output.Assets {
+			assetBytes, err := a.Encode()
+			if err != nil {
+				return nil, err
+			}
+
+			assets[i] = assetBytes
+		}
+
+		encoded.Outputs[idx] = encodedOutput{
+			InternalKey: output.InternalKey.CopyBytes(),
+			Assets:      assets,
+		}
+	}
+
+	return json.Marshal(encoded)
+}
+
+// Decode decodes the serialized packet within blob.
+func (p *Packet) Decode(blob []byte) error {
+	var encoded encodedPacket
+	if err := json.Unmarshal(blob, &encoded); err != nil {
+		return err
+	}
+
+	anchor, err := psbt.NewFromRawBytes(bytes.NewReader(encoded.Anchor), false)
+	if err != nil {
+		return fmt.Errorf("anchor: %w", err)
+	}
+
+	virtual := &VPacket{
+		Inputs:  make([]*VInput, len(encoded.Inputs)),
+		Outputs: make([]*VOutput, len(encoded.Outputs)),
+	}
+
+	for idx, input := range encoded.Inputs {
+		var prevID asset.PrevID
+		if err := prevID.Decode(input.PrevID); err != nil {
+			return fmt.Errorf("input %d: %w", idx, err)
+		}
+
+		var prevAsset asset.Asset
+		if err := prevAsset.Decode(input.Asset); err != nil {
+			return fmt.Errorf("input %d: %w", idx, err)
+		}
+
+		virtual.Inputs[idx] = &VInput{
+			PrevID: prevID,
+			Asset:  &prevAsset,
+			Proof:  input.Proof,
+		}
+	}
+
+	for idx, output := range encoded.Outputs {
+		if len(output.InternalKey) != len(asset.SerializedKey{}) {
+			return fmt.Errorf("output %d: invalid internal key", idx)
+		}
+
+		assets := make([]*asset.Asset, len(output.Assets))
+		for i, assetBytes := range output.Assets {
+			var newAsset asset.Asset
+			if err := newAsset.Decode(assetBytes); err != nil {
+				return fmt.Errorf("output %d: %w", idx, err)
+			}
+
+			assets[i] = &newAsset
+		}
+
+		virtual.Outputs[idx] = &VOutput{
+			InternalKey: asset.SerializedKey(output.InternalKey),
+			Assets:      assets,
+		}
+	}
+
+	p.Anchor = anchor
+	p.Virtual = virtual
+	p.SpentOutpoints = encoded.SpentOutpoints
+
+	return nil
+}
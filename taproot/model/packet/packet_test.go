@@ -0,0 +1,281 @@
+package packet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	assetoutpointmodel "github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
+	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/stretchr/testify/require"
+)
+
+// anchorSigner signs anchor inputs with a private key held in memory.
+type anchorSigner struct {
+	privKey *btcec.PrivateKey
+}
+
+func (a *anchorSigner) PubKey() *btcec.PublicKey {
+	return a.privKey.PubKey()
+}
+
+func (a *anchorSigner) SignTaprootInput(tx *wire.MsgTx,
+	prevOuts txscript.PrevOutputFetcher, idx int,
+	tapscriptRoot []byte) ([]byte, error) {
+
+	prevOut := prevOuts.FetchPrevOutput(tx.TxIn[idx].PreviousOutPoint)
+
+	return txscript.RawTxInTaprootSignature(
+		tx, txscript.NewTxSigHashes(tx, prevOuts), idx, prevOut.Value,
+		prevOut.PkScript, tapscriptRoot, txscript.SigHashDefault,
+		a.privKey,
+	)
+}
+
+// splitPacket returns a virtual packet splitting an asset held by two owners
+// between the first owner and a recipient.
+func splitPacket(t *testing.T, ownerA, ownerB *btcec.PrivateKey) *VPacket {
+	t.Helper()
+
+	var (
+		keyA       = asset.ToSerialized(ownerA.PubKey())
+		keyB       = asset.ToSerialized(ownerB.PubKey())
+		genesisOut = wire.OutPoint{Hash: chainhash.Hash{1}}
+		inputs     = []commitment.SplitCommitmentInput{{
+			Asset:    asset.New(genesisOut, "ticker", 0, 60, keyA, nil),
+			OutPoint: wire.OutPoint{Hash: chainhash.Hash{2}},
+		}, {
+			Asset:    asset.New(genesisOut, "ticker", 0, 40, keyB, nil),
+			OutPoint: wire.OutPoint{Hash: chainhash.Hash{3}},
+		}}
+	)
+
+	recipient, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	genesis := inputs[0].Asset.Genesis
+	rootLocator := commitment.NewLocatorByAsset(
+		0, asset.NewAsset(genesis, 50, keyA, nil),
+	)
+	splitLocator := commitment.NewLocatorByAsset(
+		1, asset.NewAsset(
+			genesis, 50, asset.ToSerialized(recipient.PubKey()), nil,
+		),
+	)
+
+	split, err := commitment.NewSplitCommitment(
+		context.Background(), inputs, rootLocator, splitLocator,
+	)
+	require.NoError(t, err)
+
+	vPacket := &VPacket{
+		Outputs: []*VOutput{{
+			InternalKey: keyA,
+			Assets:      []*asset.Asset{split.RootAsset},
+		}, {
+			InternalKey: asset.ToSerialized(recipient.PubKey()),
+			Assets: []*asset.Asset{
+				&split.SplitAssets[*splitLocator].Asset,
+			},
+		}},
+	}
+
+	for idx, input := range inputs {
+		vPacket.Inputs = append(vPacket.Inputs, NewVInput(
+			input.Asset, input.OutPoint, []byte{byte(idx)},
+		))
+	}
+
+	return vPacket
+}
+
+// TestVPacketSign makes sure each owner signs only its own inputs, and that
+// the split assets embed the root asset once it is signed.
+func TestVPacketSign(t *testing.T) {
+	ownerA, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	ownerB, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	vPacket := splitPacket(t, ownerA, ownerB)
+	splitAsset := vPacket.Outputs[1].Assets[0]
+
+	proofs, err := vPacket.InputProofs(splitAsset)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0}, {1}}, proofs)
+
+	require.False(t, vPacket.IsComplete())
+	require.ErrorIs(t, vPacket.Verify(), asset.ErrMissingTxWitness)
+
+	signed, err := vPacket.Sign(asset.NewKeySigner(ownerA))
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+	require.False(t, vPacket.IsComplete())
+
+	// Signing again adds nothing.
+	signed, err = vPacket.Sign(asset.NewKeySigner(ownerA))
+	require.NoError(t, err)
+	require.Zero(t, signed)
+
+	signed, err = vPacket.Sign(asset.NewKeySigner(ownerB))
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+	require.True(t, vPacket.IsComplete())
+	require.NoError(t, vPacket.Verify())
+
+	// A split asset embedding a stale root asset is rejected.
+	rootAsset := &splitAsset.PrevWitnesses[0].SplitCommitment.RootAsset
+	rootAsset.PrevWitnesses[1].TxWitness = nil
+	require.ErrorIs(t, vPacket.Verify(), ErrSplitRootMismatch)
+}
+
+// TestSignAnchorInputs makes sure only the anchor inputs of the signing key are
+// signed through the taproot key path, and that the anchor outputs can't change
+// afterwards.
+func TestSignAnchorInputs(t *testing.T) {
+	internalKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	tapscriptRoot := chainhash.HashB([]byte("assets"))
+	outputKey := txscript.ComputeTaprootOutputKey(
+		internalKey.PubKey(), tapscriptRoot,
+	)
+	anchorScript, err := txscript.PayToTaprootScript(outputKey)
+	require.NoError(t, err)
+
+	walletScript, err := txscript.PayToTaprootScript(internalKey.PubKey())
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{1}}, nil, nil))
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{2}}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, anchorScript))
+	tx.AddTxOut(wire.NewTxOut(5000, walletScript))
+
+	anchor, err := psbt.NewFromUnsignedTx(tx)
+	require.NoError(t, err)
+
+	prevOut := wire.NewTxOut(1000, anchorScript)
+	anchor.Inputs[0], err = NewAnchorInput(
+		prevOut, internalKey.PubKey().SerializeCompressed(),
+		tapscriptRoot,
+	)
+	require.NoError(t, err)
+	anchor.Inputs[1].WitnessUtxo = wire.NewTxOut(8000, walletScript)
+
+	require.True(t, IsAnchorInput(&anchor.Inputs[0]))
+	require.False(t, IsAnchorInput(&anchor.Inputs[1]))
+
+	// The outputs may change as long as no anchor input is signed.
+	require.NoError(t, SetAnchorOutputs(anchor, [][]byte{walletScript}))
+	require.NoError(t, SetAnchorOutputs(anchor, [][]byte{anchorScript}))
+
+	otherKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	signed, err := SignAnchorInputs(anchor, &anchorSigner{otherKey})
+	require.NoError(t, err)
+	require.Zero(t, signed)
+
+	signed, err = SignAnchorInputs(anchor, &anchorSigner{internalKey})
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+
+	require.ErrorIs(
+		t, SetAnchorOutputs(anchor, [][]byte{walletScript}),
+		ErrAnchorSigned,
+	)
+	require.NoError(t, SetAnchorOutputs(anchor, [][]byte{anchorScript}))
+
+	// The signature is valid under the anchor output script.
+	sig := anchor.Inputs[0].TaprootKeySpendSig
+	require.NoError(t, psbt.Finalize(anchor, 0))
+	require.NotEmpty(t, anchor.Inputs[0].FinalScriptWitness)
+
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for idx, txIn := range anchor.UnsignedTx.TxIn {
+		prevOuts.AddPrevOut(
+			txIn.PreviousOutPoint, anchor.Inputs[idx].WitnessUtxo,
+		)
+	}
+
+	signedTx := anchor.UnsignedTx.Copy()
+	signedTx.TxIn[0].Witness = wire.TxWitness{sig}
+
+	engine, err := txscript.NewEngine(
+		prevOut.PkScript, signedTx, 0, txscript.StandardVerifyFlags,
+		nil, txscript.NewTxSigHashes(signedTx, prevOuts),
+		prevOut.Value, prevOuts,
+	)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+}
+
+// TestPacketEncoding makes sure a packet survives its serialization.
+func TestPacketEncoding(t *testing.T) {
+	ownerA, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	ownerB, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	vPacket := splitPacket(t, ownerA, ownerB)
+	_, err = vPacket.Sign(asset.NewKeySigner(ownerA))
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{2}}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+
+	anchor, err := psbt.NewFromUnsignedTx(tx)
+	require.NoError(t, err)
+	anchor.Inputs[0].WitnessUtxo = wire.NewTxOut(2000, []byte{txscript.OP_TRUE})
+
+	p := &Packet{
+		Anchor:  anchor,
+		Virtual: vPacket,
+		SpentOutpoints: []*assetoutpointmodel.UnspentOutpoint{{
+			ID:       "outpoint",
+			Outpoint: wire.OutPoint{Hash: chainhash.Hash{2}}.String(),
+		}},
+	}
+
+	blob, err := p.Encode()
+	require.NoError(t, err)
+
+	var decoded Packet
+	require.NoError(t, decoded.Decode(blob))
+
+	require.Equal(t, p.SpentOutpoints, decoded.SpentOutpoints)
+	require.Equal(t, anchor.UnsignedTx.TxHash(), decoded.Anchor.UnsignedTx.TxHash())
+	require.Equal(t, anchor.Inputs[0].WitnessUtxo, decoded.Anchor.Inputs[0].WitnessUtxo)
+
+	require.Len(t, decoded.Virtual.Inputs, len(vPacket.Inputs))
+	for idx, input := range vPacket.Inputs {
+		decodedInput := decoded.Virtual.Inputs[idx]
+		require.Equal(t, input.PrevID, decodedInput.PrevID)
+		require.True(t, input.Asset.DeepEqual(decodedInput.Asset))
+		require.Equal(t, input.Proof, decodedInput.Proof)
+	}
+
+	require.Len(t, decoded.Virtual.Outputs, len(vPacket.Outputs))
+	for idx, output := range vPacket.Outputs {
+		decodedOutput := decoded.Virtual.Outputs[idx]
+		require.Equal(t, output.InternalKey, decodedOutput.InternalKey)
+		require.Len(t, decodedOutput.Assets, len(output.Assets))
+
+		for i, a := range output.Assets {
+			require.True(t, a.DeepEqual(decodedOutput.Assets[i]))
+		}
+	}
+
+	// The decoded packet can be signed to completion.
+	signed, err := decoded.Virtual.Sign(asset.NewKeySigner(ownerB))
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+	require.NoError(t, decoded.Virtual.Verify())
+}
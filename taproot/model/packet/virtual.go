@@ -0,0 +1,247 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+)
+
+var (
+	// ErrUnknownInput is returned when a new asset of a virtual packet
+	// spends an outpoint which isn't an input of the packet.
+	ErrUnknownInput = errors.New("packet: unknown virtual input")
+
+	// ErrSplitRootMismatch is returned when the root asset embedded in a
+	// split asset isn't the root asset committed to by the packet.
+	ErrSplitRootMismatch = errors.New("packet: split asset doesn't " +
+		"embed its root asset")
+)
+
+// VInput is an asset outpoint spent by a transfer.
+type VInput struct {
+	// PrevID identifies the outpoint in the witnesses of the new assets.
+	PrevID asset.PrevID
+
+	// Asset is the asset of the last snapshot of the proof file.
+	Asset *asset.Asset
+
+	// Proof is the encoded proof file of the outpoint.
+	Proof []byte
+}
+
+// VOutput is an anchor output of a transfer together with the assets it
+// commits to.
+type VOutput struct {
+	InternalKey asset.SerializedKey
+	Assets      []*asset.Asset
+}
+
+// VPacket is the asset level part of a transfer: the asset outpoints it spends
+// and the new assets of each anchor output. The new assets spending inputs
+// directly carry the witnesses to sign, split assets are authorized by the
+// witnesses of their root asset.
+type VPacket struct {
+	Inputs  []*VInput
+	Outputs []*VOutput
+}
+
+// NewVInput returns the input spending the asset anchored at the outpoint.
+func NewVInput(prevAsset *asset.Asset, outPoint wire.OutPoint,
+	proofFile []byte) *VInput {
+
+	return &VInput{
+		PrevID: asset.PrevID{
+			OutPoint:  outPoint,
+			ID:        prevAsset.ID(),
+			ScriptKey: prevAsset.ScriptPubkey,
+		},
+		Asset: prevAsset,
+		Proof: proofFile,
+	}
+}
+
+// Sign signs all inputs of the new assets locked to the public key of the
+// signer and returns the number of inputs signed. The root assets of
+// splits are copied into their split assets again once signed.
+func (v *VPacket) Sign(signer asset.Signer) (int, error) {
+	scriptKey := asset.ToSerialized(signer.PubKey())
+
+	signed := 0
+	for _, newAsset := range v.spendingAssets() {
+		prevAssets, err := v.prevAssets(newAsset)
+		if err != nil {
+			return 0, err
+		}
+
+		missing := 0
+		for idx, witness := range newAsset.PrevWitnesses {
+			if len(witness.TxWitness) == 0 &&
+				prevAssets[idx].ScriptPubkey == scriptKey {
+
+				missing++
+			}
+		}
+
+		if missing == 0 {
+			continue
+		}
+
+		err = asset.SignVirtualTx(newAsset, prevAssets, signer)
+		if err != nil {
+			return 0, err
+		}
+
+		v.updateSplitAssets(newAsset)
+		signed += missing
+	}
+
+	return signed, nil
+}
+
+// IsComplete returns true if every input of the new assets is signed.
+func (v *VPacket) IsComplete() bool {
+	for _, newAsset := range v.spendingAssets() {
+		for _, witness := range newAsset.PrevWitnesses {
+			if len(witness.TxWitness) == 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Verify checks the signatures of all new assets, and that every split asset
+// embeds the signed root asset of its split.
+func (v *VPacket) Verify() error {
+	roots := make(map[[32]byte]*asset.Asset)
+
+	for _, newAsset := range v.spendingAssets() {
+		prevAssets, err := v.prevAssets(newAsset)
+		if err != nil {
+			return err
+		}
+
+		if err := asset.VerifyVirtualTx(newAsset, prevAssets); err != nil {
+			return fmt.Errorf("asset %x: %w", newAsset.AssetCommitmentKey(), err)
+		}
+
+		if newAsset.SplitCommitmentRoot != nil {
+			roots[newAsset.SplitCommitmentRoot.NodeHash()] = newAsset
+		}
+	}
+
+	for _, output := range v.Outputs {
+		for _, newAsset := range output.Assets {
+			if !newAsset.HasSplitCommitmentWitness() {
+				continue
+			}
+
+			rootAsset := &newAsset.PrevWitnesses[0].SplitCommitment.RootAsset
+			if rootAsset.SplitCommitmentRoot == nil {
+				return ErrSplitRootMismatch
+			}
+
+			committedRoot, ok := roots[rootAsset.SplitCommitmentRoot.NodeHash()]
+			if !ok || !committedRoot.DeepEqual(rootAsset) {
+				return ErrSplitRootMismatch
+			}
+		}
+	}
+
+	return nil
+}
+
+// InputProofs returns the proof files of the inputs spent by the new asset, in
+// the order of the witnesses spending them. A split asset spends the inputs of
+// its root asset.
+func (v *VPacket) InputProofs(newAsset *asset.Asset) ([][]byte, error) {
+	rootAsset := newAsset
+	if newAsset.HasSplitCommitmentWitness() {
+		rootAsset = &newAsset.PrevWitnesses[0].SplitCommitment.RootAsset
+	}
+
+	proofs := make([][]byte, len(rootAsset.PrevWitnesses))
+	for idx, witness := range rootAsset.PrevWitnesses {
+		input, err := v.input(witness.PrevID)
+		if err != nil {
+			return nil, err
+		}
+
+		proofs[idx] = input.Proof
+	}
+
+	return proofs, nil
+}
+
+// spendingAssets returns the new assets spending inputs with witnesses of
+// their own, which are all new assets but the split assets.
+func (v *VPacket) spendingAssets() []*asset.Asset {
+	assets := make([]*asset.Asset, 0)
+	for _, output := range v.Outputs {
+		for _, newAsset := range output.Assets {
+			if !newAsset.HasSplitCommitmentWitness() {
+				assets = append(assets, newAsset)
+			}
+		}
+	}
+
+	return assets
+}
+
+// prevAssets returns the assets spent by the new asset, in the order of its
+// witnesses.
+func (v *VPacket) prevAssets(newAsset *asset.Asset) ([]*asset.Asset, error) {
+	prevAssets := make([]*asset.Asset, len(newAsset.PrevWitnesses))
+	for idx, witness := range newAsset.PrevWitnesses {
+		input, err := v.input(witness.PrevID)
+		if err != nil {
+			return nil, err
+		}
+
+		prevAssets[idx] = input.Asset
+	}
+
+	return prevAssets, nil
+}
+
+// input returns the input with the given previous ID.
+func (v *VPacket) input(prevID *asset.PrevID) (*VInput, error) {
+	if prevID == nil {
+		return nil, ErrUnknownInput
+	}
+
+	for _, input := range v.Inputs {
+		if input.PrevID == *prevID {
+			return input, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrUnknownInput, prevID.OutPoint)
+}
+
+// updateSplitAssets copies the root asset into the split witnesses of its
+// split assets, which have to embed the root asset as committed to.
+func (v *VPacket) updateSplitAssets(rootAsset *asset.Asset) {
+	if rootAsset.SplitCommitmentRoot == nil {
+		return
+	}
+
+	rootHash := rootAsset.SplitCommitmentRoot.NodeHash()
+
+	for _, output := range v.Outputs {
+		for _, newAsset := range output.Assets {
+			if !newAsset.HasSplitCommitmentWitness() {
+				continue
+			}
+
+			splitCommitment := newAsset.PrevWitnesses[0].SplitCommitment
+			splitRoot := splitCommitment.RootAsset.SplitCommitmentRoot
+			if splitRoot != nil && splitRoot.NodeHash() == rootHash {
+				splitCommitment.RootAsset = *rootAsset.Copy()
+			}
+		}
+	}
+}
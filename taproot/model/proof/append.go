@@ -39,12 +39,27 @@ type TransitionParams struct {
 // on-chain output, this function takes the script key of the asset to return
 // the proof for. This method returns both the encoded full provenance (proof
 // chain) and the added latest proof.
+//
+// A transition can spend several inputs, one file per input. The new proof is
+// appended to the first file, whose last snapshot is the previous outpoint of
+// the new proof, and the other files are embedded as additional inputs. All
+// files are verified, and every input has to be spent by exactly one witness
+// of the new asset, so the new proof can be validated back to each of them.
+//
+// The anchor transaction of the new proof isn't confirmed yet, so only the
+// input files are required to be confirmed in the chain of the header
+// verifier.
 func AppendTransition(
 	inputFilesBytes [][]byte,
 	params *TransitionParams,
+	headerVerifier HeaderVerifier,
 ) (*File, *Proof, error) {
 	ctx := context.Background()
 
+	if len(inputFilesBytes) == 0 {
+		return nil, nil, fmt.Errorf("no input proof files")
+	}
+
 	// Decode the proof blob into a proper file structure first.
 	f := File{}
 	if err := f.Decode(inputFilesBytes[0]); err != nil {
@@ -57,24 +72,16 @@ func AppendTransition(
 		return nil, nil, fmt.Errorf("invalid empty proof file")
 	}
 
-	lastProof, err := f.LastProof()
+	// Verify the full provenance of the input first, the new proof has to
+	// spend the last snapshot of the chain.
+	lastSnapshot, err := f.Verify(ctx, headerVerifier)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error fetching last proof: %w",
+		return nil, nil, fmt.Errorf("error verifying proof file: %w",
 			err)
 	}
 
-	_, err = lastProof.Verify(context.TODO(), nil)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error verifying last proof: %w", err)
-	}
-
-	lastPrevOut := wire.OutPoint{
-		Hash:  lastProof.AnchorTx.TxHash(),
-		Index: lastProof.InclusionProof.OutputIndex,
-	}
-
 	// We can now create the new proof entry for the asset in the params.
-	newProof, err := CreateTransitionProof(lastPrevOut, params)
+	newProof, err := CreateTransitionProof(lastSnapshot.OutPoint, params)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error creating transition "+
 			"proof: %w", err)
@@ -90,32 +97,24 @@ func AppendTransition(
 					err)
 			}
 
-			if _, err := fInput.Verify(ctx); err != nil {
-				return nil, nil, fmt.Errorf("error verify file: %w", err)
-			}
-
 			additionalFiles[i] = fInput
 		}
 
 		newProof.AdditionalInputs = additionalFiles
 	}
 
-	log.Println("=========================")
-
-	if _, err := newProof.Verify(ctx, nil); err != nil {
+	// The additional inputs are verified together with the new proof.
+	_, err = newProof.VerifyUnconfirmed(ctx, lastSnapshot, headerVerifier)
+	if err != nil {
 		return nil, nil, fmt.Errorf("error verifying proof new proof: %w", err)
 	}
 
-	// Before we encode and return the proof, we want to validate it. For
-	// that we need to start at the beginning.
+	// The chain up to the new proof was verified above, so the new proof
+	// can be appended as is.
 	if err := f.AppendProof(*newProof); err != nil {
 		return nil, nil, fmt.Errorf("error appending proof: %w", err)
 	}
 
-	if _, err := f.Verify(ctx); err != nil {
-		return nil, nil, fmt.Errorf("error verifying proof: %w", err)
-	}
-
 	return &f, newProof, nil
 }
 
@@ -2,10 +2,18 @@ package proof
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
-	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
 
 	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
 )
 
 // Locator is able to uniquely identify a Proof in the extended Taproot Asset
@@ -42,3 +50,482 @@ func (l *Locator) Hash() ([32]byte, error) {
 	// Hash the buffer.
 	return sha256.Sum256(buf.Bytes()), nil
 }
+
+// Blob is the binary encoding of a proof file.
+type Blob []byte
+
+// AnnotatedProof is a proof file together with the locator it is stored
+// under.
+type AnnotatedProof struct {
+	Locator
+
+	Blob
+}
+
+// NewAnnotatedProof encodes the proof file and annotates it with the locator
+// of the asset of its last proof.
+func NewAnnotatedProof(f *File) (*AnnotatedProof, error) {
+	loc, err := f.Locator()
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := f.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnnotatedProof{
+		Locator: *loc,
+		Blob:    blob,
+	}, nil
+}
+
+// ImportFile imports the proof file into the archive under the locator of the
+// asset of its last proof and returns the hash of the locator.
+func ImportFile(ctx context.Context, archiver Archiver,
+	f *File) ([32]byte, error) {
+
+	annotated, err := NewAnnotatedProof(f)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	if err := archiver.ImportProofs(ctx, annotated); err != nil {
+		return [32]byte{}, err
+	}
+
+	return annotated.Locator.Hash()
+}
+
+// Archiver stores proof files by their locator. Proof files are never removed
+// from an archive, a proof file of a spent asset is still needed to prove the
+// provenance of the assets it was spent into. Spent assets are only marked
+// historical by the owner of the archive, their files stay as they are.
+//
+// A locator can have several revisions of its proof file, e.g. the unconfirmed
+// and the confirmed proof of a transfer. Importing a file for a locator adds it
+// as the latest revision, the earlier revisions are kept.
+type Archiver interface {
+	// FetchProof returns the latest revision of the proof file stored
+	// under the locator, or ErrProofNotFound.
+	FetchProof(ctx context.Context, loc Locator) (Blob, error)
+
+	// FetchProofByHash returns the latest revision of the proof file
+	// stored under the locator with the given hash, or ErrProofNotFound.
+	FetchProofByHash(ctx context.Context, locHash [32]byte) (Blob, error)
+
+	// FetchProofRevisions returns every revision of the proof file stored
+	// under the locator, oldest first, or ErrProofNotFound.
+	FetchProofRevisions(ctx context.Context, loc Locator) ([]Blob, error)
+
+	// HasProof returns true if a proof file is stored under the locator.
+	HasProof(ctx context.Context, loc Locator) (bool, error)
+
+	// ImportProofs adds the proof files as the latest revisions of their
+	// locators. Importing the same file as the latest revision again is a
+	// no-op.
+	ImportProofs(ctx context.Context, proofs ...*AnnotatedProof) error
+}
+
+// FileArchiver is an Archiver that keeps every proof file in its own file
+// below a root directory. Files are sharded into sub directories by the first
+// bytes of their locator hash. The latest revision of a file is named after
+// the locator hash, the earlier revisions get their number appended.
+type FileArchiver struct {
+	// mu serializes imports, so concurrent imports of the same locator
+	// don't number their revisions the same.
+	mu sync.Mutex
+
+	root string
+}
+
+// A compile time check to ensure FileArchiver implements Archiver.
+var _ Archiver = (*FileArchiver)(nil)
+
+// NewFileArchiver creates a new file archiver below root, which is created if
+// it doesn't exist.
+func NewFileArchiver(root string) (*FileArchiver, error) {
+	if err := os.MkdirAll(root, 0750); err != nil {
+		return nil, err
+	}
+
+	return &FileArchiver{
+		root: root,
+	}, nil
+}
+
+// filename returns the name of the file of the locator hash:
+//
+//	root/<hash[0]>/<hash[1]>/<hash>
+func (a *FileArchiver) filename(locHash [32]byte) string {
+	name := hex.EncodeToString(locHash[:])
+
+	return filepath.Join(a.root, name[:2], name[2:4], name)
+}
+
+// revisionFilename returns the name of the file of an earlier revision of the
+// locator hash, numbered from one:
+//
+//	root/<hash[0]>/<hash[1]>/<hash>.<revision>
+func (a *FileArchiver) revisionFilename(locHash [32]byte, revision int) string {
+	return a.filename(locHash) + "." + strconv.Itoa(revision)
+}
+
+// FetchProof returns the proof file stored under the locator.
+func (a *FileArchiver) FetchProof(ctx context.Context, loc Locator) (Blob,
+	error) {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	return a.FetchProofByHash(ctx, locHash)
+}
+
+// FetchProofByHash returns the proof file stored under the locator hash.
+func (a *FileArchiver) FetchProofByHash(_ context.Context,
+	locHash [32]byte) (Blob, error) {
+
+	blob, err := os.ReadFile(a.filename(locHash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrProofNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return blob, nil
+}
+
+// FetchProofRevisions returns the earlier revisions of the proof file of the
+// locator followed by the latest one.
+func (a *FileArchiver) FetchProofRevisions(ctx context.Context,
+	loc Locator) ([]Blob, error) {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := a.FetchProofByHash(ctx, locHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []Blob
+	for revision := 1; ; revision++ {
+		blob, err := os.ReadFile(a.revisionFilename(locHash, revision))
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, blob)
+	}
+
+	return append(revisions, latest), nil
+}
+
+// HasProof returns true if a file exists for the locator.
+func (a *FileArchiver) HasProof(_ context.Context, loc Locator) (bool, error) {
+	locHash, err := loc.Hash()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(a.filename(locHash))
+	switch {
+	case err == nil:
+		return true, nil
+
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+
+	default:
+		return false, err
+	}
+}
+
+// ImportProofs writes the proof files. The previous revision of a file is
+// copied to the next free revision number before the file is replaced. Each
+// file is written atomically, so a reader either sees the previous or the new
+// file.
+func (a *FileArchiver) ImportProofs(_ context.Context,
+	proofs ...*AnnotatedProof) error {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, p := range proofs {
+		locHash, err := p.Locator.Hash()
+		if err != nil {
+			return err
+		}
+
+		if err := a.keepRevision(locHash, p.Blob); err != nil {
+			return err
+		}
+
+		if err := writeFileAtomic(a.filename(locHash), p.Blob); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keepRevision copies the latest revision of the file of the locator hash to
+// the next free revision number, unless there is no file yet or it already is
+// the given blob.
+func (a *FileArchiver) keepRevision(locHash [32]byte, blob Blob) error {
+	latest, err := os.ReadFile(a.filename(locHash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(latest, blob) {
+		return nil
+	}
+
+	revision := 1
+	for {
+		_, err := os.Stat(a.revisionFilename(locHash, revision))
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		revision++
+	}
+
+	return writeFileAtomic(a.revisionFilename(locHash, revision), latest)
+}
+
+// writeFileAtomic writes the data to a temporary file next to the file and
+// renames it to the file once it was flushed to disk.
+func writeFileAtomic(filename string, data []byte) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmpFile.Name()
+
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, filename)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+
+		return err
+	}
+
+	return nil
+}
+
+// MemArchiver is an in-memory Archiver.
+type MemArchiver struct {
+	mu sync.RWMutex
+
+	// proofs are the revisions of the proof files by locator hash, oldest
+	// first.
+	proofs map[[32]byte][]Blob
+}
+
+// A compile time check to ensure MemArchiver implements Archiver.
+var _ Archiver = (*MemArchiver)(nil)
+
+// NewMemArchiver creates a new empty in-memory archiver.
+func NewMemArchiver() *MemArchiver {
+	return &MemArchiver{
+		proofs: make(map[[32]byte][]Blob),
+	}
+}
+
+// FetchProof returns the proof file stored under the locator.
+func (a *MemArchiver) FetchProof(ctx context.Context, loc Locator) (Blob,
+	error) {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	return a.FetchProofByHash(ctx, locHash)
+}
+
+// FetchProofByHash returns the proof file stored under the locator hash.
+func (a *MemArchiver) FetchProofByHash(_ context.Context,
+	locHash [32]byte) (Blob, error) {
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	revisions, ok := a.proofs[locHash]
+	if !ok {
+		return nil, ErrProofNotFound
+	}
+
+	return bytes.Clone(revisions[len(revisions)-1]), nil
+}
+
+// FetchProofRevisions returns copies of the revisions of the proof file of
+// the locator.
+func (a *MemArchiver) FetchProofRevisions(_ context.Context,
+	loc Locator) ([]Blob, error) {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	revisions, ok := a.proofs[locHash]
+	if !ok {
+		return nil, ErrProofNotFound
+	}
+
+	blobs := make([]Blob, len(revisions))
+	for i, blob := range revisions {
+		blobs[i] = bytes.Clone(blob)
+	}
+
+	return blobs, nil
+}
+
+// HasProof returns true if a proof file is stored under the locator.
+func (a *MemArchiver) HasProof(_ context.Context, loc Locator) (bool, error) {
+	locHash, err := loc.Hash()
+	if err != nil {
+		return false, err
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, ok := a.proofs[locHash]
+
+	return ok, nil
+}
+
+// ImportProofs appends copies of the proof files to their revisions.
+func (a *MemArchiver) ImportProofs(_ context.Context,
+	proofs ...*AnnotatedProof) error {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, p := range proofs {
+		locHash, err := p.Locator.Hash()
+		if err != nil {
+			return err
+		}
+
+		revisions := a.proofs[locHash]
+		if len(revisions) > 0 &&
+			bytes.Equal(revisions[len(revisions)-1], p.Blob) {
+
+			continue
+		}
+
+		a.proofs[locHash] = append(revisions, bytes.Clone(p.Blob))
+	}
+
+	return nil
+}
+
+// ImportLocatorDir imports the proof files of a flat directory of files named
+// after their locator hash, like the ./locator directory proof files used to be
+// written to, into the archive. Legacy JSON files are migrated to the binary
+// encoding on the way. Files the archive already has a proof for are skipped,
+// and the directory itself is left untouched. Files that aren't valid proof
+// files are skipped and returned, so a single corrupt file doesn't keep the
+// other files from being imported. The number of imported files is returned.
+func ImportLocatorDir(ctx context.Context, dir string,
+	archiver Archiver) (int, []SkippedFile, error) {
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var (
+		numImported int
+		skipped     []SkippedFile
+	)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		filename := filepath.Join(dir, entry.Name())
+		fileBytes, err := os.ReadFile(filename)
+		if err != nil {
+			return numImported, skipped, err
+		}
+
+		annotated, err := decodeAnnotatedProof(fileBytes)
+		if err != nil {
+			skipped = append(skipped, SkippedFile{
+				Name: filename,
+				Err:  err,
+			})
+
+			continue
+		}
+
+		hasProof, err := archiver.HasProof(ctx, annotated.Locator)
+		if err != nil {
+			return numImported, skipped, err
+		}
+		if hasProof {
+			continue
+		}
+
+		if err := archiver.ImportProofs(ctx, annotated); err != nil {
+			return numImported, skipped, fmt.Errorf("unable to "+
+				"import %v: %w", filename, err)
+		}
+
+		numImported++
+	}
+
+	return numImported, skipped, nil
+}
+
+// decodeAnnotatedProof decodes a proof file, in the binary or the legacy JSON
+// encoding, and annotates it with the locator of its last proof.
+func decodeAnnotatedProof(fileBytes []byte) (*AnnotatedProof, error) {
+	var f File
+	if err := f.Decode(fileBytes); err != nil {
+		return nil, err
+	}
+
+	return NewAnnotatedProof(&f)
+}
@@ -0,0 +1,135 @@
+package proof
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/stretchr/testify/require"
+)
+
+// TestArchivers makes sure imported proof files can be fetched by their
+// locator and locator hash, and that importing a file again adds a revision
+// while the earlier revisions are kept.
+func TestArchivers(t *testing.T) {
+	ctx := context.Background()
+
+	loc := Locator{
+		AssetID:   &asset.ID{1},
+		ScriptKey: asset.SerializedKey{2},
+		OutPoint:  &wire.OutPoint{Index: 1},
+	}
+	locHash, err := loc.Hash()
+	require.NoError(t, err)
+
+	fileArchiver, err := NewFileArchiver(t.TempDir())
+	require.NoError(t, err)
+
+	archivers := map[string]Archiver{
+		"file": fileArchiver,
+		"mem":  NewMemArchiver(),
+	}
+
+	for name, archiver := range archivers {
+		t.Run(name, func(t *testing.T) {
+			hasProof, err := archiver.HasProof(ctx, loc)
+			require.NoError(t, err)
+			require.False(t, hasProof)
+
+			_, err = archiver.FetchProof(ctx, loc)
+			require.ErrorIs(t, err, ErrProofNotFound)
+
+			_, err = archiver.FetchProofRevisions(ctx, loc)
+			require.ErrorIs(t, err, ErrProofNotFound)
+
+			// The second file is imported twice, which must not add
+			// a revision.
+			blobs := []Blob{{1, 2}, {3, 4, 5}, {3, 4, 5}}
+			for _, blob := range blobs {
+				err := archiver.ImportProofs(ctx, &AnnotatedProof{
+					Locator: loc,
+					Blob:    blob,
+				})
+				require.NoError(t, err)
+
+				fetched, err := archiver.FetchProof(ctx, loc)
+				require.NoError(t, err)
+				require.Equal(t, blob, fetched)
+
+				fetched, err = archiver.FetchProofByHash(ctx, locHash)
+				require.NoError(t, err)
+				require.Equal(t, blob, fetched)
+			}
+
+			hasProof, err = archiver.HasProof(ctx, loc)
+			require.NoError(t, err)
+			require.True(t, hasProof)
+
+			revisions, err := archiver.FetchProofRevisions(ctx, loc)
+			require.NoError(t, err)
+			require.Equal(t, blobs[:2], revisions)
+
+			err = archiver.ImportProofs(ctx, &AnnotatedProof{
+				Locator: loc,
+				Blob:    blobs[0],
+			})
+			require.NoError(t, err)
+
+			revisions, err = archiver.FetchProofRevisions(ctx, loc)
+			require.NoError(t, err)
+			require.Equal(t, []Blob{{1, 2}, {3, 4, 5}, {1, 2}}, revisions)
+		})
+	}
+}
+
+// TestImportLocatorDir makes sure the valid files of a legacy locator
+// directory are imported while invalid ones are skipped and reported.
+func TestImportLocatorDir(t *testing.T) {
+	ctx := context.Background()
+
+	legacyBytes, err := os.ReadFile("testdata/legacy-file.json")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"valid":     legacyBytes,
+		"truncated": legacyBytes[:len(legacyBytes)/2],
+		"empty":     []byte("{}"),
+		"garbage":   {1, 2, 3},
+	}
+	for name, fileBytes := range files {
+		err := os.WriteFile(filepath.Join(dir, name), fileBytes, 0600)
+		require.NoError(t, err)
+	}
+
+	archiver := NewMemArchiver()
+
+	numImported, skipped, err := ImportLocatorDir(ctx, dir, archiver)
+	require.NoError(t, err)
+	require.Equal(t, 1, numImported)
+	require.Len(t, skipped, 3)
+
+	for _, f := range skipped {
+		require.NotEqual(t, filepath.Join(dir, "valid"), f.Name)
+		require.Contains(t, files, filepath.Base(f.Name))
+		require.Error(t, f.Err)
+	}
+
+	var f File
+	require.NoError(t, f.Decode(legacyBytes))
+
+	annotated, err := NewAnnotatedProof(&f)
+	require.NoError(t, err)
+
+	blob, err := archiver.FetchProof(ctx, annotated.Locator)
+	require.NoError(t, err)
+	require.Equal(t, annotated.Blob, blob)
+
+	// Files the archive already has are skipped on the next import.
+	numImported, _, err = ImportLocatorDir(ctx, dir, archiver)
+	require.NoError(t, err)
+	require.Zero(t, numImported)
+}
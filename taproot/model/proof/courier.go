@@ -0,0 +1,258 @@
+package proof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	// ErrProofNotFound is returned by a courier that has no proof for the
+	// requested locator.
+	ErrProofNotFound = errors.New("proof not found")
+
+	// ErrInvalidDelivery is returned by a courier that rejects a delivered
+	// proof file, because it doesn't verify or doesn't prove the asset of
+	// the locator it was delivered under.
+	ErrInvalidDelivery = errors.New("invalid proof delivery")
+)
+
+// ProofCourier delivers the proof file of an asset to its receiver. The sender
+// delivers the file under the locator of the received asset, and the receiver
+// picks it up with the same locator.
+type ProofCourier interface {
+	// DeliverProof hands over the proof file of the asset identified by
+	// the locator.
+	DeliverProof(ctx context.Context, loc Locator, f *File) error
+
+	// ReceiveProof fetches the proof file of the asset identified by the
+	// locator. ErrProofNotFound is returned if no file was delivered for
+	// the locator.
+	ReceiveProof(ctx context.Context, loc Locator) (*File, error)
+}
+
+// FileCourier is a ProofCourier that keeps the proof files in a directory,
+// named after the hash of their locator.
+type FileCourier struct {
+	dir string
+}
+
+// A compile time check to ensure FileCourier implements ProofCourier.
+var _ ProofCourier = (*FileCourier)(nil)
+
+// NewFileCourier creates a new courier that keeps proof files in dir.
+func NewFileCourier(dir string) *FileCourier {
+	return &FileCourier{
+		dir: dir,
+	}
+}
+
+// filename returns the name of the proof file of the locator.
+func (c *FileCourier) filename(loc Locator) (string, error) {
+	locHash, err := loc.Hash()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(c.dir, fmt.Sprintf("%x", locHash)), nil
+}
+
+// DeliverProof writes the proof file into the directory of the courier.
+func (c *FileCourier) DeliverProof(_ context.Context, loc Locator,
+	f *File) error {
+
+	filename, err := c.filename(loc)
+	if err != nil {
+		return err
+	}
+
+	fileBytes, err := f.Encode()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0750); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, fileBytes, 0666)
+}
+
+// ReceiveProof reads the proof file from the directory of the courier.
+func (c *FileCourier) ReceiveProof(_ context.Context, loc Locator) (*File,
+	error) {
+
+	filename, err := c.filename(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	fileBytes, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrProofNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	if err := f.Decode(fileBytes); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// MemCourier is an in-memory ProofCourier.
+type MemCourier struct {
+	mu     sync.Mutex
+	proofs map[[32]byte][]byte
+}
+
+// A compile time check to ensure MemCourier implements ProofCourier.
+var _ ProofCourier = (*MemCourier)(nil)
+
+// NewMemCourier creates a new empty in-memory courier.
+func NewMemCourier() *MemCourier {
+	return &MemCourier{
+		proofs: make(map[[32]byte][]byte),
+	}
+}
+
+// DeliverProof stores the encoded proof file in memory.
+func (c *MemCourier) DeliverProof(_ context.Context, loc Locator,
+	f *File) error {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return err
+	}
+
+	fileBytes, err := f.Encode()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.proofs[locHash] = fileBytes
+
+	return nil
+}
+
+// ReceiveProof decodes the proof file stored for the locator.
+func (c *MemCourier) ReceiveProof(_ context.Context, loc Locator) (*File,
+	error) {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	fileBytes, ok := c.proofs[locHash]
+	c.mu.Unlock()
+
+	if !ok {
+		return nil, ErrProofNotFound
+	}
+
+	var f File
+	if err := f.Decode(fileBytes); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// ArchiveCourier is a ProofCourier that receives proof files from the archive
+// the proofs of all other assets are kept in. Delivered files are verified and
+// kept in a separate archive, so a delivery never replaces a file of the main
+// archive, and are only received if the main archive has no file for their
+// locator.
+type ArchiveCourier struct {
+	archiver       Archiver
+	deliveries     Archiver
+	headerVerifier HeaderVerifier
+}
+
+// A compile time check to ensure ArchiveCourier implements ProofCourier.
+var _ ProofCourier = (*ArchiveCourier)(nil)
+
+// NewArchiveCourier creates a new courier backed by the archive, keeping the
+// delivered files in the deliveries archive.
+func NewArchiveCourier(archiver, deliveries Archiver,
+	headerVerifier HeaderVerifier) *ArchiveCourier {
+
+	return &ArchiveCourier{
+		archiver:       archiver,
+		deliveries:     deliveries,
+		headerVerifier: headerVerifier,
+	}
+}
+
+// DeliverProof verifies the proof file and imports it into the deliveries
+// archive. The anchor transaction of the last proof doesn't have to be
+// confirmed yet, but the file has to prove the asset of the locator.
+func (c *ArchiveCourier) DeliverProof(ctx context.Context, loc Locator,
+	f *File) error {
+
+	locHash, err := loc.Hash()
+	if err != nil {
+		return err
+	}
+
+	fileLoc, err := f.Locator()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDelivery, err)
+	}
+
+	fileLocHash, err := fileLoc.Hash()
+	if err != nil {
+		return err
+	}
+
+	if fileLocHash != locHash {
+		return fmt.Errorf("%w: file proves another asset than the "+
+			"locator", ErrInvalidDelivery)
+	}
+
+	if _, err := f.VerifyUnconfirmed(ctx, c.headerVerifier); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDelivery, err)
+	}
+
+	blob, err := f.Encode()
+	if err != nil {
+		return err
+	}
+
+	return c.deliveries.ImportProofs(ctx, &AnnotatedProof{
+		Locator: loc,
+		Blob:    blob,
+	})
+}
+
+// ReceiveProof fetches the proof file from the archive, or from the
+// deliveries if the archive has no file for the locator.
+func (c *ArchiveCourier) ReceiveProof(ctx context.Context, loc Locator) (*File,
+	error) {
+
+	blob, err := c.archiver.FetchProof(ctx, loc)
+	if errors.Is(err, ErrProofNotFound) {
+		blob, err = c.deliveries.FetchProof(ctx, loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	if err := f.Decode(blob); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
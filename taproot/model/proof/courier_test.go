@@ -0,0 +1,146 @@
+package proof
+
+import (
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestProofCouriers makes sure a delivered proof file can only be received
+// with the locator it was delivered under.
+func TestProofCouriers(t *testing.T) {
+	ctx := context.Background()
+
+	proofBytes := []byte{1, 2, 3}
+	file := &File{
+		Version: FileVersionV0,
+		Proofs: []*HashedProof{{
+			ProofBytes: proofBytes,
+			Hash:       hashProof(proofBytes, [32]byte{}),
+		}},
+	}
+
+	loc := Locator{
+		AssetID:   &asset.ID{1},
+		ScriptKey: asset.SerializedKey{2},
+		OutPoint:  &wire.OutPoint{Index: 1},
+	}
+	otherLoc := loc
+	otherLoc.OutPoint = &wire.OutPoint{Index: 2}
+
+	couriers := map[string]ProofCourier{
+		"file": NewFileCourier(t.TempDir()),
+		"mem":  NewMemCourier(),
+	}
+
+	for name, courier := range couriers {
+		t.Run(name, func(t *testing.T) {
+			_, err := courier.ReceiveProof(ctx, loc)
+			require.ErrorIs(t, err, ErrProofNotFound)
+
+			require.NoError(t, courier.DeliverProof(ctx, loc, file))
+
+			received, err := courier.ReceiveProof(ctx, loc)
+			require.NoError(t, err)
+			require.Equal(t, file, received)
+
+			_, err = courier.ReceiveProof(ctx, otherLoc)
+			require.ErrorIs(t, err, ErrProofNotFound)
+		})
+	}
+}
+
+// genesisFile returns the proof file of a newly minted asset whose minting
+// transaction isn't confirmed yet.
+func genesisFile(t *testing.T) *File {
+	t.Helper()
+
+	owner, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	var (
+		genesisOut = wire.OutPoint{Hash: chainhash.Hash{1}}
+		a          = asset.New(
+			genesisOut, "ticker", 0, 100,
+			asset.ToSerialized(owner.PubKey()), nil,
+		)
+	)
+
+	proofs, err := NewMintingBlobs(zap.NewNop(), &MintParams{
+		BaseProofParams: anchorParams(t, genesisOut, a),
+		GenesisPoint:    genesisOut,
+	})
+	require.NoError(t, err)
+
+	f, err := NewFile(*proofs[0])
+	require.NoError(t, err)
+
+	return f
+}
+
+// TestArchiveCourier makes sure only verified files proving the asset of
+// their locator are delivered, and that deliveries never replace the files
+// of the main archive.
+func TestArchiveCourier(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		archiver   = NewMemArchiver()
+		deliveries = NewMemArchiver()
+		courier    = NewArchiveCourier(
+			archiver, deliveries, NewMemHeaderVerifier(),
+		)
+		file = genesisFile(t)
+	)
+
+	loc, err := file.Locator()
+	require.NoError(t, err)
+
+	otherLoc := *loc
+	otherLoc.OutPoint = &wire.OutPoint{Index: 9}
+
+	err = courier.DeliverProof(ctx, otherLoc, file)
+	require.ErrorIs(t, err, ErrInvalidDelivery)
+
+	tampered := genesisFile(t)
+	lastProof, err := tampered.LastProof()
+	require.NoError(t, err)
+	lastProof.Asset.Amount++
+	require.NoError(t, tampered.ReplaceLastProof(*lastProof))
+
+	tamperedLoc, err := tampered.Locator()
+	require.NoError(t, err)
+
+	err = courier.DeliverProof(ctx, *tamperedLoc, tampered)
+	require.ErrorIs(t, err, ErrInvalidDelivery)
+
+	_, err = courier.ReceiveProof(ctx, *loc)
+	require.ErrorIs(t, err, ErrProofNotFound)
+
+	// A valid delivery is received while the main archive has no file for
+	// the locator.
+	require.NoError(t, courier.DeliverProof(ctx, *loc, file))
+
+	received, err := courier.ReceiveProof(ctx, *loc)
+	require.NoError(t, err)
+	require.Equal(t, file, received)
+
+	hasProof, err := archiver.HasProof(ctx, *loc)
+	require.NoError(t, err)
+	require.False(t, hasProof)
+
+	// The file of the main archive takes precedence over deliveries.
+	archived := &AnnotatedProof{Locator: *loc, Blob: Blob{1, 2, 3}}
+	require.NoError(t, archiver.ImportProofs(ctx, archived))
+	require.NoError(t, courier.DeliverProof(ctx, *loc, file))
+
+	blob, err := archiver.FetchProof(ctx, *loc)
+	require.NoError(t, err)
+	require.Equal(t, archived.Blob, blob)
+}
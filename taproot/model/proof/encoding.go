@@ -0,0 +1,395 @@
+package proof
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/quocky/taproot-asset/taproot/model/tlv"
+)
+
+// The TLV types of the proof encoding.
+const (
+	tapscriptProofBip86Type tlv.Type = 0
+
+	taprootProofOutputIndexType     tlv.Type = 0
+	taprootProofInternalKeyType     tlv.Type = 2
+	taprootProofCommitmentProofType tlv.Type = 4
+	taprootProofTapscriptProofType  tlv.Type = 6
+
+	proofPrevOutType          tlv.Type = 0
+	proofAnchorTxType         tlv.Type = 2
+	proofAssetType            tlv.Type = 4
+	proofInclusionProofType   tlv.Type = 6
+	proofExclusionProofsType  tlv.Type = 8
+	proofSplitRootProofType   tlv.Type = 10
+	proofAdditionalInputsType tlv.Type = 12
+	proofGenesisRevealType    tlv.Type = 14
+	proofBlockHeaderType      tlv.Type = 16
+	proofBlockHeightType      tlv.Type = 18
+	proofTxMerkleProofType    tlv.Type = 20
+	proofMetaRevealType       tlv.Type = 22
+)
+
+// Encode returns the TLV encoding of the tapscript proof.
+func (p *TapscriptProof) Encode() ([]byte, error) {
+	return tlv.Encode(
+		tlv.NewRecord(tapscriptProofBip86Type, tlv.Bool(p.Bip86)),
+	)
+}
+
+// Decode decodes the TLV encoded tapscript proof within blob.
+func (p *TapscriptProof) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{tapscriptProofBip86Type})
+	if err != nil {
+		return fmt.Errorf("tapscript proof: %w", err)
+	}
+
+	p.Bip86, err = tlv.ReadBool(stream[tapscriptProofBip86Type])
+
+	return err
+}
+
+// Encode returns the TLV encoding of the taproot proof.
+func (p *TaprootProof) Encode() ([]byte, error) {
+	records := []tlv.Record{
+		tlv.NewRecord(
+			taprootProofOutputIndexType, tlv.Uint32(p.OutputIndex),
+		),
+		tlv.NewRecord(taprootProofInternalKeyType, p.InternalKey[:]),
+	}
+
+	if p.CommitmentProof != nil {
+		commitmentProofBytes, err := p.CommitmentProof.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, tlv.NewRecord(
+			taprootProofCommitmentProofType, commitmentProofBytes,
+		))
+	}
+
+	if p.TapscriptProof != nil {
+		tapscriptProofBytes, err := p.TapscriptProof.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, tlv.NewRecord(
+			taprootProofTapscriptProofType, tapscriptProofBytes,
+		))
+	}
+
+	return tlv.Encode(records...)
+}
+
+// Decode decodes the TLV encoded taproot proof within blob.
+func (p *TaprootProof) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		taprootProofOutputIndexType, taprootProofInternalKeyType,
+	}, taprootProofCommitmentProofType, taprootProofTapscriptProofType)
+	if err != nil {
+		return fmt.Errorf("taproot proof: %w", err)
+	}
+
+	p.OutputIndex, err = tlv.ReadUint32(stream[taprootProofOutputIndexType])
+	if err != nil {
+		return err
+	}
+
+	err = tlv.ReadFixed(
+		p.InternalKey[:], stream[taprootProofInternalKeyType],
+	)
+	if err != nil {
+		return err
+	}
+
+	p.CommitmentProof = nil
+	if b, ok := stream[taprootProofCommitmentProofType]; ok {
+		p.CommitmentProof = &commitment.CommitmentProof{}
+		if err := p.CommitmentProof.Decode(b); err != nil {
+			return err
+		}
+	}
+
+	p.TapscriptProof = nil
+	if b, ok := stream[taprootProofTapscriptProofType]; ok {
+		p.TapscriptProof = &TapscriptProof{}
+		if err := p.TapscriptProof.Decode(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Encode returns the canonical TLV encoding of the proof. These are the bytes
+// the chained hash of a proof file commits to.
+func (p *Proof) Encode() ([]byte, error) {
+	var txBytes bytes.Buffer
+	if err := p.AnchorTx.Serialize(&txBytes); err != nil {
+		return nil, err
+	}
+
+	assetBytes, err := p.Asset.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	inclusionProofBytes, err := p.InclusionProof.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	exclusionProofs := make([][]byte, len(p.ExclusionProofs))
+	for idx := range p.ExclusionProofs {
+		exclusionProofs[idx], err = p.ExclusionProofs[idx].Encode()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	records := []tlv.Record{
+		tlv.NewRecord(proofPrevOutType, tlv.OutPoint(&p.PrevOut)),
+		tlv.NewRecord(proofAnchorTxType, txBytes.Bytes()),
+		tlv.NewRecord(proofAssetType, assetBytes),
+		tlv.NewRecord(proofInclusionProofType, inclusionProofBytes),
+		tlv.NewRecord(
+			proofExclusionProofsType, tlv.List(exclusionProofs),
+		),
+	}
+
+	if p.SplitRootProof != nil {
+		splitRootProofBytes, err := p.SplitRootProof.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, tlv.NewRecord(
+			proofSplitRootProofType, splitRootProofBytes,
+		))
+	}
+
+	if len(p.AdditionalInputs) > 0 {
+		additionalInputs := make([][]byte, len(p.AdditionalInputs))
+		for idx := range p.AdditionalInputs {
+			additionalInputs[idx], err = p.AdditionalInputs[idx].Encode()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		records = append(records, tlv.NewRecord(
+			proofAdditionalInputsType, tlv.List(additionalInputs),
+		))
+	}
+
+	if p.GenesisReveal != nil {
+		genesisBytes, err := p.GenesisReveal.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, tlv.NewRecord(
+			proofGenesisRevealType, genesisBytes,
+		))
+	}
+
+	// The block of the anchor transaction is only known once it is
+	// confirmed, so the chain fields are either all present or all absent.
+	if p.IsConfirmed() {
+		var headerBytes bytes.Buffer
+		if err := p.BlockHeader.Serialize(&headerBytes); err != nil {
+			return nil, err
+		}
+
+		txMerkleProofBytes, err := p.TxMerkleProof.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records,
+			tlv.NewRecord(proofBlockHeaderType, headerBytes.Bytes()),
+			tlv.NewRecord(
+				proofBlockHeightType, tlv.Uint32(p.BlockHeight),
+			),
+			tlv.NewRecord(proofTxMerkleProofType, txMerkleProofBytes),
+		)
+	}
+
+	// The meta reveal has the highest type, so it comes after the chain
+	// fields.
+	if p.MetaReveal != nil {
+		metaRevealBytes, err := p.MetaReveal.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, tlv.NewRecord(
+			proofMetaRevealType, metaRevealBytes,
+		))
+	}
+
+	return tlv.Encode(records...)
+}
+
+// Decode decodes the TLV encoded proof within blob.
+func (p *Proof) Decode(blob []byte) error {
+	stream, err := tlv.DecodeKnown(blob, []tlv.Type{
+		proofPrevOutType, proofAnchorTxType, proofAssetType,
+		proofInclusionProofType, proofExclusionProofsType,
+	}, proofSplitRootProofType, proofAdditionalInputsType,
+		proofGenesisRevealType, proofBlockHeaderType, proofBlockHeightType,
+		proofTxMerkleProofType, proofMetaRevealType)
+	if err != nil {
+		return fmt.Errorf("proof: %w", err)
+	}
+
+	p.PrevOut, err = tlv.ReadOutPoint(stream[proofPrevOutType])
+	if err != nil {
+		return err
+	}
+
+	p.AnchorTx = wire.MsgTx{}
+	err = p.AnchorTx.Deserialize(bytes.NewReader(stream[proofAnchorTxType]))
+	if err != nil {
+		return err
+	}
+
+	if err := p.Asset.Decode(stream[proofAssetType]); err != nil {
+		return err
+	}
+
+	if err := p.InclusionProof.Decode(stream[proofInclusionProofType]); err != nil {
+		return err
+	}
+
+	exclusionProofs, err := tlv.ReadList(stream[proofExclusionProofsType])
+	if err != nil {
+		return err
+	}
+
+	p.ExclusionProofs = make([]*TaprootProof, len(exclusionProofs))
+	for idx := range exclusionProofs {
+		p.ExclusionProofs[idx] = &TaprootProof{}
+		err := p.ExclusionProofs[idx].Decode(exclusionProofs[idx])
+		if err != nil {
+			return err
+		}
+	}
+
+	p.SplitRootProof = nil
+	if b, ok := stream[proofSplitRootProofType]; ok {
+		p.SplitRootProof = &TaprootProof{}
+		if err := p.SplitRootProof.Decode(b); err != nil {
+			return err
+		}
+	}
+
+	p.AdditionalInputs = nil
+	if b, ok := stream[proofAdditionalInputsType]; ok {
+		additionalInputs, err := tlv.ReadList(b)
+		if err != nil {
+			return err
+		}
+
+		p.AdditionalInputs = make([]File, len(additionalInputs))
+		for idx := range additionalInputs {
+			err := p.AdditionalInputs[idx].Decode(additionalInputs[idx])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	p.GenesisReveal = nil
+	if b, ok := stream[proofGenesisRevealType]; ok {
+		p.GenesisReveal = &asset.Genesis{}
+		if err := p.GenesisReveal.Decode(b); err != nil {
+			return err
+		}
+	}
+
+	p.MetaReveal = nil
+	if b, ok := stream[proofMetaRevealType]; ok {
+		p.MetaReveal = &asset.MetaReveal{}
+		if err := p.MetaReveal.Decode(b); err != nil {
+			return err
+		}
+	}
+
+	p.BlockHeader = wire.BlockHeader{}
+	p.BlockHeight = 0
+	p.TxMerkleProof = TxMerkleProof{}
+	if b, ok := stream[proofBlockHeaderType]; ok {
+		err := stream.Require(
+			proofBlockHeightType, proofTxMerkleProofType,
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(b) != wire.MaxBlockHeaderPayload {
+			return tlv.ErrInvalidLength
+		}
+
+		err = p.BlockHeader.Deserialize(bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+
+		p.BlockHeight, err = tlv.ReadUint32(stream[proofBlockHeightType])
+		if err != nil {
+			return err
+		}
+
+		err = p.TxMerkleProof.Decode(stream[proofTxMerkleProofType])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// legacyProof is the JSON representation of a proof written before the binary
+// encoding was introduced. The distinct type drops the JSON methods of Proof so
+// the default struct decoding is used.
+type legacyProof Proof
+
+// MarshalJSON encodes the proof as its binary TLV encoding, so proofs that are
+// sent over the HTTP API are exactly the bytes a proof file commits to.
+func (p Proof) MarshalJSON() ([]byte, error) {
+	proofBytes, err := p.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(proofBytes)
+}
+
+// UnmarshalJSON decodes a proof encoded with MarshalJSON. For backwards
+// compatibility, the legacy JSON object representation is accepted as well.
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	if isJSONObject(data) {
+		return json.Unmarshal(data, (*legacyProof)(p))
+	}
+
+	var proofBytes []byte
+	if err := json.Unmarshal(data, &proofBytes); err != nil {
+		return err
+	}
+
+	return p.Decode(proofBytes)
+}
+
+// isJSONObject returns true if the given JSON value is an object.
+func isJSONObject(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
@@ -0,0 +1,146 @@
+package proof
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLegacyFileMigration makes sure a proof file in the legacy JSON format is
+// decoded, migrated to the binary encoding and survives an encoding round trip.
+func TestLegacyFileMigration(t *testing.T) {
+	legacyBytes, err := os.ReadFile("testdata/legacy-file.json")
+	require.NoError(t, err)
+	require.True(t, IsLegacyFile(legacyBytes))
+
+	var f File
+	require.NoError(t, f.Decode(legacyBytes))
+	require.Len(t, f.Proofs, 3)
+
+	fileBytes, err := f.Encode()
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(fileBytes, FileMagic[:]))
+	require.False(t, IsLegacyFile(fileBytes))
+
+	var decoded File
+	require.NoError(t, decoded.Decode(fileBytes))
+	require.Equal(t, f, decoded)
+
+	// Every proof must re-encode to exactly the bytes the chained hash
+	// commits to.
+	for idx := range decoded.Proofs {
+		p, err := decoded.ProofAt(uint32(idx))
+		require.NoError(t, err)
+
+		proofBytes, err := p.Encode()
+		require.NoError(t, err)
+		require.Equal(t, decoded.Proofs[idx].ProofBytes, proofBytes)
+	}
+
+	// The JSON representation used by the HTTP API wraps the binary
+	// encoding.
+	jsonBytes, err := json.Marshal(&decoded)
+	require.NoError(t, err)
+
+	var fromJSON File
+	require.NoError(t, json.Unmarshal(jsonBytes, &fromJSON))
+	require.Equal(t, decoded, fromJSON)
+}
+
+// TestMigrateLegacyFiles makes sure legacy files are rewritten in the binary
+// encoding, while corrupt files are skipped and left untouched.
+func TestMigrateLegacyFiles(t *testing.T) {
+	legacyBytes, err := os.ReadFile("testdata/legacy-file.json")
+	require.NoError(t, err)
+
+	var (
+		dir          = t.TempDir()
+		validFile    = filepath.Join(dir, "valid")
+		corruptFile  = filepath.Join(dir, "corrupt")
+		corruptBytes = legacyBytes[:len(legacyBytes)/2]
+	)
+	require.NoError(t, os.WriteFile(validFile, legacyBytes, 0600))
+	require.NoError(t, os.WriteFile(corruptFile, corruptBytes, 0600))
+
+	numMigrated, skipped, err := MigrateLegacyFiles(dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, numMigrated)
+	require.Len(t, skipped, 1)
+	require.Equal(t, corruptFile, skipped[0].Name)
+	require.ErrorIs(t, skipped[0].Err, ErrInvalidFileFormat)
+
+	fileBytes, err := os.ReadFile(validFile)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(fileBytes, FileMagic[:]))
+
+	fileBytes, err = os.ReadFile(corruptFile)
+	require.NoError(t, err)
+	require.Equal(t, corruptBytes, fileBytes)
+}
+
+// TestFileDecodeInvalidHash makes sure a tampered proof is detected while
+// decoding.
+func TestFileDecodeInvalidHash(t *testing.T) {
+	legacyBytes, err := os.ReadFile("testdata/legacy-file.json")
+	require.NoError(t, err)
+
+	var f File
+	require.NoError(t, f.Decode(legacyBytes))
+
+	f.Proofs[1].ProofBytes[0] ^= 1
+
+	fileBytes, err := f.Encode()
+	require.NoError(t, err)
+
+	var decoded File
+	require.ErrorIs(t, decoded.Decode(fileBytes), ErrInvalidProofHash)
+
+	fileBytes[len(FileMagic)] = 1
+	require.ErrorIs(t, decoded.Decode(fileBytes), ErrUnknownFileVersion)
+}
+
+// TestConfirmedGenesisProofWithMeta makes sure a confirmed genesis proof that
+// reveals the metadata of its asset survives an encoding round trip.
+func TestConfirmedGenesisProofWithMeta(t *testing.T) {
+	legacyBytes, err := os.ReadFile("testdata/legacy-file.json")
+	require.NoError(t, err)
+
+	var f File
+	require.NoError(t, f.Decode(legacyBytes))
+
+	p, err := f.ProofAt(0)
+	require.NoError(t, err)
+	require.NotNil(t, p.GenesisReveal)
+
+	p.MetaReveal = &asset.MetaReveal{
+		Type: asset.MetaOpaque,
+		Data: []byte("metadata"),
+	}
+
+	block := &wire.MsgBlock{
+		Header:       wire.BlockHeader{Version: 1, Nonce: 1},
+		Transactions: []*wire.MsgTx{&p.AnchorTx},
+	}
+	require.NoError(t, p.SetBlockInfo(block, 100))
+	require.True(t, p.IsConfirmed())
+
+	proofBytes, err := p.Encode()
+	require.NoError(t, err)
+
+	var decoded Proof
+	require.NoError(t, decoded.Decode(proofBytes))
+	require.True(t, decoded.IsConfirmed())
+	require.Equal(t, p.BlockHeader.BlockHash(), decoded.BlockHeader.BlockHash())
+	require.Equal(t, p.BlockHeight, decoded.BlockHeight)
+	require.Equal(t, p.MetaReveal, decoded.MetaReveal)
+
+	decodedBytes, err := decoded.Encode()
+	require.NoError(t, err)
+	require.Equal(t, proofBytes, decodedBytes)
+}
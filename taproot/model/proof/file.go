@@ -1,23 +1,49 @@
 package proof
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/btcsuite/btcd/wire"
 	"github.com/quocky/taproot-asset/taproot/model/asset"
 	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/quocky/taproot-asset/taproot/model/tlv"
 	"github.com/quocky/taproot-asset/taproot/utils"
 )
 
-const LocatorFilePath = "./locator/%x"
+const (
+	// FileVersionV0 is the first version of the binary proof file format.
+	FileVersionV0 byte = 0
+
+	// maxNumProofs is the maximum number of proofs a file may contain. It
+	// prevents large allocations when decoding untrusted files.
+	maxNumProofs = 1 << 16
+)
 
 var (
+	// FileMagic is the magic header every binary proof file starts with.
+	FileMagic = [4]byte{'T', 'A', 'P', 'F'}
+
 	ErrNoProofAvailable = errors.New("no proof available")
+
+	// ErrUnknownFileVersion is returned when decoding a proof file with a
+	// version this implementation does not understand.
+	ErrUnknownFileVersion = errors.New("unknown proof file version")
+
+	// ErrInvalidFileFormat is returned when a blob is neither a binary nor
+	// a legacy JSON proof file.
+	ErrInvalidFileFormat = errors.New("invalid proof file format")
+
+	// ErrInvalidProofHash is returned when the chained hash of a proof
+	// does not match its content.
+	ErrInvalidProofHash = errors.New("invalid proof chain hash")
 )
 
 type HashedProof struct {
@@ -26,6 +52,9 @@ type HashedProof struct {
 }
 
 type File struct {
+	// Version is the version of the binary encoding of the file.
+	Version byte
+
 	Proofs []*HashedProof
 }
 
@@ -44,7 +73,7 @@ func NewFile(proofs ...Proof) (*File, error) {
 	for idx := range proofs {
 		proof := proofs[idx]
 
-		proofBytes, err := json.Marshal(proof)
+		proofBytes, err := proof.Encode()
 		if err != nil {
 			return nil, err
 		}
@@ -57,7 +86,8 @@ func NewFile(proofs ...Proof) (*File, error) {
 	}
 
 	return &File{
-		Proofs: linkedProofs,
+		Version: FileVersionV0,
+		Proofs:  linkedProofs,
 	}, nil
 }
 
@@ -101,8 +131,8 @@ func (f *File) ProofAt(index uint32) (*Proof, error) {
 		proof      = &Proof{}
 		proofBytes = f.Proofs[index].ProofBytes
 	)
-	if err := json.Unmarshal(proofBytes, &proof); err != nil {
-		return nil, fmt.Errorf("error decoding proof: %v", err)
+	if err := proof.Decode(proofBytes); err != nil {
+		return nil, fmt.Errorf("error decoding proof: %w", err)
 	}
 
 	return proof, nil
@@ -126,9 +156,9 @@ func (f *File) AppendProof(proof Proof) error {
 		prevHash = f.Proofs[len(f.Proofs)-1].Hash
 	}
 
-	proofBytes, err := json.Marshal(&proof)
+	proofBytes, err := proof.Encode()
 	if err != nil {
-		log.Println("[AppendProof] err := json.Marshal(&proof), err ", err)
+		log.Println("[AppendProof] err := proof.Encode(), err ", err)
 
 		return err
 	}
@@ -141,68 +171,314 @@ func (f *File) AppendProof(proof Proof) error {
 	return nil
 }
 
-func (f *File) Store() ([32]byte, error) {
+// ReplaceLastProof replaces the last proof of the file, for example once the
+// anchor transaction of the proof is confirmed, and recalculates its chained
+// hash.
+func (f *File) ReplaceLastProof(proof Proof) error {
+	if err := f.IsValid(); err != nil {
+		return err
+	}
+
+	lastProof := f.Proofs[len(f.Proofs)-1]
+	f.Proofs = f.Proofs[:len(f.Proofs)-1]
+
+	if err := f.AppendProof(proof); err != nil {
+		f.Proofs = append(f.Proofs, lastProof)
+
+		return err
+	}
+
+	return nil
+}
+
+// ConfirmLastProof adds the block the anchor transaction of the last proof was
+// confirmed in to the last proof of the file.
+func (f *File) ConfirmLastProof(block *wire.MsgBlock, height uint32) error {
 	lastProof, err := f.LastProof()
 	if err != nil {
-		log.Println("[file.Store] get last proof fail", err)
+		return err
+	}
 
-		return [32]byte{}, err
+	if err := lastProof.SetBlockInfo(block, height); err != nil {
+		return err
 	}
 
-	locator := Locator{
+	return f.ReplaceLastProof(*lastProof)
+}
+
+// Locator returns the locator of the asset the last proof of the file
+// transitions to.
+func (f *File) Locator() (*Locator, error) {
+	lastProof, err := f.LastProof()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Locator{
 		AssetID:   utils.ToPtr(lastProof.Asset.ID()),
 		ScriptKey: lastProof.Asset.ScriptPubkey,
 		OutPoint: wire.NewOutPoint(
 			utils.ToPtr(lastProof.AnchorTx.TxHash()),
 			lastProof.InclusionProof.OutputIndex,
 		),
+	}, nil
+}
+
+// Encode returns the binary encoding of the file:
+//
+//	magic (4 bytes) || version (1 byte) || num_proofs (var int) ||
+//	[proof_bytes (var bytes) || hash (32 bytes)]...
+func (f *File) Encode() ([]byte, error) {
+	if f.Version != FileVersionV0 {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownFileVersion,
+			f.Version)
 	}
 
-	filenameBytes, err := locator.Hash()
-	if err != nil {
-		log.Println("[proof.Store] Hash locator fail", err)
+	var buf bytes.Buffer
+	_, _ = buf.Write(FileMagic[:])
+	_ = buf.WriteByte(f.Version)
 
-		return [32]byte{}, err
+	if err := wire.WriteVarInt(&buf, 0, uint64(len(f.Proofs))); err != nil {
+		return nil, err
 	}
 
-	filename := fmt.Sprintf(LocatorFilePath, filenameBytes)
+	for _, hashedProof := range f.Proofs {
+		err := wire.WriteVarBytes(&buf, 0, hashedProof.ProofBytes)
+		if err != nil {
+			return nil, err
+		}
 
-	fileBytes, err := json.Marshal(*f)
-	if err != nil {
-		log.Println("fileBytes, err := json.Marshal(hashProofs) fail", err)
+		_, _ = buf.Write(hashedProof.Hash[:])
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode decodes a proof file from blob. Files written in the legacy JSON
+// format are accepted as well and migrated to the binary encoding in memory,
+// after the legacy proof chain was checked for integrity.
+func (f *File) Decode(blob []byte) error {
+	switch {
+	case bytes.HasPrefix(blob, FileMagic[:]):
+		return f.decodeBinary(blob[len(FileMagic):])
+
+	case IsLegacyFile(blob):
+		return f.decodeLegacy(blob)
 
-		return [32]byte{}, err
+	default:
+		return ErrInvalidFileFormat
 	}
+}
 
-	_ = os.Mkdir("locator", 0750)
+// decodeBinary decodes the binary file encoding following the magic header.
+func (f *File) decodeBinary(blob []byte) error {
+	r := bytes.NewReader(blob)
 
-	err = os.WriteFile(filename, fileBytes, 0666)
+	version, err := r.ReadByte()
 	if err != nil {
-		log.Println("os.WriteFile(nameFile, hashProofsData fail", err)
+		return err
+	}
+	if version != FileVersionV0 {
+		return fmt.Errorf("%w: %d", ErrUnknownFileVersion, version)
+	}
+
+	numProofs, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+	if numProofs > maxNumProofs {
+		return fmt.Errorf("%w: too many proofs (%d)",
+			ErrInvalidFileFormat, numProofs)
+	}
+
+	var (
+		prevHash [sha256.Size]byte
+		proofs   = make([]*HashedProof, 0, numProofs)
+	)
+	for i := uint64(0); i < numProofs; i++ {
+		proofBytes, err := wire.ReadVarBytes(
+			r, 0, tlv.MaxRecordSize, "proof",
+		)
+		if err != nil {
+			return err
+		}
+
+		var hash [sha256.Size]byte
+		if _, err := io.ReadFull(r, hash[:]); err != nil {
+			return err
+		}
+
+		if hash != hashProof(proofBytes, prevHash) {
+			return fmt.Errorf("%w: proof %d", ErrInvalidProofHash, i)
+		}
+
+		proofs = append(proofs, &HashedProof{
+			ProofBytes: proofBytes,
+			Hash:       hash,
+		})
+		prevHash = hash
+	}
 
-		return [32]byte{}, err
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidFileFormat,
+			r.Len())
 	}
 
-	return filenameBytes, nil
+	f.Version = version
+	f.Proofs = proofs
+
+	return nil
 }
 
-func (f *File) Decode(blob []byte) error {
-	if err := json.Unmarshal(blob, f); err != nil {
+// legacyFile is the JSON representation of a proof file written before the
+// binary encoding was introduced. The proof bytes are JSON encoded proofs and
+// the chained hashes commit to those JSON bytes.
+type legacyFile struct {
+	Proofs []*HashedProof
+}
+
+// IsLegacyFile returns true if the blob is a proof file in the legacy JSON
+// format.
+func IsLegacyFile(blob []byte) bool {
+	return isJSONObject(blob)
+}
+
+// decodeLegacy decodes a legacy JSON proof file and re-encodes all of its
+// proofs with the binary encoding.
+func (f *File) decodeLegacy(blob []byte) error {
+	var legacy legacyFile
+	if err := json.Unmarshal(blob, &legacy); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFileFormat, err)
+	}
+
+	var (
+		prevHash [sha256.Size]byte
+		proofs   = make([]Proof, len(legacy.Proofs))
+	)
+	for idx, hashedProof := range legacy.Proofs {
+		if hashedProof == nil {
+			return fmt.Errorf("%w: empty legacy proof %d",
+				ErrInvalidFileFormat, idx)
+		}
+
+		if hashedProof.Hash != hashProof(hashedProof.ProofBytes, prevHash) {
+			return fmt.Errorf("%w: legacy proof %d",
+				ErrInvalidProofHash, idx)
+		}
+		prevHash = hashedProof.Hash
+
+		err := json.Unmarshal(
+			hashedProof.ProofBytes, (*legacyProof)(&proofs[idx]),
+		)
+		if err != nil {
+			return fmt.Errorf("error decoding legacy proof %d: %w",
+				idx, err)
+		}
+	}
+
+	migrated, err := NewFile(proofs...)
+	if err != nil {
 		return err
 	}
 
+	*f = *migrated
+
 	return nil
 }
 
-func FileBytesFromName(nameFile string) ([]byte, error) {
-	fileByte, err := os.ReadFile(nameFile)
+// SkippedFile is a file of a legacy proof directory that was skipped because
+// it isn't a valid proof file.
+type SkippedFile struct {
+	// Name is the path of the file.
+	Name string
+
+	// Err is the reason the file was skipped.
+	Err error
+}
+
+// MigrateLegacyFiles rewrites all legacy JSON proof files within dir in the
+// binary encoding. Files that already use the binary encoding are left
+// untouched, and files that can't be decoded are skipped and returned, so a
+// single corrupt file doesn't keep the other files from being migrated. The
+// number of migrated files is returned.
+func MigrateLegacyFiles(dir string) (int, []SkippedFile, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var (
+		numMigrated int
+		skipped     []SkippedFile
+	)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		filename := filepath.Join(dir, entry.Name())
+		blob, err := os.ReadFile(filename)
+		if err != nil {
+			return numMigrated, skipped, err
+		}
+
+		if !IsLegacyFile(blob) {
+			continue
+		}
+
+		var f File
+		if err := f.Decode(blob); err != nil {
+			skipped = append(skipped, SkippedFile{
+				Name: filename,
+				Err:  err,
+			})
+
+			continue
+		}
+
+		fileBytes, err := f.Encode()
+		if err != nil {
+			return numMigrated, skipped, fmt.Errorf("unable to "+
+				"migrate %v: %w", filename, err)
+		}
+
+		if err := os.WriteFile(filename, fileBytes, 0666); err != nil {
+			return numMigrated, skipped, err
+		}
+
+		numMigrated++
+	}
+
+	return numMigrated, skipped, nil
+}
+
+// MarshalJSON encodes the file as its binary encoding.
+func (f File) MarshalJSON() ([]byte, error) {
+	fileBytes, err := f.Encode()
 	if err != nil {
-		log.Println("[FileFromName] fileByte, err := os.ReadFile(nameFile)", err)
+		return nil, err
+	}
+
+	return json.Marshal(fileBytes)
+}
 
-		return nil, errors.New("[FileFromName] read filename fail " + err.Error())
+// UnmarshalJSON decodes a file encoded with MarshalJSON. For backwards
+// compatibility, the legacy JSON object representation is accepted as well.
+func (f *File) UnmarshalJSON(data []byte) error {
+	if isJSONObject(data) {
+		return f.decodeLegacy(data)
+	}
+
+	var fileBytes []byte
+	if err := json.Unmarshal(data, &fileBytes); err != nil {
+		return err
 	}
 
-	return fileByte, nil
+	return f.Decode(fileBytes)
 }
 
 // AssetSnapshot commits to the result of a valid proof within a proof file.
@@ -0,0 +1,105 @@
+package proof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// ErrUnknownBlockHeader is returned when a block header isn't part of
+	// the best chain at the claimed height.
+	ErrUnknownBlockHeader = errors.New("block header not found in best " +
+		"chain")
+)
+
+// HeaderVerifier verifies that a block header is part of the best chain at the
+// given height.
+type HeaderVerifier interface {
+	VerifyHeader(ctx context.Context, header wire.BlockHeader,
+		height uint32) error
+}
+
+// BlockHashFetcher fetches the hash of the best chain block at a height. It is
+// implemented by the btcd RPC client.
+type BlockHashFetcher interface {
+	GetBlockHash(height int64) (*chainhash.Hash, error)
+}
+
+// A compile time check to ensure the btcd RPC client can back the verifier.
+var _ BlockHashFetcher = (*rpcclient.Client)(nil)
+
+// RPCHeaderVerifier is a HeaderVerifier that looks up the best chain through
+// the RPC interface of a btcd compatible node.
+type RPCHeaderVerifier struct {
+	client BlockHashFetcher
+}
+
+// NewRPCHeaderVerifier creates a new header verifier backed by the given RPC
+// client.
+func NewRPCHeaderVerifier(client BlockHashFetcher) *RPCHeaderVerifier {
+	return &RPCHeaderVerifier{
+		client: client,
+	}
+}
+
+// VerifyHeader checks that the hash of the best chain block at the given height
+// is the hash of the given header.
+func (v *RPCHeaderVerifier) VerifyHeader(_ context.Context,
+	header wire.BlockHeader, height uint32) error {
+
+	blockHash, err := v.client.GetBlockHash(int64(height))
+	if err != nil {
+		return fmt.Errorf("unable to fetch block hash at height %d: %w",
+			height, err)
+	}
+
+	if *blockHash != header.BlockHash() {
+		return ErrUnknownBlockHeader
+	}
+
+	return nil
+}
+
+// MemHeaderVerifier is an in-memory HeaderVerifier that only knows about the
+// headers that were added to it.
+type MemHeaderVerifier struct {
+	mu     sync.RWMutex
+	hashes map[uint32]chainhash.Hash
+}
+
+// NewMemHeaderVerifier creates a new, empty in-memory header verifier.
+func NewMemHeaderVerifier() *MemHeaderVerifier {
+	return &MemHeaderVerifier{
+		hashes: make(map[uint32]chainhash.Hash),
+	}
+}
+
+// AddHeader adds the given header at the given height to the best chain of
+// the verifier, replacing any header previously added at that height.
+func (v *MemHeaderVerifier) AddHeader(header wire.BlockHeader, height uint32) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.hashes[height] = header.BlockHash()
+}
+
+// VerifyHeader checks that the given header was added at the given height.
+func (v *MemHeaderVerifier) VerifyHeader(_ context.Context,
+	header wire.BlockHeader, height uint32) error {
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	blockHash, ok := v.hashes[height]
+	if !ok || blockHash != header.BlockHash() {
+		return ErrUnknownBlockHeader
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 
 	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
 	"github.com/quocky/taproot-asset/taproot/model/commitment"
 )
 
@@ -15,6 +16,10 @@ type AssetProofs []*Proof
 type MintParams struct {
 	BaseProofParams
 	GenesisPoint wire.OutPoint
+
+	// MetaReveals holds the metadata of the minted assets that commit to
+	// metadata, keyed by asset ID.
+	MetaReveals map[asset.ID]*asset.MetaReveal
 }
 
 func NewMintingBlobs(logger *zap.Logger, params *MintParams) (AssetProofs, error) {
@@ -22,7 +27,7 @@ func NewMintingBlobs(logger *zap.Logger, params *MintParams) (AssetProofs, error
 
 	logger.Debug("template proof: ", zap.Reflect("template", template))
 	proofs, err := committedProofs(
-		template, params.TapCommitment,
+		template, params.TapCommitment, params.MetaReveals,
 	)
 	if err != nil {
 		return nil, err
@@ -35,7 +40,9 @@ func NewMintingBlobs(logger *zap.Logger, params *MintParams) (AssetProofs, error
 	for key := range proofs {
 		proof := proofs[key]
 
-		_, err = proof.Verify(ctx, nil)
+		// The minting transaction isn't broadcast yet, and genesis
+		// proofs have no inputs to verify against the chain.
+		_, err = proof.VerifyUnconfirmed(ctx, nil, nil)
 
 		if err != nil {
 			return nil, fmt.Errorf("generate invalid proof: %w", err)
@@ -67,7 +74,8 @@ func createTemplateProof(params *BaseProofParams, genesisPoint wire.OutPoint) *P
 //	}, nil
 //}
 
-func committedProofs(template *Proof, tapCommitment *commitment.TapCommitment) (AssetProofs, error) {
+func committedProofs(template *Proof, tapCommitment *commitment.TapCommitment,
+	metaReveals map[asset.ID]*asset.MetaReveal) (AssetProofs, error) {
 
 	assets := tapCommitment.Assets()
 	proofs := make(AssetProofs, len(assets))
@@ -88,6 +96,7 @@ func committedProofs(template *Proof, tapCommitment *commitment.TapCommitment) (
 
 		assetProof.InclusionProof.CommitmentProof = commitmentProof
 		assetProof.GenesisReveal = &newAsset.Genesis
+		assetProof.MetaReveal = metaReveals[newAsset.ID()]
 
 		proofs[i] = &assetProof
 	}
@@ -41,6 +41,73 @@ var (
 	// with the asset ID.
 	ErrGenesisRevealAssetIDMismatch = errors.New("genesis reveal asset " +
 		"ID mismatch")
+
+	// ErrNonGenesisAssetWithMetaReveal is an error returned if an asset
+	// proof for a non-genesis asset contains a meta reveal.
+	ErrNonGenesisAssetWithMetaReveal = errors.New("non genesis asset has " +
+		"meta reveal")
+
+	// ErrMetaRevealRequired is an error returned if an asset proof for a
+	// genesis asset that commits to metadata is missing a meta reveal.
+	ErrMetaRevealRequired = errors.New("meta reveal required")
+
+	// ErrMetaRevealMismatch is an error returned if the meta reveal of an
+	// asset proof doesn't match the meta hash of the genesis reveal.
+	ErrMetaRevealMismatch = errors.New("meta reveal doesn't match meta " +
+		"hash")
+
+	// ErrMissingGenesisProof is an error returned if the first proof of a
+	// proof file isn't the genesis proof of the asset.
+	ErrMissingGenesisProof = errors.New("first proof is not a genesis " +
+		"proof")
+
+	// ErrUnexpectedGenesisProof is an error returned if a genesis proof
+	// is found in the middle of a proof chain.
+	ErrUnexpectedGenesisProof = errors.New("genesis proof must be the " +
+		"first proof in the chain")
+
+	// ErrPrevOutMismatch is an error returned if the prev out of a proof
+	// doesn't spend the outpoint of the previous asset snapshot.
+	ErrPrevOutMismatch = errors.New("prev out doesn't match previous " +
+		"asset snapshot")
+
+	// ErrUnknownPrevID is an error returned if a previous witness of a
+	// state transition references an input that is neither the previous
+	// asset snapshot nor one of the additional inputs.
+	ErrUnknownPrevID = errors.New("prev ID doesn't reference a known " +
+		"input")
+
+	// ErrDuplicateInput is an error returned if the same input is
+	// provided more than once to a state transition.
+	ErrDuplicateInput = errors.New("duplicate input in state transition")
+
+	// ErrUnspentInput is an error returned if the previous asset snapshot
+	// or one of the additional inputs isn't spent by the state transition.
+	ErrUnspentInput = errors.New("input not spent by state transition")
+
+	// ErrAnchorInputNotSpent is an error returned if the anchor
+	// transaction of a state transition doesn't spend the outpoint an
+	// input asset is anchored in.
+	ErrAnchorInputNotSpent = errors.New("anchor transaction doesn't " +
+		"spend input anchor outpoint")
+
+	// ErrAssetMismatch is an error returned if a state transition spends
+	// inputs of another asset, or of another asset group, than the asset it
+	// creates.
+	ErrAssetMismatch = errors.New("state transition changes the asset " +
+		"of its inputs")
+
+	// ErrAmountMismatch is an error returned if the amounts of a state
+	// transition aren't conserved.
+	ErrAmountMismatch = errors.New("input and output amounts don't match")
+
+	// ErrInvalidSplitCommitment is an error returned if a split asset
+	// isn't committed to by the split commitment root of its root asset.
+	ErrInvalidSplitCommitment = errors.New("invalid split commitment")
+
+	// ErrUnconfirmedProof is an error returned if a proof doesn't prove
+	// that its anchor transaction was confirmed in a block.
+	ErrUnconfirmedProof = errors.New("anchor transaction not confirmed")
 )
 
 type Interface interface {
@@ -49,12 +116,50 @@ type Interface interface {
 }
 
 type Proof struct {
-	PrevOut          wire.OutPoint // TODO: genesisPoint
-	AnchorTx         wire.MsgTx
+	PrevOut wire.OutPoint // TODO: genesisPoint
+
+	// BlockHeader is the header of the block the anchor transaction was
+	// confirmed in. It's only set once the anchor transaction is mined.
+	BlockHeader wire.BlockHeader
+
+	// BlockHeight is the height of the block with the above header.
+	BlockHeight uint32
+
+	AnchorTx wire.MsgTx
+
+	// TxMerkleProof proves the inclusion of the anchor transaction in the
+	// block with the above header.
+	TxMerkleProof TxMerkleProof
+
 	Asset            asset.Asset
 	InclusionProof   TaprootProof
 	ExclusionProofs  []*TaprootProof
 	SplitRootProof   *TaprootProof
 	AdditionalInputs []File
 	GenesisReveal    *asset.Genesis
+
+	// MetaReveal is the metadata of the asset. It is only set in the
+	// genesis proof of an asset that commits to metadata.
+	MetaReveal *asset.MetaReveal
+}
+
+// IsConfirmed returns true if the proof carries the block the anchor
+// transaction was confirmed in.
+func (p *Proof) IsConfirmed() bool {
+	return p.BlockHeader != wire.BlockHeader{}
+}
+
+// SetBlockInfo adds the block the anchor transaction was confirmed in to the
+// proof.
+func (p *Proof) SetBlockInfo(block *wire.MsgBlock, height uint32) error {
+	txMerkleProof, err := NewTxMerkleProofFromBlock(block, &p.AnchorTx)
+	if err != nil {
+		return err
+	}
+
+	p.BlockHeader = block.Header
+	p.BlockHeight = height
+	p.TxMerkleProof = *txMerkleProof
+
+	return nil
 }
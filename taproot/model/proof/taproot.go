@@ -103,6 +103,10 @@ func (p TaprootProof) DeriveByAssetExclusion(
 	// asset ID, but we want to verify that the particular asset we care
 	// about isn't included.
 	default:
+		if p.CommitmentProof.AssetProof.TapKey != tapCommitmentKey {
+			return nil, commitment.ErrTapKeyMismatch
+		}
+
 		log.Printf("Deriving commitment by asset exclusion")
 		tapCommitment, err = p.CommitmentProof.
 			DeriveByAssetExclusion(assetCommitmentKey)
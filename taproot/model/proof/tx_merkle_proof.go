@@ -0,0 +1,194 @@
+package proof
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// maxTxMerkleProofNodes is the maximum number of nodes of a merkle
+	// proof. A block can't contain enough transactions for a deeper tree.
+	maxTxMerkleProofNodes = 32
+)
+
+var (
+	// ErrTxNotInBlock is returned when creating a merkle proof for a
+	// transaction that isn't part of the given block.
+	ErrTxNotInBlock = errors.New("transaction not found in block")
+
+	// ErrInvalidTxMerkleProof is returned when a transaction merkle proof
+	// doesn't resolve to the merkle root of the block header.
+	ErrInvalidTxMerkleProof = errors.New("invalid transaction merkle proof")
+)
+
+// TxMerkleProof is the proof of a transaction's inclusion in the merkle tree
+// of a block.
+type TxMerkleProof struct {
+	// Nodes are the sibling hashes on the path from the transaction to
+	// the merkle root, starting at the bottom of the tree.
+	Nodes []chainhash.Hash
+
+	// Bits indicates for each node whether it is the left sibling of the
+	// hash computed up to that point.
+	Bits []bool
+}
+
+// NewTxMerkleProof computes the merkle proof of the transaction at txIdx in the
+// given list of block transactions.
+func NewTxMerkleProof(txs []*wire.MsgTx, txIdx int) (*TxMerkleProof, error) {
+	if txIdx < 0 || txIdx >= len(txs) {
+		return nil, fmt.Errorf("invalid transaction index %d", txIdx)
+	}
+
+	level := make([]chainhash.Hash, len(txs))
+	for idx := range txs {
+		level[idx] = txs[idx].TxHash()
+	}
+
+	var (
+		proof TxMerkleProof
+		pos   = txIdx
+	)
+	for len(level) > 1 {
+		// Bitcoin duplicates the last hash of a level with an odd
+		// number of hashes.
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+
+		siblingIsLeft := pos%2 == 1
+
+		var sibling chainhash.Hash
+		if siblingIsLeft {
+			sibling = level[pos-1]
+		} else {
+			sibling = level[pos+1]
+		}
+
+		proof.Nodes = append(proof.Nodes, sibling)
+		proof.Bits = append(proof.Bits, siblingIsLeft)
+
+		nextLevel := make([]chainhash.Hash, len(level)/2)
+		for idx := range nextLevel {
+			nextLevel[idx] = hashMerkleBranches(
+				&level[2*idx], &level[2*idx+1],
+			)
+		}
+
+		level = nextLevel
+		pos /= 2
+	}
+
+	return &proof, nil
+}
+
+// NewTxMerkleProofFromBlock computes the merkle proof of the given transaction
+// in the given block.
+func NewTxMerkleProofFromBlock(block *wire.MsgBlock,
+	tx *wire.MsgTx) (*TxMerkleProof, error) {
+
+	txHash := tx.TxHash()
+	for idx := range block.Transactions {
+		if block.Transactions[idx].TxHash() == txHash {
+			return NewTxMerkleProof(block.Transactions, idx)
+		}
+	}
+
+	return nil, ErrTxNotInBlock
+}
+
+// Verify checks that the merkle proof resolves to the given merkle root for the
+// given transaction.
+func (p *TxMerkleProof) Verify(tx *wire.MsgTx, merkleRoot chainhash.Hash) bool {
+	if len(p.Nodes) != len(p.Bits) {
+		return false
+	}
+
+	current := tx.TxHash()
+	for idx := range p.Nodes {
+		if p.Bits[idx] {
+			current = hashMerkleBranches(&p.Nodes[idx], &current)
+		} else {
+			current = hashMerkleBranches(&current, &p.Nodes[idx])
+		}
+	}
+
+	return current == merkleRoot
+}
+
+// Encode encodes the merkle proof as the number of nodes, followed by the
+// nodes and a bit field holding the bits.
+func (p *TxMerkleProof) Encode() ([]byte, error) {
+	if len(p.Nodes) != len(p.Bits) ||
+		len(p.Nodes) > maxTxMerkleProofNodes {
+
+		return nil, ErrInvalidTxMerkleProof
+	}
+
+	var buf bytes.Buffer
+	if err := wire.WriteVarInt(&buf, 0, uint64(len(p.Nodes))); err != nil {
+		return nil, err
+	}
+
+	for idx := range p.Nodes {
+		buf.Write(p.Nodes[idx][:])
+	}
+
+	bitField := make([]byte, (len(p.Bits)+7)/8)
+	for idx, bit := range p.Bits {
+		if bit {
+			bitField[idx/8] |= 1 << (idx % 8)
+		}
+	}
+	buf.Write(bitField)
+
+	return buf.Bytes(), nil
+}
+
+// Decode decodes a merkle proof encoded with Encode.
+func (p *TxMerkleProof) Decode(blob []byte) error {
+	r := bytes.NewReader(blob)
+
+	numNodes, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+
+	if numNodes > maxTxMerkleProofNodes {
+		return ErrInvalidTxMerkleProof
+	}
+
+	bitFieldLen := (int(numNodes) + 7) / 8
+	if r.Len() != int(numNodes)*chainhash.HashSize+bitFieldLen {
+		return ErrInvalidTxMerkleProof
+	}
+
+	p.Nodes = make([]chainhash.Hash, numNodes)
+	for idx := range p.Nodes {
+		_, _ = r.Read(p.Nodes[idx][:])
+	}
+
+	bitField := make([]byte, bitFieldLen)
+	_, _ = r.Read(bitField)
+
+	p.Bits = make([]bool, numNodes)
+	for idx := range p.Bits {
+		p.Bits[idx] = bitField[idx/8]&(1<<(idx%8)) != 0
+	}
+
+	return nil
+}
+
+// hashMerkleBranches returns the double SHA256 of the concatenation of the two
+// given hashes.
+func hashMerkleBranches(left, right *chainhash.Hash) chainhash.Hash {
+	var buf [chainhash.HashSize * 2]byte
+	copy(buf[:chainhash.HashSize], left[:])
+	copy(buf[chainhash.HashSize:], right[:])
+
+	return chainhash.DoubleHashH(buf[:])
+}
@@ -0,0 +1,64 @@
+package proof
+
+import (
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTxMerkleProof makes sure a merkle proof of every transaction of a block
+// resolves to the merkle root computed by btcd and survives an encoding round
+// trip.
+func TestTxMerkleProof(t *testing.T) {
+	for numTxs := 1; numTxs <= 9; numTxs++ {
+		txs := make([]*wire.MsgTx, numTxs)
+		utilTxs := make([]*btcutil.Tx, numTxs)
+		for idx := range txs {
+			txs[idx] = wire.NewMsgTx(2)
+			txs[idx].LockTime = uint32(idx)
+			utilTxs[idx] = btcutil.NewTx(txs[idx])
+		}
+
+		merkleRoot := blockchain.CalcMerkleRoot(utilTxs, false)
+
+		for idx := range txs {
+			merkleProof, err := NewTxMerkleProof(txs, idx)
+			require.NoError(t, err)
+			require.True(t, merkleProof.Verify(txs[idx], merkleRoot))
+
+			proofBytes, err := merkleProof.Encode()
+			require.NoError(t, err)
+
+			var decoded TxMerkleProof
+			require.NoError(t, decoded.Decode(proofBytes))
+			require.True(t, decoded.Verify(txs[idx], merkleRoot))
+
+			// The proof must not be valid for any other transaction.
+			other := txs[(idx+1)%numTxs]
+			if numTxs > 1 {
+				require.False(t, merkleProof.Verify(other, merkleRoot))
+			}
+		}
+	}
+}
+
+// TestMemHeaderVerifier makes sure the in-memory header verifier only accepts
+// headers that were added at the claimed height.
+func TestMemHeaderVerifier(t *testing.T) {
+	ctx := context.Background()
+	verifier := NewMemHeaderVerifier()
+
+	header := wire.BlockHeader{Version: 1, Nonce: 42}
+	require.ErrorIs(t, verifier.VerifyHeader(ctx, header, 1), ErrUnknownBlockHeader)
+
+	verifier.AddHeader(header, 1)
+	require.NoError(t, verifier.VerifyHeader(ctx, header, 1))
+	require.ErrorIs(t, verifier.VerifyHeader(ctx, header, 2), ErrUnknownBlockHeader)
+
+	otherHeader := wire.BlockHeader{Version: 1, Nonce: 43}
+	require.ErrorIs(t, verifier.VerifyHeader(ctx, otherHeader, 1), ErrUnknownBlockHeader)
+}
@@ -10,11 +10,38 @@ import (
 	"github.com/btcsuite/btcd/wire"
 	"github.com/quocky/taproot-asset/taproot/model/asset"
 	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
 )
 
+// Verify verifies the proof against the previous asset snapshot, including
+// the confirmation of the anchor transaction in the best chain known to the
+// header verifier.
 func (p *Proof) Verify(
 	ctx context.Context,
 	prev *AssetSnapshot,
+	headerVerifier HeaderVerifier,
+) (*AssetSnapshot, error) {
+
+	snapshot, err := p.VerifyUnconfirmed(ctx, prev, headerVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.verifyAnchorTx(ctx, headerVerifier); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// VerifyUnconfirmed verifies the proof like Verify, except that the anchor
+// transaction of the proof itself isn't required to be confirmed yet. This is
+// used for new proofs before their anchor transaction is broadcast. The header
+// verifier is still used for the additional inputs of the proof.
+func (p *Proof) VerifyUnconfirmed(
+	ctx context.Context,
+	prev *AssetSnapshot,
+	headerVerifier HeaderVerifier,
 ) (*AssetSnapshot, error) {
 
 	// TODO: validate p.asset (check asset name)
@@ -26,6 +53,16 @@ func (p *Proof) Verify(
 		return nil, err
 	}
 
+	if err := p.Asset.Validate(); err != nil {
+		return nil, err
+	}
+
+	if p.Asset.GroupKey != nil {
+		if err := p.Asset.GroupKey.Verify(p.Asset.Genesis); err != nil {
+			return nil, err
+		}
+	}
+
 	if p.Asset.HasSplitCommitmentWitness() {
 		if p.SplitRootProof == nil {
 			return nil, ErrMissingSplitRootProof
@@ -34,6 +71,10 @@ func (p *Proof) Verify(
 		if err := p.verifySplitRootProof(); err != nil {
 			return nil, err
 		}
+
+		if err := p.verifySplitCommitment(); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := p.verifyExclusionProofs(); err != nil {
@@ -46,6 +87,8 @@ func (p *Proof) Verify(
 	switch {
 	case !isGenesisAsset && hasGenesisReveal:
 		return nil, ErrNonGenesisAssetWithGenesisReveal
+	case !isGenesisAsset && p.MetaReveal != nil:
+		return nil, ErrNonGenesisAssetWithMetaReveal
 	case isGenesisAsset && !hasGenesisReveal:
 		return nil, ErrGenesisRevealRequired
 	case isGenesisAsset && hasGenesisReveal:
@@ -54,7 +97,18 @@ func (p *Proof) Verify(
 		}
 	}
 
-	var splitAsset bool = false
+	// Without a previous snapshot the proof can only be verified in
+	// isolation. Otherwise, it has to spend the previous snapshot.
+	switch {
+	case prev != nil && isGenesisAsset:
+		return nil, ErrUnexpectedGenesisProof
+
+	case prev != nil:
+		err := p.verifyTransition(ctx, prev, headerVerifier)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &AssetSnapshot{
 		Asset: &p.Asset,
@@ -66,10 +120,194 @@ func (p *Proof) Verify(
 		OutputIndex: p.InclusionProof.OutputIndex,
 		InternalKey: p.InclusionProof.InternalKey,
 		ScriptRoot:  assetCommitment,
-		SplitAsset:  splitAsset, // TODO: genesis process -> no-existed Split Asset
+		SplitAsset:  p.Asset.HasSplitCommitmentWitness(),
 	}, nil
 }
 
+// verifyAnchorTx checks that the anchor transaction is included in the block
+// of the proof and that the block is part of the best chain.
+func (p *Proof) verifyAnchorTx(ctx context.Context,
+	headerVerifier HeaderVerifier) error {
+
+	if !p.IsConfirmed() {
+		return ErrUnconfirmedProof
+	}
+
+	if !p.TxMerkleProof.Verify(&p.AnchorTx, p.BlockHeader.MerkleRoot) {
+		return ErrInvalidTxMerkleProof
+	}
+
+	return headerVerifier.VerifyHeader(ctx, p.BlockHeader, p.BlockHeight)
+}
+
+// verifyTransition checks that the state transition in the proof spends the
+// previous snapshot and all additional inputs exactly once, both in the asset
+// witnesses and on chain by the anchor transaction, that all inputs are of the
+// asset it creates, that the sum of the input amounts is conserved by the
+// outputs and that every input is signed by its script key.
+func (p *Proof) verifyTransition(ctx context.Context, prev *AssetSnapshot,
+	headerVerifier HeaderVerifier) error {
+
+	if p.PrevOut != prev.OutPoint {
+		return ErrPrevOutMismatch
+	}
+
+	inputs, err := p.collectInputs(ctx, prev, headerVerifier)
+	if err != nil {
+		return err
+	}
+
+	// For a split asset the inputs are spent by the root asset of the
+	// split, which also commits to the amounts of all split outputs.
+	rootAsset := &p.Asset
+	if p.Asset.HasSplitCommitmentWitness() {
+		rootAsset = &p.Asset.PrevWitnesses[0].SplitCommitment.RootAsset
+	}
+
+	if !sameAsset(&p.Asset, rootAsset) {
+		return ErrAssetMismatch
+	}
+
+	anchorInputs := make(map[wire.OutPoint]struct{}, len(p.AnchorTx.TxIn))
+	for _, txIn := range p.AnchorTx.TxIn {
+		anchorInputs[txIn.PreviousOutPoint] = struct{}{}
+	}
+
+	var (
+		inputAmount uint64
+		prevAssets  = make([]*asset.Asset, 0, len(rootAsset.PrevWitnesses))
+	)
+	for _, witness := range rootAsset.PrevWitnesses {
+		if witness.PrevID == nil {
+			return ErrUnknownPrevID
+		}
+
+		if _, ok := anchorInputs[witness.PrevID.OutPoint]; !ok {
+			return ErrAnchorInputNotSpent
+		}
+
+		input, ok := inputs[*witness.PrevID]
+		if !ok {
+			return ErrUnknownPrevID
+		}
+		delete(inputs, *witness.PrevID)
+		prevAssets = append(prevAssets, input)
+
+		// Otherwise the amount of one asset could be spent into the
+		// same amount of any other asset.
+		if !sameAsset(input, rootAsset) {
+			return ErrAssetMismatch
+		}
+
+		if err := mssmt.CheckSumOverflowUint64(
+			inputAmount, input.Amount,
+		); err != nil {
+			return err
+		}
+		inputAmount += input.Amount
+	}
+
+	if len(inputs) != 0 {
+		return ErrUnspentInput
+	}
+
+	outputAmount := rootAsset.Amount
+	if rootAsset.SplitCommitmentRoot != nil {
+		outputAmount = rootAsset.SplitCommitmentRoot.NodeSum()
+	}
+
+	if inputAmount != outputAmount {
+		return ErrAmountMismatch
+	}
+
+	// Every input must be signed by its script key, otherwise whoever
+	// controls the anchor outputs could move the assets.
+	return asset.VerifyVirtualTx(rootAsset, prevAssets)
+}
+
+// sameAsset returns true if both assets have the same asset ID and group key.
+func sameAsset(a, b *asset.Asset) bool {
+	return a.ID() == b.ID() && a.GroupKey.IsEqual(b.GroupKey)
+}
+
+// collectInputs returns the assets spent by the state transition in the proof,
+// keyed by the PrevID that references them. The previous snapshot is always
+// the first input, the additional input files are verified and their last
+// snapshots make up the remaining inputs.
+func (p *Proof) collectInputs(ctx context.Context, prev *AssetSnapshot,
+	headerVerifier HeaderVerifier) (map[asset.PrevID]*asset.Asset, error) {
+
+	snapshots := make([]*AssetSnapshot, 0, len(p.AdditionalInputs)+1)
+	snapshots = append(snapshots, prev)
+
+	for idx := range p.AdditionalInputs {
+		snapshot, err := p.AdditionalInputs[idx].Verify(
+			ctx, headerVerifier,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid additional input %d: %w",
+				idx, err)
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	inputs := make(map[asset.PrevID]*asset.Asset, len(snapshots))
+	for _, snapshot := range snapshots {
+		prevID := asset.PrevID{
+			OutPoint:  snapshot.OutPoint,
+			ID:        snapshot.Asset.ID(),
+			ScriptKey: snapshot.Asset.ScriptPubkey,
+		}
+
+		if _, ok := inputs[prevID]; ok {
+			return nil, ErrDuplicateInput
+		}
+
+		inputs[prevID] = snapshot.Asset
+	}
+
+	return inputs, nil
+}
+
+// verifySplitCommitment checks that the split asset in the proof is committed
+// to by the split commitment root of its root asset.
+func (p *Proof) verifySplitCommitment() error {
+	splitCommitment := p.Asset.PrevWitnesses[0].SplitCommitment
+
+	splitRoot := splitCommitment.RootAsset.SplitCommitmentRoot
+	if splitRoot == nil {
+		return ErrInvalidSplitCommitment
+	}
+
+	// The split asset is committed to without its split commitment
+	// witness, see commitment.NewSplitCommitment.
+	splitAsset := p.Asset.Copy()
+	splitAsset.PrevWitnesses = []asset.Witness{{
+		PrevID:          &asset.ZeroPrevID,
+		SplitCommitment: nil,
+	}}
+	splitAsset.SplitCommitmentRoot = nil
+
+	splitLeaf, err := splitAsset.Leaf()
+	if err != nil {
+		return err
+	}
+
+	locator := commitment.SplitLocator{
+		OutputIndex: p.InclusionProof.OutputIndex,
+		AssetID:     p.Asset.ID(),
+		ScriptKey:   p.Asset.ScriptPubkey,
+	}
+
+	root := splitCommitment.Proof.Root(locator.Hash(), splitLeaf)
+	if !mssmt.IsEqualNode(root, splitRoot) {
+		return ErrInvalidSplitCommitment
+	}
+
+	return nil
+}
+
 // verifyGenesisReveal checks that the genesis reveal present in the proof at
 // minting validates against the asset ID and proof details.
 func (p *Proof) verifyGenesisReveal() error {
@@ -98,6 +336,34 @@ func (p *Proof) verifyGenesisReveal() error {
 		return ErrGenesisRevealAssetIDMismatch
 	}
 
+	return p.verifyMetaReveal()
+}
+
+// verifyMetaReveal checks that the meta reveal of a genesis proof is present
+// exactly if the genesis commits to metadata, and that it matches the
+// committed meta hash.
+func (p *Proof) verifyMetaReveal() error {
+	reveal := p.GenesisReveal
+
+	switch {
+	case !reveal.HasMeta() && p.MetaReveal == nil:
+		return nil
+
+	case !reveal.HasMeta():
+		return ErrMetaRevealMismatch
+
+	case p.MetaReveal == nil:
+		return ErrMetaRevealRequired
+	}
+
+	if err := p.MetaReveal.Validate(); err != nil {
+		return err
+	}
+
+	if p.MetaReveal.MetaHash() != reveal.MetaHash {
+		return ErrMetaRevealMismatch
+	}
+
 	return nil
 }
 
@@ -210,8 +476,32 @@ func verifyTaprootProof(
 	return nil, commitment.ErrInvalidTaprootProof
 }
 
+// Verify verifies the whole proof chain of the file, starting at the genesis
+// proof. Each proof is verified against the snapshot of the proof before it
+// and the snapshot of the last proof is returned. All anchor transactions have
+// to be confirmed in the best chain known to the header verifier.
+//
 // TODO(roasbeef): pass in the expected genesis point here?
-func (f *File) Verify(ctx context.Context) (*AssetSnapshot, error) {
+func (f *File) Verify(ctx context.Context,
+	headerVerifier HeaderVerifier) (*AssetSnapshot, error) {
+
+	return f.verify(ctx, headerVerifier, true)
+}
+
+// VerifyUnconfirmed verifies the whole proof chain of the file like Verify,
+// except that the anchor transaction of the last proof isn't required to be
+// confirmed yet.
+func (f *File) VerifyUnconfirmed(ctx context.Context,
+	headerVerifier HeaderVerifier) (*AssetSnapshot, error) {
+
+	return f.verify(ctx, headerVerifier, false)
+}
+
+// verify walks the proof chain of the file, optionally skipping the
+// confirmation check of the last proof.
+func (f *File) verify(ctx context.Context, headerVerifier HeaderVerifier,
+	confirmLast bool) (*AssetSnapshot, error) {
+
 	var prev *AssetSnapshot
 	for idx := range f.Proofs {
 		decodedProof, err := f.ProofAt(uint32(idx))
@@ -219,7 +509,16 @@ func (f *File) Verify(ctx context.Context) (*AssetSnapshot, error) {
 			return nil, err
 		}
 
-		result, err := decodedProof.Verify(ctx, prev)
+		if idx == 0 && !decodedProof.Asset.IsGenesisAsset() {
+			return nil, ErrMissingGenesisProof
+		}
+
+		verify := decodedProof.Verify
+		if idx == len(f.Proofs)-1 && !confirmLast {
+			verify = decodedProof.VerifyUnconfirmed
+		}
+
+		result, err := verify(ctx, prev, headerVerifier)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,147 @@
+package proof
+
+import (
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/stretchr/testify/require"
+)
+
+// transitionProof returns the proof of the new asset spending the previous
+// snapshot, anchored alone in the only output of the anchor transaction. The
+// new asset is signed by the owner of the previous asset.
+func transitionProof(t *testing.T, prev *AssetSnapshot, newAsset *asset.Asset,
+	owner *btcec.PrivateKey) *Proof {
+
+	t.Helper()
+
+	newAsset.PrevWitnesses = []asset.Witness{{
+		PrevID: &asset.PrevID{
+			OutPoint:  prev.OutPoint,
+			ID:        prev.Asset.ID(),
+			ScriptKey: prev.Asset.ScriptPubkey,
+		},
+	}}
+	require.NoError(t, asset.SignVirtualTx(
+		newAsset, []*asset.Asset{prev.Asset}, asset.NewKeySigner(owner),
+	))
+
+	p, err := CreateTransitionProof(prev.OutPoint, &TransitionParams{
+		BaseProofParams: anchorParams(t, prev.OutPoint, newAsset),
+		NewAsset:        newAsset,
+	})
+	require.NoError(t, err)
+
+	return p
+}
+
+// anchorParams returns the parameters of an anchor transaction spending the
+// outpoint into a single output that commits to the asset alone.
+func anchorParams(t *testing.T, prevOut wire.OutPoint,
+	a *asset.Asset) BaseProofParams {
+
+	t.Helper()
+
+	assetCommitment, err := commitment.NewAssetCommitment(
+		context.Background(), a,
+	)
+	require.NoError(t, err)
+	tapCommitment, err := commitment.NewTapCommitment(assetCommitment)
+	require.NoError(t, err)
+
+	internalKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	tapscriptRoot := txscript.AssembleTaprootScriptTree(
+		tapCommitment.TapLeaf(),
+	).RootNode.TapHash()
+	pkScript, err := txscript.PayToTaprootScript(
+		txscript.ComputeTaprootOutputKey(
+			internalKey.PubKey(), tapscriptRoot[:],
+		),
+	)
+	require.NoError(t, err)
+
+	anchorTx := wire.NewMsgTx(2)
+	anchorTx.AddTxIn(wire.NewTxIn(&prevOut, nil, nil))
+	anchorTx.AddTxOut(wire.NewTxOut(1000, pkScript))
+
+	return BaseProofParams{
+		Tx:            anchorTx,
+		InternalKey:   asset.ToSerialized(internalKey.PubKey()),
+		TapCommitment: tapCommitment,
+	}
+}
+
+// TestVerifyTransition makes sure a state transition only verifies if it
+// spends the previous snapshot into the same amount of the same asset.
+func TestVerifyTransition(t *testing.T) {
+	ctx := context.Background()
+
+	owner, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	recipient, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	var (
+		ownerKey     = asset.ToSerialized(owner.PubKey())
+		recipientKey = asset.ToSerialized(recipient.PubKey())
+		genesisOut   = wire.OutPoint{Hash: chainhash.Hash{1}}
+		prevAsset    = asset.New(genesisOut, "ticker", 0, 100, ownerKey, nil)
+		otherGenesis = asset.NewGenesis(genesisOut, "other", 0, asset.Normal)
+		prev         = &AssetSnapshot{
+			Asset:    prevAsset,
+			OutPoint: wire.OutPoint{Hash: chainhash.Hash{2}},
+		}
+	)
+
+	testCases := []struct {
+		name     string
+		newAsset *asset.Asset
+		modify   func(p *Proof)
+		err      error
+	}{{
+		name:     "valid transfer",
+		newAsset: asset.NewAsset(prevAsset.Genesis, 100, recipientKey, nil),
+	}, {
+		name:     "spend into another asset",
+		newAsset: asset.NewAsset(otherGenesis, 100, recipientKey, nil),
+		err:      ErrAssetMismatch,
+	}, {
+		name:     "inflated amount",
+		newAsset: asset.NewAsset(prevAsset.Genesis, 101, recipientKey, nil),
+		err:      ErrAmountMismatch,
+	}, {
+		name:     "broken prev out",
+		newAsset: asset.NewAsset(prevAsset.Genesis, 100, recipientKey, nil),
+		modify: func(p *Proof) {
+			p.PrevOut.Index++
+		},
+		err: ErrPrevOutMismatch,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p := transitionProof(t, prev, testCase.newAsset, owner)
+			if testCase.modify != nil {
+				testCase.modify(p)
+			}
+
+			snapshot, err := p.VerifyUnconfirmed(ctx, prev, nil)
+			if testCase.err != nil {
+				require.ErrorIs(t, err, testCase.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testCase.newAsset.ID(), snapshot.Asset.ID())
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+var byteOrder = binary.BigEndian
+
+// Uint32 returns the big endian encoding of v.
+func Uint32(v uint32) []byte {
+	var b [4]byte
+	byteOrder.PutUint32(b[:], v)
+
+	return b[:]
+}
+
+// Uint64 returns the big endian encoding of v.
+func Uint64(v uint64) []byte {
+	var b [8]byte
+	byteOrder.PutUint64(b[:], v)
+
+	return b[:]
+}
+
+// Bool returns the single byte encoding of v.
+func Bool(v bool) []byte {
+	if v {
+		return []byte{1}
+	}
+
+	return []byte{0}
+}
+
+// ReadUint32 decodes a big endian uint32 value.
+func ReadUint32(b []byte) (uint32, error) {
+	if len(b) != 4 {
+		return 0, fmt.Errorf("%w: expected 4 bytes, got %d",
+			ErrInvalidLength, len(b))
+	}
+
+	return byteOrder.Uint32(b), nil
+}
+
+// ReadUint64 decodes a big endian uint64 value.
+func ReadUint64(b []byte) (uint64, error) {
+	if len(b) != 8 {
+		return 0, fmt.Errorf("%w: expected 8 bytes, got %d",
+			ErrInvalidLength, len(b))
+	}
+
+	return byteOrder.Uint64(b), nil
+}
+
+// ReadBool decodes a single byte boolean value.
+func ReadBool(b []byte) (bool, error) {
+	if len(b) != 1 || b[0] > 1 {
+		return false, fmt.Errorf("%w: invalid bool", ErrInvalidLength)
+	}
+
+	return b[0] == 1, nil
+}
+
+// ReadFixed copies b into dst, making sure the lengths match.
+func ReadFixed(dst, b []byte) error {
+	if len(b) != len(dst) {
+		return fmt.Errorf("%w: expected %d bytes, got %d",
+			ErrInvalidLength, len(dst), len(b))
+	}
+
+	copy(dst, b)
+
+	return nil
+}
+
+// OutPoint returns the encoding of the given outpoint (32 byte hash followed by
+// the little endian output index, as on the wire).
+func OutPoint(op *wire.OutPoint) []byte {
+	var buf bytes.Buffer
+	_ = wire.WriteOutPoint(&buf, 0, 0, op)
+
+	return buf.Bytes()
+}
+
+// ReadOutPoint decodes an outpoint encoded with OutPoint.
+func ReadOutPoint(b []byte) (wire.OutPoint, error) {
+	var op wire.OutPoint
+	if len(b) != 36 {
+		return op, fmt.Errorf("%w: expected 36 bytes, got %d",
+			ErrInvalidLength, len(b))
+	}
+
+	copy(op.Hash[:], b[:32])
+	op.Index = binary.LittleEndian.Uint32(b[32:])
+
+	return op, nil
+}
+
+// List encodes a list of values as a var int count followed by each value
+// prefixed with its var int length.
+func List(items [][]byte) []byte {
+	var buf bytes.Buffer
+	_ = wire.WriteVarInt(&buf, 0, uint64(len(items)))
+	for _, item := range items {
+		_ = wire.WriteVarBytes(&buf, 0, item)
+	}
+
+	return buf.Bytes()
+}
+
+// ReadList decodes a list of values encoded with List.
+func ReadList(b []byte) ([][]byte, error) {
+	r := bytes.NewReader(b)
+
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every item takes at least one byte for its length prefix.
+	if count > uint64(r.Len()) {
+		return nil, fmt.Errorf("%w: list count %d too large",
+			ErrInvalidLength, count)
+	}
+
+	items := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		item, err := wire.ReadVarBytes(
+			r, 0, MaxRecordSize, "tlv list item",
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes in list",
+			ErrInvalidLength, r.Len())
+	}
+
+	return items, nil
+}
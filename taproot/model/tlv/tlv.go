@@ -0,0 +1,198 @@
+package tlv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// MaxRecordSize is the maximum size of a single record value. It
+	// prevents large allocations when decoding untrusted streams.
+	MaxRecordSize = 1<<26 - 1 // Approx. 64 MB.
+)
+
+var (
+	// ErrRecordsOutOfOrder is returned when the records of a stream are not
+	// sorted by strictly increasing type.
+	ErrRecordsOutOfOrder = errors.New("tlv: records out of order")
+
+	// ErrRecordTooLarge is returned when a record value exceeds
+	// MaxRecordSize.
+	ErrRecordTooLarge = fmt.Errorf(
+		"tlv: record exceeds maximum size of %d bytes", MaxRecordSize,
+	)
+
+	// ErrMissingRecord is returned when a mandatory record is not present
+	// in a decoded stream.
+	ErrMissingRecord = errors.New("tlv: missing mandatory record")
+
+	// ErrUnknownRequiredType is returned when a decoded stream contains an
+	// unknown even type. Even types must be understood by the reader, odd
+	// types may safely be ignored.
+	ErrUnknownRequiredType = errors.New("tlv: unknown required type")
+
+	// ErrInvalidLength is returned when a fixed size value has an
+	// unexpected length.
+	ErrInvalidLength = errors.New("tlv: invalid value length")
+)
+
+// Type is the identifier of a single record within a stream.
+type Type uint64
+
+// Record is a single type-length-value entry of a stream.
+type Record struct {
+	Type  Type
+	Value []byte
+}
+
+// NewRecord creates a new record with the given type and value.
+func NewRecord(typ Type, value []byte) Record {
+	return Record{
+		Type:  typ,
+		Value: value,
+	}
+}
+
+// Stream is a decoded set of records keyed by their type.
+type Stream map[Type][]byte
+
+// EncodeStream writes the given records to w. Records MUST be passed in
+// strictly increasing type order, which makes the encoding canonical: the same
+// set of records always results in the same bytes.
+func EncodeStream(w io.Writer, records ...Record) error {
+	for idx := range records {
+		record := records[idx]
+
+		if idx > 0 && record.Type <= records[idx-1].Type {
+			return ErrRecordsOutOfOrder
+		}
+
+		if len(record.Value) > MaxRecordSize {
+			return ErrRecordTooLarge
+		}
+
+		if err := wire.WriteVarInt(w, 0, uint64(record.Type)); err != nil {
+			return err
+		}
+
+		err := wire.WriteVarBytes(w, 0, record.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Encode returns the encoded bytes of the given records.
+func Encode(records ...Record) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := EncodeStream(&buf, records...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeStream reads records from r until EOF. The records must be sorted by
+// strictly increasing type, otherwise ErrRecordsOutOfOrder is returned.
+func DecodeStream(r io.Reader) (Stream, error) {
+	var (
+		stream   = make(Stream)
+		prevType Type
+		first    = true
+	)
+
+	for {
+		typ, err := wire.ReadVarInt(r, 0)
+		if errors.Is(err, io.EOF) {
+			return stream, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !first && Type(typ) <= prevType {
+			return nil, ErrRecordsOutOfOrder
+		}
+
+		value, err := wire.ReadVarBytes(
+			r, 0, MaxRecordSize, "tlv record",
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		stream[Type(typ)] = value
+		prevType = Type(typ)
+		first = false
+	}
+}
+
+// Decode decodes the stream encoded within blob.
+func Decode(blob []byte) (Stream, error) {
+	return DecodeStream(bytes.NewReader(blob))
+}
+
+// Has returns true if the stream contains a record of the given type.
+func (s Stream) Has(typ Type) bool {
+	_, ok := s[typ]
+	return ok
+}
+
+// Require returns ErrMissingRecord if any of the given types is not present in
+// the stream.
+func (s Stream) Require(types ...Type) error {
+	for _, typ := range types {
+		if !s.Has(typ) {
+			return fmt.Errorf("%w: type %d", ErrMissingRecord, typ)
+		}
+	}
+
+	return nil
+}
+
+// CheckUnknown returns ErrUnknownRequiredType if the stream contains an even
+// type that is not within the list of known types.
+func (s Stream) CheckUnknown(known ...Type) error {
+	knownSet := make(map[Type]struct{}, len(known))
+	for _, typ := range known {
+		knownSet[typ] = struct{}{}
+	}
+
+	for typ := range s {
+		if _, ok := knownSet[typ]; ok || typ%2 == 1 {
+			continue
+		}
+
+		return fmt.Errorf("%w: type %d", ErrUnknownRequiredType, typ)
+	}
+
+	return nil
+}
+
+// DecodeKnown decodes the stream encoded within blob, makes sure all the given
+// mandatory types are present and that there are no unknown even types.
+func DecodeKnown(blob []byte, mandatory []Type,
+	optional ...Type) (Stream, error) {
+
+	stream, err := Decode(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Require(mandatory...); err != nil {
+		return nil, err
+	}
+
+	known := append(append([]Type{}, mandatory...), optional...)
+	if err := stream.CheckUnknown(known...); err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
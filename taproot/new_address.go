@@ -0,0 +1,32 @@
+package taproot
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/quocky/taproot-asset/taproot/address"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+)
+
+// NewAddress creates a Taproot Asset address to receive the given amount of
+// the asset. The received asset and its anchor output are both locked to the
+// wallet key.
+func (t *Taproot) NewAddress(assetID string, amount uint64) (*address.Tap, error) {
+	assetIDBytes, err := hex.DecodeString(assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(assetIDBytes) != len(asset.ID{}) {
+		return nil, fmt.Errorf("invalid asset id length %d", len(assetIDBytes))
+	}
+
+	walletKey := asset.ToSerialized(t.walletKey)
+
+	return t.addressMaker.NewAddress(asset.ID(assetIDBytes), amount, walletKey, walletKey)
+}
+
+// DecodeAddress decodes a Taproot Asset address of the network of the wallet.
+func (t *Taproot) DecodeAddress(addr string) (*address.Tap, error) {
+	return t.addressMaker.DecodeAddress(addr)
+}
@@ -0,0 +1,213 @@
+package onchain
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// bnbMaxTries bounds the search of the branch and bound coin selection.
+const bnbMaxTries = 100000
+
+// ErrInsufficientFunds is returned when the wallet UTXOs don't cover the
+// outputs and the fee of a transaction.
+var ErrInsufficientFunds = errors.New("not enough utxos to pay the outputs and the fee")
+
+// Coin is a wallet UTXO with its value net of the fee of spending it.
+type Coin struct {
+	UTXO           *UnspentTXOut
+	EffectiveValue btcutil.Amount
+}
+
+// CoinSelectionStrategy selects coins whose effective values add up to at
+// least the target. A selection adding up to more than the target plus the
+// cost of change pays the excess back in a change output, any excess below it
+// is left to the fee. The coins are sorted by descending effective value.
+type CoinSelectionStrategy func(coins []Coin, target, costOfChange btcutil.Amount) []Coin
+
+// BranchAndBound searches for a selection which needs no change output,
+// adding up to at most the cost of change more than the target. It returns
+// nil if there is none.
+func BranchAndBound(coins []Coin, target, costOfChange btcutil.Amount) []Coin {
+	// remaining[i] is the value of the coins from index i on.
+	remaining := make([]btcutil.Amount, len(coins)+1)
+	for i := len(coins) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + coins[i].EffectiveValue
+	}
+
+	var (
+		selected  = make([]bool, len(coins))
+		best      []bool
+		bestWaste btcutil.Amount
+		tries     = 0
+	)
+
+	var search func(depth int, value btcutil.Amount)
+	search = func(depth int, value btcutil.Amount) {
+		tries++
+
+		switch {
+		case tries > bnbMaxTries:
+			return
+
+		// Even with all remaining coins the target isn't reached, or
+		// the selection already overshoots.
+		case value+remaining[depth] < target, value > target+costOfChange:
+			return
+
+		case value >= target:
+			if waste := value - target; best == nil || waste < bestWaste {
+				best = append([]bool(nil), selected...)
+				bestWaste = waste
+			}
+
+			return
+
+		case depth == len(coins):
+			return
+		}
+
+		selected[depth] = true
+		search(depth+1, value+coins[depth].EffectiveValue)
+
+		selected[depth] = false
+		search(depth+1, value)
+	}
+
+	search(0, 0)
+
+	if best == nil {
+		return nil
+	}
+
+	selection := make([]Coin, 0, len(coins))
+	for i, ok := range best {
+		if ok {
+			selection = append(selection, coins[i])
+		}
+	}
+
+	return selection
+}
+
+// LargestFirst selects the largest coins until they pay for the target and a
+// change output, or, failing that, at least for the target. It returns nil if
+// all coins together don't reach the target.
+func LargestFirst(coins []Coin, target, costOfChange btcutil.Amount) []Coin {
+	var value btcutil.Amount
+	for i, c := range coins {
+		value += c.EffectiveValue
+
+		if value >= target+costOfChange {
+			return coins[:i+1]
+		}
+	}
+
+	if value >= target {
+		return coins
+	}
+
+	return nil
+}
+
+// DefaultCoinSelection are the strategies SelectUTXOs tries in turn.
+var DefaultCoinSelection = []CoinSelectionStrategy{BranchAndBound, LargestFirst}
+
+// SelectUTXOs selects the wallet UTXOs funding a transaction which spends
+// the asset anchor outputs, pays the outputs and sends change to the change
+// script, at the given fee rate.
+func SelectUTXOs(
+	utxos []*UnspentTXOut,
+	unspentAssets []*UnspentAssetsByIdResult,
+	outputs []*wire.TxOut,
+	changeScript []byte,
+	feeRate SatPerKVByte,
+	strategies ...CoinSelectionStrategy,
+) ([]*UnspentTXOut, error) {
+	if len(strategies) == 0 {
+		strategies = DefaultCoinSelection
+	}
+
+	tx := wire.NewMsgTx(2)
+	prevScripts := make([][]byte, 0, len(unspentAssets))
+
+	var target btcutil.Amount
+	for _, unspent := range unspentAssets {
+		tx.AddTxIn(wire.NewTxIn(unspent.Outpoint, nil, nil))
+		prevScripts = append(prevScripts, unspent.ScriptOutput)
+		target -= btcutil.Amount(unspent.AmtSats)
+	}
+
+	for _, txOut := range outputs {
+		tx.AddTxOut(txOut)
+		target += btcutil.Amount(txOut.Value)
+	}
+
+	baseVSize, err := EstimateVSize(tx, prevScripts)
+	if err != nil {
+		return nil, err
+	}
+
+	// One more virtual byte covers the segwit marker and flag, which the
+	// template may lack before the wallet inputs are added.
+	target += feeRate.FeeForVSize(baseVSize + 1)
+	if target <= 0 {
+		return nil, nil
+	}
+
+	coins := make([]Coin, 0, len(utxos))
+	for _, utxo := range utxos {
+		inputVSize, err := inputVSize(utxo.LockScript)
+		if err != nil {
+			continue
+		}
+
+		effectiveValue := utxo.Amount - feeRate.FeeForVSize(inputVSize)
+		if effectiveValue <= 0 {
+			continue
+		}
+
+		coins = append(coins, Coin{
+			UTXO:           utxo,
+			EffectiveValue: effectiveValue,
+		})
+	}
+
+	sort.SliceStable(coins, func(i, j int) bool {
+		return coins[i].EffectiveValue > coins[j].EffectiveValue
+	})
+
+	costOfChange := CostOfChange(feeRate, changeScript)
+
+	for _, strategy := range strategies {
+		selection := strategy(coins, target, costOfChange)
+		if selection == nil {
+			continue
+		}
+
+		selected := make([]*UnspentTXOut, len(selection))
+		for i, c := range selection {
+			selected[i] = c.UTXO
+		}
+
+		return selected, nil
+	}
+
+	return nil, ErrInsufficientFunds
+}
+
+// inputVSize returns the virtual size an input spending an output with the
+// given script adds to a segwit transaction, rounded up.
+func inputVSize(pkScript []byte) (int64, error) {
+	sigScriptSize, witnessSize, err := inputSize(pkScript)
+	if err != nil {
+		return 0, err
+	}
+
+	// The outpoint, the sequence and the length of the signature script.
+	baseSize := 36 + 4 + wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize
+
+	return int64((baseSize*4 + max(witnessSize, 1) + 3) / 4), nil
+}
@@ -0,0 +1,95 @@
+package onchain
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	p2wpkhScript = append([]byte{txscript.OP_0, txscript.OP_DATA_20}, make([]byte, 20)...)
+	p2trScript   = append([]byte{txscript.OP_1, txscript.OP_DATA_32}, make([]byte, 32)...)
+)
+
+func newUTXO(index uint32, amount btcutil.Amount) *UnspentTXOut {
+	return &UnspentTXOut{
+		Outpoint:   wire.NewOutPoint(&chainhash.Hash{1}, index),
+		LockScript: p2wpkhScript,
+		Amount:     amount,
+	}
+}
+
+func TestEstimateVSize(t *testing.T) {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 1), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, p2trScript))
+	tx.AddTxOut(wire.NewTxOut(1000, p2wpkhScript))
+
+	// A P2TR key spend and a P2WPKH spend paying to a P2TR and a P2WPKH
+	// output: 10.5 vB overhead, 57.5 and 68.5 vB inputs, 43 and 31 vB
+	// outputs.
+	vsize, err := EstimateVSize(tx, [][]byte{p2trScript, p2wpkhScript})
+	require.NoError(t, err)
+	require.EqualValues(t, 211, vsize)
+
+	_, err = EstimateVSize(tx, [][]byte{p2trScript})
+	require.Error(t, err)
+}
+
+func TestSelectUTXOs(t *testing.T) {
+	var (
+		feeRate = SatPerVByte(1)
+		outputs = []*wire.TxOut{wire.NewTxOut(1000, p2trScript)}
+	)
+
+	// The P2WPKH inputs cost 69 sat each, the transaction without them
+	// 10.5 + 43 = 54 sat, rounded up with the segwit marker.
+	t.Run("exact match without change", func(t *testing.T) {
+		utxos := []*UnspentTXOut{
+			newUTXO(0, 100_000),
+			newUTXO(1, 600),
+			newUTXO(2, 600),
+		}
+
+		selected, err := SelectUTXOs(utxos, nil, outputs, p2wpkhScript, feeRate)
+		require.NoError(t, err)
+		require.Equal(t, []*UnspentTXOut{utxos[1], utxos[2]}, selected)
+	})
+
+	t.Run("largest first with change", func(t *testing.T) {
+		utxos := []*UnspentTXOut{
+			newUTXO(0, 5_000),
+			newUTXO(1, 100_000),
+		}
+
+		selected, err := SelectUTXOs(utxos, nil, outputs, p2wpkhScript, feeRate)
+		require.NoError(t, err)
+		require.Equal(t, []*UnspentTXOut{utxos[1]}, selected)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		utxos := []*UnspentTXOut{
+			newUTXO(0, 500),
+			newUTXO(1, 500),
+		}
+
+		_, err := SelectUTXOs(utxos, nil, outputs, p2wpkhScript, feeRate)
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	})
+
+	t.Run("dust utxos are skipped", func(t *testing.T) {
+		utxos := []*UnspentTXOut{
+			newUTXO(0, 60),
+			newUTXO(1, 2_000),
+		}
+
+		selected, err := SelectUTXOs(utxos, nil, outputs, p2wpkhScript, SatPerVByte(2))
+		require.NoError(t, err)
+		require.Equal(t, []*UnspentTXOut{utxos[1]}, selected)
+	})
+}
@@ -0,0 +1,140 @@
+package onchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// SatPerKVByte is a fee rate in satoshis per 1000 virtual bytes.
+type SatPerKVByte btcutil.Amount
+
+const (
+	// FeeRateFloor is the lowest fee rate, the minimum relay fee of the
+	// node. Estimates below it are raised to it.
+	FeeRateFloor = SatPerKVByte(mempool.DefaultMinRelayTxFee)
+
+	// DefaultConfTarget is the number of blocks a transaction should
+	// confirm in when estimating its fee rate.
+	DefaultConfTarget = 6
+)
+
+// SatPerVByte returns the fee rate of the given satoshis per virtual byte.
+func SatPerVByte(satPerVByte uint64) SatPerKVByte {
+	return SatPerKVByte(satPerVByte * 1000)
+}
+
+// FeeForVSize returns the fee of a transaction of the given virtual size,
+// rounded up to whole satoshis.
+func (r SatPerKVByte) FeeForVSize(vsize int64) btcutil.Amount {
+	return btcutil.Amount((int64(r)*vsize + 999) / 1000)
+}
+
+func (r SatPerKVByte) String() string {
+	return fmt.Sprintf("%.3f sat/vB", float64(r)/1000)
+}
+
+// FeeEstimator estimates the fee rate a transaction needs to confirm within
+// the given number of blocks.
+type FeeEstimator interface {
+	EstimateFeeRate(confTarget uint32) (SatPerKVByte, error)
+}
+
+// StaticFeeEstimator estimates the same fee rate for every target. It stands
+// in for the node's estimate when a fee rate is given by the user or the
+// node has no estimate.
+type StaticFeeEstimator struct {
+	FeeRate SatPerKVByte
+}
+
+func NewStaticFeeEstimator(feeRate SatPerKVByte) *StaticFeeEstimator {
+	return &StaticFeeEstimator{
+		FeeRate: feeRate,
+	}
+}
+
+func (e *StaticFeeEstimator) EstimateFeeRate(uint32) (SatPerKVByte, error) {
+	return max(e.FeeRate, FeeRateFloor), nil
+}
+
+// FallbackFeeEstimator asks the primary estimator first and the fallback if
+// the primary fails.
+type FallbackFeeEstimator struct {
+	primary  FeeEstimator
+	fallback FeeEstimator
+}
+
+func NewFallbackFeeEstimator(primary, fallback FeeEstimator) *FallbackFeeEstimator {
+	return &FallbackFeeEstimator{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+func (e *FallbackFeeEstimator) EstimateFeeRate(confTarget uint32) (SatPerKVByte, error) {
+	feeRate, err := e.primary.EstimateFeeRate(confTarget)
+	if err == nil {
+		return feeRate, nil
+	}
+
+	return e.fallback.EstimateFeeRate(confTarget)
+}
+
+// EstimateFeeRate estimates the fee rate with estimatesmartfee, or with
+// estimatefee on nodes which don't implement it, like btcd.
+func (c *Client) EstimateFeeRate(confTarget uint32) (SatPerKVByte, error) {
+	var btcPerKVByte float64
+
+	result, err := c.client.EstimateSmartFee(int64(confTarget), &btcjson.EstimateModeConservative)
+	switch {
+	case err == nil && result.FeeRate != nil:
+		btcPerKVByte = *result.FeeRate
+
+	case err == nil:
+		return 0, fmt.Errorf("no fee estimate: %v", result.Errors)
+
+	default:
+		btcPerKVByte, err = c.client.EstimateFee(int64(confTarget))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if btcPerKVByte <= 0 {
+		return 0, errors.New("no fee estimate")
+	}
+
+	feeRate, err := btcutil.NewAmount(btcPerKVByte)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(SatPerKVByte(feeRate), FeeRateFloor), nil
+}
+
+// MinOutputValue returns the smallest value of an output with the script
+// which the node doesn't reject as dust.
+func MinOutputValue(pkScript []byte) btcutil.Amount {
+	threshold := mempool.GetDustThreshold(wire.NewTxOut(0, pkScript))
+
+	return btcutil.Amount((threshold*int64(mempool.DefaultMinRelayTxFee) + 999) / 1000)
+}
+
+// CostOfChange returns what a change output with the script costs at the fee
+// rate: the fee for its size and its smallest value. A leftover below it is
+// left to the fee instead of paying a change output.
+func CostOfChange(feeRate SatPerKVByte, changeScript []byte) btcutil.Amount {
+	return feeRate.FeeForVSize(int64(outputSize(changeScript))) +
+		MinOutputValue(changeScript)
+}
+
+// TaprootDustLimit is the smallest value of a taproot output, the outputs
+// anchoring assets.
+var TaprootDustLimit = MinOutputValue(
+	append([]byte{txscript.OP_1, txscript.OP_DATA_32}, make([]byte, 32)...),
+)
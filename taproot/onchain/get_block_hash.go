@@ -0,0 +1,10 @@
+package onchain
+
+import (
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// GetBlockHash returns the hash of the best chain block at the given height.
+func (c *Client) GetBlockHash(height int64) (*chainhash.Hash, error) {
+	return c.client.GetBlockHash(height)
+}
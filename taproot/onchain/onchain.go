@@ -1,6 +1,7 @@
 package onchain
 
 import (
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
@@ -10,19 +11,22 @@ import (
 )
 
 type Interface interface {
+	FeeEstimator
+
 	OpenWallet() error
-	DumpWIF() (*btcutil.WIF, error)
+	WalletPubKey() (*btcec.PublicKey, error)
 	ListUTXOs() ([]*UnspentTXOut, error)
 	NewTxMaker(UTXOs []*UnspentTXOut,
 		unspentAssets []*UnspentAssetsByIdResult,
 		receivers []*BtcOutputInfo,
 		senderBtcAddress btcutil.Address,
-		fee btcutil.Amount,
+		feeRate SatPerKVByte,
 	) (*TxMaker, error)
 
 	SendRawTx(rawTx *wire.MsgTx) (*chainhash.Hash, error)
 	SignRawTx(rawTx *wire.MsgTx) (*wire.MsgTx, error)
 	GetSenderAddress() (btcutil.Address, error)
+	GetBlockHash(height int64) (*chainhash.Hash, error)
 }
 
 type Client struct {
@@ -0,0 +1,96 @@
+package onchain
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// p2pkhSigScriptSize is the size of a signature script spending a
+	// P2PKH output: a DER signature of up to 72 bytes with its sighash
+	// type and a compressed public key, each with its push opcode.
+	p2pkhSigScriptSize = 1 + 73 + 1 + 33
+
+	// nestedP2WPKHSigScriptSize is the size of the signature script
+	// spending a P2WPKH output nested in P2SH, the push of the witness
+	// program.
+	nestedP2WPKHSigScriptSize = 1 + 22
+
+	// p2wpkhWitnessSize is the size of the witness spending a P2WPKH
+	// output: the item count, the signature and the public key with their
+	// lengths.
+	p2wpkhWitnessSize = 1 + 1 + 73 + 1 + 33
+
+	// taprootKeySpendWitnessSize is the size of the witness spending a
+	// taproot output through the key path with the default sighash type:
+	// the item count and the 64 byte schnorr signature with its length.
+	taprootKeySpendWitnessSize = 1 + 1 + 64
+)
+
+// inputSize returns the size of the signature script and the witness of an
+// input spending an output with the given script.
+func inputSize(pkScript []byte) (int, int, error) {
+	switch class := txscript.GetScriptClass(pkScript); class {
+	case txscript.PubKeyHashTy:
+		return p2pkhSigScriptSize, 0, nil
+
+	case txscript.ScriptHashTy:
+		return nestedP2WPKHSigScriptSize, p2wpkhWitnessSize, nil
+
+	case txscript.WitnessV0PubKeyHashTy:
+		return 0, p2wpkhWitnessSize, nil
+
+	case txscript.WitnessV1TaprootTy:
+		return 0, taprootKeySpendWitnessSize, nil
+
+	default:
+		return 0, 0, fmt.Errorf("unsupported input script class %v", class)
+	}
+}
+
+// EstimateVSize returns the virtual size of the transaction once its inputs,
+// which spend outputs with the given scripts, are signed.
+func EstimateVSize(tx *wire.MsgTx, prevScripts [][]byte) (int64, error) {
+	if len(prevScripts) != len(tx.TxIn) {
+		return 0, fmt.Errorf("got %d previous output scripts for %d inputs",
+			len(prevScripts), len(tx.TxIn))
+	}
+
+	var (
+		baseSize    = tx.SerializeSizeStripped()
+		witnessSize = 0
+		hasWitness  = false
+	)
+
+	for i, txIn := range tx.TxIn {
+		sigScriptSize, inputWitnessSize, err := inputSize(prevScripts[i])
+		if err != nil {
+			return 0, err
+		}
+
+		// The stripped size counts the unsigned script instead.
+		baseSize += wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize -
+			wire.VarIntSerializeSize(uint64(len(txIn.SignatureScript))) - len(txIn.SignatureScript)
+
+		// Inputs without witness still have an empty witness once
+		// the transaction has one.
+		witnessSize += max(inputWitnessSize, 1)
+		hasWitness = hasWitness || inputWitnessSize > 0
+	}
+
+	weight := int64(baseSize * blockchain.WitnessScaleFactor)
+	if hasWitness {
+		// The segwit marker and flag.
+		weight += int64(2 + witnessSize)
+	}
+
+	return (weight + blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor, nil
+}
+
+// outputSize returns the serialized size of an output with the script.
+func outputSize(pkScript []byte) int {
+	return wire.NewTxOut(0, pkScript).SerializeSize()
+}
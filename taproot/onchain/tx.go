@@ -1,8 +1,10 @@
 package onchain
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"log"
 
 	"github.com/btcsuite/btcd/btcec/v2"
@@ -17,11 +19,25 @@ type TxIncludeOutPubKey struct {
 	OutPubKeys map[int32]asset.SerializedKey
 }
 
+var (
+	// ErrAnchorKeyMismatch is returned when the internal key and tapscript
+	// root of an asset anchor input don't commit to its output script.
+	ErrAnchorKeyMismatch = errors.New("anchor internal key and tapscript " +
+		"root don't match output script")
+)
+
+// UnspentAssetsByIdResult is an anchor output of assets that is spent by a
+// transaction through the taproot key path.
 type UnspentAssetsByIdResult struct {
-	Outpoint         *wire.OutPoint
-	AmtSats          int64
-	ScriptOutput     []byte
-	InternalKey      []byte
+	Outpoint     *wire.OutPoint
+	AmtSats      int64
+	ScriptOutput []byte
+
+	// InternalKey is the compressed internal key of the anchor output.
+	InternalKey []byte
+
+	// TaprootAssetRoot is the tapscript root hash committing to the
+	// assets of the anchor output.
 	TaprootAssetRoot []byte
 }
 
@@ -31,10 +47,13 @@ type TxMaker struct {
 	unspentAssets  []*UnspentAssetsByIdResult
 	senderAddress  btcutil.Address
 	btcOutputInfos []*BtcOutputInfo
-	fee            btcutil.Amount
+	feeRate        SatPerKVByte
 
 	Tx            *wire.MsgTx
 	OutputPubKeys map[int32]asset.SerializedKey
+
+	// Fee is the fee paid by the transaction created by CreateTemplateTx.
+	Fee btcutil.Amount
 }
 
 func (c *Client) NewTxMaker(
@@ -42,7 +61,7 @@ func (c *Client) NewTxMaker(
 	unspentAssets []*UnspentAssetsByIdResult,
 	outputInfos []*BtcOutputInfo,
 	senderBtcAddress btcutil.Address,
-	fee btcutil.Amount,
+	feeRate SatPerKVByte,
 ) (*TxMaker, error) {
 
 	return &TxMaker{
@@ -50,28 +69,37 @@ func (c *Client) NewTxMaker(
 		unspentAssets:  unspentAssets,
 		senderAddress:  senderBtcAddress,
 		btcOutputInfos: outputInfos,
-		fee:            fee,
+		feeRate:        feeRate,
 		Tx:             nil,
 		OutputPubKeys:  make(map[int32]asset.SerializedKey),
 	}, nil
 }
 
+// CreateTemplateTx creates the unsigned transaction spending the asset anchor
+// inputs and the wallet UTXOs to the outputs. The fee is the fee rate applied
+// to the virtual size of the signed transaction. What is left over goes to a
+// change output of the sender, unless it would be dust.
 func (t *TxMaker) CreateTemplateTx() error {
 	tx := wire.NewMsgTx(2)
 
-	inputAmount := btcutil.Amount(0)
-	outputAmount := btcutil.Amount(0)
+	var (
+		inputAmount  = btcutil.Amount(0)
+		outputAmount = btcutil.Amount(0)
+		prevScripts  = make([][]byte, 0, len(t.unspentAssets)+len(t.UTXOs))
+	)
 
-	if t.unspentAssets != nil { // TODO:
-		for _, unspent := range t.unspentAssets {
-			inputAmount += btcutil.Amount(unspent.AmtSats)
-			tx.AddTxIn(wire.NewTxIn(unspent.Outpoint, nil, nil))
-		}
+	// The asset anchor inputs come first, so their input index is their
+	// index in the list of unspent assets.
+	for _, unspent := range t.unspentAssets {
+		inputAmount += btcutil.Amount(unspent.AmtSats)
+		tx.AddTxIn(wire.NewTxIn(unspent.Outpoint, nil, nil))
+		prevScripts = append(prevScripts, unspent.ScriptOutput)
 	}
 
 	for _, u := range t.UTXOs {
 		inputAmount += u.Amount
 		tx.AddTxIn(wire.NewTxIn(u.Outpoint, nil, nil))
+		prevScripts = append(prevScripts, u.LockScript)
 	}
 
 	for i, output := range t.btcOutputInfos {
@@ -86,19 +114,35 @@ func (t *TxMaker) CreateTemplateTx() error {
 		tx.AddTxOut(wire.NewTxOut(int64(output.SatAmount), pkScript))
 	}
 
-	if outputAmount > inputAmount {
-		return errors.New("output amount is greater than input amount")
+	vsize, err := EstimateVSize(tx, prevScripts)
+	if err != nil {
+		return err
 	}
 
-	if inputAmount-outputAmount-t.fee > 0 {
-		pkScript, err := txscript.PayToAddrScript(t.senderAddress)
-		if err != nil {
-			return err
-		}
-		tx.AddTxOut(wire.NewTxOut(int64(inputAmount-outputAmount-t.fee), pkScript))
+	fee := t.feeRate.FeeForVSize(vsize)
+	if inputAmount < outputAmount+fee {
+		return fmt.Errorf("%w: inputs %v, outputs %v, fee %v",
+			ErrInsufficientFunds, inputAmount, outputAmount, fee)
+	}
+
+	changeScript, err := txscript.PayToAddrScript(t.senderAddress)
+	if err != nil {
+		return err
+	}
+
+	feeWithChange := t.feeRate.FeeForVSize(vsize + int64(outputSize(changeScript)))
+	change := inputAmount - outputAmount - feeWithChange
+
+	if change >= MinOutputValue(changeScript) {
+		tx.AddTxOut(wire.NewTxOut(int64(change), changeScript))
+		fee = feeWithChange
+	} else {
+		fee = inputAmount - outputAmount
 	}
 
 	t.Tx = tx
+	t.Fee = fee
+
 	return nil
 }
 
@@ -165,7 +209,6 @@ func (t *TxMaker) createPrevOutFetchers() *txscript.MultiPrevOutFetcher {
 		prevOutFetchers.AddPrevOut(*u.Outpoint, wire.NewTxOut(int64(u.Amount), u.LockScript))
 	}
 
-	// TODO:
 	for _, unspent := range t.unspentAssets {
 		prevOutFetchers.AddPrevOut(*unspent.Outpoint, wire.NewTxOut(int64(unspent.AmtSats), unspent.ScriptOutput))
 	}
@@ -173,32 +216,62 @@ func (t *TxMaker) createPrevOutFetchers() *txscript.MultiPrevOutFetcher {
 	return prevOutFetchers
 }
 
-// SignTaprootInput function sign only taproot input in onchain transaction
-func (t *TxMaker) SignTaprootInput(privKey *btcec.PrivateKey) error {
+// TaprootSigner signs the taproot key spend of transaction inputs.
+type TaprootSigner interface {
+	// SignTaprootInput returns the BIP-341 key spend signature of the
+	// input with the given index, whose output key is the internal key
+	// of the signer tweaked with the tapscript root.
+	SignTaprootInput(tx *wire.MsgTx, prevOuts txscript.PrevOutputFetcher,
+		idx int, tapscriptRoot []byte) ([]byte, error)
+}
+
+// SignTaprootInput signs the asset anchor inputs of the transaction through the
+// taproot key path. The internal key of the signer is the internal key of the
+// anchor outputs, which is tweaked with the tapscript root committing to their
+// assets.
+//
+// The signatures commit to all inputs and outputs, so the transaction must
+// not be modified afterwards. The inputs of the wallet are signed separately.
+func (t *TxMaker) SignTaprootInput(signer TaprootSigner) error {
 	prevOutFetchers := t.createPrevOutFetchers()
 
-	if t.unspentAssets != nil {
+	for index, unspent := range t.unspentAssets {
+		if err := checkAnchorScript(unspent); err != nil {
+			return err
+		}
 
-		for index, unspent := range t.unspentAssets {
+		sig, err := signer.SignTaprootInput(
+			t.Tx, prevOutFetchers, index, unspent.TaprootAssetRoot,
+		)
+		if err != nil {
+			return err
+		}
 
-			sigHashes := txscript.NewTxSigHashes(t.Tx, prevOutFetchers)
-			tapScriptRootHash := unspent.TaprootAssetRoot
+		t.Tx.TxIn[index].Witness = wire.TxWitness{sig}
+	}
 
-			sig, err := txscript.RawTxInTaprootSignature(
-				t.Tx, sigHashes, index,
-				unspent.AmtSats,
-				unspent.InternalKey,
-				tapScriptRootHash,
-				txscript.SigHashSingle,
-				privKey,
-			)
-			if err != nil {
-				return err
-			}
+	return nil
+}
 
-			t.Tx.TxIn[index].Witness = wire.TxWitness{sig}
-		}
+// checkAnchorScript checks that the output script of an asset anchor input is
+// the taproot output key derived from its internal key and tapscript root.
+func checkAnchorScript(unspent *UnspentAssetsByIdResult) error {
+	internalKey, err := btcec.ParsePubKey(unspent.InternalKey)
+	if err != nil {
+		return err
+	}
+
+	outputKey := txscript.ComputeTaprootOutputKey(
+		internalKey, unspent.TaprootAssetRoot,
+	)
+
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+	if err != nil {
+		return err
+	}
 
+	if !bytes.Equal(pkScript, unspent.ScriptOutput) {
+		return ErrAnchorKeyMismatch
 	}
 
 	return nil
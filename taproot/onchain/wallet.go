@@ -1,10 +1,12 @@
 package onchain
 
 import (
+	"encoding/hex"
+	"fmt"
 	"log"
 	"os"
 
-	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcec/v2"
 )
 
 func (c *Client) OpenWallet() error {
@@ -17,22 +19,27 @@ func (c *Client) OpenWallet() error {
 	return nil
 }
 
-func (c *Client) DumpWIF() (*btcutil.WIF, error) {
-	err := c.OpenWallet()
+// WalletPubKey returns the public key of the sender address of the wallet. The
+// private key stays in the wallet.
+func (c *Client) WalletPubKey() (*btcec.PublicKey, error) {
+	defaultAddress, err := c.GetSenderAddress()
 	if err != nil {
 		return nil, err
 	}
 
-	defaultAddress, err := btcutil.DecodeAddress(c.networkConfig.SenderAddress, c.networkConfig.ParamsObject)
+	result, err := c.client.ValidateAddress(defaultAddress)
 	if err != nil {
 		return nil, err
 	}
 
-	wif, err := c.client.DumpPrivKey(defaultAddress)
+	if !result.IsMine || result.PubKey == "" {
+		return nil, fmt.Errorf("sender address %s isn't a key of the wallet", defaultAddress)
+	}
+
+	pubKeyBytes, err := hex.DecodeString(result.PubKey)
 	if err != nil {
 		return nil, err
 	}
 
-	return wif, nil
-
+	return btcec.ParsePubKey(pubKeyBytes)
 }
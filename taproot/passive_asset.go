@@ -0,0 +1,215 @@
+package taproot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	assetoutpointmodel "github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
+	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/quocky/taproot-asset/taproot/model/packet"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+)
+
+// passiveAssets are the assets anchored alongside the asset outpoints spent by
+// a transfer without being transferred themselves. They are re-anchored in the
+// return output of the transfer, so their provenance continues from the proof
+// files of their previous outpoints.
+type passiveAssets struct {
+	// assets are the re-anchored assets, still to be signed.
+	assets []*asset.Asset
+
+	// inputs are the previous outpoints spent by the re-anchored assets.
+	inputs []*packet.VInput
+
+	// outpoints are the previous outpoints of the passive assets, marked
+	// spent together with the transferred outpoints.
+	outpoints []*assetoutpointmodel.UnspentOutpoint
+}
+
+// spentWith returns the given transferred outpoints followed by the previous
+// outpoints of the passive assets.
+func (p *passiveAssets) spentWith(
+	unspentOutpoints []*assetoutpointmodel.UnspentOutpoint,
+) []*assetoutpointmodel.UnspentOutpoint {
+
+	spent := make([]*assetoutpointmodel.UnspentOutpoint, 0, len(unspentOutpoints)+len(p.outpoints))
+	spent = append(spent, unspentOutpoints...)
+
+	return append(spent, p.outpoints...)
+}
+
+// passiveInput is the previous outpoint of a passive asset.
+type passiveInput struct {
+	input     commitment.SplitCommitmentInput
+	fileBytes []byte
+	outpoint  *assetoutpointmodel.UnspentOutpoint
+}
+
+// vInput returns the virtual input spending the passive outpoint.
+func (p *passiveInput) vInput() *packet.VInput {
+	return packet.NewVInput(p.input.Asset, p.input.OutPoint, p.fileBytes)
+}
+
+// collectPassiveAssets re-anchors the assets of value held in the anchor
+// outputs of the given outpoints which aren't spent by the transfer. Each
+// passive asset spends its previous outpoint to the wallet key again, and the
+// outpoints of an asset spread over several anchor outputs are merged into one.
+// Zero value roots of earlier splits are left behind, they are locked to the
+// NUMS key and carry nothing.
+//
+// Assets locked to another key can't be re-anchored by the wallet, and assets
+// of the transferred IDs would take the leaf of the transferred asset, so both
+// fail the transfer instead of being destroyed with their anchor output.
+func (t *Taproot) collectPassiveAssets(
+	ctx context.Context,
+	unspentOutpoints []*assetoutpointmodel.UnspentOutpoint,
+	activeIDs map[asset.ID]struct{},
+	headerVerifier proof.HeaderVerifier,
+) (*passiveAssets, error) {
+	var (
+		walletKey = asset.ToSerialized(t.walletKey)
+		seen      = make(map[string]struct{}, len(unspentOutpoints))
+		assetIDs  = make([]asset.ID, 0)
+		inputs    = make(map[asset.ID][]*passiveInput)
+	)
+
+	for _, uo := range unspentOutpoints {
+		seen[uo.ID] = struct{}{}
+	}
+
+	for _, uo := range unspentOutpoints {
+		if len(uo.RelatedAnchorAssets) != len(uo.RelatedAnchorAssetProofs) {
+			return nil, fmt.Errorf("anchor output %s: got %d proof files for %d related assets",
+				uo.Outpoint, len(uo.RelatedAnchorAssetProofs), len(uo.RelatedAnchorAssets))
+		}
+
+		for idx, relatedBytes := range uo.RelatedAnchorAssets {
+			var related assetoutpointmodel.UnspentOutpoint
+			if err := json.Unmarshal(relatedBytes, &related); err != nil {
+				return nil, err
+			}
+
+			// The outpoints of a shared anchor output list each other.
+			if _, ok := seen[related.ID]; ok || related.Amount == 0 {
+				continue
+			}
+			seen[related.ID] = struct{}{}
+
+			// The passive outpoint is spent through the same anchor
+			// output.
+			related.Outpoint = uo.Outpoint
+			related.AmtSats = uo.AmtSats
+			related.InternalKey = uo.InternalKey
+			related.TaprootAssetRoot = uo.TaprootAssetRoot
+			related.ScriptOutput = uo.ScriptOutput
+			related.TxID = uo.TxID
+
+			in, err := verifyPassiveInput(ctx, &related, uo.RelatedAnchorAssetProofs[idx], headerVerifier)
+			if err != nil {
+				return nil, err
+			}
+
+			prevAsset := in.input.Asset
+			if prevAsset.ScriptPubkey != walletKey {
+				return nil, fmt.Errorf("anchor output %s holds asset outpoint %s locked to another key",
+					uo.Outpoint, related.ID)
+			}
+
+			assetID := prevAsset.ID()
+			if _, ok := activeIDs[assetID]; ok {
+				return nil, fmt.Errorf("anchor output %s holds asset outpoint %s of a transferred "+
+					"asset which isn't spent", uo.Outpoint, related.ID)
+			}
+
+			if _, ok := inputs[assetID]; !ok {
+				assetIDs = append(assetIDs, assetID)
+			}
+			inputs[assetID] = append(inputs[assetID], in)
+		}
+	}
+
+	passive := &passiveAssets{
+		assets: make([]*asset.Asset, 0, len(assetIDs)),
+	}
+
+	for _, assetID := range assetIDs {
+		newAsset, err := reAnchorAsset(inputs[assetID], walletKey)
+		if err != nil {
+			return nil, fmt.Errorf("re-anchor asset %x: %w", assetID[:], err)
+		}
+
+		for _, in := range inputs[assetID] {
+			passive.inputs = append(passive.inputs, in.vInput())
+			passive.outpoints = append(passive.outpoints, in.outpoint)
+		}
+
+		passive.assets = append(passive.assets, newAsset)
+	}
+
+	return passive, nil
+}
+
+// reAnchorAsset creates the asset spending the given outpoints of a passive
+// asset to the script key again.
+func reAnchorAsset(inputs []*passiveInput,
+	scriptKey asset.SerializedKey) (*asset.Asset, error) {
+
+	if len(inputs) == 1 {
+		passiveAsset, err := commitment.NewPassiveAsset(inputs[0].input)
+		if err != nil {
+			return nil, err
+		}
+
+		return passiveAsset.Asset, nil
+	}
+
+	mergeInputs := make([]commitment.SplitCommitmentInput, len(inputs))
+	for idx, in := range inputs {
+		mergeInputs[idx] = in.input
+	}
+
+	merge, err := commitment.NewMerge(mergeInputs, scriptKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return merge.Asset, nil
+}
+
+// verifyPassiveInput verifies the proof file of a passive asset outpoint and
+// returns the asset of its last snapshot, which has to match the outpoint.
+func verifyPassiveInput(
+	ctx context.Context,
+	outpoint *assetoutpointmodel.UnspentOutpoint,
+	fileBytes []byte,
+	headerVerifier proof.HeaderVerifier,
+) (*passiveInput, error) {
+	var f proof.File
+	if err := f.Decode(fileBytes); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := f.Verify(ctx, headerVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proof file of asset outpoint %s: %w", outpoint.ID, err)
+	}
+
+	if snapshot.OutPoint.String() != outpoint.Outpoint ||
+		snapshot.Asset.Amount != outpoint.Amount ||
+		!bytes.Equal(snapshot.Asset.ScriptPubkey[:], outpoint.ScriptKey) {
+
+		return nil, fmt.Errorf("proof file doesn't prove asset outpoint %s", outpoint.ID)
+	}
+
+	return &passiveInput{
+		input: commitment.SplitCommitmentInput{
+			Asset:    snapshot.Asset.Copy(),
+			OutPoint: snapshot.OutPoint,
+		},
+		fileBytes: fileBytes,
+		outpoint:  outpoint,
+	}, nil
+}
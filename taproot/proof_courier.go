@@ -0,0 +1,94 @@
+package taproot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/quocky/taproot-asset/taproot/http_model/courier"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+)
+
+// HTTPCourier is a proof.ProofCourier that delivers and receives proof files
+// through the proof endpoints of the server.
+type HTTPCourier struct {
+	httpClient *resty.Client
+	baseURL    string
+}
+
+// A compile time check to ensure HTTPCourier implements proof.ProofCourier.
+var _ proof.ProofCourier = (*HTTPCourier)(nil)
+
+// NewHTTPCourier creates a new courier talking to the server at baseURL.
+func NewHTTPCourier(httpClient *resty.Client, baseURL string) *HTTPCourier {
+	return &HTTPCourier{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+	}
+}
+
+// DeliverProof uploads the proof file to the server.
+func (c *HTTPCourier) DeliverProof(ctx context.Context, loc proof.Locator,
+	f *proof.File) error {
+
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetBody(courier.DeliverProofReq{
+			Locator: courier.NewLocator(loc),
+			File:    f,
+		}).
+		Post(c.baseURL + "/proofs")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusNoContent {
+		return fmt.Errorf("deliver proof failed with status %d",
+			resp.StatusCode())
+	}
+
+	return nil
+}
+
+// ReceiveProof downloads the proof file of the locator from the server.
+func (c *HTTPCourier) ReceiveProof(ctx context.Context,
+	loc proof.Locator) (*proof.File, error) {
+
+	reqLoc := courier.NewLocator(loc)
+
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"asset_id":   reqLoc.AssetID,
+			"script_key": reqLoc.ScriptKey,
+			"outpoint":   reqLoc.OutPoint,
+		}).
+		Get(c.baseURL + "/proofs")
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+
+	case http.StatusNotFound:
+		return nil, proof.ErrProofNotFound
+
+	default:
+		return nil, fmt.Errorf("receive proof failed with status %d",
+			resp.StatusCode())
+	}
+
+	var proofResp courier.ReceiveProofResp
+	if err := json.Unmarshal(resp.Body(), &proofResp); err != nil {
+		return nil, err
+	}
+
+	if proofResp.File == nil {
+		return nil, proof.ErrProofNotFound
+	}
+
+	return proofResp.File, nil
+}
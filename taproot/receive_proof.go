@@ -0,0 +1,65 @@
+package taproot
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+)
+
+// ReceiveProof pulls the proof file of the asset received at the outpoint and
+// locked to the wallet key from the proof courier. The whole proof chain is
+// verified against the chain before the file is imported into the local proof
+// directory.
+func (t *Taproot) ReceiveProof(ctx context.Context, assetID string,
+	outPoint string) (*proof.AssetSnapshot, error) {
+
+	assetIDBytes, err := hex.DecodeString(assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(assetIDBytes) != len(asset.ID{}) {
+		return nil, fmt.Errorf("invalid asset id length %d", len(assetIDBytes))
+	}
+
+	op, err := wire.NewOutPointFromString(outPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := proof.Locator{
+		AssetID:   (*asset.ID)(assetIDBytes),
+		ScriptKey: asset.ToSerialized(t.walletKey),
+		OutPoint:  op,
+	}
+
+	file, err := t.proofCourier.ReceiveProof(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := file.Verify(ctx, proof.NewRPCHeaderVerifier(t.btcClient))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proof file: %w", err)
+	}
+
+	// The courier could hand out any valid file, so make sure it actually
+	// proves the asset we asked for.
+	if snapshot.Asset.ID() != *loc.AssetID ||
+		snapshot.Asset.ScriptPubkey != loc.ScriptKey ||
+		snapshot.OutPoint != *loc.OutPoint {
+
+		return nil, errors.New("proof file doesn't prove the requested asset")
+	}
+
+	if err := t.localProofs.DeliverProof(ctx, loc, file); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
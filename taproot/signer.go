@@ -0,0 +1,100 @@
+package taproot
+
+import (
+	"context"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/packet"
+	"github.com/quocky/taproot-asset/taproot/onchain"
+)
+
+// ErrSigningUnsupported is returned by a signer which can derive the keys of
+// the wallet but can't create the signatures asked for.
+var ErrSigningUnsupported = errors.New("signer can't create this signature")
+
+// Signer holds the wallet key, which locks the assets and the asset anchor
+// outputs of the wallet. The private key is never handed out, so it can live in
+// btcwallet, an encrypted keystore or another process.
+//
+// Keys are derived from the wallet key with a BIP-341 tweak: the key derived
+// with a tweak is the wallet key tweaked as the output key of a taproot output
+// whose tapscript root is the tweak. A nil tweak is the wallet key itself.
+type Signer interface {
+	// DeriveKey returns the public key derived from the wallet key with
+	// the tweak.
+	DeriveKey(ctx context.Context, tweak []byte) (*btcec.PublicKey, error)
+
+	// SignSchnorr returns the BIP-340 signature of the 32 byte digest by
+	// the key derived with the tweak.
+	SignSchnorr(ctx context.Context, tweak, digest []byte) (*schnorr.Signature, error)
+
+	// SignTaprootInput returns the BIP-341 key spend signature of the
+	// input with the given index, whose output key is the wallet key
+	// tweaked with the tapscript root.
+	SignTaprootInput(ctx context.Context, tx *wire.MsgTx,
+		prevOuts txscript.PrevOutputFetcher, idx int, tapscriptRoot []byte) ([]byte, error)
+}
+
+// boundSigner signs with a key derived from the wallet key within the context
+// of a single operation, for the signing code of the model packages.
+type boundSigner struct {
+	ctx    context.Context
+	signer Signer
+	tweak  []byte
+	pubKey *btcec.PublicKey
+}
+
+// Compile time checks to ensure boundSigner implements the signers of the
+// model packages.
+var (
+	_ asset.Signer          = (*boundSigner)(nil)
+	_ packet.AnchorSigner   = (*boundSigner)(nil)
+	_ onchain.TaprootSigner = (*boundSigner)(nil)
+)
+
+// bindSigner returns the signer of the key derived from the wallet key with the
+// tweak, bound to the context.
+func (t *Taproot) bindSigner(ctx context.Context, tweak []byte) (*boundSigner, error) {
+	pubKey := t.walletKey
+	if tweak != nil {
+		var err error
+		pubKey, err = t.signer.DeriveKey(ctx, tweak)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &boundSigner{
+		ctx:    ctx,
+		signer: t.signer,
+		tweak:  tweak,
+		pubKey: pubKey,
+	}, nil
+}
+
+// PubKey returns the derived public key.
+func (b *boundSigner) PubKey() *btcec.PublicKey {
+	return b.pubKey
+}
+
+// SignSchnorr signs the digest with the derived key.
+func (b *boundSigner) SignSchnorr(digest []byte) (*schnorr.Signature, error) {
+	return b.signer.SignSchnorr(b.ctx, b.tweak, digest)
+}
+
+// SignTaprootInput signs the key spend of a taproot input whose internal key is
+// the wallet key.
+func (b *boundSigner) SignTaprootInput(tx *wire.MsgTx,
+	prevOuts txscript.PrevOutputFetcher, idx int, tapscriptRoot []byte) ([]byte, error) {
+
+	if b.tweak != nil {
+		return nil, errors.New("taproot inputs are signed with the wallet key")
+	}
+
+	return b.signer.SignTaprootInput(b.ctx, tx, prevOuts, idx, tapscriptRoot)
+}
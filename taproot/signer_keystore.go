@@ -0,0 +1,149 @@
+package taproot
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keystoreVersion = 1
+
+	// scrypt parameters of new keystores, the ones recommended for
+	// interactive logins. They are stored in the keystore, so they can be
+	// raised without breaking existing keystores.
+	keystoreScryptN = 1 << 15
+	keystoreScryptR = 8
+	keystoreScryptP = 1
+)
+
+// ErrKeystorePassphrase is returned when a keystore can't be decrypted with
+// the given passphrase.
+var ErrKeystorePassphrase = errors.New("keystore: wrong passphrase")
+
+// keystoreFile is the content of a keystore file. The wallet key is encrypted
+// with AES-256-GCM under a key derived from the passphrase with scrypt. The
+// public key is authenticated along with it.
+type keystoreFile struct {
+	Version    int    `json:"version"`
+	PubKey     []byte `json:"pub_key"`
+	Salt       []byte `json:"salt"`
+	ScryptN    int    `json:"scrypt_n"`
+	ScryptR    int    `json:"scrypt_r"`
+	ScryptP    int    `json:"scrypt_p"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// CreateKeystore writes the wallet key to a new keystore file at path,
+// encrypted with the passphrase. An existing file is never overwritten.
+func CreateKeystore(path string, passphrase []byte, privKey *btcec.PrivateKey) error {
+	if len(passphrase) == 0 {
+		return errors.New("keystore: empty passphrase")
+	}
+
+	ks := keystoreFile{
+		Version: keystoreVersion,
+		PubKey:  privKey.PubKey().SerializeCompressed(),
+		Salt:    make([]byte, 32),
+		ScryptN: keystoreScryptN,
+		ScryptR: keystoreScryptR,
+		ScryptP: keystoreScryptP,
+	}
+
+	if _, err := rand.Read(ks.Salt); err != nil {
+		return err
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return err
+	}
+
+	ks.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(ks.Nonce); err != nil {
+		return err
+	}
+
+	ks.Ciphertext = aead.Seal(nil, ks.Nonce, privKey.Serialize(), ks.PubKey)
+
+	blob, err := json.Marshal(ks)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(blob); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// OpenKeystore decrypts the wallet key of the keystore file at path with the
+// passphrase, and returns a signer holding it in memory.
+func OpenKeystore(path string, passphrase []byte) (*MemSigner, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ks keystoreFile
+	if err := json.Unmarshal(blob, &ks); err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+
+	if ks.Version != keystoreVersion {
+		return nil, fmt.Errorf("keystore: unknown version %d", ks.Version)
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ks.Nonce) != aead.NonceSize() {
+		return nil, errors.New("keystore: invalid nonce")
+	}
+
+	keyBytes, err := aead.Open(nil, ks.Nonce, ks.Ciphertext, ks.PubKey)
+	if err != nil {
+		return nil, ErrKeystorePassphrase
+	}
+
+	privKey, pubKey := btcec.PrivKeyFromBytes(keyBytes)
+	if !bytes.Equal(pubKey.SerializeCompressed(), ks.PubKey) {
+		return nil, errors.New("keystore: private key doesn't match public key")
+	}
+
+	return NewMemSigner(privKey), nil
+}
+
+// aead returns the cipher encrypting the wallet key with the passphrase.
+func (k *keystoreFile) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, k.Salt, k.ScryptN, k.ScryptR, k.ScryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
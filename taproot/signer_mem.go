@@ -0,0 +1,67 @@
+package taproot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// MemSigner is a Signer holding the wallet key in memory. It is meant for
+// tests, and backs the keys unlocked from a keystore, see OpenKeystore.
+type MemSigner struct {
+	privKey *btcec.PrivateKey
+}
+
+// A compile time check to ensure MemSigner implements Signer.
+var _ Signer = (*MemSigner)(nil)
+
+// NewMemSigner creates a signer of the given wallet key.
+func NewMemSigner(privKey *btcec.PrivateKey) *MemSigner {
+	return &MemSigner{privKey: privKey}
+}
+
+// DeriveKey returns the public key derived from the wallet key with the tweak.
+func (m *MemSigner) DeriveKey(_ context.Context, tweak []byte) (*btcec.PublicKey, error) {
+	return m.derivePrivKey(tweak).PubKey(), nil
+}
+
+// SignSchnorr signs the digest with the key derived with the tweak.
+func (m *MemSigner) SignSchnorr(_ context.Context, tweak,
+	digest []byte) (*schnorr.Signature, error) {
+
+	return schnorr.Sign(m.derivePrivKey(tweak), digest)
+}
+
+// SignTaprootInput signs the key spend of the input with the wallet key
+// tweaked with the tapscript root.
+func (m *MemSigner) SignTaprootInput(_ context.Context, tx *wire.MsgTx,
+	prevOuts txscript.PrevOutputFetcher, idx int, tapscriptRoot []byte) ([]byte, error) {
+
+	if idx < 0 || idx >= len(tx.TxIn) {
+		return nil, fmt.Errorf("invalid input index %d", idx)
+	}
+
+	prevOut := prevOuts.FetchPrevOutput(tx.TxIn[idx].PreviousOutPoint)
+	if prevOut == nil {
+		return nil, fmt.Errorf("unknown previous output of input %d", idx)
+	}
+
+	return txscript.RawTxInTaprootSignature(
+		tx, txscript.NewTxSigHashes(tx, prevOuts), idx, prevOut.Value,
+		prevOut.PkScript, tapscriptRoot, txscript.SigHashDefault, m.privKey,
+	)
+}
+
+// derivePrivKey returns the private key derived from the wallet key with the
+// tweak.
+func (m *MemSigner) derivePrivKey(tweak []byte) *btcec.PrivateKey {
+	if tweak == nil {
+		return m.privKey
+	}
+
+	return txscript.TweakTaprootPrivKey(*m.privKey, tweak)
+}
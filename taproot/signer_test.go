@@ -0,0 +1,109 @@
+package taproot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeriveKey makes sure the keys derived by a signer are the BIP-341 tweaks
+// of the wallet key, and that a watch-only signer derives the same keys but
+// can't sign with them.
+func TestDeriveKey(t *testing.T) {
+	ctx := context.Background()
+
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	memSigner := NewMemSigner(privKey)
+	walletSigner := &WalletSigner{pubKey: privKey.PubKey()}
+
+	for _, tweak := range [][]byte{nil, groupKeyTweak("ticker")} {
+		pubKey, err := memSigner.DeriveKey(ctx, tweak)
+		require.NoError(t, err)
+
+		wantKey := privKey.PubKey()
+		if tweak != nil {
+			wantKey = txscript.ComputeTaprootOutputKey(wantKey, tweak)
+		}
+		require.True(t, pubKey.IsEqual(wantKey))
+
+		walletPubKey, err := walletSigner.DeriveKey(ctx, tweak)
+		require.NoError(t, err)
+		require.True(t, pubKey.IsEqual(walletPubKey))
+
+		digest := chainhash.HashB([]byte("digest"))
+		sig, err := memSigner.SignSchnorr(ctx, tweak, digest)
+		require.NoError(t, err)
+		require.True(t, sig.Verify(digest, pubKey))
+
+		_, err = walletSigner.SignSchnorr(ctx, tweak, digest)
+		require.ErrorIs(t, err, ErrSigningUnsupported)
+	}
+}
+
+// TestMemSignerTaprootInput makes sure the key spend signature of an anchor
+// input is valid under its output script.
+func TestMemSignerTaprootInput(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	tapscriptRoot := chainhash.HashB([]byte("assets"))
+	pkScript, err := txscript.PayToTaprootScript(
+		txscript.ComputeTaprootOutputKey(privKey.PubKey(), tapscriptRoot),
+	)
+	require.NoError(t, err)
+
+	prevOut := wire.NewTxOut(1000, pkScript)
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{1}}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(500, pkScript))
+
+	prevOuts := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+
+	sig, err := NewMemSigner(privKey).SignTaprootInput(
+		context.Background(), tx, prevOuts, 0, tapscriptRoot,
+	)
+	require.NoError(t, err)
+
+	tx.TxIn[0].Witness = wire.TxWitness{sig}
+	engine, err := txscript.NewEngine(
+		prevOut.PkScript, tx, 0, txscript.StandardVerifyFlags, nil,
+		txscript.NewTxSigHashes(tx, prevOuts), prevOut.Value, prevOuts,
+	)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+}
+
+// TestKeystore makes sure a keystore only opens with its passphrase and is
+// never overwritten.
+func TestKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	passphrase := []byte("passphrase")
+
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	require.Error(t, CreateKeystore(path, nil, privKey))
+	require.NoError(t, CreateKeystore(path, passphrase, privKey))
+
+	otherKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	require.Error(t, CreateKeystore(path, passphrase, otherKey))
+
+	_, err = OpenKeystore(path, []byte("wrong"))
+	require.ErrorIs(t, err, ErrKeystorePassphrase)
+
+	signer, err := OpenKeystore(path, passphrase)
+	require.NoError(t, err)
+
+	pubKey, err := signer.DeriveKey(context.Background(), nil)
+	require.NoError(t, err)
+	require.True(t, pubKey.IsEqual(privKey.PubKey()))
+}
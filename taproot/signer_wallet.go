@@ -0,0 +1,69 @@
+package taproot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/onchain"
+)
+
+// WalletSigner is a watch-only Signer whose wallet key is the key of the
+// sender address in btcwallet. The private key never leaves btcwallet, only its
+// public key is looked up over RPC.
+//
+// The RPC interface of btcwallet only signs the wallet's own outputs, with
+// signrawtransaction. It has no call creating BIP-340 signatures over a digest
+// or signing an output tweaked with a tapscript root, which every asset spend
+// and asset anchor spend needs, so these fail with ErrSigningUnsupported. Keys
+// can still be derived, to create addresses, receive proofs and look up the
+// assets of the wallet, while spending them needs a signer holding the key,
+// see CreateKeystore.
+type WalletSigner struct {
+	pubKey *btcec.PublicKey
+}
+
+// A compile time check to ensure WalletSigner implements Signer.
+var _ Signer = (*WalletSigner)(nil)
+
+// NewWalletSigner creates a signer of the key of the sender address of the
+// wallet.
+func NewWalletSigner(btcClient onchain.Interface) (*WalletSigner, error) {
+	pubKey, err := btcClient.WalletPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("wallet key: %w", err)
+	}
+
+	return &WalletSigner{pubKey: pubKey}, nil
+}
+
+// DeriveKey returns the public key derived from the wallet key with the tweak.
+// Taproot tweaks are public, so no private key is needed.
+func (w *WalletSigner) DeriveKey(_ context.Context, tweak []byte) (*btcec.PublicKey, error) {
+	if tweak == nil {
+		return w.pubKey, nil
+	}
+
+	return txscript.ComputeTaprootOutputKey(w.pubKey, tweak), nil
+}
+
+// SignSchnorr always fails, btcwallet can't create BIP-340 signatures.
+func (w *WalletSigner) SignSchnorr(context.Context, []byte,
+	[]byte) (*schnorr.Signature, error) {
+
+	return nil, fmt.Errorf("btcwallet can't create the BIP-340 signature "+
+		"of an asset, spending it needs a keystore: %w",
+		ErrSigningUnsupported)
+}
+
+// SignTaprootInput always fails, btcwallet can't sign outputs tweaked with a
+// tapscript root.
+func (w *WalletSigner) SignTaprootInput(context.Context, *wire.MsgTx,
+	txscript.PrevOutputFetcher, int, []byte) ([]byte, error) {
+
+	return nil, fmt.Errorf("btcwallet can't sign an asset anchor input, "+
+		"spending it needs a keystore: %w", ErrSigningUnsupported)
+}
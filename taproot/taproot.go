@@ -2,50 +2,94 @@ package taproot
 
 import (
 	"context"
+	"os"
+
 	"go.uber.org/zap"
 
-	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/go-resty/resty/v2"
 	"github.com/quocky/taproot-asset/taproot/address"
+	"github.com/quocky/taproot-asset/taproot/http_model/transfer"
+	universesdk "github.com/quocky/taproot-asset/taproot/http_model/universe"
 	utxoasset "github.com/quocky/taproot-asset/taproot/http_model/utxo_asset"
 	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/packet"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
 	"github.com/quocky/taproot-asset/taproot/onchain"
+	"github.com/quocky/taproot-asset/taproot/universe"
 )
 
 const (
-	// mock fee for every transaction on bitcoin chain
-	DEFAULT_FEE = 1000
-
-	// amount on each output contain asset commitment
-	DEFAULT_OUTPUT_AMOUNT = 50
+	// fee rate in sat/kvB used when the node has no fee estimate
+	DEFAULT_FEE_RATE = 10_000
 
 	DEFAULT_MINTING_OUTPUT_INDEX = 0
 
 	DEFAULT_RETURN_OUTPUT_INDEX = 0
 
 	DEFAULT_TRANSFER_OUTPUT_INDEX = 1
+
+	// directory the received proof files are imported into
+	DEFAULT_PROOF_DIR = "proofs"
 )
 
+// anchorOutputValue is the amount on each output containing an asset
+// commitment, the smallest amount which isn't dust.
+var anchorOutputValue = int32(onchain.TaprootDustLimit)
+
 type Interface interface {
-	MintAsset(ctx context.Context, names []string, amounts []int32) error
-	GetAssetUTXOs(ctx context.Context, assetID string, amount int32) (*utxoasset.UnspentAssetResp, error)
-	TransferAsset(receiverPubKey []asset.SerializedKey, assetId string, amount []int32) error
+	MintAsset(ctx context.Context, names []string, amounts []uint64, assetType asset.Type, meta *asset.MetaReveal, grouped bool, feeRate onchain.SatPerKVByte) error
+	GetAssetUTXOs(ctx context.Context, assetID string, amount uint64) (*utxoasset.UnspentAssetResp, error)
+	TransferAsset(receiverPubKey []asset.SerializedKey, assetId string, amount []uint64, feeRate onchain.SatPerKVByte) error
+	TransferAssetToAddresses(addrs []*address.Tap, feeRate onchain.SatPerKVByte) error
+	BatchTransfer(ctx context.Context, recipients []*Recipient, feeRate onchain.SatPerKVByte) error
+	BatchTransferToAddresses(ctx context.Context, addrs []*address.Tap, feeRate onchain.SatPerKVByte) error
+	MergeAssets(ctx context.Context, assetID string, feeRate onchain.SatPerKVByte) error
+	FundTransfer(ctx context.Context, addrs []*address.Tap, feeRate onchain.SatPerKVByte) (*packet.Packet, error)
+	SignTransfer(ctx context.Context, p *packet.Packet) error
+	FinalizeTransfer(ctx context.Context, p *packet.Packet, maxFeeRate onchain.SatPerKVByte) (*transfer.TransferReq, error)
+	PublishTransfer(ctx context.Context, req *transfer.TransferReq) error
+	NewAddress(assetID string, amount uint64) (*address.Tap, error)
+	DecodeAddress(addr string) (*address.Tap, error)
+	ReceiveProof(ctx context.Context, assetID string, outPoint string) (*proof.AssetSnapshot, error)
+	VerifyUniverseLeaf(ctx context.Context, assetID string, treeType universe.TreeType, key universe.LeafKey) (*universe.Leaf, *universesdk.Node, error)
 }
 
 type Taproot struct {
 	logger       *zap.Logger
 	btcClient    onchain.Interface
-	wif          *btcutil.WIF
+	feeEstimator onchain.FeeEstimator
+	signer       Signer
+	walletKey    *btcec.PublicKey
 	addressMaker address.TapAddrMaker
 	httpClient   *resty.Client
+	proofCourier proof.ProofCourier
+	localProofs  proof.ProofCourier
 }
 
-func NewTaproot(btcClient onchain.Interface, wif *btcutil.WIF, addressMaker address.TapAddrMaker) Interface {
+// NewTaproot creates a client of the wallet whose key is held by the signer.
+func NewTaproot(btcClient onchain.Interface, signer Signer, addressMaker address.TapAddrMaker) (Interface, error) {
+	walletKey, err := signer.DeriveKey(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := resty.New()
+
+	// The node may have no fee estimate yet, e.g. on a fresh simnet.
+	feeEstimator := onchain.NewFallbackFeeEstimator(
+		btcClient, onchain.NewStaticFeeEstimator(DEFAULT_FEE_RATE),
+	)
+
 	return &Taproot{
 		logger:       zap.NewNop(),
 		btcClient:    btcClient,
-		wif:          wif,
+		feeEstimator: feeEstimator,
+		signer:       signer,
+		walletKey:    walletKey,
 		addressMaker: addressMaker,
-		httpClient:   resty.New(),
-	}
+		httpClient:   httpClient,
+		proofCourier: NewHTTPCourier(httpClient, os.Getenv("SERVER_BASE_URL")),
+		localProofs:  proof.NewFileCourier(DEFAULT_PROOF_DIR),
+	}, nil
 }
@@ -2,139 +2,162 @@ package taproot
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
-	"os"
 
-	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/wire"
-	"github.com/quocky/taproot-asset/taproot/http_model/transfer"
+	"github.com/quocky/taproot-asset/taproot/address"
 	utxoasset "github.com/quocky/taproot-asset/taproot/http_model/utxo_asset"
 	"github.com/quocky/taproot-asset/taproot/model/asset"
 	"github.com/quocky/taproot-asset/taproot/model/commitment"
 	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/quocky/taproot-asset/taproot/model/packet"
 	"github.com/quocky/taproot-asset/taproot/model/proof"
 	"github.com/quocky/taproot-asset/taproot/onchain"
-	"github.com/quocky/taproot-asset/taproot/utils"
 )
 
-func (t *Taproot) TransferAsset(receiverPubKey []asset.SerializedKey, assetId string, amount []int32) error {
-	ctx := context.Background()
+func (t *Taproot) TransferAsset(receiverPubKey []asset.SerializedKey, assetId string, amount []uint64, feeRate onchain.SatPerKVByte) error {
+	return t.transferAsset(assetId, amount, receiverPubKey, receiverPubKey, feeRate)
+}
+
+// TransferAssetToAddresses sends the assets requested by the given Taproot
+// Asset addresses. All addresses must request the same asset.
+func (t *Taproot) TransferAssetToAddresses(addrs []*address.Tap, feeRate onchain.SatPerKVByte) error {
+	if len(addrs) == 0 {
+		return errors.New("no addresses to transfer to")
+	}
 
 	var (
-		expectedAmount = int32(2*DEFAULT_OUTPUT_AMOUNT + DEFAULT_FEE)
+		assetID      = addrs[0].AssetID
+		amounts      = make([]uint64, len(addrs))
+		scriptKeys   = make([]asset.SerializedKey, len(addrs))
+		internalKeys = make([]asset.SerializedKey, len(addrs))
 	)
 
-	UTXOs, err := t.btcClient.ListUTXOs()
-	if err != nil {
-		return err
-	}
+	for idx, addr := range addrs {
+		if addr.AssetID != assetID {
+			return fmt.Errorf("address %d requests asset %x, expected %x", idx, addr.AssetID[:], assetID[:])
+		}
 
-	bestUTXOs, err := chooseBestUTXOs(UTXOs, expectedAmount)
-	if err != nil {
-		return err
+		amounts[idx] = addr.Amount
+		scriptKeys[idx] = addr.ScriptKey
+		internalKeys[idx] = addr.InternalKey
 	}
 
-	totalAmount := utils.CalcSum(amount)
+	return t.transferAsset(hex.EncodeToString(assetID[:]), amounts, scriptKeys, internalKeys, feeRate)
+}
 
-	assetUTXOs, err := t.GetAssetUTXOs(ctx, assetId, totalAmount)
+// transferAsset sends the given amounts of the asset to the given script keys,
+// each anchored in an output with the matching internal key. A zero fee rate
+// is estimated.
+func (t *Taproot) transferAsset(assetId string, amount []uint64,
+	receiverPubKey, receiverInternalKey []asset.SerializedKey,
+	feeRate onchain.SatPerKVByte) error {
+
+	ctx := context.Background()
+
+	if len(amount) != len(receiverPubKey) || len(amount) != len(receiverInternalKey) {
+		return errors.New("len amounts and receivers is different")
+	}
+
+	feeRate, err := t.resolveFeeRate(feeRate)
 	if err != nil {
 		return err
 	}
 
-	assetGenOutpoint, err := wire.NewOutPointFromString(assetUTXOs.GenesisPoint.PrevOut)
+	totalAmount, err := sumAmounts(amount)
 	if err != nil {
-		fmt.Println("wire.NewOutPointFromString(assetUTXOs.GenesisPoint.PrevOut) got error", err)
-
 		return err
 	}
 
-	transferAssets := prepareAssets(assetGenOutpoint, assetUTXOs.GenesisAsset.AssetName, amount, receiverPubKey)
-
-	returnAssets, err := t.createReturnAsset(assetGenOutpoint, assetUTXOs.GenesisAsset.AssetName, assetUTXOs, transferAssets)
+	assetUTXOs, err := t.GetAssetUTXOs(ctx, assetId, totalAmount)
 	if err != nil {
-		fmt.Println("t.createReturnAsset(assetGenOutpoint, assetUTXOs.GenesisAsset.AssetName, assetUTXOs, transferAssets) got error", err)
-
 		return err
 	}
 
-	log.Println("[Transfer Asset] Create return asset success!", returnAssets.Assets)
+	headerVerifier := proof.NewRPCHeaderVerifier(t.btcClient)
 
-	btcOutputInfos, _, err := t.prepareBtcOutputs(ctx, assetUTXOs, transferAssets, returnAssets.Assets)
+	splitCommitmentInputs, err := creatSplitCommitmentInputs(ctx, assetUTXOs, headerVerifier)
 	if err != nil {
-		fmt.Println("t.createTransferAddresses(ctx, unspentAssets, transferAssets),  err ", err)
-		return err
+		return fmt.Errorf("split commitment inputs: %w", err)
 	}
 
-	txIncludeOutPubKey, err := t.createTxOnChain(bestUTXOs, nil,
-		btcOutputInfos, btcutil.Amount(DEFAULT_FEE), true)
+	// All outputs keep the genesis of the spent asset, so the asset ID
+	// stays the same across transfers.
+	assetGenesis := splitCommitmentInputs[0].Asset.Genesis
+
+	transferAssets := prepareAssets(assetGenesis, amount, receiverPubKey)
+
+	returnAssets, err := t.createReturnAsset(assetGenesis, assetUTXOs, transferAssets)
 	if err != nil {
-		return err
+		return fmt.Errorf("return asset: %w", err)
 	}
 
-	files, err := createFiles(
-		assetUTXOs.InputFilesBytes,
-		btcOutputInfos,
-		txIncludeOutPubKey.Tx,
-	)
+	log.Println("[Transfer Asset] Create return asset success!", returnAssets.Assets)
+
+	activeIDs := map[asset.ID]struct{}{assetGenesis.ID(): {}}
+
+	passive, err := t.collectPassiveAssets(ctx, assetUTXOs.UnspentOutpoints, activeIDs, headerVerifier)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("files: ", files)
-
-	data := transfer.TransferReq{
-		GenesisAsset:     &assetUTXOs.GenesisAsset,
-		AnchorTx:         txIncludeOutPubKey.Tx,
-		AmtSats:          DEFAULT_OUTPUT_AMOUNT,
-		BtcOutputInfos:   btcOutputInfos,
-		UnspentOutpoints: assetUTXOs.UnspentOutpoints,
-		Files:            files,
-	}
+	returnAssets.Assets = append(returnAssets.Assets, passive.assets...)
 
-	postResp, err := t.httpClient.R().SetBody(data).Post(os.Getenv("SERVER_BASE_URL") + "/transfer-asset")
+	outputs, err := prepareOutputs(ctx, splitCommitmentInputs, transferAssets, receiverInternalKey,
+		asset.ToSerialized(t.walletKey), returnAssets.Assets)
 	if err != nil {
-		log.Println("t.httpClient.R().SetBody(data).Post(\"/transfer-asset\") got error", err)
-
-		return err
+		return fmt.Errorf("prepare outputs: %w", err)
 	}
 
-	log.Println("[Transfer Asset] Post transfer asset success!", postResp)
+	vPacket := &packet.VPacket{
+		Inputs:  append(newVInputs(splitCommitmentInputs, assetUTXOs.InputFilesBytes), passive.inputs...),
+		Outputs: outputs,
+	}
 
-	return nil
+	return t.anchorTransfer(ctx, vPacket, passive.spentWith(assetUTXOs.UnspentOutpoints), feeRate)
 }
 
+// createFiles appends the proofs of the new assets anchored in the transaction
+// to the proof files of the inputs they spend, see packet.VPacket.InputProofs.
+// The files are returned in the order of the outputs and their assets.
 func createFiles(
-	inputFilesBytes [][]byte, // TODO: nen doi thanh map ?
+	vPacket *packet.VPacket,
+	headerVerifier proof.HeaderVerifier,
 	btcOutputInfos []*onchain.BtcOutputInfo,
 	tx *wire.MsgTx,
 ) ([]*proof.File, error) {
-	curFiles := make([]*proof.File, len(btcOutputInfos))
+	curFiles := make([]*proof.File, 0, len(btcOutputInfos))
 
 	for i := range btcOutputInfos {
-		log.Println("btcOutputInfos[i].GetOutputAsset()[0].Amount", btcOutputInfos[i].GetOutputAsset()[0].Amount)
+		for _, newAsset := range btcOutputInfos[i].GetOutputAsset() {
+			inputFilesBytes, err := vPacket.InputProofs(newAsset)
+			if err != nil {
+				return nil, err
+			}
 
-		exclusionProofs, err := makeExclusionProofs(i, btcOutputInfos)
-		if err != nil {
-			log.Println("[createFiles] makeExclusionProofs fail", err)
+			exclusionProofs, err := makeExclusionProofs(i, newAsset, btcOutputInfos)
+			if err != nil {
+				log.Println("[createFiles] makeExclusionProofs fail", err)
 
-			return nil, err
-		}
+				return nil, err
+			}
 
-		curFile, _, err := proof.AppendTransition(inputFilesBytes, makeLocatorTransitionParams(
-			i, DEFAULT_RETURN_OUTPUT_INDEX,
-			tx, btcOutputInfos,
-			exclusionProofs,
-		))
-		if err != nil {
-			log.Println("proof.AppendTransition", err)
+			curFile, _, err := proof.AppendTransition(inputFilesBytes, makeLocatorTransitionParams(
+				i, DEFAULT_RETURN_OUTPUT_INDEX, newAsset,
+				tx, btcOutputInfos,
+				exclusionProofs,
+			), headerVerifier)
+			if err != nil {
+				log.Println("proof.AppendTransition", err)
 
-			return nil, err
-		}
+				return nil, err
+			}
 
-		curFiles[i] = curFile
+			curFiles = append(curFiles, curFile)
+		}
 	}
 
 	return curFiles, nil
@@ -142,12 +165,11 @@ func createFiles(
 
 func makeLocatorTransitionParams(
 	i, outIndex int,
+	newAsset *asset.Asset,
 	tx *wire.MsgTx,
 	btcOutputInfos []*onchain.BtcOutputInfo,
 	exclusionProofs []*proof.TaprootProof,
 ) *proof.TransitionParams {
-	fmt.Println("btcOutputInfos[i].AddrResult", btcOutputInfos[i].AddrResult.GetTapCommitment())
-
 	return &proof.TransitionParams{
 		BaseProofParams: proof.BaseProofParams{
 			Tx:              tx,
@@ -156,35 +178,24 @@ func makeLocatorTransitionParams(
 			TapCommitment:   btcOutputInfos[i].AddrResult.GetTapCommitment(),
 			ExclusionProofs: exclusionProofs,
 		},
-		NewAsset:             btcOutputInfos[i].OutputAsset[0].Copy(), // TODO:
+		NewAsset:             newAsset.Copy(),
 		RootOutputIndex:      uint32(outIndex),
 		RootInternalKey:      btcOutputInfos[outIndex].AddrResult.PubKey,
 		RootTaprootAssetTree: btcOutputInfos[outIndex].AddrResult.GetTapCommitment(),
 	}
 }
 
-func makeExclusionProofs(curID int, btcOutputInfos []*onchain.BtcOutputInfo) ([]*proof.TaprootProof, error) {
-	log.Println("makeExclusionProofs: ")
-	utils.PrintStruct(btcOutputInfos[0].OutputAsset[0])
-	utils.PrintStruct(btcOutputInfos[1].OutputAsset[0])
-
-	log.Println("----=-==makeExclusionProofs: ")
-	log.Println(btcOutputInfos[curID].GetOutputAsset()[0].Copy().PrevWitnesses[0].SplitCommitment)
-
-	curAsset := btcOutputInfos[curID].GetOutputAsset()[0].Copy()
-	//curAsset.PrevWitnesses[0].SplitCommitment = nil
-
-	fmt.Println("curAssetcurAssetcurAssetcurAssetcurAssetcurAssetcurAsset")
-	utils.PrintStruct(curAsset)
+// makeExclusionProofs proves that the asset isn't committed to in any output
+// but the one with the given index.
+func makeExclusionProofs(curID int, curAsset *asset.Asset,
+	btcOutputInfos []*onchain.BtcOutputInfo) ([]*proof.TaprootProof, error) {
 
-	exclusionProofs := make([]*proof.TaprootProof, 0)
+	exclusionProofs := make([]*proof.TaprootProof, 0, len(btcOutputInfos)-1)
 	for idx, exclusion := range btcOutputInfos {
 		if idx == curID {
 			continue
 		}
 
-		utils.PrintStruct(exclusion.OutputAsset[0])
-
 		_, commitmentProof, err := exclusion.GetAddrResult().GetTapCommitment().CreateProof(
 			curAsset.TapCommitmentKey(),
 			curAsset.AssetCommitmentKey(),
@@ -207,177 +218,159 @@ type returnAssetsResp struct {
 	Assets []*asset.Asset
 }
 
-func (t *Taproot) createReturnAsset(assetGenOutpoint *wire.OutPoint,
-	assetName string,
+func (t *Taproot) createReturnAsset(assetGenesis asset.Genesis,
 	assetUTXOs *utxoasset.UnspentAssetResp, transferAsset []*asset.Asset) (*returnAssetsResp, error) {
 
 	if len(assetUTXOs.UnspentOutpoints) == 0 || len(transferAsset) == 0 {
 		return nil, errors.New("createReturnAsset: assetUTXOs or transferAsset is empty")
 	}
 
-	passiveAssets, err := getPassiveAssets(assetUTXOs, transferAsset[0])
-	if err != nil {
+	inputAmounts := make([]uint64, len(assetUTXOs.UnspentOutpoints))
+	for idx, a := range assetUTXOs.UnspentOutpoints {
+		inputAmounts[idx] = a.Amount
+	}
 
+	totalAmount, err := sumAmounts(inputAmounts)
+	if err != nil {
+		return nil, err
 	}
-	totalAmount := int32(0)
-	transferAmount := int32(0)
-	for _, a := range assetUTXOs.UnspentOutpoints {
-		totalAmount += a.Amount
+
+	transferAmounts := make([]uint64, len(transferAsset))
+	for idx, a := range transferAsset {
+		transferAmounts[idx] = a.Amount
 	}
 
-	for _, a := range transferAsset {
-		transferAmount += a.Amount
+	transferAmount, err := sumAmounts(transferAmounts)
+	if err != nil {
+		return nil, err
 	}
 
-	if (totalAmount - transferAmount) < 0 {
-		return nil, errors.New("createReturnAsset: totalAmount - transferAmount < 0")
+	if totalAmount < transferAmount {
+		return nil, errors.New("createReturnAsset: totalAmount < transferAmount")
 	}
 
-	returnAsset := []*asset.Asset{asset.New(*assetGenOutpoint, assetName,
-		DEFAULT_RETURN_OUTPUT_INDEX, totalAmount-transferAmount,
-		asset.ToSerialized(t.wif.PrivKey.PubKey()), nil,
+	change := totalAmount - transferAmount
+	returnAsset := []*asset.Asset{asset.NewAsset(assetGenesis,
+		change, t.returnScriptKey(change), nil,
 	)}
-	returnAsset = append(returnAsset, passiveAssets...)
 
 	return &returnAssetsResp{
 		Assets: returnAsset,
 	}, nil
 }
 
-func getPassiveAssets(utxOs *utxoasset.UnspentAssetResp, transferAsset *asset.Asset) ([]*asset.Asset, error) {
-	activeAssetId := transferAsset.ID()
-	passiveAssets := make([]*asset.Asset, 0)
-	for _, u := range utxOs.UnspentOutpoints {
-		for _, a := range u.RelatedAnchorAssets {
-			var curAsset *asset.Asset
+// returnScriptKey returns the script key of the root asset of a split which
+// returns the given change to the wallet. Without change, the root asset
+// carries no value and is locked to the un-spendable NUMS key.
+func (t *Taproot) returnScriptKey(change uint64) asset.SerializedKey {
+	if change == 0 {
+		return asset.NUMSKey
+	}
 
-			err := json.Unmarshal([]byte(a), &curAsset)
-			if err != nil {
-				log.Println("json.Unmarshal([]byte(a), &curAsset) got error", err)
-				return nil, err
-			}
-			if curAsset.ID() != activeAssetId {
-				passiveAssets = append(passiveAssets, curAsset)
-			}
+	return asset.ToSerialized(t.walletKey)
+}
 
+// sumAmounts returns the sum of the given asset amounts, failing if the sum
+// overflows.
+func sumAmounts(amounts []uint64) (uint64, error) {
+	sum := uint64(0)
+	for _, amount := range amounts {
+		if err := mssmt.CheckSumOverflowUint64(sum, amount); err != nil {
+			return 0, err
 		}
+
+		sum += amount
 	}
 
-	return passiveAssets, nil
+	return sum, nil
 }
 
-func prepareAssets(assetGenOutpoint *wire.OutPoint,
-	assetName string, amount []int32,
+func prepareAssets(assetGenesis asset.Genesis, amount []uint64,
 	receiverPubKey []asset.SerializedKey,
 ) []*asset.Asset {
 
 	transferAsset := make([]*asset.Asset, len(amount))
 
 	for idx, a := range amount {
-		transferAsset[idx] = asset.New(*assetGenOutpoint, assetName,
-			DEFAULT_TRANSFER_OUTPUT_INDEX, a, receiverPubKey[idx], nil,
+		transferAsset[idx] = asset.NewAsset(assetGenesis, a,
+			receiverPubKey[idx], nil,
 		)
 	}
 
 	return transferAsset
 }
 
-func (t *Taproot) prepareBtcOutputs(
+// prepareOutputs splits the inputs between the transfer assets, each anchored
+// in an output with the matching internal key, and returns the outputs. The
+// root asset of the split is the first return asset, it is anchored in the
+// return output together with the other return assets. The root asset isn't
+// signed yet.
+func prepareOutputs(
 	ctx context.Context,
-	assetUTXOs *utxoasset.UnspentAssetResp,
+	splitCommitmentInputs []commitment.SplitCommitmentInput,
 	transferAsset []*asset.Asset,
+	transferInternalKeys []asset.SerializedKey,
+	returnInternalKey asset.SerializedKey,
 	returnAsset []*asset.Asset,
-) ([]*onchain.BtcOutputInfo, *commitment.SplitCommitment, error) {
-	var (
-		returnPubKey   = asset.ToSerialized(t.wif.PrivKey.PubKey())
-		btcOutputInfos = make([]*onchain.BtcOutputInfo, 0)
-	)
-
-	splitCommitment, err := createSplitCommitment(ctx, assetUTXOs, returnAsset[0], transferAsset) // returnAsset[0] is active asset
+) ([]*packet.VOutput, error) {
+	splitCommitment, err := createSplitCommitment(ctx, splitCommitmentInputs, returnAsset[0], transferAsset) // returnAsset[0] is active asset
 	if err != nil {
-		log.Println("[prepareBtcOutputs] createSplitCommitment(ctx, unspentAssets, returnAsset, transferAsset), err ", err)
-		return nil, nil, err
+		log.Println("[prepareOutputs] createSplitCommitment(ctx, unspentAssets, returnAsset, transferAsset), err ", err)
+		return nil, err
 	}
 
 	returnAsset[0] = splitCommitment.RootAsset
 
-	ca := classifyAsset(returnAsset)
-
-	returnAssetCommitments := createReturnAssetCommitments(ctx, ca)
-	tapReturnCommitment, err := commitment.NewTapCommitment(returnAssetCommitments...)
-
-	fmt.Println("tapReturnCommitment: ", tapReturnCommitment.TreeRoot.NodeHash(), tapReturnCommitment.TreeRoot.NodeSum())
-	utils.PrintStruct(tapReturnCommitment)
-
-	returnOutputInfo, err := t.addressMaker.CreateTapAddr(returnPubKey, tapReturnCommitment)
-	if err != nil {
-		return nil, nil, err
-	}
-	btcOutputInfos = append(btcOutputInfos, onchain.NewBtcOutputInfo(returnOutputInfo, DEFAULT_OUTPUT_AMOUNT, returnAsset...))
-
-	rootLocator := commitment.NewLocatorByAsset(returnAsset[0])
-
-	for locator, splitAsset := range splitCommitment.SplitAssets {
-		if locator == *rootLocator {
-			continue
-		}
-
-		log.Println("bdefore Copy: splitAsset.Asset.PrevWitnesses[0].SplitCommitment", splitAsset.Asset.PrevWitnesses[0].SplitCommitment)
-		splitAssetCopy := splitAsset.Asset.Copy()
-		splitAssetCopy.PrevWitnesses[0].SplitCommitment = nil
-
-		log.Println("after Copy: splitAsset.Asset.PrevWitnesses[0].SplitCommitment", splitAsset.Asset.PrevWitnesses[0].SplitCommitment)
-
-		transferCommitment, err := commitment.NewAssetCommitment(ctx, splitAssetCopy)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		tapTransferCommitment, err := commitment.NewTapCommitment(transferCommitment)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		fmt.Println("tapTransferCommitment: ", tapTransferCommitment.TreeRoot.NodeHash(), tapTransferCommitment.TreeRoot.NodeSum())
-		utils.PrintStruct(tapTransferCommitment)
+	outputs := []*packet.VOutput{{
+		InternalKey: returnInternalKey,
+		Assets:      returnAsset,
+	}}
 
-		transferOutputInfo, err := t.addressMaker.CreateTapAddr(splitAssetCopy.ScriptPubkey, tapTransferCommitment)
-		if err != nil {
-			return nil, nil, err
-		}
+	// The transfer outputs follow the return output in the same order as
+	// the transfer assets, see createSplitCommitment.
+	for idx, a := range transferAsset {
+		locator := commitment.NewLocatorByAsset(
+			uint32(DEFAULT_TRANSFER_OUTPUT_INDEX+idx), a,
+		)
+		splitAsset := splitCommitment.SplitAssets[*locator]
 
-		btcOutputInfos = append(btcOutputInfos, onchain.NewBtcOutputInfo(transferOutputInfo, DEFAULT_OUTPUT_AMOUNT, &splitAsset.Asset))
+		outputs = append(outputs, &packet.VOutput{
+			InternalKey: transferInternalKeys[idx],
+			Assets:      []*asset.Asset{&splitAsset.Asset},
+		})
 	}
 
-	return btcOutputInfos, splitCommitment, nil
+	return outputs, nil
 }
 
-func createReturnAssetCommitments(ctx context.Context, ca map[[32]byte][]*asset.Asset) []*commitment.AssetCommitment {
-
-	returnAssetCommitments := make([]*commitment.AssetCommitment, 0)
-	for _, assets := range ca {
-		if len(assets) == 0 {
-			continue
+// newOutputCommitment returns the commitment of an anchor output holding the
+// given assets. Split assets are committed to without their split commitment
+// witness, which refers back to the commitment of the root asset.
+func newOutputCommitment(ctx context.Context,
+	assets []*asset.Asset) (*commitment.TapCommitment, error) {
+
+	committedAssets := make([]*asset.Asset, len(assets))
+	for idx, a := range assets {
+		if a.HasSplitCommitmentWitness() {
+			a = a.Copy()
+			a.PrevWitnesses[0].SplitCommitment = nil
 		}
 
-		assetCommitments, err := commitment.NewAssetCommitment(ctx, assets...)
-		if err != nil {
-			log.Println("[createReturnAssetCommitments] commitment.NewAssetCommitment(ctx, assets...), err ", err)
-			return nil
-		}
+		committedAssets[idx] = a
+	}
 
-		returnAssetCommitments = append(returnAssetCommitments, assetCommitments)
+	assetCommitments, err := genAssetCommitments(ctx, committedAssets)
+	if err != nil {
+		return nil, err
 	}
 
-	return returnAssetCommitments
+	return commitment.NewTapCommitment(assetCommitments...)
 }
 
+// classifyAsset groups the assets by the asset commitment they belong to.
 func classifyAsset(returnAsset []*asset.Asset) map[[32]byte][]*asset.Asset {
 	ca := make(map[[32]byte][]*asset.Asset)
 	for _, a := range returnAsset {
-		if ca[a.AssetCommitmentKey()] == nil {
-			ca[a.AssetCommitmentKey()] = make([]*asset.Asset, 0)
-		}
 		ca[a.TapCommitmentKey()] = append(ca[a.TapCommitmentKey()], a)
 	}
 
@@ -385,59 +378,66 @@ func classifyAsset(returnAsset []*asset.Asset) map[[32]byte][]*asset.Asset {
 }
 
 func createSplitCommitment(ctx context.Context,
-	assetUTXOs *utxoasset.UnspentAssetResp,
+	splitCommitmentInput []commitment.SplitCommitmentInput,
 	returnAsset *asset.Asset, transferAsset []*asset.Asset,
 ) (*commitment.SplitCommitment, error) {
-	splitCommitmentInput, err := creatSplitCommitmentInputs(assetUTXOs)
-	if err != nil {
-		return nil, err
-	}
-
-	rootLocator := commitment.NewLocatorByAsset(returnAsset)
+	rootLocator := commitment.NewLocatorByAsset(
+		DEFAULT_RETURN_OUTPUT_INDEX, returnAsset,
+	)
 
 	externalLocators := make([]*commitment.SplitLocator, len(transferAsset))
 	for idx, a := range transferAsset {
-		externalLocators[idx] = commitment.NewLocatorByAsset(a)
-
+		externalLocators[idx] = commitment.NewLocatorByAsset(
+			uint32(DEFAULT_TRANSFER_OUTPUT_INDEX+idx), a,
+		)
 	}
 
 	return commitment.NewSplitCommitment(ctx, splitCommitmentInput, rootLocator, externalLocators...)
 }
 
+// creatSplitCommitmentInputs returns the assets spent by the transfer. Each
+// input proof file is verified and the asset is taken from its last snapshot,
+// so the PrevIDs of the transfer reference the exact asset state the proofs
+// commit to. The files have to prove the outpoints returned by the server, in
+// the same order.
 func creatSplitCommitmentInputs(
+	ctx context.Context,
 	assetUTXOs *utxoasset.UnspentAssetResp,
+	headerVerifier proof.HeaderVerifier,
 ) ([]commitment.SplitCommitmentInput, error) {
-	res := make([]commitment.SplitCommitmentInput, 0)
+	if len(assetUTXOs.InputFilesBytes) == 0 {
+		return nil, errors.New("creatSplitCommitmentInputs: no input proof files")
+	}
 
-	assetGenOutpoint, err := wire.NewOutPointFromString(assetUTXOs.GenesisPoint.PrevOut)
-	if err != nil {
-		return nil, err
+	if len(assetUTXOs.InputFilesBytes) != len(assetUTXOs.UnspentOutpoints) {
+		return nil, fmt.Errorf("creatSplitCommitmentInputs: got %d proof files for %d outpoints",
+			len(assetUTXOs.InputFilesBytes), len(assetUTXOs.UnspentOutpoints))
 	}
 
-	assetName := assetUTXOs.GenesisAsset.AssetName
+	res := make([]commitment.SplitCommitmentInput, 0, len(assetUTXOs.InputFilesBytes))
 
-	for _, input := range assetUTXOs.UnspentOutpoints {
-		inputOutPoint, err := wire.NewOutPointFromString(input.Outpoint)
-		if err != nil {
+	for idx, fileBytes := range assetUTXOs.InputFilesBytes {
+		var f proof.File
+		if err := f.Decode(fileBytes); err != nil {
 			return nil, err
 		}
 
-		curAsset := asset.New(*assetGenOutpoint,
-			assetName, inputOutPoint.Index, input.Amount,
-			asset.SerializedKey(input.ScriptKey),
-			nil,
-		)
+		snapshot, err := f.Verify(ctx, headerVerifier)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proof file of input %d: %w", idx, err)
+		}
+
+		unspentOutpoint := assetUTXOs.UnspentOutpoints[idx]
+		if snapshot.OutPoint.String() != unspentOutpoint.Outpoint ||
+			snapshot.Asset.Amount != unspentOutpoint.Amount {
 
-		if len(input.SplitCommitmentRootHash) != 0 {
-			curAsset.SplitCommitmentRoot = mssmt.NewComputedNode(
-				mssmt.NodeHash(input.SplitCommitmentRootHash),
-				uint64(input.SplitCommitmentRootValue),
-			)
+			return nil, fmt.Errorf("proof file of input %d doesn't prove outpoint %s",
+				idx, unspentOutpoint.Outpoint)
 		}
 
 		res = append(res, commitment.SplitCommitmentInput{
-			Asset:    curAsset,
-			OutPoint: *inputOutPoint,
+			Asset:    snapshot.Asset.Copy(),
+			OutPoint: snapshot.OutPoint,
 		})
 	}
 
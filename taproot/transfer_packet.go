@@ -0,0 +1,503 @@
+package taproot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/address"
+	"github.com/quocky/taproot-asset/taproot/http_model/transfer"
+	assetoutpointmodel "github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
+	"github.com/quocky/taproot-asset/taproot/model/commitment"
+	"github.com/quocky/taproot-asset/taproot/model/packet"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	"github.com/quocky/taproot-asset/taproot/onchain"
+	"go.uber.org/zap"
+)
+
+// maxFeeRateFactor is how much higher than the estimated fee rate the fee
+// rate of a transfer finalized without a maximum fee rate may be. It leaves
+// room for the estimate to move between funding and finalizing the transfer.
+const maxFeeRateFactor = 2
+
+var (
+	// ErrUnexpectedOutputs is returned when the anchor transaction of a
+	// transfer pays more than the asset anchor outputs and the change of
+	// the wallet.
+	ErrUnexpectedOutputs = errors.New("anchor tx has unexpected outputs")
+
+	// ErrFeeTooHigh is returned when the anchor transaction of a transfer
+	// pays a fee above the maximum fee rate.
+	ErrFeeTooHigh = errors.New("anchor tx fee too high")
+)
+
+// FundTransfer funds a batch transfer to the given Taproot Asset addresses,
+// see BatchTransfer, without signing it. The returned packet is signed with
+// SignTransfer by every holder of a key it spends, and then finalized and
+// published. A zero fee rate is estimated.
+func (t *Taproot) FundTransfer(ctx context.Context, addrs []*address.Tap,
+	feeRate onchain.SatPerKVByte) (*packet.Packet, error) {
+
+	feeRate, err := t.resolveFeeRate(feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	vPacket, spentOutpoints, err := t.batchPacket(ctx, addrRecipients(addrs))
+	if err != nil {
+		return nil, err
+	}
+
+	return t.fundPacket(ctx, vPacket, spentOutpoints, feeRate)
+}
+
+// SignTransfer signs the inputs of the transfer locked to the wallet key. The
+// assets are signed first. Once all of them are signed, the anchor outputs are
+// committed to the signed assets and the asset anchor inputs are signed, since
+// their signatures cover the outputs. The inputs paying the fee are signed by
+// the wallet when the transfer is finalized.
+func (t *Taproot) SignTransfer(ctx context.Context, p *packet.Packet) error {
+	signer, err := t.bindSigner(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	signed, err := p.Virtual.Sign(signer)
+	if err != nil {
+		return fmt.Errorf("sign virtual packet: %w", err)
+	}
+
+	if !p.Virtual.IsComplete() {
+		log.Println("[Sign Transfer] Signed", signed, "asset inputs, waiting for the other signers")
+
+		return nil
+	}
+
+	btcOutputInfos, err := t.commitOutputs(ctx, p.Virtual)
+	if err != nil {
+		return err
+	}
+
+	txOuts, err := btcOutputs(btcOutputInfos)
+	if err != nil {
+		return err
+	}
+
+	pkScripts := make([][]byte, len(txOuts))
+	for idx, txOut := range txOuts {
+		pkScripts[idx] = txOut.PkScript
+	}
+
+	if err := packet.SetAnchorOutputs(p.Anchor, pkScripts); err != nil {
+		return err
+	}
+
+	anchorSigned, err := packet.SignAnchorInputs(p.Anchor, signer)
+	if err != nil {
+		return fmt.Errorf("sign anchor: %w", err)
+	}
+
+	log.Println("[Sign Transfer] Signed", signed, "asset inputs and", anchorSigned, "anchor inputs")
+
+	return nil
+}
+
+// FinalizeTransfer checks that the assets and the asset anchor inputs of the
+// transfer are signed, lets the wallet sign the inputs paying the fee and
+// returns the transfer with the final anchor transaction and the new proofs.
+// The outputs after the asset anchor outputs may only be a change output of
+// the wallet, and the fee may not exceed the maximum fee rate, which is
+// maxFeeRateFactor times the estimated fee rate if zero.
+func (t *Taproot) FinalizeTransfer(ctx context.Context, p *packet.Packet,
+	maxFeeRate onchain.SatPerKVByte) (*transfer.TransferReq, error) {
+
+	if err := p.Virtual.Verify(); err != nil {
+		return nil, fmt.Errorf("virtual packet: %w", err)
+	}
+
+	btcOutputInfos, err := t.commitOutputs(ctx, p.Virtual)
+	if err != nil {
+		return nil, err
+	}
+
+	txOuts, err := btcOutputs(btcOutputInfos)
+	if err != nil {
+		return nil, err
+	}
+
+	anchorOuts := p.Anchor.UnsignedTx.TxOut
+	if len(anchorOuts) < len(txOuts) || psbt.VerifyOutputsEqual(anchorOuts[:len(txOuts)], txOuts) != nil {
+		return nil, errors.New("anchor outputs don't commit to the signed assets")
+	}
+
+	if maxFeeRate == 0 {
+		estimated, err := t.resolveFeeRate(0)
+		if err != nil {
+			return nil, err
+		}
+
+		maxFeeRate = estimated * maxFeeRateFactor
+	}
+
+	if err := t.checkWalletOutputs(p.Anchor, len(txOuts), maxFeeRate); err != nil {
+		return nil, err
+	}
+
+	for idx := range p.Anchor.Inputs {
+		if !packet.IsAnchorInput(&p.Anchor.Inputs[idx]) {
+			continue
+		}
+
+		if len(p.Anchor.Inputs[idx].TaprootKeySpendSig) == 0 {
+			return nil, fmt.Errorf("anchor input %d isn't signed", idx)
+		}
+
+		if err := psbt.Finalize(p.Anchor, idx); err != nil {
+			return nil, fmt.Errorf("finalize anchor input %d: %w", idx, err)
+		}
+	}
+
+	// The wallet signs its own inputs on a copy of the transaction, so it
+	// can't touch the witnesses of the asset anchor inputs.
+	walletTx, err := t.btcClient.SignRawTx(p.Anchor.UnsignedTx.Copy())
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range p.Anchor.Inputs {
+		pInput := &p.Anchor.Inputs[idx]
+		if packet.IsAnchorInput(pInput) {
+			continue
+		}
+
+		txIn := walletTx.TxIn[idx]
+		if len(txIn.SignatureScript) == 0 && len(txIn.Witness) == 0 {
+			return nil, fmt.Errorf("wallet didn't sign input %d", idx)
+		}
+
+		if len(txIn.SignatureScript) > 0 {
+			pInput.FinalScriptSig = txIn.SignatureScript
+		}
+
+		if len(txIn.Witness) > 0 {
+			var witness bytes.Buffer
+			if err := psbt.WriteTxWitness(&witness, txIn.Witness); err != nil {
+				return nil, err
+			}
+
+			pInput.FinalScriptWitness = witness.Bytes()
+		}
+	}
+
+	anchorTx, err := psbt.Extract(p.Anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := createFiles(
+		p.Virtual,
+		proof.NewRPCHeaderVerifier(t.btcClient),
+		btcOutputInfos,
+		anchorTx,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer.TransferReq{
+		AnchorTx:         anchorTx,
+		AmtSats:          anchorOutputValue,
+		BtcOutputInfos:   btcOutputInfos,
+		UnspentOutpoints: p.SpentOutpoints,
+		Files:            files,
+	}, nil
+}
+
+// PublishTransfer posts the finalized transfer to the server, which broadcasts
+// its anchor transaction.
+func (t *Taproot) PublishTransfer(ctx context.Context, req *transfer.TransferReq) error {
+	postResp, err := t.httpClient.R().SetContext(ctx).SetBody(req).Post(os.Getenv("SERVER_BASE_URL") + "/transfer-asset")
+	if err != nil {
+		log.Println("t.httpClient.R().SetBody(data).Post(\"/transfer-asset\") got error", err)
+
+		return err
+	}
+
+	if postResp.IsError() {
+		return fmt.Errorf("publish transfer failed: %s", postResp.String())
+	}
+
+	log.Println("[Transfer Asset] Post transfer asset success!", postResp)
+
+	return nil
+}
+
+// anchorTransfer funds, signs, finalizes and publishes the transfer of the
+// virtual packet with the wallet key.
+func (t *Taproot) anchorTransfer(
+	ctx context.Context,
+	vPacket *packet.VPacket,
+	spentOutpoints []*assetoutpointmodel.UnspentOutpoint,
+	feeRate onchain.SatPerKVByte,
+) error {
+	p, err := t.fundPacket(ctx, vPacket, spentOutpoints, feeRate)
+	if err != nil {
+		return err
+	}
+
+	if err := t.SignTransfer(ctx, p); err != nil {
+		return err
+	}
+
+	req, err := t.FinalizeTransfer(ctx, p, feeRate)
+	if err != nil {
+		return err
+	}
+
+	return t.PublishTransfer(ctx, req)
+}
+
+// checkWalletOutputs checks the change and the fee of the anchor transaction
+// before the wallet signs its inputs, see checkChangeAndFee. The inputs which
+// don't spend asset anchor outputs have to spend wallet UTXOs with the value
+// and script given in the PSBT, the fee is computed from them.
+func (t *Taproot) checkWalletOutputs(anchor *psbt.Packet, numAnchorOuts int,
+	maxFeeRate onchain.SatPerKVByte) error {
+
+	utxos, err := t.btcClient.ListUTXOs()
+	if err != nil {
+		return err
+	}
+
+	walletUTXOs := make(map[wire.OutPoint]*onchain.UnspentTXOut, len(utxos))
+	for _, utxo := range utxos {
+		walletUTXOs[*utxo.Outpoint] = utxo
+	}
+
+	for idx := range anchor.Inputs {
+		pInput := &anchor.Inputs[idx]
+		if packet.IsAnchorInput(pInput) {
+			continue
+		}
+
+		utxo, ok := walletUTXOs[anchor.UnsignedTx.TxIn[idx].PreviousOutPoint]
+		if !ok || pInput.WitnessUtxo == nil ||
+			pInput.WitnessUtxo.Value != int64(utxo.Amount) ||
+			!bytes.Equal(pInput.WitnessUtxo.PkScript, utxo.LockScript) {
+
+			return fmt.Errorf("input %d doesn't spend a wallet utxo", idx)
+		}
+	}
+
+	senderBtcAddr, err := t.btcClient.GetSenderAddress()
+	if err != nil {
+		return err
+	}
+
+	changeScript, err := txscript.PayToAddrScript(senderBtcAddr)
+	if err != nil {
+		return err
+	}
+
+	return checkChangeAndFee(anchor, numAnchorOuts, changeScript, maxFeeRate)
+}
+
+// checkChangeAndFee checks that the anchor transaction pays at most one output
+// after its asset anchor outputs, the change to the change script, and that its
+// fee doesn't exceed the maximum fee rate. Without a change output the fee may
+// be higher by the cost of change, as a leftover below it goes to the fee.
+func checkChangeAndFee(anchor *psbt.Packet, numAnchorOuts int,
+	changeScript []byte, maxFeeRate onchain.SatPerKVByte) error {
+
+	tx := anchor.UnsignedTx
+
+	walletOuts := tx.TxOut[numAnchorOuts:]
+	switch {
+	case len(walletOuts) > 1:
+		return fmt.Errorf("%w: %d outputs after the asset anchor outputs",
+			ErrUnexpectedOutputs, len(walletOuts))
+
+	case len(walletOuts) == 1 && !bytes.Equal(walletOuts[0].PkScript, changeScript):
+		return fmt.Errorf("%w: output %d isn't the change of the wallet",
+			ErrUnexpectedOutputs, numAnchorOuts)
+	}
+
+	var (
+		inputAmount  btcutil.Amount
+		outputAmount btcutil.Amount
+		prevScripts  = make([][]byte, len(tx.TxIn))
+	)
+
+	for idx := range anchor.Inputs {
+		prevOut := anchor.Inputs[idx].WitnessUtxo
+		if prevOut == nil {
+			return fmt.Errorf("input %d has no previous output", idx)
+		}
+
+		inputAmount += btcutil.Amount(prevOut.Value)
+		prevScripts[idx] = prevOut.PkScript
+	}
+
+	for _, txOut := range tx.TxOut {
+		outputAmount += btcutil.Amount(txOut.Value)
+	}
+
+	if outputAmount > inputAmount {
+		return fmt.Errorf("outputs %v exceed inputs %v", outputAmount, inputAmount)
+	}
+
+	vsize, err := onchain.EstimateVSize(tx, prevScripts)
+	if err != nil {
+		return err
+	}
+
+	maxFee := maxFeeRate.FeeForVSize(vsize)
+	if len(walletOuts) == 0 {
+		maxFee += onchain.CostOfChange(maxFeeRate, changeScript)
+	}
+
+	if fee := inputAmount - outputAmount; fee > maxFee {
+		return fmt.Errorf("%w: fee %v exceeds %v at %v", ErrFeeTooHigh,
+			fee, maxFee, maxFeeRate)
+	}
+
+	return nil
+}
+
+// fundPacket creates the anchor PSBT of the virtual packet. It spends the
+// anchor outputs of the spent asset outpoints and the wallet UTXOs paying the
+// fee, and pays the asset anchor outputs followed by the change of the wallet.
+// The anchor output scripts commit to the unsigned assets until the packet is
+// signed, they keep their size so the fee stays the same.
+func (t *Taproot) fundPacket(
+	ctx context.Context,
+	vPacket *packet.VPacket,
+	spentOutpoints []*assetoutpointmodel.UnspentOutpoint,
+	feeRate onchain.SatPerKVByte,
+) (*packet.Packet, error) {
+	btcOutputInfos, err := t.commitOutputs(ctx, vPacket)
+	if err != nil {
+		return nil, err
+	}
+
+	txOuts, err := btcOutputs(btcOutputInfos)
+	if err != nil {
+		return nil, err
+	}
+
+	bestUTXOs, err := t.selectUTXOs(spentOutpoints, txOuts, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	unspentAssets, err := makeUnspentAssetsByIdResult(spentOutpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	senderBtcAddr, err := t.btcClient.GetSenderAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	txMaker, err := t.btcClient.NewTxMaker(bestUTXOs, unspentAssets, btcOutputInfos, senderBtcAddr, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := txMaker.CreateTemplateTx(); err != nil {
+		return nil, err
+	}
+
+	t.logger.Debug("[fund packet] Create template tx success!",
+		zap.Stringer("fee_rate", feeRate), zap.Int64("fee", int64(txMaker.Fee)))
+
+	anchor, err := psbt.NewFromUnsignedTx(txMaker.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The asset anchor inputs come first, followed by the wallet UTXOs.
+	for idx, unspent := range unspentAssets {
+		pInput, err := packet.NewAnchorInput(
+			wire.NewTxOut(unspent.AmtSats, unspent.ScriptOutput),
+			unspent.InternalKey, unspent.TaprootAssetRoot,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("anchor input %s: %w", unspent.Outpoint, err)
+		}
+
+		anchor.Inputs[idx] = pInput
+	}
+
+	for idx, utxo := range bestUTXOs {
+		anchor.Inputs[len(unspentAssets)+idx].WitnessUtxo = wire.NewTxOut(
+			int64(utxo.Amount), utxo.LockScript,
+		)
+	}
+
+	for idx, outputInfo := range btcOutputInfos {
+		anchor.Outputs[idx].TaprootInternalKey = outputInfo.GetAddrResult().PubKey.SchnorrSerialized()
+	}
+
+	// The server only needs to know which outpoints are spent, the anchor
+	// and proof data is in the packet already.
+	spent := make([]*assetoutpointmodel.UnspentOutpoint, len(spentOutpoints))
+	for idx, uo := range spentOutpoints {
+		spentCopy := *uo
+		spentCopy.Proof = nil
+		spentCopy.RelatedAnchorAssets = nil
+		spentCopy.RelatedAnchorAssetProofs = nil
+
+		spent[idx] = &spentCopy
+	}
+
+	return &packet.Packet{
+		Anchor:         anchor,
+		Virtual:        vPacket,
+		SpentOutpoints: spent,
+	}, nil
+}
+
+// commitOutputs returns the anchor outputs of the virtual packet, each
+// committing to its assets under its internal key.
+func (t *Taproot) commitOutputs(ctx context.Context,
+	vPacket *packet.VPacket) ([]*onchain.BtcOutputInfo, error) {
+
+	btcOutputInfos := make([]*onchain.BtcOutputInfo, len(vPacket.Outputs))
+	for idx, output := range vPacket.Outputs {
+		tapCommitment, err := newOutputCommitment(ctx, output.Assets)
+		if err != nil {
+			return nil, fmt.Errorf("commit output %d: %w", idx, err)
+		}
+
+		outputInfo, err := t.addressMaker.CreateTapAddr(output.InternalKey, tapCommitment)
+		if err != nil {
+			return nil, err
+		}
+
+		btcOutputInfos[idx] = onchain.NewBtcOutputInfo(outputInfo, anchorOutputValue, output.Assets...)
+	}
+
+	return btcOutputInfos, nil
+}
+
+// newVInputs returns the virtual inputs spending the given asset inputs, whose
+// proof files are in the same order.
+func newVInputs(inputs []commitment.SplitCommitmentInput,
+	files [][]byte) []*packet.VInput {
+
+	vInputs := make([]*packet.VInput, len(inputs))
+	for idx, input := range inputs {
+		vInputs[idx] = packet.NewVInput(input.Asset, input.OutPoint, files[idx])
+	}
+
+	return vInputs
+}
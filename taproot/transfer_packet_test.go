@@ -0,0 +1,99 @@
+package taproot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/onchain"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckChangeAndFee makes sure an anchor transaction is only finalized if
+// it pays nothing but the asset anchor outputs and the change of the wallet,
+// at a fee rate up to the maximum.
+func TestCheckChangeAndFee(t *testing.T) {
+	var (
+		anchorScript = append([]byte{0x51, 0x20}, bytes.Repeat([]byte{1}, 32)...)
+		changeScript = append([]byte{0x00, 0x14}, bytes.Repeat([]byte{2}, 20)...)
+		otherScript  = append([]byte{0x00, 0x14}, bytes.Repeat([]byte{3}, 20)...)
+		maxFeeRate   = onchain.SatPerVByte(10)
+	)
+
+	// newAnchor returns the anchor of a transfer spending a wallet UTXO of
+	// 100000 sats to an asset anchor output and the given outputs.
+	newAnchor := func(t *testing.T, txOuts ...*wire.TxOut) *psbt.Packet {
+		tx := wire.NewMsgTx(2)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{1}}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(1000, anchorScript))
+		for _, txOut := range txOuts {
+			tx.AddTxOut(txOut)
+		}
+
+		anchor, err := psbt.NewFromUnsignedTx(tx)
+		require.NoError(t, err)
+
+		anchor.Inputs[0].WitnessUtxo = wire.NewTxOut(100000, changeScript)
+
+		return anchor
+	}
+
+	testCases := []struct {
+		name   string
+		txOuts []*wire.TxOut
+		err    error
+	}{{
+		name:   "change",
+		txOuts: []*wire.TxOut{wire.NewTxOut(97500, changeScript)},
+	}, {
+		name: "no change",
+		err:  ErrFeeTooHigh,
+	}, {
+		name:   "fee too high",
+		txOuts: []*wire.TxOut{wire.NewTxOut(90000, changeScript)},
+		err:    ErrFeeTooHigh,
+	}, {
+		name:   "change to another script",
+		txOuts: []*wire.TxOut{wire.NewTxOut(97500, otherScript)},
+		err:    ErrUnexpectedOutputs,
+	}, {
+		name: "output after the change",
+		txOuts: []*wire.TxOut{
+			wire.NewTxOut(90000, changeScript),
+			wire.NewTxOut(7000, changeScript),
+		},
+		err: ErrUnexpectedOutputs,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			anchor := newAnchor(t, testCase.txOuts...)
+
+			err := checkChangeAndFee(anchor, 1, changeScript, maxFeeRate)
+			require.ErrorIs(t, err, testCase.err)
+		})
+	}
+
+	// A leftover too small for a change output is left to the fee.
+	vsize, err := onchain.EstimateVSize(
+		newAnchor(t).UnsignedTx, [][]byte{changeScript},
+	)
+	require.NoError(t, err)
+
+	maxFee := maxFeeRate.FeeForVSize(vsize) +
+		onchain.CostOfChange(maxFeeRate, changeScript)
+
+	anchor := newAnchor(t)
+	anchor.UnsignedTx.TxOut[0].Value = 100000 - int64(maxFee)
+	require.NoError(t, checkChangeAndFee(anchor, 1, changeScript, maxFeeRate))
+
+	anchor.UnsignedTx.TxOut[0].Value--
+	require.ErrorIs(t,
+		checkChangeAndFee(anchor, 1, changeScript, maxFeeRate), ErrFeeTooHigh,
+	)
+
+	anchor.Inputs[0].WitnessUtxo = nil
+	require.Error(t, checkChangeAndFee(anchor, 1, changeScript, maxFeeRate))
+}
@@ -1,21 +1,26 @@
 package taproot
 
 import (
-	"github.com/btcsuite/btcd/btcutil"
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	assetoutpointmodel "github.com/quocky/taproot-asset/taproot/model/asset_outpoint"
 	"github.com/quocky/taproot-asset/taproot/onchain"
+	"go.uber.org/zap"
 )
 
-type GetUnspentAssetsByIdResult struct {
-}
-
 func (t *Taproot) createTxOnChain(
+	ctx context.Context,
 	UTXOs []*onchain.UnspentTXOut,
-	unspentAssets *GetUnspentAssetsByIdResult, // TODO:
+	unspentOutpoints []*assetoutpointmodel.UnspentOutpoint,
 	outputInfos []*onchain.BtcOutputInfo,
-	fee btcutil.Amount,
+	feeRate onchain.SatPerKVByte,
 	isMint bool,
 ) (*onchain.TxIncludeOutPubKey, error) {
-	unspentAssetsOnChains, err := makeUnspentAssetsByIdResult(unspentAssets)
+	unspentAssetsOnChains, err := makeUnspentAssetsByIdResult(unspentOutpoints)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +34,7 @@ func (t *Taproot) createTxOnChain(
 		UTXOs, unspentAssetsOnChains,
 		outputInfos,
 		senderBtcAddr,
-		fee,
+		feeRate,
 	)
 	if err != nil {
 		return nil, err
@@ -40,54 +45,130 @@ func (t *Taproot) createTxOnChain(
 		return nil, err
 	}
 
+	t.logger.Debug("[prepare tx] Create template tx success!",
+		zap.Stringer("fee_rate", feeRate), zap.Int64("fee", int64(txMaker.Fee)))
+
 	//err = txMaker.AddRevealData(isMint)
 	//if err != nil {
 	//	log.Println("[prepare tx] cannot add reveal data, ", err)
 	//	return nil, err
 	//}
 
-	if err := txMaker.SignTaprootInput(t.wif.PrivKey); err != nil {
+	// The wallet signs its own inputs first, so it can't touch the
+	// witnesses of the asset anchor inputs. Since all signatures commit to
+	// the whole transaction, the order of signing doesn't matter otherwise.
+	finalTx, err := t.btcClient.SignRawTx(txMaker.Tx)
+	if err != nil {
 		return nil, err
 	}
 
-	finalTx, err := t.btcClient.SignRawTx(txMaker.Tx)
+	txMaker.Tx = finalTx
+
+	signer, err := t.bindSigner(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := txMaker.SignTaprootInput(signer); err != nil {
+		return nil, err
+	}
+
 	return &onchain.TxIncludeOutPubKey{
-		Tx:         finalTx,
+		Tx:         txMaker.Tx,
 		OutPubKeys: txMaker.OutputPubKeys,
 	}, nil
 }
 
+// resolveFeeRate returns the given fee rate, or the estimated fee rate if it
+// is zero.
+func (t *Taproot) resolveFeeRate(feeRate onchain.SatPerKVByte) (onchain.SatPerKVByte, error) {
+	if feeRate != 0 {
+		return max(feeRate, onchain.FeeRateFloor), nil
+	}
+
+	return t.feeEstimator.EstimateFeeRate(onchain.DefaultConfTarget)
+}
+
+// selectUTXOs selects the wallet UTXOs paying for a transaction which spends
+// the given asset outpoints and creates the given outputs at the fee rate.
+func (t *Taproot) selectUTXOs(
+	unspentOutpoints []*assetoutpointmodel.UnspentOutpoint,
+	outputs []*wire.TxOut,
+	feeRate onchain.SatPerKVByte,
+) ([]*onchain.UnspentTXOut, error) {
+	utxos, err := t.btcClient.ListUTXOs()
+	if err != nil {
+		return nil, err
+	}
+
+	unspentAssets, err := makeUnspentAssetsByIdResult(unspentOutpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	senderBtcAddr, err := t.btcClient.GetSenderAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	changeScript, err := txscript.PayToAddrScript(senderBtcAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return onchain.SelectUTXOs(utxos, unspentAssets, outputs, changeScript, feeRate)
+}
+
+// btcOutputs returns the outputs of the transaction paying to the outputs.
+func btcOutputs(outputInfos []*onchain.BtcOutputInfo) ([]*wire.TxOut, error) {
+	txOuts := make([]*wire.TxOut, len(outputInfos))
+	for i, output := range outputInfos {
+		pkScript, err := txscript.PayToAddrScript(output.AddrResult.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		txOuts[i] = wire.NewTxOut(int64(output.SatAmount), pkScript)
+	}
+
+	return txOuts, nil
+}
+
+// makeUnspentAssetsByIdResult returns the anchor outputs of the given asset
+// outpoints that have to be spent on chain. Assets anchored in the same output
+// share a single input.
 func makeUnspentAssetsByIdResult(
-	unspentAssets *GetUnspentAssetsByIdResult,
+	unspentOutpoints []*assetoutpointmodel.UnspentOutpoint,
 ) ([]*onchain.UnspentAssetsByIdResult, error) {
-	//if unspentAssets == nil {
-	//	return nil, nil
-	//}
-	//
-	//unspentAssetsOnchains := make([]*onchain.UnspentAssetsByIdResult, len(unspentAssets.UnspentOutpoints))
-	//for id, unspentOutpoint := range unspentAssets.UnspentOutpoints {
-	//
-	//	outpoint, err := wire.NewOutPointFromString(unspentOutpoint.Outpoint)
-	//	if err != nil {
-	//		log.Println("[makeUnspentAssetsByIdResult] wire.NewOutPointFromString, err", err)
-	//
-	//		return nil, err
-	//	}
-	//
-	//	unspentAssetsOnchains[id] = &onchain.UnspentAssetsByIdResult{
-	//		Outpoint:         outpoint,
-	//		AmtSats:          int64(unspentOutpoint.AmtSats),
-	//		ScriptOutput:     unspentOutpoint.ScriptOutput,
-	//		InternalKey:      unspentOutpoint.InternalKey,
-	//		TapCommitment: unspentOutpoint.TapCommitment,
-	//	}
-	//}
-	//
-	//return unspentAssetsOnchains, nil
+	var (
+		unspentAssetsOnChains = make([]*onchain.UnspentAssetsByIdResult, 0, len(unspentOutpoints))
+		seen                  = make(map[wire.OutPoint]struct{}, len(unspentOutpoints))
+	)
+
+	for _, unspentOutpoint := range unspentOutpoints {
+		outpoint, err := wire.NewOutPointFromString(unspentOutpoint.Outpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid anchor outpoint %s: %w", unspentOutpoint.Outpoint, err)
+		}
+
+		if _, ok := seen[*outpoint]; ok {
+			continue
+		}
+		seen[*outpoint] = struct{}{}
+
+		if len(unspentOutpoint.TaprootAssetRoot) != 32 {
+			return nil, fmt.Errorf("invalid tapscript root %s of anchor outpoint %s",
+				hex.EncodeToString(unspentOutpoint.TaprootAssetRoot), outpoint)
+		}
+
+		unspentAssetsOnChains = append(unspentAssetsOnChains, &onchain.UnspentAssetsByIdResult{
+			Outpoint:         outpoint,
+			AmtSats:          int64(unspentOutpoint.AmtSats),
+			ScriptOutput:     unspentOutpoint.ScriptOutput,
+			InternalKey:      unspentOutpoint.InternalKey,
+			TaprootAssetRoot: unspentOutpoint.TaprootAssetRoot,
+		})
+	}
 
-	return nil, nil
+	return unspentAssetsOnChains, nil
 }
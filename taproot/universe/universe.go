@@ -0,0 +1,188 @@
+package universe
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+)
+
+// TreeType is the type of a universe tree of an asset.
+type TreeType uint8
+
+const (
+	// IssuanceTree is the tree of the issuance proofs of an asset.
+	IssuanceTree TreeType = 0
+
+	// TransferTree is the tree of the transfer proofs of an asset.
+	TransferTree TreeType = 1
+)
+
+var (
+	// ErrUnknownTreeType is returned for a tree type this implementation
+	// does not understand.
+	ErrUnknownTreeType = errors.New("universe: unknown tree type")
+
+	// ErrLeafNotFound is returned when a universe tree has no leaf at the
+	// requested key.
+	ErrLeafNotFound = errors.New("universe: leaf not found")
+
+	// ErrInvalidInclusionProof is returned when a leaf isn't included in a
+	// universe tree under the claimed root.
+	ErrInvalidInclusionProof = errors.New("universe: invalid inclusion " +
+		"proof")
+)
+
+// String returns the name of the tree type.
+func (t TreeType) String() string {
+	switch t {
+	case IssuanceTree:
+		return "issuance"
+
+	case TransferTree:
+		return "transfer"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+// ParseTreeType parses the name of a tree type as returned by TreeType.String.
+func ParseTreeType(s string) (TreeType, error) {
+	switch strings.ToLower(s) {
+	case IssuanceTree.String():
+		return IssuanceTree, nil
+
+	case TransferTree.String():
+		return TransferTree, nil
+
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownTreeType, s)
+	}
+}
+
+// LeafKey identifies a leaf of a universe tree by the anchor outpoint and the
+// script key of the asset the leaf proves.
+type LeafKey struct {
+	// OutPoint is the anchor outpoint of the asset.
+	OutPoint wire.OutPoint
+
+	// ScriptKey is the script key of the asset.
+	ScriptKey asset.SerializedKey
+}
+
+// UniverseKey returns the key of the leaf within the MS-SMT:
+//
+//	sha256(txid || output_index || script_key)
+func (k LeafKey) UniverseKey() [32]byte {
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], k.OutPoint.Index)
+
+	h := sha256.New()
+	_, _ = h.Write(k.OutPoint.Hash[:])
+	_, _ = h.Write(index[:])
+	_, _ = h.Write(k.ScriptKey[:])
+
+	return *(*[32]byte)(h.Sum(nil))
+}
+
+// Leaf is a leaf of a universe tree. It commits to the proof file of an asset,
+// and sums up to the amount of the asset.
+type Leaf struct {
+	// Key is the key of the leaf.
+	Key LeafKey
+
+	// AssetID is the ID of the asset the leaf proves.
+	AssetID asset.ID
+
+	// Amount is the amount of the asset.
+	Amount uint64
+
+	// ProofFile is the encoded proof file of the asset.
+	ProofFile proof.Blob
+}
+
+// NewLeaf creates the leaf of the asset of the last proof of the proof file.
+func NewLeaf(f *proof.File) (*Leaf, error) {
+	lastProof, err := f.LastProof()
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := f.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Leaf{
+		Key: LeafKey{
+			OutPoint: wire.OutPoint{
+				Hash:  lastProof.AnchorTx.TxHash(),
+				Index: lastProof.InclusionProof.OutputIndex,
+			},
+			ScriptKey: lastProof.Asset.ScriptPubkey,
+		},
+		AssetID:   lastProof.Asset.ID(),
+		Amount:    lastProof.Asset.Amount,
+		ProofFile: blob,
+	}, nil
+}
+
+// SMTLeaf returns the MS-SMT leaf node of the leaf. The node holds the hash of
+// the proof file instead of the file itself, so the trees stay small however
+// long the proof chains get, and the files are kept in the proof archive.
+func (l *Leaf) SMTLeaf() *mssmt.LeafNode {
+	return mssmt.NewLeafNode(ProofFileHash(l.ProofFile), l.Amount)
+}
+
+// ProofFileHash returns the value of the MS-SMT leaf node of a leaf with the
+// proof file:
+//
+//	sha256(proof_file)
+func ProofFileHash(blob proof.Blob) []byte {
+	fileHash := sha256.Sum256(blob)
+
+	return fileHash[:]
+}
+
+// EncodeMerkleProof returns the encoding of the compressed merkle proof.
+func EncodeMerkleProof(merkleProof *mssmt.Proof) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := merkleProof.Compress().Encode(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeMerkleProof decodes a merkle proof encoded with EncodeMerkleProof.
+func DecodeMerkleProof(blob []byte) (*mssmt.Proof, error) {
+	var compressed mssmt.CompressedProof
+	if err := compressed.Decode(bytes.NewReader(blob)); err != nil {
+		return nil, err
+	}
+
+	return compressed.Decompress()
+}
+
+// VerifyInclusion checks that the leaf is included in the universe tree with
+// the given root.
+func VerifyInclusion(root mssmt.Node, leaf *Leaf,
+	merkleProof *mssmt.Proof) error {
+
+	if !mssmt.VerifyMerkleProof(
+		leaf.Key.UniverseKey(), leaf.SMTLeaf(), merkleProof, root,
+	) {
+
+		return ErrInvalidInclusionProof
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package universe
+
+import (
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/quocky/taproot-asset/taproot/model/asset"
+	"github.com/quocky/taproot-asset/taproot/model/mssmt"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInclusionProof makes sure an encoded inclusion proof of a leaf verifies
+// against the root of the tree, and doesn't verify for a modified leaf.
+func TestInclusionProof(t *testing.T) {
+	ctx := context.Background()
+	tree := mssmt.NewCompactedTree(mssmt.NewDefaultStore())
+
+	leaves := make([]*Leaf, 4)
+	for i := range leaves {
+		leaves[i] = &Leaf{
+			Key: LeafKey{
+				OutPoint:  wire.OutPoint{Index: uint32(i)},
+				ScriptKey: asset.SerializedKey{byte(i)},
+			},
+			Amount:    uint64(i + 1),
+			ProofFile: []byte{byte(i)},
+		}
+
+		_, err := tree.Insert(
+			ctx, leaves[i].Key.UniverseKey(), leaves[i].SMTLeaf(),
+		)
+		require.NoError(t, err)
+	}
+
+	root, err := tree.Root(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, root.NodeSum())
+
+	for _, leaf := range leaves {
+		merkleProof, err := tree.MerkleProof(ctx, leaf.Key.UniverseKey())
+		require.NoError(t, err)
+
+		proofBytes, err := EncodeMerkleProof(merkleProof)
+		require.NoError(t, err)
+
+		decoded, err := DecodeMerkleProof(proofBytes)
+		require.NoError(t, err)
+		require.NoError(t, VerifyInclusion(root, leaf, decoded))
+
+		modified := *leaf
+		modified.Amount++
+		require.ErrorIs(
+			t, VerifyInclusion(root, &modified, decoded),
+			ErrInvalidInclusionProof,
+		)
+
+		modified = *leaf
+		modified.ProofFile = []byte{0xff}
+		require.ErrorIs(
+			t, VerifyInclusion(root, &modified, decoded),
+			ErrInvalidInclusionProof,
+		)
+	}
+}
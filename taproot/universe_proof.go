@@ -0,0 +1,97 @@
+package taproot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	universesdk "github.com/quocky/taproot-asset/taproot/http_model/universe"
+	"github.com/quocky/taproot-asset/taproot/model/proof"
+	"github.com/quocky/taproot-asset/taproot/universe"
+)
+
+// VerifyUniverseLeaf fetches the leaf of the universe tree of the asset at the
+// given anchor outpoint and script key together with its inclusion proof. The
+// leaf is only returned if it is included under the root of the tree and its
+// proof file is valid and proves the asset of the leaf.
+func (t *Taproot) VerifyUniverseLeaf(ctx context.Context, assetID string,
+	treeType universe.TreeType, key universe.LeafKey) (*universe.Leaf,
+	*universesdk.Node, error) {
+
+	reqKey := universesdk.NewLeafKey(key)
+
+	resp, err := t.httpClient.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"outpoint":   reqKey.OutPoint,
+			"script_key": reqKey.ScriptKey,
+		}).
+		Get(fmt.Sprintf("%s/universe/proof/%s/%s",
+			os.Getenv("SERVER_BASE_URL"), assetID, treeType))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+
+	case http.StatusNotFound:
+		return nil, nil, universe.ErrLeafNotFound
+
+	default:
+		return nil, nil, fmt.Errorf("get universe proof failed with "+
+			"status %d", resp.StatusCode())
+	}
+
+	var inclusionProof universesdk.InclusionProofResp
+	if err := json.Unmarshal(resp.Body(), &inclusionProof); err != nil {
+		return nil, nil, err
+	}
+
+	leaf, err := inclusionProof.Leaf.UniverseLeaf()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if leaf.Key != key {
+		return nil, nil, errors.New("universe returned another leaf")
+	}
+
+	root, err := inclusionProof.Root.SMTNode()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merkleProof, err := universe.DecodeMerkleProof(inclusionProof.MerkleProof)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := universe.VerifyInclusion(root, leaf, merkleProof); err != nil {
+		return nil, nil, err
+	}
+
+	var file proof.File
+	if err := file.Decode(leaf.ProofFile); err != nil {
+		return nil, nil, err
+	}
+
+	snapshot, err := file.Verify(ctx, proof.NewRPCHeaderVerifier(t.btcClient))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid universe proof file: %w", err)
+	}
+
+	if snapshot.Asset.ID() != leaf.AssetID ||
+		snapshot.Asset.Amount != leaf.Amount ||
+		snapshot.Asset.ScriptPubkey != key.ScriptKey ||
+		snapshot.OutPoint != key.OutPoint {
+
+		return nil, nil, errors.New("universe proof file doesn't prove " +
+			"the leaf asset")
+	}
+
+	return leaf, &inclusionProof.Root, nil
+}